@@ -9,6 +9,7 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // embed the timezone database for the recurring todos, the docker image may not have it
 
 	"github.com/Nidal-Bakir/go-todo-backend/internal/appenv" // autoload .env with init function. Do not remove this line
 	"github.com/Nidal-Bakir/go-todo-backend/internal/logger"
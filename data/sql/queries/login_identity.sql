@@ -268,32 +268,67 @@ last_used_at = NOW()
 WHERE id = @id;
 
 
--- WITH new_user AS (
---     INSERT INTO users (username, first_name)
---     VALUES (concat('guest_', gen_random_uuid()), 'Guest')
---     RETURNING id
--- ),
--- new_identity AS (
---     INSERT INTO login_identity (user_id)
---     SELECT id FROM new_user
---     RETURNING id, user_id
--- ),
--- new_guest AS (
---     INSERT INTO guest_login_option (login_identity_id, device_id)
---     SELECT id, $1 FROM new_identity
---     RETURNING login_identity_id
--- ),
--- new_session AS (
---     INSERT INTO session (token, originated_from, used_installation, expires_at)
---     VALUES (
---         $2,                        -- token
---         (SELECT login_identity_id FROM new_guest),
---         $3,                        -- installation_id
---         NOW() + INTERVAL '30 days' -- expires
---     )
---     RETURNING token
--- )
--- SELECT token FROM new_session;
+-- name: LoginIdentityCreateNewUserAndGuestLoginIdentity :one
+WITH new_user AS (
+  INSERT INTO users (
+    username,
+    first_name
+  )
+  VALUES (
+    @user_username::text,
+    @user_first_name::text
+  )
+  RETURNING id AS user_id, username, profile_image, first_name, middle_name, last_name, created_at, updated_at, blocked_at, blocked_until, deleted_at, role_name
+),
+new_identity AS (
+  INSERT INTO login_identity (
+    user_id,
+    identity_type
+  )
+  VALUES (
+    (SELECT user_id FROM new_user),
+    'guest'
+  )
+  RETURNING id
+),
+new_guest_login_identity AS (
+  INSERT INTO guest_login_identity (
+    login_identity_id,
+    device_id
+  )
+  VALUES (
+    (SELECT id FROM new_identity),
+    @guest_device_id::text
+  )
+)
+SELECT u.user_id, u.username, u.profile_image, u.first_name, u.middle_name, u.last_name, u.created_at, u.updated_at, u.blocked_at, u.blocked_until, u.deleted_at, u.role_name, i.id AS new_login_identity_id FROM new_user AS u, new_identity AS i;
+
+
+-- name: LoginIdentityGetGuestLoginIdentityWithUser :one
+SELECT
+    li.id AS login_identity_id,
+    gli.id AS guest_login_identity_id,
+    gli.device_id AS guest_device_id,
+
+    u.id AS user_id,
+    u.username AS user_username,
+    u.profile_image AS user_profile_image,
+    u.first_name AS user_first_name,
+    u.middle_name AS user_middle_name,
+    u.last_name AS user_last_name,
+    u.created_at AS user_created_at,
+    u.updated_at AS user_updated_at,
+    u.blocked_at AS user_blocked_at,
+    u.blocked_until AS user_blocked_until,
+    u.role_name AS user_role_name
+FROM not_deleted_users AS u
+    JOIN active_login_identity AS li
+        ON u.id = li.user_id
+    JOIN active_guest_login_identity AS gli
+        ON li.id = gli.login_identity_id
+WHERE gli.device_id = @device_id::text
+    AND li.identity_type = 'guest'
+LIMIT 1;
 
 
 -- name: LoginIdentityCreateNewUserAndOIDCLoginIdentity :one
@@ -439,3 +474,158 @@ new_oidc_login_identity AS (
     )
 )
 SELECT u.user_id, u.username, u.profile_image, u.first_name, u.middle_name, u.last_name, u.created_at, u.updated_at, u.blocked_at, u.blocked_until, u.deleted_at, u.role_name, i.id AS new_login_identity_id FROM new_user AS u, new_identity AS i;
+
+
+-- name: LoginIdentityCreateOIDCLoginIdentityForUser :one
+WITH new_identity AS (
+  INSERT INTO login_identity (
+    user_id,
+    identity_type
+  )
+  VALUES (
+    @user_id::int,
+    'oidc'
+  )
+  RETURNING id
+),
+oauth_provider_record AS (
+    SELECT
+        @oauth_provider_name::text AS provider_name,
+        @oauth_provider_is_oidc_capable::bool AS is_oidc_capable
+),
+oauth_provider_record_merge_op AS (
+    MERGE INTO oauth_provider AS target
+    USING oauth_provider_record AS r
+    ON target.name = r.provider_name AND target.is_oidc_capable = r.is_oidc_capable
+    WHEN NOT MATCHED THEN
+        INSERT (name, is_oidc_capable)
+        VALUES (r.provider_name, r.is_oidc_capable)
+),
+oauth_connection_record AS (
+    SELECT
+        (SELECT provider_name from oauth_provider_record) AS provider_name,
+        @oauth_scopes::text[] AS scopes
+),
+oauth_connection_record_merge_op AS (
+    MERGE INTO oauth_connection AS target
+    USING oauth_connection_record AS r
+    ON target.provider_name = r.provider_name AND target.scopes = r.scopes
+    WHEN NOT MATCHED THEN
+        INSERT (provider_name, scopes)
+        VALUES (r.provider_name, r.scopes)
+    RETURNING target.*
+),
+oauth_connection_row AS (
+    SELECT id, provider_name, scopes, created_at, updated_at, deleted_at FROM oauth_connection_record_merge_op
+    UNION ALL
+    SELECT id, provider_name, scopes, created_at, updated_at, deleted_at from oauth_connection
+        WHERE provider_name = (SELECT provider_name from oauth_provider_record)
+            AND scopes = (SELECT scopes from oauth_connection_record)
+),
+new_oauth_integration AS (
+    INSERT INTO oauth_integration (
+        oauth_connection_id,
+        integration_type
+    )
+    VALUES (
+        (SELECT id FROM oauth_connection_row),
+        'user'
+    )
+    RETURNING id
+),
+new_oauth_token AS (
+	INSERT INTO oauth_token (
+	    oauth_integration_id,
+	    access_token,
+	    refresh_token,
+	    token_type,
+	    expires_at,
+	    issued_at
+	)
+	SELECT
+	    (SELECT id FROM new_oauth_integration),
+	    sqlc.narg(oauth_access_token)::text,
+	    sqlc.narg(oauth_refresh_token)::text,
+	    sqlc.narg(oauth_token_type)::text,
+	    @oauth_token_expires_at::timestamp,
+	    @oauth_token_issued_at::timestamp
+	WHERE (
+	    sqlc.narg(oauth_access_token)::text IS NOT NULL AND sqlc.narg(oauth_access_token)::text <> ''
+	) OR (
+	    sqlc.narg(oauth_refresh_token)::text IS NOT NULL AND sqlc.narg(oauth_refresh_token)::text <> ''
+	)
+),
+new_user_integration AS (
+    INSERT INTO user_integration (
+        oauth_integration_id,
+        user_id
+    )
+    VALUES (
+        (SELECT id FROM new_oauth_integration),
+        @user_id::int
+    )
+    RETURNING id
+),
+new_oidc_data AS (
+    INSERT INTO oidc_data (
+        provider_name,
+        sub,
+        email,
+        iss,
+        aud,
+        given_name,
+        family_name,
+        name,
+        picture
+    )
+    VALUES (
+        (SELECT provider_name from oauth_provider_record),
+        @oidc_sub::text,
+        sqlc.narg(oidc_email)::text,
+        @oidc_iss::text,
+        @oidc_aud::text,
+        sqlc.narg(oidc_given_name)::text,
+        sqlc.narg(oidc_family_name)::text,
+        sqlc.narg(oidc_name)::text,
+        sqlc.narg(oidc_picture)::text
+    )
+    RETURNING id
+),
+new_oidc_login_identity AS (
+    INSERT INTO oidc_login_identity (
+        login_identity_id,
+        oidc_data_id
+    )
+    VALUES (
+        (SELECT id FROM new_identity),
+        (SELECT id FROM new_oidc_data)
+    )
+)
+SELECT id AS new_login_identity_id FROM new_identity;
+
+
+-- name: LoginIdentityGetGuestLoginIdentityByUserId :one
+SELECT
+    li.id AS login_identity_id,
+    gli.id AS guest_login_identity_id,
+    gli.device_id AS guest_device_id
+FROM active_login_identity AS li
+    JOIN active_guest_login_identity AS gli
+        ON li.id = gli.login_identity_id
+WHERE li.user_id = @user_id::int
+    AND li.identity_type = 'guest'
+LIMIT 1;
+
+
+-- name: LoginIdentitySoftDeleteGuestLoginIdentity :exec
+WITH soft_deleted_guest_login_identity AS (
+    UPDATE guest_login_identity
+    SET deleted_at = NOW()
+    WHERE login_identity_id = @login_identity_id::int
+        AND deleted_at IS NULL
+)
+UPDATE login_identity
+SET deleted_at = NOW()
+WHERE id = @login_identity_id::int
+    AND identity_type = 'guest'
+    AND deleted_at IS NULL;
@@ -0,0 +1,77 @@
+-- name: MfaGetUserMfaStatus :one
+SELECT
+    COALESCE(u.mfa_enforced, FALSE)::bool AS mfa_enforced,
+    (t.confirmed_at IS NOT NULL)::bool AS totp_enabled
+FROM not_deleted_users AS u
+    LEFT JOIN user_totp AS t
+        ON u.id = t.user_id
+WHERE u.id = @user_id::int
+LIMIT 1;
+
+
+-- name: MfaGetTotpForUser :one
+SELECT *
+FROM user_totp
+WHERE user_id = @user_id::int
+LIMIT 1;
+
+
+-- name: MfaUpsertUnconfirmedTotpForUser :execrows
+INSERT INTO user_totp (
+    user_id,
+    secret
+)
+VALUES (
+    @user_id::int,
+    @secret::text
+)
+ON CONFLICT (user_id) DO UPDATE
+SET secret = EXCLUDED.secret,
+    confirmed_at = NULL,
+    last_used_step = NULL
+WHERE user_totp.confirmed_at IS NULL;
+
+
+-- name: MfaUseTotpStepForUser :execrows
+UPDATE user_totp
+SET last_used_step = @step::bigint
+WHERE user_id = @user_id::int
+    AND (
+        last_used_step IS NULL
+        OR last_used_step < @step::bigint
+    );
+
+
+-- name: MfaConfirmTotpForUser :exec
+UPDATE user_totp
+SET confirmed_at = NOW()
+WHERE user_id = @user_id::int
+    AND confirmed_at IS NULL;
+
+
+-- name: MfaDeleteTotpForUser :exec
+DELETE FROM user_totp
+WHERE user_id = @user_id::int;
+
+
+-- name: MfaCreateRecoveryCodesForUser :exec
+INSERT INTO user_mfa_recovery_code (
+    user_id,
+    code_hash
+)
+SELECT
+    @user_id::int,
+    unnest(@code_hashes::text[]);
+
+
+-- name: MfaDeleteRecoveryCodesForUser :exec
+DELETE FROM user_mfa_recovery_code
+WHERE user_id = @user_id::int;
+
+
+-- name: MfaUseRecoveryCodeForUser :execrows
+UPDATE user_mfa_recovery_code
+SET used_at = NOW()
+WHERE user_id = @user_id::int
+    AND code_hash = @code_hash::text
+    AND used_at IS NULL;
@@ -61,3 +61,15 @@ VALUES (
     (SELECT id FROM new_oauth_integration),
     @user_id::int
 );
+
+
+-- name: OauthCreateProvider :exec
+INSERT INTO oauth_provider (
+    name,
+    is_oidc_capable
+)
+VALUES (
+    @name::text,
+    @is_oidc_capable::bool
+)
+ON CONFLICT (name) DO NOTHING;
@@ -3,14 +3,36 @@ SELECT
     name,
     created_at,
     updated_at
-FROM permission;
+FROM permission
+WHERE deleted_at IS NULL
+ORDER BY name;
 
 -- name: PermGetAllRoles :many
 SELECT
     name,
     created_at,
     updated_at
-FROM role;
+FROM role
+WHERE deleted_at IS NULL
+ORDER BY name;
+
+-- name: PermGetRoleByName :one
+SELECT
+    name,
+    created_at,
+    updated_at
+FROM role
+WHERE name = $1
+    AND deleted_at IS NULL;
+
+-- name: PermGetPermissionByName :one
+SELECT
+    name,
+    created_at,
+    updated_at
+FROM permission
+WHERE name = $1
+    AND deleted_at IS NULL;
 
 -- name: PermGetRoleWithItsPermissions :many
 SELECT
@@ -19,11 +41,16 @@ SELECT
 FROM role AS r
     JOIN role_permission AS rp ON r.name = rp.role_name
     JOIN permission AS p ON p.name = rp.permission_name
-WHERE r.name = $1;
+WHERE r.name = $1
+    AND r.deleted_at IS NULL
+    AND p.deleted_at IS NULL;
 
 -- name: PermCreateNewPermission :one
 INSERT INTO permission(name)
-VALUES($1)
+VALUES($1) ON CONFLICT (name) DO
+UPDATE
+SET deleted_at = NULL
+WHERE permission.deleted_at IS NOT NULL
 RETURNING *;
 
 -- name: PermCreateNewPermissions :copyfrom
@@ -32,7 +59,10 @@ VALUES($1);
 
 -- name: PermCreateNewRole :one
 INSERT INTO role(name)
-VALUES($1)
+VALUES($1) ON CONFLICT (name) DO
+UPDATE
+SET deleted_at = NULL
+WHERE role.deleted_at IS NOT NULL
 RETURNING *;
 
 -- name: PermCreateNewRoles :copyfrom
@@ -41,23 +71,39 @@ VALUES($1);
 
 -- name: PermAddPermissionToRole :exec
 INSERT INTO role_permission(role_name, permission_name)
-VALUES($1, $2);
+VALUES($1, $2) ON CONFLICT (role_name, permission_name) DO NOTHING;
 
 -- name: PermAddPermissionsToRoles :copyfrom
 INSERT INTO role_permission(role_name, permission_name)
 VALUES($1, $2);
 
--- name: PermRemovePermissionFromRole :exec
+-- name: PermRemovePermissionFromRole :execrows
 DELETE FROM role_permission
 WHERE role_name = $1
     AND permission_name = $2;
 
--- name: PermSoftDeletePermission :exec
+-- name: PermRemovePermissionFromAllRoles :many
+DELETE FROM role_permission
+WHERE permission_name = $1
+RETURNING role_name;
+
+-- name: PermRemoveAllPermissionsFromRole :exec
+DELETE FROM role_permission
+WHERE role_name = $1;
+
+-- name: PermUnsetRoleForAllUsers :exec
+UPDATE users
+SET role_name = NULL
+WHERE role_name = $1;
+
+-- name: PermSoftDeletePermission :execrows
 UPDATE permission
 SET deleted_at = NOW()
-WHERE name = $1;
+WHERE name = $1
+    AND deleted_at IS NULL;
 
--- name: PermSoftDeleteRole :exec
+-- name: PermSoftDeleteRole :execrows
 UPDATE role
 SET deleted_at = NOW()
-WHERE name = $1;
+WHERE name = $1
+    AND deleted_at IS NULL;
@@ -33,4 +33,76 @@ SET deleted_at = NOW()
 FROM active_login_identity AS li
 WHERE
     s.originated_from = li.id
-    AND li.user_id    = $1;
\ No newline at end of file
+    AND li.user_id    = $1;
+
+
+-- name: SessionMoveActiveSessionsToLoginIdentity :exec
+UPDATE session
+SET originated_from = @to_login_identity_id::int
+WHERE originated_from = @from_login_identity_id::int
+    AND deleted_at IS NULL;
+
+
+-- name: SessionGetActiveSessionsForUser :many
+SELECT
+    s.id,
+    s.ip_address,
+    s.created_at,
+    s.expires_at,
+    s.last_active_at,
+    li.identity_type AS login_identity_type,
+    i.id AS installation_id,
+    i.device_os,
+    i.device_os_version,
+    i.device_manufacturer,
+    i.client_type,
+    i.app_version
+FROM active_session AS s
+    JOIN active_login_identity AS li ON s.originated_from = li.id
+    JOIN installation AS i ON s.used_installation = i.id
+WHERE li.user_id = @user_id::int
+ORDER BY s.last_active_at DESC;
+
+
+-- name: SessionGetActiveSessionForUser :one
+SELECT s.*
+FROM active_session AS s
+    JOIN active_login_identity AS li ON s.originated_from = li.id
+WHERE s.id = @session_id::int
+    AND li.user_id = @user_id::int
+LIMIT 1;
+
+
+-- name: SessionTouchLastActiveAt :exec
+UPDATE session
+SET last_active_at = NOW()
+WHERE id = @id::int
+    AND last_active_at < NOW() - INTERVAL '1 minute';
+
+
+-- name: SessionRotateToken :execrows
+UPDATE session
+SET token = @new_token::text,
+    expires_at = @expires_at::timestamptz
+WHERE id = @id::int
+    AND token = @old_token::text
+    AND expires_at > NOW()
+    AND deleted_at IS NULL;
+
+
+-- name: SessionAddUsedRefreshToken :exec
+INSERT INTO session_used_refresh_token (
+    token_hash,
+    session_id
+)
+VALUES (
+    @token_hash::text,
+    @session_id::int
+);
+
+
+-- name: SessionGetSessionIdByUsedRefreshToken :one
+SELECT session_id
+FROM session_used_refresh_token
+WHERE token_hash = @token_hash::text
+LIMIT 1;
@@ -1,15 +1,24 @@
--- name: TodoSoftDeleteTodoLinkedToUser :exec
+-- name: TodoSoftDeleteTodoLinkedToUser :execrows
 UPDATE todo
 SET deleted_at = NOW()
 WHERE id = $1
-    AND user_id = $2;
+    AND (
+        (list_id IS NULL AND user_id = $2)
+        OR list_id IN (
+            SELECT m.list_id
+            FROM active_todo_list_member AS m
+            WHERE m.user_id = $2
+                AND m.role IN ('editor', 'owner')
+        )
+    )
+    AND deleted_at IS NULL;
 
 
 -- name: TodoCreateTodo :one
 INSERT INTO
-	todo (title, body, status, user_id)
+	todo (title, body, status, user_id, due_at, remind_at, list_id, recurrence_rule, recurrence_timezone, recurrence_start)
 VALUES
-	($1, $2, $3, $4)
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 RETURNING
 	*;
 
@@ -17,20 +26,81 @@ RETURNING
 -- name: TodoGetTodoLinkedToUser :one
 SELECT * FROM todo
 WHERE id = $1
-    AND user_id = $2
+    AND (
+        (list_id IS NULL AND user_id = $2)
+        OR list_id IN (
+            SELECT m.list_id
+            FROM active_todo_list_member AS m
+            WHERE m.user_id = $2
+        )
+    )
     AND deleted_at IS NULL
 LIMIT 1;
 
 
+-- name: TodoGetTodoForWriteLinkedToUser :one
+SELECT * FROM todo
+WHERE id = $1
+    AND (
+        (list_id IS NULL AND user_id = $2)
+        OR list_id IN (
+            SELECT m.list_id
+            FROM active_todo_list_member AS m
+            WHERE m.user_id = $2
+                AND m.role IN ('editor', 'owner')
+        )
+    )
+    AND deleted_at IS NULL
+LIMIT 1
+FOR UPDATE;
+
+
 -- name: TodoUpdateTodo :one
 UPDATE todo
 SET
 	title = COALESCE(sqlc.narg ('title'), title),
 	body = COALESCE(sqlc.narg ('body'), body),
-	status = COALESCE(sqlc.narg ('status'), status)
+	status = COALESCE(sqlc.narg ('status'), status),
+	due_at = CASE
+		WHEN sqlc.arg ('clear_due_at')::BOOLEAN THEN NULL
+		ELSE COALESCE(sqlc.narg ('due_at'), due_at)
+	END,
+	remind_at = CASE
+		WHEN sqlc.arg ('clear_remind_at')::BOOLEAN THEN NULL
+		ELSE COALESCE(sqlc.narg ('remind_at'), remind_at)
+	END,
+	reminder_sent_at = CASE
+		WHEN sqlc.arg ('clear_remind_at')::BOOLEAN OR sqlc.narg ('remind_at') IS NOT NULL THEN NULL
+		ELSE reminder_sent_at
+	END,
+	reminder_attempts = CASE
+		WHEN sqlc.arg ('clear_remind_at')::BOOLEAN OR sqlc.narg ('remind_at') IS NOT NULL THEN 0
+		ELSE reminder_attempts
+	END,
+	list_id = CASE
+		WHEN sqlc.arg ('clear_list_id')::BOOLEAN THEN NULL
+		ELSE COALESCE(sqlc.narg ('list_id'), list_id)
+	END,
+	recurrence_rule = CASE
+		WHEN sqlc.arg ('clear_recurrence')::BOOLEAN THEN NULL
+		ELSE COALESCE(sqlc.narg ('recurrence_rule'), recurrence_rule)
+	END,
+	recurrence_timezone = COALESCE(sqlc.narg ('recurrence_timezone'), recurrence_timezone),
+	recurrence_start = CASE
+		WHEN sqlc.arg ('clear_recurrence')::BOOLEAN THEN NULL
+		ELSE COALESCE(sqlc.narg ('recurrence_start'), recurrence_start)
+	END
 WHERE
 	id = $1
-	AND user_id = $2
+    AND (
+        (list_id IS NULL AND user_id = $2)
+        OR list_id IN (
+            SELECT m.list_id
+            FROM active_todo_list_member AS m
+            WHERE m.user_id = $2
+                AND m.role IN ('editor', 'owner')
+        )
+    )
     AND deleted_at IS NULL
 RETURNING
 	*;
@@ -39,8 +109,262 @@ RETURNING
 SELECT
     *
 FROM todo
-WHERE user_id = $1
-   AND deleted_at IS NULL
-ORDER BY created_at DESC
-OFFSET $2
-LIMIT $3;
+WHERE (
+        (list_id IS NULL AND user_id = @user_id)
+        OR list_id IN (
+            SELECT m.list_id
+            FROM active_todo_list_member AS m
+            WHERE m.user_id = @user_id
+        )
+    )
+    AND deleted_at IS NULL
+    AND (
+        sqlc.narg('status')::TEXT IS NULL
+        OR status = sqlc.narg('status')
+    )
+    AND (
+        sqlc.narg('list_id')::INTEGER IS NULL
+        OR list_id = sqlc.narg('list_id')
+    )
+    AND (
+        sqlc.narg('query')::TEXT IS NULL
+        OR to_tsvector('simple', title || ' ' || body) @@ websearch_to_tsquery('simple', sqlc.narg('query'))
+    )
+    AND (
+        sqlc.narg('tag_ids')::INTEGER[] IS NULL
+        OR (
+            SELECT COUNT(*)
+            FROM todo_tag_link AS tl
+                JOIN todo_tag AS tt ON tt.id = tl.tag_id
+            WHERE tl.todo_id = todo.id
+                AND tt.user_id = @user_id
+                AND tl.tag_id = ANY(sqlc.narg('tag_ids')::INTEGER[])
+        ) >= CASE
+            WHEN sqlc.arg('tag_match_all')::BOOLEAN THEN cardinality(sqlc.narg('tag_ids')::INTEGER[])
+            ELSE 1
+        END
+    )
+    AND (
+        sqlc.narg('created_after')::TIMESTAMPTZ IS NULL
+        OR created_at >= sqlc.narg('created_after')
+    )
+    AND (
+        sqlc.narg('created_before')::TIMESTAMPTZ IS NULL
+        OR created_at <= sqlc.narg('created_before')
+    )
+    AND (
+        sqlc.narg('updated_after')::TIMESTAMPTZ IS NULL
+        OR updated_at >= sqlc.narg('updated_after')
+    )
+    AND (
+        sqlc.narg('updated_before')::TIMESTAMPTZ IS NULL
+        OR updated_at <= sqlc.narg('updated_before')
+    )
+    AND (
+        sqlc.narg('cursor_created_at')::TIMESTAMPTZ IS NULL
+        OR (
+            @sort::TEXT = 'created_at'
+            AND (created_at, id) > (sqlc.narg('cursor_created_at'), sqlc.narg('cursor_id')::INTEGER)
+        )
+        OR (
+            @sort::TEXT = '-created_at'
+            AND (created_at, id) < (sqlc.narg('cursor_created_at'), sqlc.narg('cursor_id')::INTEGER)
+        )
+    )
+ORDER BY
+    CASE WHEN @sort::TEXT = 'created_at' THEN created_at END ASC,
+    CASE WHEN @sort::TEXT = '-created_at' THEN created_at END DESC,
+    CASE WHEN @sort::TEXT = 'updated_at' THEN updated_at END ASC,
+    CASE WHEN @sort::TEXT = '-updated_at' THEN updated_at END DESC,
+    CASE WHEN @sort::TEXT = 'due_at' THEN due_at END ASC NULLS LAST,
+    CASE WHEN @sort::TEXT = '-due_at' THEN due_at END DESC NULLS LAST,
+    CASE WHEN @sort::TEXT = 'title' THEN title END ASC,
+    CASE WHEN @sort::TEXT = '-title' THEN title END DESC,
+    CASE WHEN @sort::TEXT = 'created_at' THEN id END ASC,
+    id DESC
+OFFSET @page_offset
+LIMIT @page_limit;
+
+-- name: TodoClaimDueReminders :many
+UPDATE todo
+SET
+    reminder_sent_at = NOW(),
+    reminder_attempts = reminder_attempts + 1
+WHERE id IN (
+        SELECT t.id
+        FROM todo AS t
+        WHERE t.remind_at <= NOW()
+            AND t.reminder_sent_at IS NULL
+            AND t.deleted_at IS NULL
+        ORDER BY t.remind_at
+        LIMIT $1
+        FOR UPDATE SKIP LOCKED
+    )
+RETURNING id, title, due_at, remind_at, user_id;
+
+-- name: TodoReleaseFailedReminder :exec
+UPDATE todo
+SET reminder_sent_at = NULL
+WHERE id = @id
+    AND reminder_attempts < @max_attempts::SMALLINT;
+
+-- name: TodoGetReminderTargetForUser :one
+SELECT
+    u.id AS user_id,
+    contact.email,
+    contact.phone,
+    inst.locale,
+    inst.timezone_offset_in_minutes
+FROM users AS u
+    LEFT JOIN LATERAL (
+        SELECT
+            pli.email,
+            pli.phone
+        FROM active_login_identity AS li
+            JOIN active_password_login_identity AS pli ON pli.login_identity_id = li.id
+        WHERE li.user_id = u.id
+        ORDER BY li.is_primary DESC NULLS LAST, li.last_used_at DESC
+        LIMIT 1
+    ) AS contact ON TRUE
+    LEFT JOIN LATERAL (
+        SELECT
+            i.locale,
+            i.timezone_offset_in_minutes
+        FROM not_deleted_installation AS i
+            JOIN active_session AS s ON s.id = i.attach_to
+            JOIN login_identity AS li ON li.id = s.originated_from
+        WHERE li.user_id = u.id
+        ORDER BY s.last_active_at DESC
+        LIMIT 1
+    ) AS inst ON TRUE
+WHERE u.id = $1
+    AND u.deleted_at IS NULL;
+
+-- name: TodoGetDeletedTodosForUser :many
+SELECT
+    *
+FROM todo
+WHERE (
+        (list_id IS NULL AND user_id = @user_id)
+        OR list_id IN (
+            SELECT m.list_id
+            FROM active_todo_list_member AS m
+            WHERE m.user_id = @user_id
+        )
+    )
+    AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC, id DESC
+OFFSET @page_offset
+LIMIT @page_limit;
+
+-- name: TodoGetDeletedTodosForUserAfterCursor :many
+SELECT
+    *
+FROM todo
+WHERE (
+        (list_id IS NULL AND user_id = @user_id)
+        OR list_id IN (
+            SELECT m.list_id
+            FROM active_todo_list_member AS m
+            WHERE m.user_id = @user_id
+        )
+    )
+    AND deleted_at IS NOT NULL
+    AND (
+        sqlc.narg('cursor_deleted_at')::TIMESTAMPTZ IS NULL
+        OR (deleted_at, id) < (sqlc.narg('cursor_deleted_at'), sqlc.narg('cursor_id')::INTEGER)
+    )
+ORDER BY deleted_at DESC, id DESC
+LIMIT @page_limit;
+
+-- name: TodoRestoreTodoLinkedToUser :one
+UPDATE todo
+SET deleted_at = NULL
+WHERE id = $1
+    AND (
+        (list_id IS NULL AND user_id = $2)
+        OR list_id IN (
+            SELECT m.list_id
+            FROM active_todo_list_member AS m
+            WHERE m.user_id = $2
+                AND m.role IN ('editor', 'owner')
+        )
+    )
+    AND deleted_at IS NOT NULL
+RETURNING *;
+
+-- name: TodoPurgeTodoLinkedToUser :execrows
+DELETE FROM todo
+WHERE id = $1
+    AND (
+        (list_id IS NULL AND user_id = $2)
+        OR list_id IN (
+            SELECT m.list_id
+            FROM active_todo_list_member AS m
+            WHERE m.user_id = $2
+                AND m.role IN ('editor', 'owner')
+        )
+    )
+    AND deleted_at IS NOT NULL;
+
+-- name: TodoPurgeDeletedTodos :execrows
+DELETE FROM todo
+WHERE id IN (
+        SELECT t.id
+        FROM todo AS t
+        WHERE t.deleted_at < @deleted_before
+        LIMIT @batch_size
+    );
+
+-- name: TodoMoveTodosOfList :many
+UPDATE todo
+SET list_id = sqlc.narg('to_list_id')
+WHERE list_id = @from_list_id
+    AND deleted_at IS NULL
+RETURNING id;
+
+-- name: TodoSoftDeleteTodosOfList :many
+UPDATE todo
+SET deleted_at = NOW(),
+    list_id = NULL
+WHERE list_id = @list_id
+    AND deleted_at IS NULL
+RETURNING id;
+
+
+-- name: TodoClearRecurrence :one
+UPDATE todo
+SET
+    recurrence_rule = NULL,
+    recurrence_start = NULL
+WHERE id = $1
+RETURNING *;
+
+-- name: TodoChangeStatusOfUserTodos :many
+UPDATE todo
+SET status = @to_status
+WHERE user_id = @user_id
+    AND status = @from_status
+RETURNING id;
+
+-- name: TodoTouchTodo :exec
+UPDATE todo
+SET updated_at = NOW()
+WHERE id = $1;
+
+-- name: TodoTouchTodosOfTag :exec
+UPDATE todo
+SET updated_at = NOW()
+WHERE id IN (
+        SELECT l.todo_id
+        FROM todo_tag_link AS l
+            JOIN todo_tag AS t ON t.id = l.tag_id
+        WHERE l.tag_id = @tag_id
+            AND t.user_id = @user_id
+    );
+
+-- name: TodoTouchTodosOfUserWithStatuses :exec
+UPDATE todo
+SET updated_at = NOW()
+WHERE user_id = @user_id
+    AND status = ANY(@statuses::TEXT[]);
@@ -0,0 +1,55 @@
+-- name: TodoActivityCreateActivity :exec
+INSERT INTO
+    todo_activity (todo_id, user_id, action, changes)
+VALUES
+    ($1, $2, $3, $4);
+
+-- name: TodoActivityCreateActivities :exec
+INSERT INTO
+    todo_activity (todo_id, user_id, action, changes)
+SELECT
+    unnest(@todo_ids::INTEGER[]),
+    @user_id::INTEGER,
+    @action::TEXT,
+    @changes::JSONB;
+
+-- name: TodoActivityGetActivities :many
+SELECT
+    a.id,
+    a.todo_id,
+    a.user_id,
+    a.action,
+    a.changes,
+    a.created_at,
+    u.username,
+    u.first_name,
+    u.last_name,
+    u.profile_image
+FROM todo_activity AS a
+    LEFT JOIN not_deleted_users AS u ON u.id = a.user_id
+WHERE a.todo_id = @todo_id
+ORDER BY a.created_at DESC, a.id DESC
+OFFSET @page_offset
+LIMIT @page_limit;
+
+-- name: TodoActivityGetActivitiesAfterCursor :many
+SELECT
+    a.id,
+    a.todo_id,
+    a.user_id,
+    a.action,
+    a.changes,
+    a.created_at,
+    u.username,
+    u.first_name,
+    u.last_name,
+    u.profile_image
+FROM todo_activity AS a
+    LEFT JOIN not_deleted_users AS u ON u.id = a.user_id
+WHERE a.todo_id = @todo_id
+    AND (
+        sqlc.narg('cursor_created_at')::TIMESTAMPTZ IS NULL
+        OR (a.created_at, a.id) < (sqlc.narg('cursor_created_at'), sqlc.narg('cursor_id')::INTEGER)
+    )
+ORDER BY a.created_at DESC, a.id DESC
+LIMIT @page_limit;
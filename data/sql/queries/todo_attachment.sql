@@ -0,0 +1,45 @@
+-- name: TodoAttachmentGetAttachments :many
+SELECT
+    *
+FROM todo_attachment
+WHERE todo_id = $1
+ORDER BY created_at, id;
+
+-- name: TodoAttachmentGetAttachment :one
+SELECT
+    *
+FROM todo_attachment
+WHERE id = $1
+    AND todo_id = $2;
+
+-- name: TodoAttachmentCountAttachments :one
+SELECT
+    COUNT(*)
+FROM todo_attachment
+WHERE todo_id = $1;
+
+-- name: TodoAttachmentCreateAttachment :one
+INSERT INTO
+    todo_attachment (todo_id, user_id, storage_key, filename, content_type, size_bytes)
+VALUES
+    ($1, $2, $3, $4, $5, $6)
+RETURNING
+    *;
+
+-- name: TodoAttachmentDeleteAttachment :one
+DELETE FROM todo_attachment
+WHERE id = $1
+    AND todo_id = $2
+RETURNING
+    *;
+
+-- name: TodoAttachmentGetDeletedBlobs :many
+SELECT
+    storage_key
+FROM todo_attachment_deleted_blob
+ORDER BY deleted_at
+LIMIT $1;
+
+-- name: TodoAttachmentDeleteDeletedBlob :exec
+DELETE FROM todo_attachment_deleted_blob
+WHERE storage_key = $1;
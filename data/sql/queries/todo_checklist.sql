@@ -0,0 +1,82 @@
+-- name: TodoChecklistGetItems :many
+SELECT
+    *
+FROM todo_checklist_item
+WHERE todo_id = $1
+ORDER BY position, id;
+
+-- name: TodoChecklistCreateItem :one
+INSERT INTO
+    todo_checklist_item (todo_id, text, position)
+VALUES
+    (
+        @todo_id,
+        @text,
+        COALESCE(
+            sqlc.narg('position')::INTEGER,
+            (
+                SELECT COALESCE(MAX(i.position) + 1, 0)
+                FROM todo_checklist_item AS i
+                WHERE i.todo_id = @todo_id
+            )
+        )
+    )
+RETURNING
+    *;
+
+-- name: TodoChecklistGetItemForUpdate :one
+SELECT
+    *
+FROM todo_checklist_item
+WHERE id = $1
+    AND todo_id = $2
+FOR UPDATE;
+
+-- name: TodoChecklistUpdateItem :one
+UPDATE todo_checklist_item
+SET
+    text = COALESCE(sqlc.narg('text'), text),
+    is_done = COALESCE(sqlc.narg('is_done'), is_done),
+    position = COALESCE(sqlc.narg('position'), position)
+WHERE
+    id = $1
+    AND todo_id = $2
+RETURNING
+    *;
+
+-- name: TodoChecklistReorderItems :execrows
+UPDATE todo_checklist_item AS i
+SET position = o.position::INTEGER
+FROM unnest(@item_ids::INTEGER[]) WITH ORDINALITY AS o(id, position)
+WHERE i.id = o.id
+    AND i.todo_id = @todo_id;
+
+-- name: TodoChecklistDeleteItem :one
+DELETE FROM todo_checklist_item
+WHERE id = $1
+    AND todo_id = $2
+RETURNING
+    *;
+
+-- name: TodoChecklistCompleteAllItems :execrows
+UPDATE todo_checklist_item
+SET is_done = TRUE
+WHERE todo_id = $1
+    AND is_done = FALSE;
+
+-- name: TodoChecklistCopyItems :exec
+INSERT INTO
+    todo_checklist_item (todo_id, text, position)
+SELECT
+    @to_todo_id, text, position
+FROM todo_checklist_item
+WHERE todo_id = @from_todo_id;
+
+-- name: TodoChecklistGetProgressOfTodos :many
+SELECT
+    todo_id,
+    COUNT(*) AS total,
+    COUNT(*) FILTER (WHERE is_done) AS done
+FROM todo_checklist_item
+WHERE todo_id = ANY(@todo_ids::INTEGER[])
+GROUP BY todo_id;
@@ -0,0 +1,78 @@
+-- name: TodoCommentGetComments :many
+SELECT
+    c.id,
+    c.todo_id,
+    c.user_id,
+    c.body,
+    c.created_at,
+    c.updated_at,
+    u.username,
+    u.first_name,
+    u.last_name,
+    u.profile_image
+FROM todo_comment AS c
+    LEFT JOIN not_deleted_users AS u ON u.id = c.user_id
+WHERE c.todo_id = @todo_id
+ORDER BY c.created_at, c.id
+OFFSET @page_offset
+LIMIT @page_limit;
+
+-- name: TodoCommentGetCommentsAfterCursor :many
+SELECT
+    c.id,
+    c.todo_id,
+    c.user_id,
+    c.body,
+    c.created_at,
+    c.updated_at,
+    u.username,
+    u.first_name,
+    u.last_name,
+    u.profile_image
+FROM todo_comment AS c
+    LEFT JOIN not_deleted_users AS u ON u.id = c.user_id
+WHERE c.todo_id = @todo_id
+    AND (
+        sqlc.narg('cursor_created_at')::TIMESTAMPTZ IS NULL
+        OR (c.created_at, c.id) > (sqlc.narg('cursor_created_at'), sqlc.narg('cursor_id')::INTEGER)
+    )
+ORDER BY c.created_at, c.id
+LIMIT @page_limit;
+
+-- name: TodoCommentGetComment :one
+SELECT
+    c.id,
+    c.todo_id,
+    c.user_id,
+    c.body,
+    c.created_at,
+    c.updated_at,
+    u.username,
+    u.first_name,
+    u.last_name,
+    u.profile_image
+FROM todo_comment AS c
+    LEFT JOIN not_deleted_users AS u ON u.id = c.user_id
+WHERE c.id = @id
+    AND c.todo_id = @todo_id;
+
+-- name: TodoCommentCreateComment :one
+INSERT INTO
+    todo_comment (todo_id, user_id, body)
+VALUES
+    ($1, $2, $3)
+RETURNING
+    id;
+
+-- name: TodoCommentUpdateComment :execrows
+UPDATE todo_comment
+SET body = @body
+WHERE id = @id
+    AND todo_id = @todo_id
+    AND user_id = @user_id;
+
+-- name: TodoCommentDeleteComment :execrows
+DELETE FROM todo_comment
+WHERE id = $1
+    AND todo_id = $2
+    AND user_id = $3;
@@ -0,0 +1,181 @@
+-- name: TodoListCreateList :one
+INSERT INTO
+    todo_list (user_id, name, color, position)
+VALUES
+    ($1, $2, $3, $4)
+RETURNING
+    *;
+
+-- name: TodoListGetListsForUser :many
+SELECT
+    sqlc.embed(l),
+    m.role
+FROM todo_list AS l
+    JOIN todo_list_member AS m ON m.list_id = l.id
+WHERE m.user_id = @user_id
+    AND l.deleted_at IS NULL
+    AND (
+        @include_archived::BOOLEAN
+        OR l.is_archived = FALSE
+    )
+ORDER BY l.position, l.id;
+
+-- name: TodoListGetListLinkedToUser :one
+SELECT
+    sqlc.embed(l),
+    m.role
+FROM todo_list AS l
+    JOIN todo_list_member AS m ON m.list_id = l.id
+WHERE l.id = $1
+    AND m.user_id = $2
+    AND l.deleted_at IS NULL;
+
+-- name: TodoListUpdateList :one
+UPDATE todo_list
+SET
+    name = COALESCE(sqlc.narg('name'), name),
+    color = CASE
+        WHEN sqlc.arg('clear_color')::BOOLEAN THEN NULL
+        ELSE COALESCE(sqlc.narg('color'), color)
+    END,
+    position = COALESCE(sqlc.narg('position'), position),
+    is_archived = COALESCE(sqlc.narg('is_archived'), is_archived)
+WHERE
+    id = $1
+    AND id IN (
+        SELECT m.list_id
+        FROM todo_list_member AS m
+        WHERE m.user_id = $2
+            AND m.role = 'owner'
+    )
+    AND deleted_at IS NULL
+RETURNING
+    *;
+
+-- name: TodoListSoftDeleteList :execrows
+UPDATE todo_list
+SET deleted_at = NOW()
+WHERE id = $1
+    AND id IN (
+        SELECT m.list_id
+        FROM todo_list_member AS m
+        WHERE m.user_id = $2
+            AND m.role = 'owner'
+    )
+    AND deleted_at IS NULL;
+
+-- name: TodoListAddMember :exec
+INSERT INTO
+    todo_list_member (list_id, user_id, role)
+VALUES
+    ($1, $2, $3)
+ON CONFLICT (list_id, user_id) DO NOTHING;
+
+-- name: TodoListGetMembers :many
+SELECT
+    m.user_id,
+    u.username,
+    u.first_name,
+    u.last_name,
+    u.profile_image,
+    m.role,
+    m.created_at
+FROM active_todo_list_member AS m
+    JOIN not_deleted_users AS u ON u.id = m.user_id
+WHERE m.list_id = $1
+ORDER BY m.created_at, m.user_id;
+
+-- name: TodoListGetOwnersForUpdate :many
+SELECT
+    user_id
+FROM todo_list_member
+WHERE list_id = $1
+    AND role = 'owner'
+FOR UPDATE;
+
+-- name: TodoListUpdateMemberRole :execrows
+UPDATE todo_list_member
+SET role = $3
+WHERE list_id = $1
+    AND user_id = $2;
+
+-- name: TodoListRemoveMember :execrows
+DELETE FROM todo_list_member
+WHERE list_id = $1
+    AND user_id = $2;
+
+-- name: TodoListFindInvitee :one
+SELECT
+    u.id,
+    u.username
+FROM not_deleted_users AS u
+    LEFT JOIN (
+        active_login_identity AS li
+        JOIN active_password_login_identity AS pli ON pli.login_identity_id = li.id
+    ) ON li.user_id = u.id
+    AND pli.email = sqlc.narg('email')
+WHERE u.username = sqlc.narg('username')
+    OR pli.email IS NOT NULL
+ORDER BY pli.email IS NULL, u.id
+LIMIT 1;
+
+-- name: TodoListCreateInvitation :one
+INSERT INTO
+    todo_list_invitation (list_id, inviter_id, invitee_id, role)
+VALUES
+    ($1, $2, $3, $4)
+ON CONFLICT (list_id, invitee_id) WHERE status = 'pending' DO UPDATE
+SET inviter_id = EXCLUDED.inviter_id,
+    role = EXCLUDED.role
+RETURNING
+    *;
+
+-- name: TodoListGetPendingInvitationsOfList :many
+SELECT
+    sqlc.embed(i),
+    l.name AS list_name,
+    inviter.username AS inviter_username,
+    invitee.username AS invitee_username
+FROM todo_list_invitation AS i
+    JOIN todo_list AS l ON l.id = i.list_id
+    JOIN users AS inviter ON inviter.id = i.inviter_id
+    JOIN users AS invitee ON invitee.id = i.invitee_id
+WHERE i.list_id = $1
+    AND i.status = 'pending'
+ORDER BY i.created_at DESC, i.id DESC;
+
+-- name: TodoListGetPendingInvitationsForUser :many
+SELECT
+    sqlc.embed(i),
+    l.name AS list_name,
+    inviter.username AS inviter_username,
+    invitee.username AS invitee_username
+FROM todo_list_invitation AS i
+    JOIN todo_list AS l ON l.id = i.list_id
+    JOIN users AS inviter ON inviter.id = i.inviter_id
+    JOIN users AS invitee ON invitee.id = i.invitee_id
+WHERE i.invitee_id = $1
+    AND i.status = 'pending'
+    AND l.deleted_at IS NULL
+ORDER BY i.created_at DESC, i.id DESC;
+
+-- name: TodoListRespondToInvitation :one
+UPDATE todo_list_invitation
+SET status = @status,
+    responded_at = NOW()
+WHERE id = @id
+    AND invitee_id = @invitee_id
+    AND status = 'pending'
+    AND list_id IN (
+        SELECT l.id
+        FROM todo_list AS l
+        WHERE l.deleted_at IS NULL
+    )
+RETURNING
+    *;
+
+-- name: TodoListDeleteInvitation :execrows
+DELETE FROM todo_list_invitation
+WHERE id = $1
+    AND list_id = $2
+    AND status = 'pending';
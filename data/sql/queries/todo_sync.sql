@@ -0,0 +1,92 @@
+-- name: TodoSyncGetHorizon :one
+SELECT pg_snapshot_xmin(pg_current_snapshot())::TEXT::BIGINT AS horizon;
+
+-- name: TodoSyncGetChanges :many
+SELECT
+    c.todo_id,
+    c.xid,
+    c.changed_at,
+    c.is_tombstone
+FROM (
+        SELECT
+            t.id AS todo_id,
+            sc.xid,
+            t.updated_at AS changed_at,
+            FALSE AS is_tombstone
+        FROM todo AS t
+            JOIN todo_sync_change AS sc ON sc.todo_id = t.id
+        WHERE (
+                (t.list_id IS NULL AND t.user_id = @user_id)
+                OR t.list_id IN (
+                    SELECT m.list_id
+                    FROM active_todo_list_member AS m
+                    WHERE m.user_id = @user_id
+                )
+            )
+        UNION ALL
+        SELECT
+            s.todo_id,
+            s.xid,
+            s.created_at AS changed_at,
+            TRUE AS is_tombstone
+        FROM todo_sync_tombstone AS s
+        WHERE (
+                (s.list_id IS NULL AND s.user_id = @user_id)
+                OR s.list_id IN (
+                    SELECT m.list_id
+                    FROM active_todo_list_member AS m
+                    WHERE m.user_id = @user_id
+                )
+            )
+    ) AS c
+WHERE (c.xid, c.todo_id) > (@since_xid::BIGINT, @since_id::INTEGER)
+    AND c.xid < @horizon::BIGINT
+ORDER BY c.xid, c.todo_id
+LIMIT @page_limit;
+
+-- name: TodoSyncGetTodos :many
+SELECT
+    *
+FROM todo
+WHERE id = ANY(@todo_ids::INTEGER[])
+    AND (
+        (list_id IS NULL AND user_id = @user_id)
+        OR list_id IN (
+            SELECT m.list_id
+            FROM active_todo_list_member AS m
+            WHERE m.user_id = @user_id
+        )
+    );
+
+-- name: TodoSyncGetListIdsOfUser :many
+SELECT
+    list_id
+FROM active_todo_list_member
+WHERE user_id = $1
+ORDER BY list_id;
+
+-- name: TodoSyncPurgeTombstones :one
+WITH purged AS (
+    DELETE FROM todo_sync_tombstone
+    WHERE id IN (
+            SELECT s.id
+            FROM todo_sync_tombstone AS s
+            WHERE s.created_at < @created_before
+            LIMIT @batch_size
+        )
+    RETURNING todo_id
+),
+purged_activity AS (
+    DELETE FROM todo_activity
+    WHERE todo_id IN (
+            SELECT p.todo_id
+            FROM purged AS p
+        )
+        AND NOT EXISTS (
+            SELECT 1
+            FROM todo AS t
+            WHERE t.id = todo_activity.todo_id
+        )
+)
+SELECT COUNT(*)
+FROM purged;
@@ -0,0 +1,82 @@
+-- name: TodoTagGetTagsForUser :many
+SELECT *
+FROM todo_tag
+WHERE user_id = $1
+ORDER BY lower(name), id;
+
+-- name: TodoTagCreateTag :one
+INSERT INTO
+    todo_tag (user_id, name, color)
+VALUES
+    ($1, $2, $3)
+RETURNING
+    *;
+
+-- name: TodoTagUpdateTag :one
+UPDATE todo_tag
+SET
+    name = COALESCE(sqlc.narg('name'), name),
+    color = CASE
+        WHEN sqlc.arg('clear_color')::BOOLEAN THEN NULL
+        ELSE COALESCE(sqlc.narg('color'), color)
+    END
+WHERE
+    id = $1
+    AND user_id = $2
+RETURNING
+    *;
+
+-- name: TodoTagDeleteTag :execrows
+DELETE FROM todo_tag
+WHERE id = $1
+    AND user_id = $2;
+
+-- name: TodoTagCountTagsOfUser :one
+SELECT COUNT(*)
+FROM todo_tag
+WHERE user_id = @user_id
+    AND id = ANY(@tag_ids::INTEGER[]);
+
+-- name: TodoTagAttachTags :execrows
+INSERT INTO
+    todo_tag_link (todo_id, tag_id)
+SELECT
+    @todo_id, unnest(@tag_ids::INTEGER[])
+ON CONFLICT (todo_id, tag_id) DO NOTHING;
+
+-- name: TodoTagDetachTag :execrows
+DELETE FROM todo_tag_link
+WHERE todo_id = $1
+    AND tag_id = $2
+    AND tag_id IN (
+        SELECT t.id
+        FROM todo_tag AS t
+        WHERE t.user_id = $3
+    );
+
+-- name: TodoTagDetachTagsOfUserFromTodo :exec
+DELETE FROM todo_tag_link
+WHERE todo_id = @todo_id
+    AND tag_id IN (
+        SELECT t.id
+        FROM todo_tag AS t
+        WHERE t.user_id = @user_id
+    );
+
+-- name: TodoTagCopyTags :exec
+INSERT INTO
+    todo_tag_link (todo_id, tag_id)
+SELECT
+    @to_todo_id, tag_id
+FROM todo_tag_link
+WHERE todo_id = @from_todo_id;
+
+-- name: TodoTagGetTagsOfTodos :many
+SELECT
+    l.todo_id,
+    sqlc.embed(t)
+FROM todo_tag_link AS l
+    JOIN todo_tag AS t ON t.id = l.tag_id
+WHERE l.todo_id = ANY(@todo_ids::INTEGER[])
+    AND t.user_id = @user_id
+ORDER BY lower(t.name), t.id;
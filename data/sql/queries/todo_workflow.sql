@@ -0,0 +1,89 @@
+-- name: TodoWorkflowGetStatuses :many
+SELECT
+    *
+FROM todo_workflow_status
+WHERE user_id = $1
+ORDER BY position, id;
+
+-- name: TodoWorkflowCreateDefaultStatuses :exec
+INSERT INTO
+    todo_workflow_status (user_id, name, position, is_completed)
+SELECT
+    @user_id::INTEGER,
+    d.name,
+    d.position,
+    d.is_completed
+FROM (
+        VALUES
+            ('pending', 0, FALSE),
+            ('in_progress', 1, FALSE),
+            ('done', 2, TRUE)
+    ) AS d (name, position, is_completed)
+WHERE NOT EXISTS (
+        SELECT 1
+        FROM todo_workflow_status AS s
+        WHERE s.user_id = @user_id
+    )
+ON CONFLICT DO NOTHING;
+
+-- name: TodoWorkflowCreateStatus :one
+INSERT INTO
+    todo_workflow_status (user_id, name, position)
+VALUES
+    (
+        @user_id,
+        @name,
+        COALESCE(
+            sqlc.narg('position')::INTEGER,
+            (
+                SELECT COALESCE(MAX(s.position) + 1, 0)
+                FROM todo_workflow_status AS s
+                WHERE s.user_id = @user_id
+            )
+        )
+    )
+RETURNING
+    *;
+
+-- name: TodoWorkflowUpdateStatus :one
+UPDATE todo_workflow_status
+SET
+    name = COALESCE(sqlc.narg('name'), name),
+    position = COALESCE(sqlc.narg('position'), position)
+WHERE
+    id = $1
+    AND user_id = $2
+RETURNING
+    *;
+
+-- name: TodoWorkflowClearCompletedStatus :exec
+UPDATE todo_workflow_status
+SET is_completed = FALSE
+WHERE user_id = $1
+    AND is_completed;
+
+-- name: TodoWorkflowSetCompletedStatus :execrows
+UPDATE todo_workflow_status
+SET is_completed = TRUE
+WHERE id = $1
+    AND user_id = $2;
+
+-- name: TodoWorkflowReorderStatuses :execrows
+UPDATE todo_workflow_status AS s
+SET position = o.position::INTEGER
+FROM unnest(@status_ids::INTEGER[]) WITH ORDINALITY AS o(id, position)
+WHERE s.id = o.id
+    AND s.user_id = @user_id;
+
+-- name: TodoWorkflowDeleteStatus :execrows
+DELETE FROM todo_workflow_status
+WHERE id = $1
+    AND user_id = $2;
+
+-- name: TodoWorkflowGetCompletedStatusesOfUsers :many
+SELECT
+    user_id,
+    name
+FROM todo_workflow_status
+WHERE user_id = ANY(@user_ids::INTEGER[])
+    AND is_completed;
@@ -16,11 +16,11 @@ WHERE id = $1
 LIMIT 1;
 
 
--- name: UsersGetUserAndSessionDataBySessionToken :one
+-- name: UsersGetUserAndSessionDataBySessionId :one
 SELECT s.id as session_id,
-    s.token as session_token,
     s.originated_from as session_originated_from,
     s.used_installation as session_used_installation,
+    s.expires_at as session_expires_at,
 
     u.id as user_id,
     u.username as user_username,
@@ -34,7 +34,7 @@ SELECT s.id as session_id,
 FROM active_session AS s
     JOIN active_login_identity AS li ON s.originated_from = li.id
     JOIN not_deleted_users AS u ON u.id = li.user_id
-WHERE s.token = $1
+WHERE s.id = $1
 LIMIT 1;
 
 -- name: UsersIsUsernameUsed :one
@@ -63,12 +63,128 @@ SET username = $2,
 WHERE id = $1
 RETURNING *;
 
+-- name: UsersUpdateNamesForUser :exec
+UPDATE users
+SET first_name = @first_name::text,
+    last_name = sqlc.narg(last_name)::text
+WHERE id = @id::int;
+
 -- name: UsersUpdateUsernameForUser :exec
 UPDATE users
 SET username = $2
 WHERE id = $1;
 
--- name: UsersSoftDeleteUser :exec
+-- name: UsersSetMfaEnforcedForUser :execrows
+UPDATE users
+SET mfa_enforced = @mfa_enforced::bool
+WHERE id = @id::int
+    AND deleted_at IS NULL;
+
+-- name: UsersSoftDeleteUser :execrows
 UPDATE users
 SET deleted_at = NOW()
-WHERE id = $1;
+WHERE id = $1
+    AND deleted_at IS NULL;
+
+-- name: UsersSearchUsers :many
+SELECT
+    u.id,
+    u.username,
+    u.profile_image,
+    u.first_name,
+    u.middle_name,
+    u.last_name,
+    u.role_name,
+    u.mfa_enforced,
+    u.blocked_at,
+    u.blocked_until,
+    u.deleted_at,
+    u.created_at
+FROM users AS u
+WHERE (@include_deleted::bool OR u.deleted_at IS NULL)
+    AND (sqlc.narg(role_name)::text IS NULL OR u.role_name = sqlc.narg(role_name)::text)
+    AND (
+        sqlc.narg(query)::text IS NULL
+        OR u.username ILIKE '%' || sqlc.narg(query)::text || '%' ESCAPE '\'
+        OR (u.first_name || ' ' || COALESCE(u.last_name, '')) ILIKE '%' || sqlc.narg(query)::text || '%' ESCAPE '\'
+        OR EXISTS (
+            SELECT 1
+            FROM login_identity AS li
+                JOIN password_login_identity AS pli ON pli.login_identity_id = li.id
+            WHERE li.user_id = u.id
+                AND (pli.email ILIKE '%' || sqlc.narg(query)::text || '%' ESCAPE '\' OR pli.phone LIKE '%' || sqlc.narg(query)::text || '%' ESCAPE '\')
+        )
+    )
+ORDER BY u.id DESC
+LIMIT @page_limit::int OFFSET @page_offset::int;
+
+-- name: UsersGetUserForAdmin :one
+SELECT
+    u.id,
+    u.username,
+    u.profile_image,
+    u.first_name,
+    u.middle_name,
+    u.last_name,
+    u.role_name,
+    u.mfa_enforced,
+    u.blocked_at,
+    u.blocked_until,
+    u.deleted_at,
+    u.created_at,
+    b.reason AS block_reason
+FROM users AS u
+    LEFT JOIN LATERAL (
+        SELECT ub.reason
+        FROM user_block AS ub
+        WHERE ub.user_id = u.id
+            AND ub.unblocked_at IS NULL
+        ORDER BY ub.id DESC
+        LIMIT 1
+    ) AS b ON u.blocked_at IS NOT NULL
+WHERE u.id = @id::int
+LIMIT 1;
+
+-- name: UsersBlockUser :execrows
+UPDATE users
+SET blocked_at = NOW(),
+    blocked_until = sqlc.narg(blocked_until)::timestamptz
+WHERE id = @id::int
+    AND deleted_at IS NULL;
+
+-- name: UsersCreateUserBlock :exec
+INSERT INTO user_block (
+    user_id,
+    blocked_by,
+    reason,
+    blocked_until
+)
+VALUES (
+    @user_id::int,
+    sqlc.narg(blocked_by)::int,
+    @reason::text,
+    sqlc.narg(blocked_until)::timestamptz
+);
+
+-- name: UsersUnblockUser :execrows
+UPDATE users
+SET blocked_at = NULL,
+    blocked_until = NULL
+WHERE id = @id::int
+    AND deleted_at IS NULL;
+
+-- name: UsersEndUserBlocks :exec
+UPDATE user_block
+SET unblocked_at = NOW()
+WHERE user_id = @user_id::int
+    AND unblocked_at IS NULL;
+
+-- name: UsersSetRoleForUser :execrows
+UPDATE users
+SET role_name = sqlc.narg(role_name)::text
+WHERE id = @id::int
+    AND deleted_at IS NULL
+    AND (
+        sqlc.narg(role_name)::text IS NULL
+        OR EXISTS (SELECT 1 FROM role WHERE name = sqlc.narg(role_name)::text AND deleted_at IS NULL)
+    );
@@ -9,6 +9,7 @@ require (
 	github.com/google/uuid v1.6.0
 	github.com/jackc/pgx/v5 v5.7.5
 	github.com/nicksnyder/go-i18n/v2 v2.6.0
+	github.com/nyaruka/phonenumbers v1.6.7
 	github.com/pressly/goose/v3 v3.25.0
 	github.com/rs/cors v1.11.1
 	github.com/rs/zerolog v1.34.0
@@ -19,10 +20,7 @@ require (
 	google.golang.org/api v0.248.0
 )
 
-require (
-	github.com/nyaruka/phonenumbers v1.6.7 // indirect
-	golang.org/x/exp v0.0.0-20250620022241-b7579e27df2b // indirect
-)
+require golang.org/x/exp v0.0.0-20250620022241-b7579e27df2b // indirect
 
 require (
 	cloud.google.com/go/auth v0.16.5 // indirect
@@ -8,6 +8,9 @@ import "strings"
 //
 // NOTE: you can not have "," in the values. not supported.
 func DecodeEnvList(listAsStr string) []string {
+	if len(listAsStr) < 2 {
+		return []string{}
+	}
 	if string(listAsStr[0]) != "[" || string(listAsStr[len(listAsStr)-1]) != "]" {
 		return []string{}
 	}
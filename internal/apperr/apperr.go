@@ -103,6 +103,7 @@ var (
 	ErrUnexpectedErrorOccurred = NewAppErrWithTr(errors.New("unexpected error occurred"), l10n.UnexpectedErrorOccurredTrId, "res_2")
 	ErrTooManyRequests         = NewAppErrWithTr(errors.New("too many requests"), l10n.TooManyRequestsTrId, "res_3")
 	ErrInvalidId               = NewAppErrWithTr(errors.New("invalid id"), l10n.InvalidId, "res_4")
+	ErrInvalidCursor           = NewAppErrWithTr(errors.New("invalid pagination cursor"), l10n.InvalidCursorTrId, "res_5")
 
 	// auth
 	ErrInvalidEmail                      = NewAppErrWithTr(errors.New("invalid email"), l10n.InvalidEmailTrId, "auth_1")
@@ -119,17 +120,54 @@ var (
 	ErrInstallationTokenInUse            = NewAppErrWithErrorCode(errors.New("cannot link with the provided installation token — it is already linked to another user, or the current user if you did not unlinked(logout) yet"), "auth_13")
 	ErrAlreadyUsedEmailWithOidc          = NewAppErrWithTr(errors.New("already used email with open id connect"), l10n.AlreadyUsedEmailWithOidcTrId, "auth_13")
 	ErrAlreadyUsedEmailWithPasswordLogin = NewAppErrWithTr(errors.New("already used email with normal password login"), l10n.AlreadyUsedEmailWithPasswordLoginTrId, "auth_14")
+	ErrNotGuestUser                      = NewAppErrWithTr(errors.New("the user is not a guest"), l10n.NotGuestUserTrId, "auth_15")
+	ErrOidcAccountAlreadyLinked          = NewAppErrWithTr(errors.New("the oidc account is already linked to another user"), l10n.OidcAccountAlreadyLinkedTrId, "auth_16")
+	ErrInvalidMfaCode                    = NewAppErrWithTr(errors.New("invalid mfa code"), l10n.InvalidMfaCodeTrId, "auth_17")
+	ErrMfaAlreadyEnabled                 = NewAppErrWithTr(errors.New("mfa already enabled"), l10n.MfaAlreadyEnabledTrId, "auth_18")
+	ErrMfaNotEnabled                     = NewAppErrWithTr(errors.New("mfa not enabled"), l10n.MfaNotEnabledTrId, "auth_19")
+	ErrMfaEnforced                       = NewAppErrWithTr(errors.New("mfa is enforced for the user"), l10n.MfaEnforcedTrId, "auth_20")
+	ErrInvalidMfaChallenge               = NewAppErrWithTr(errors.New("invalid or expired mfa challenge"), l10n.InvalidMfaChallengeTrId, "auth_21")
+	ErrInvalidRefreshToken               = NewAppErrWithTr(errors.New("invalid or expired refresh token"), l10n.InvalidRefreshTokenTrId, "auth_22")
+	ErrRefreshTokenReused                = NewAppErrWithTr(errors.New("refresh token reuse detected, the session is revoked"), l10n.RefreshTokenReusedTrId, "auth_23")
 
 	// jwt
 	ErrExpiredSessionToken             = NewAppErrWithTr(errors.New("expired session token"), l10n.ExpiredSessionToken, "auth_13")
 	ErrExpiredInstallationSessionToken = NewAppErrWithErrorCode(errors.New("expired installation session token"), "auth_14")
 
 	// user
-	ErrBlockedUser = NewAppErrWithTr(errors.New("blocked user"), l10n.BlockedUser, "user_1")
+	ErrBlockedUser            = NewAppErrWithTr(errors.New("blocked user"), l10n.BlockedUser, "user_1")
+	ErrCannotManageOwnAccount = NewAppErrWithTr(errors.New("can not do this action on your own account"), l10n.CannotManageOwnAccountTrId, "user_2")
+	ErrInvalidRole            = NewAppErrWithTr(errors.New("invalid role"), l10n.InvalidRoleTrId, "user_3")
+	ErrInvalidBlockedUntil    = NewAppErrWithTr(errors.New("blocked until must be in the future"), l10n.InvalidBlockedUntilTrId, "user_4")
 
 	// todo
-	ErrUnsupportedTodoStatus = NewAppErrWithTr(errors.New("unsupported todo status"), l10n.UnsupportedTodoStatus, "todo_1")
+	ErrUnsupportedTodoStatus           = NewAppErrWithTr(errors.New("unsupported todo status"), l10n.UnsupportedTodoStatus, "todo_1")
+	ErrUnsupportedTodoSort             = NewAppErrWithTr(errors.New("unsupported todo sort"), l10n.UnsupportedTodoSortTrId, "todo_2")
+	ErrInvalidTodoList                 = NewAppErrWithTr(errors.New("invalid todo list"), l10n.InvalidTodoListTrId, "todo_3")
+	ErrUnsupportedTodoListRole         = NewAppErrWithTr(errors.New("unsupported todo list role"), l10n.UnsupportedTodoListRoleTrId, "todo_4")
+	ErrTodoListInviteeNotFound         = NewAppErrWithTr(errors.New("todo list invitee not found"), l10n.TodoListInviteeNotFoundTrId, "todo_5")
+	ErrTodoListAlreadyMember           = NewAppErrWithTr(errors.New("already a member of the todo list"), l10n.TodoListAlreadyMemberTrId, "todo_6")
+	ErrTodoListLastOwner               = NewAppErrWithTr(errors.New("the todo list should have at least one owner"), l10n.TodoListLastOwnerTrId, "todo_7")
+	ErrInvalidRecurrenceRule           = NewAppErrWithTr(errors.New("invalid recurrence rule"), l10n.InvalidRecurrenceRuleTrId, "todo_8")
+	ErrRecurrenceNeedsDueAt            = NewAppErrWithTr(errors.New("a recurring todo should have a due date"), l10n.RecurrenceNeedsDueAtTrId, "todo_9")
+	ErrInvalidTimezone                 = NewAppErrWithTr(errors.New("invalid timezone"), l10n.InvalidTimezoneTrId, "todo_10")
+	ErrInvalidTodoTag                  = NewAppErrWithTr(errors.New("invalid todo tag"), l10n.InvalidTodoTagTrId, "todo_11")
+	ErrTodoTagAlreadyExists            = NewAppErrWithTr(errors.New("todo tag already exists"), l10n.TodoTagAlreadyExistsTrId, "todo_12")
+	ErrTodoWorkflowStatusAlreadyExists = NewAppErrWithTr(errors.New("todo workflow status already exists"), l10n.TodoWorkflowStatusAlreadyExistsTrId, "todo_13")
+	ErrCannotDeleteCompletedTodoStatus = NewAppErrWithTr(errors.New("can not delete the completed todo status"), l10n.CannotDeleteCompletedTodoStatusTrId, "todo_14")
+	ErrTooManyTodoAttachments          = NewAppErrWithTr(errors.New("too many todo attachments"), l10n.TooManyTodoAttachmentsTrId, "todo_15")
+	ErrTodoAttachmentTooLarge          = NewAppErrWithTr(errors.New("todo attachment too large"), l10n.TodoAttachmentTooLargeTrId, "todo_16")
+	ErrUnsupportedTodoAttachmentType   = NewAppErrWithTr(errors.New("unsupported todo attachment type"), l10n.UnsupportedTodoAttachmentTypeTrId, "todo_17")
+	ErrTodoVersionMismatch             = NewAppErrWithTr(errors.New("the todo version does not match"), l10n.TodoVersionMismatchTrId, "todo_18")
+	ErrInvalidTodoSyncToken            = NewAppErrWithTr(errors.New("invalid todo sync token"), l10n.InvalidTodoSyncTokenTrId, "todo_19")
+	ErrTodoAttachmentsUnavailable      = NewAppErrWithTr(errors.New("the todo attachments are not available, no blob store is configured"), l10n.TodoAttachmentsUnavailableTrId, "todo_20")
+	ErrTodoRemindAtAfterDueAt          = NewAppErrWithTr(errors.New("the remind_at should not be after the due_at"), l10n.TodoRemindAtAfterDueAtTrId, "todo_21")
 
 	// perm
-	ErrPermissionDenied = NewAppErrWithErrorCode(errors.New("permission denied"), "perm_1")
+	ErrPermissionDenied                     = NewAppErrWithErrorCode(errors.New("permission denied"), "perm_1")
+	ErrRoleAlreadyExists                    = NewAppErrWithTr(errors.New("role already exists"), l10n.RoleAlreadyExistsTrId, "perm_2")
+	ErrPermissionAlreadyExists              = NewAppErrWithTr(errors.New("permission already exists"), l10n.PermissionAlreadyExistsTrId, "perm_3")
+	ErrCannotDeleteBaseRoleOrPerm           = NewAppErrWithTr(errors.New("can not delete a base role or permission"), l10n.CannotDeleteBaseRoleOrPermTrId, "perm_4")
+	ErrCannotChangeOwnOrBaseRolePermissions = NewAppErrWithTr(errors.New("can not change the permissions of your own role or a base role"), l10n.CannotChangeOwnOrBaseRolePermissionsTrId, "perm_5")
+	ErrCannotManageHigherRole               = NewAppErrWithTr(errors.New("can not manage a user with permissions that you do not have, or with a base role"), l10n.CannotManageHigherRoleTrId, "perm_6")
 )
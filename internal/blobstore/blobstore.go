@@ -0,0 +1,86 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+const (
+	StoreTypeLocal = "local"
+	StoreTypeS3    = "s3"
+)
+
+var (
+	storeType = os.Getenv("BLOB_STORE")
+
+	// the local store
+	localDir     = os.Getenv("BLOB_LOCAL_DIR")
+	localBaseURL = os.Getenv("BLOB_LOCAL_BASE_URL")
+	signingKey   = os.Getenv("BLOB_SIGNING_KEY")
+
+	// the S3 compatible store (AWS S3, MinIO, ...)
+	s3Endpoint        = os.Getenv("BLOB_S3_ENDPOINT")
+	s3Region          = os.Getenv("BLOB_S3_REGION")
+	s3Bucket          = os.Getenv("BLOB_S3_BUCKET")
+	s3AccessKeyId     = os.Getenv("BLOB_S3_ACCESS_KEY_ID")
+	s3SecretAccessKey = os.Getenv("BLOB_S3_SECRET_ACCESS_KEY")
+)
+
+const defaultLocalDir = "./storage/blobs"
+
+var (
+	ErrInvalidSignature = errors.New("invalid or expired blob signature")
+	// the attachments are disabled, see NewStoreFromEnv
+	ErrNotConfigured = errors.New("no blob store is configured")
+)
+
+// Store keeps the uploaded files (blobs) by their keys.
+// The keys are generated by the app, and they are made of [a-zA-Z0-9/_-]
+type Store interface {
+	// Put stores the content under the key, replacing any old content
+	Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error
+	// Delete does not fail if the key does not exist
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a url that downloads the blob without any auth until it expires,
+	// the downloaded file is named filename
+	SignedURL(ctx context.Context, key, filename string, expiresIn time.Duration) (string, error)
+}
+
+// NewStoreFromEnv creates the store selected by BLOB_STORE. Without BLOB_STORE it is the
+// local store if it is configured, or ErrNotConfigured so the app runs without a store
+func NewStoreFromEnv() (Store, error) {
+	if len(storeType) == 0 && (len(signingKey) == 0 || len(localBaseURL) == 0) {
+		return nil, ErrNotConfigured
+	}
+
+	switch storeType {
+	case "", StoreTypeLocal:
+		if len(signingKey) == 0 || len(localBaseURL) == 0 {
+			return nil, errors.New("BLOB_SIGNING_KEY and BLOB_LOCAL_BASE_URL are required for the local blob store")
+		}
+		dir := localDir
+		if len(dir) == 0 {
+			dir = defaultLocalDir
+		}
+		return NewLocalStore(dir, localBaseURL, []byte(signingKey))
+
+	case StoreTypeS3:
+		if len(s3Endpoint) == 0 || len(s3Bucket) == 0 || len(s3AccessKeyId) == 0 || len(s3SecretAccessKey) == 0 {
+			return nil, errors.New("BLOB_S3_ENDPOINT, BLOB_S3_BUCKET, BLOB_S3_ACCESS_KEY_ID and BLOB_S3_SECRET_ACCESS_KEY are required for the s3 blob store")
+		}
+		return NewS3Store(S3Config{
+			Endpoint:        s3Endpoint,
+			Region:          s3Region,
+			Bucket:          s3Bucket,
+			AccessKeyId:     s3AccessKeyId,
+			SecretAccessKey: s3SecretAccessKey,
+		})
+
+	default:
+		return nil, fmt.Errorf("unsupported BLOB_STORE %q, it should be %s or %s", storeType, StoreTypeLocal, StoreTypeS3)
+	}
+}
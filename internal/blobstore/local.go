@@ -0,0 +1,132 @@
+package blobstore
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// the keys can not escape the dir of the store e.g. "../../etc/passwd"
+var keyRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]+(/[a-zA-Z0-9_-]+)*$`)
+
+// LocalStore keeps the blobs in a dir on the local filesystem. Its signed urls
+// point to BaseURL, that should be served by a handler that calls OpenSigned
+type LocalStore struct {
+	dir     string
+	baseURL string
+	key     []byte
+}
+
+func NewLocalStore(dir, baseURL string, signingKey []byte) (*LocalStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	return &LocalStore{dir: dir, baseURL: strings.TrimSuffix(baseURL, "/"), key: signingKey}, nil
+}
+
+func (s *LocalStore) Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+
+	// written to a temp file first, so a failed upload does not leave a partial file behind
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	n, err := io.Copy(tmp, content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if n != size {
+		return fmt.Errorf("the blob size is %d, expected %d", n, size)
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *LocalStore) SignedURL(ctx context.Context, key, filename string, expiresIn time.Duration) (string, error) {
+	if !keyRegex.MatchString(key) {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+
+	expires := strconv.FormatInt(time.Now().Add(expiresIn).Unix(), 10)
+
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("filename", filename)
+	query.Set("signature", s.sign(key, expires, filename))
+
+	return s.baseURL + "/" + key + "?" + query.Encode(), nil
+}
+
+// OpenSigned opens the blob of a signed url, the query is the query of the url.
+// It returns ErrInvalidSignature for the tampered and the expired urls,
+// and fs.ErrNotExist for the deleted blobs
+func (s *LocalStore) OpenSigned(key string, query url.Values) (file *os.File, filename string, err error) {
+	expires, filename, signature := query.Get("expires"), query.Get("filename"), query.Get("signature")
+
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return nil, "", ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(signature), []byte(s.sign(key, expires, filename))) {
+		return nil, "", ErrInvalidSignature
+	}
+
+	path, err := s.path(key)
+	if err != nil {
+		return nil, "", ErrInvalidSignature
+	}
+
+	file, err = os.Open(path)
+	if err != nil {
+		return nil, "", err
+	}
+	return file, filename, nil
+}
+
+func (s *LocalStore) sign(key, expires, filename string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(key + "\n" + expires + "\n" + filename))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (s *LocalStore) path(key string) (string, error) {
+	if !keyRegex.MatchString(key) {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
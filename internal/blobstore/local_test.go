@@ -0,0 +1,224 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestLocalStore(t *testing.T) *LocalStore {
+	s, err := NewLocalStore(filepath.Join(t.TempDir(), "blobs"), "http://localhost/blobs/", []byte("test signing key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func putString(t *testing.T, s *LocalStore, key, content string) {
+	if err := s.Put(context.Background(), key, strings.NewReader(content), int64(len(content)), "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// returns the key and the query of a signed url
+func signedURL(t *testing.T, s *LocalStore, key, filename string, expiresIn time.Duration) (string, url.Values) {
+	signed, err := s.SignedURL(context.Background(), key, filename, expiresIn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimPrefix(u.Path, "/blobs/"), u.Query()
+}
+
+func TestLocalStorePutGetDelete(t *testing.T) {
+	s := newTestLocalStore(t)
+	ctx := context.Background()
+	const key = "todo/1/attachment-1"
+
+	putString(t, s, key, "hello")
+
+	signedKey, query := signedURL(t, s, key, "notes.txt", time.Minute)
+	if signedKey != key {
+		t.Errorf("got the key %q in the signed url, want %q", signedKey, key)
+	}
+
+	file, filename, err := s.OpenSigned(signedKey, query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := io.ReadAll(file)
+	file.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "hello" || filename != "notes.txt" {
+		t.Errorf("OpenSigned = %q, %q, want \"hello\", \"notes.txt\"", content, filename)
+	}
+
+	// a put with the wrong size does not replace the blob
+	if err := s.Put(ctx, key, strings.NewReader("bye"), 10, "text/plain"); err == nil {
+		t.Error("Put with the wrong size should fail")
+	}
+	file, _, err = s.OpenSigned(signedKey, query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, _ = io.ReadAll(file)
+	file.Close()
+	if string(content) != "hello" {
+		t.Errorf("got %q after the failed put, want \"hello\"", content)
+	}
+
+	if err := s.Delete(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.OpenSigned(signedKey, query); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("OpenSigned after Delete = %v, want fs.ErrNotExist", err)
+	}
+	// deleting a missing blob is not an error
+	if err := s.Delete(ctx, key); err != nil {
+		t.Errorf("Delete of a missing blob = %v", err)
+	}
+}
+
+func TestLocalStoreExpiredURL(t *testing.T) {
+	s := newTestLocalStore(t)
+	putString(t, s, "a", "hello")
+
+	key, query := signedURL(t, s, "a", "a.txt", -time.Minute)
+	if _, _, err := s.OpenSigned(key, query); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("OpenSigned of an expired url = %v, want ErrInvalidSignature", err)
+	}
+
+	// pushing the expiry forward breaks the signature
+	query.Set("expires", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+	if _, _, err := s.OpenSigned(key, query); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("OpenSigned with a modified expiry = %v, want ErrInvalidSignature", err)
+	}
+}
+
+func TestLocalStoreModifiedSignature(t *testing.T) {
+	s := newTestLocalStore(t)
+	putString(t, s, "a", "hello")
+	putString(t, s, "b", "secret")
+
+	key, query := signedURL(t, s, "a", "a.txt", time.Minute)
+
+	tests := []struct {
+		name   string
+		key    string
+		modify func(q url.Values)
+	}{
+		{name: "signature", key: key, modify: func(q url.Values) { q.Set("signature", q.Get("signature")[1:]+"A") }},
+		{name: "no signature", key: key, modify: func(q url.Values) { q.Del("signature") }},
+		{name: "filename", key: key, modify: func(q url.Values) { q.Set("filename", "a.html") }},
+		{name: "expires", key: key, modify: func(q url.Values) { q.Set("expires", "not a time") }},
+		{name: "key", key: "b", modify: func(q url.Values) {}},
+		{name: "signing key", key: key, modify: func(q url.Values) {
+			other, err := NewLocalStore(t.TempDir(), "http://localhost/blobs", []byte("other key"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			q.Set("signature", other.sign(key, q.Get("expires"), q.Get("filename")))
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := url.Values{}
+			for k, v := range query {
+				q[k] = append([]string(nil), v...)
+			}
+			tt.modify(q)
+
+			file, _, err := s.OpenSigned(tt.key, q)
+			if file != nil {
+				file.Close()
+			}
+			if !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("OpenSigned = %v, want ErrInvalidSignature", err)
+			}
+		})
+	}
+}
+
+func TestLocalStoreTraversalKeys(t *testing.T) {
+	s := newTestLocalStore(t)
+	ctx := context.Background()
+	root := filepath.Dir(s.dir)
+
+	outside := filepath.Join(root, "outside")
+	if err := os.WriteFile(outside, []byte("secret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	keys := []string{
+		"",
+		"../outside",
+		"a/../../outside",
+		"a/../b",
+		"./a",
+		"/etc/passwd",
+		outside,
+		"a//b",
+		"a/",
+		`..\outside`,
+		"a/b\x00",
+	}
+
+	for _, key := range keys {
+		t.Run(key, func(t *testing.T) {
+			if err := s.Put(ctx, key, strings.NewReader("x"), 1, "text/plain"); err == nil {
+				t.Errorf("Put(%q) should fail", key)
+			}
+			if err := s.Delete(ctx, key); err == nil {
+				t.Errorf("Delete(%q) should fail", key)
+			}
+			if _, err := s.SignedURL(ctx, key, "x.txt", time.Minute); err == nil {
+				t.Errorf("SignedURL(%q) should fail", key)
+			}
+
+			// even with a valid signature of the key
+			expires := strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10)
+			query := url.Values{}
+			query.Set("expires", expires)
+			query.Set("filename", "x.txt")
+			query.Set("signature", s.sign(key, expires, "x.txt"))
+
+			file, _, err := s.OpenSigned(key, query)
+			if file != nil {
+				file.Close()
+			}
+			if !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("OpenSigned(%q) = %v, want ErrInvalidSignature", key, err)
+			}
+		})
+	}
+
+	// nothing was written or removed outside the store dir
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if strings.Join(names, ",") != "blobs,outside" {
+		t.Errorf("got the entries %v next to the store dir, want [blobs outside]", names)
+	}
+	if content, err := os.ReadFile(outside); err != nil || string(content) != "secret" {
+		t.Errorf("the file outside the store dir was changed: %q, %v", content, err)
+	}
+}
@@ -0,0 +1,268 @@
+package blobstore
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultS3Region = "us-east-1"
+
+	sigV4Algorithm   = "AWS4-HMAC-SHA256"
+	sigV4DateLayout  = "20060102T150405Z"
+	sigV4DayLayout   = "20060102"
+	unsignedPayload  = "UNSIGNED-PAYLOAD"
+	maxPresignExpiry = 7 * 24 * time.Hour
+)
+
+type S3Config struct {
+	// e.g. https://s3.eu-central-1.amazonaws.com or http://localhost:9000 for MinIO
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyId     string
+	SecretAccessKey string
+}
+
+// S3Store keeps the blobs in a bucket of an S3 compatible storage, the requests
+// are signed with AWS Signature Version 4 and use the path-style urls
+// (endpoint/bucket/key), that are supported by AWS S3 and MinIO
+type S3Store struct {
+	endpoint *url.URL
+	config   S3Config
+	client   *http.Client
+	// the time of the signatures, replaced in the tests
+	now func() time.Time
+}
+
+func NewS3Store(config S3Config) (*S3Store, error) {
+	endpoint, err := url.Parse(strings.TrimSuffix(config.Endpoint, "/"))
+	if err != nil || len(endpoint.Scheme) == 0 || len(endpoint.Host) == 0 {
+		return nil, fmt.Errorf("invalid s3 endpoint %q", config.Endpoint)
+	}
+	if len(config.Region) == 0 {
+		config.Region = defaultS3Region
+	}
+	return &S3Store{
+		endpoint: endpoint,
+		config:   config,
+		client:   &http.Client{Timeout: 5 * time.Minute},
+		now:      time.Now,
+	}, nil
+}
+
+func (s *S3Store) Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error {
+	req, err := s.newRequest(ctx, http.MethodPut, key, content)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType)
+
+	return s.do(req, http.StatusOK)
+}
+
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	// S3 returns 204 even if the key does not exist, some compatible storages return 404
+	return s.do(req, http.StatusNoContent, http.StatusOK, http.StatusNotFound)
+}
+
+func (s *S3Store) SignedURL(ctx context.Context, key, filename string, expiresIn time.Duration) (string, error) {
+	if !keyRegex.MatchString(key) {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+
+	query := url.Values{}
+	query.Set("response-content-disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+
+	return s.presignURL(s.objectURL(key), query, min(expiresIn, maxPresignExpiry)), nil
+}
+
+// presignURL returns u with the query and the auth params of a presigned GET, see
+// https://docs.aws.amazon.com/AmazonS3/latest/API/sigv4-query-string-auth.html
+func (s *S3Store) presignURL(u *url.URL, query url.Values, expiresIn time.Duration) string {
+	now := s.now().UTC()
+
+	query.Set("X-Amz-Algorithm", sigV4Algorithm)
+	query.Set("X-Amz-Credential", s.config.AccessKeyId+"/"+s.scope(now))
+	query.Set("X-Amz-Date", now.Format(sigV4DateLayout))
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expiresIn.Seconds())))
+	query.Set("X-Amz-SignedHeaders", "host")
+	u.RawQuery = canonicalQuery(query)
+
+	headers := http.Header{}
+	headers.Set("Host", u.Host)
+	signature := s.signature(http.MethodGet, u, headers, unsignedPayload, now)
+
+	u.RawQuery += "&X-Amz-Signature=" + signature
+	return u.String()
+}
+
+func (s *S3Store) newRequest(ctx context.Context, method, key string, body io.Reader) (*http.Request, error) {
+	if !keyRegex.MatchString(key) {
+		return nil, fmt.Errorf("invalid blob key %q", key)
+	}
+	return s.newSignedRequest(ctx, method, s.objectURL(key), body)
+}
+
+// newSignedRequest returns a request with the Authorization header of the signature v4,
+// the query of u should be in the canonical form (see canonicalQuery)
+func (s *S3Store) newSignedRequest(ctx context.Context, method string, u *url.URL, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now().UTC()
+	// the body is streamed, so it is not part of the signature
+	req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+	req.Header.Set("X-Amz-Date", now.Format(sigV4DateLayout))
+
+	headers := req.Header.Clone()
+	headers.Set("Host", u.Host)
+	signedHeaders := strings.Join(signedHeaderNames(headers), ";")
+	signature := s.signature(method, u, headers, unsignedPayload, now)
+
+	req.Header.Set(
+		"Authorization",
+		fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s", sigV4Algorithm, s.config.AccessKeyId, s.scope(now), signedHeaders, signature),
+	)
+	return req, nil
+}
+
+func (s *S3Store) do(req *http.Request, okStatuses ...int) error {
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	for _, status := range okStatuses {
+		if res.StatusCode == status {
+			return nil
+		}
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+	return fmt.Errorf("s3 %s %s: %s: %s", req.Method, req.URL.Path, res.Status, body)
+}
+
+func (s *S3Store) objectURL(key string) *url.URL {
+	u := *s.endpoint
+	u.Path = u.Path + "/" + s.config.Bucket + "/" + key
+	return &u
+}
+
+func (s *S3Store) scope(t time.Time) string {
+	return t.Format(sigV4DayLayout) + "/" + s.config.Region + "/s3/aws4_request"
+}
+
+// signature signs the request as described in
+// https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-header-based-auth.html
+// the u.RawQuery should be in the canonical form (see canonicalQuery)
+func (s *S3Store) signature(method string, u *url.URL, headers http.Header, payloadHash string, t time.Time) string {
+	names := signedHeaderNames(headers)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers.Get(name)) + "\n")
+	}
+
+	canonicalRequest := strings.Join(
+		[]string{
+			method,
+			uriEncode(u.Path, false),
+			u.RawQuery,
+			canonicalHeaders.String(),
+			strings.Join(names, ";"),
+			payloadHash,
+		},
+		"\n",
+	)
+
+	stringToSign := strings.Join(
+		[]string{
+			sigV4Algorithm,
+			t.Format(sigV4DateLayout),
+			s.scope(t),
+			hex.EncodeToString(sha256Sum([]byte(canonicalRequest))),
+		},
+		"\n",
+	)
+
+	signingKey := hmacSha256([]byte("AWS4"+s.config.SecretAccessKey), t.Format(sigV4DayLayout))
+	signingKey = hmacSha256(signingKey, s.config.Region)
+	signingKey = hmacSha256(signingKey, "s3")
+	signingKey = hmacSha256(signingKey, "aws4_request")
+
+	return hex.EncodeToString(hmacSha256(signingKey, stringToSign))
+}
+
+func signedHeaderNames(headers http.Header) []string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, strings.ToLower(name))
+	}
+	sort.Strings(names)
+	return names
+}
+
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		for _, v := range query[k] {
+			parts = append(parts, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode encodes everything except the unreserved characters of RFC 3986,
+// and the slashes of the paths
+func uriEncode(str string, encodeSlash bool) string {
+	var b strings.Builder
+	for _, c := range []byte(str) {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func sha256Sum(b []byte) []byte {
+	sum := sha256.Sum256(b)
+	return sum[:]
+}
+
+func hmacSha256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -132,9 +132,121 @@ func (q *Queries) LoginIdentityCreateNewPasswordLoginIdentity(ctx context.Contex
 	return i, err
 }
 
-const loginIdentityCreateNewUserAndOIDCLoginIdentity = `-- name: LoginIdentityCreateNewUserAndOIDCLoginIdentity :one
+const loginIdentityCreateNewUserAndGuestLoginIdentity = `-- name: LoginIdentityCreateNewUserAndGuestLoginIdentity :one
+WITH new_user AS (
+  INSERT INTO users (
+    username,
+    first_name
+  )
+  VALUES (
+    $1::text,
+    $2::text
+  )
+  RETURNING id AS user_id, username, profile_image, first_name, middle_name, last_name, created_at, updated_at, blocked_at, blocked_until, deleted_at, role_name
+),
+new_identity AS (
+  INSERT INTO login_identity (
+    user_id,
+    identity_type
+  )
+  VALUES (
+    (SELECT user_id FROM new_user),
+    'guest'
+  )
+  RETURNING id
+),
+new_guest_login_identity AS (
+  INSERT INTO guest_login_identity (
+    login_identity_id,
+    device_id
+  )
+  VALUES (
+    (SELECT id FROM new_identity),
+    $3::text
+  )
+)
+SELECT u.user_id, u.username, u.profile_image, u.first_name, u.middle_name, u.last_name, u.created_at, u.updated_at, u.blocked_at, u.blocked_until, u.deleted_at, u.role_name, i.id AS new_login_identity_id FROM new_user AS u, new_identity AS i
+`
 
+type LoginIdentityCreateNewUserAndGuestLoginIdentityParams struct {
+	UserUsername  string `json:"user_username"`
+	UserFirstName string `json:"user_first_name"`
+	GuestDeviceID string `json:"guest_device_id"`
+}
 
+type LoginIdentityCreateNewUserAndGuestLoginIdentityRow struct {
+	UserID             int32              `json:"user_id"`
+	Username           string             `json:"username"`
+	ProfileImage       pgtype.Text        `json:"profile_image"`
+	FirstName          string             `json:"first_name"`
+	MiddleName         pgtype.Text        `json:"middle_name"`
+	LastName           pgtype.Text        `json:"last_name"`
+	CreatedAt          pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt          pgtype.Timestamptz `json:"updated_at"`
+	BlockedAt          pgtype.Timestamptz `json:"blocked_at"`
+	BlockedUntil       pgtype.Timestamptz `json:"blocked_until"`
+	DeletedAt          pgtype.Timestamptz `json:"deleted_at"`
+	RoleName           pgtype.Text        `json:"role_name"`
+	NewLoginIdentityID int32              `json:"new_login_identity_id"`
+}
+
+// LoginIdentityCreateNewUserAndGuestLoginIdentity
+//
+//	WITH new_user AS (
+//	  INSERT INTO users (
+//	    username,
+//	    first_name
+//	  )
+//	  VALUES (
+//	    $1::text,
+//	    $2::text
+//	  )
+//	  RETURNING id AS user_id, username, profile_image, first_name, middle_name, last_name, created_at, updated_at, blocked_at, blocked_until, deleted_at, role_name
+//	),
+//	new_identity AS (
+//	  INSERT INTO login_identity (
+//	    user_id,
+//	    identity_type
+//	  )
+//	  VALUES (
+//	    (SELECT user_id FROM new_user),
+//	    'guest'
+//	  )
+//	  RETURNING id
+//	),
+//	new_guest_login_identity AS (
+//	  INSERT INTO guest_login_identity (
+//	    login_identity_id,
+//	    device_id
+//	  )
+//	  VALUES (
+//	    (SELECT id FROM new_identity),
+//	    $3::text
+//	  )
+//	)
+//	SELECT u.user_id, u.username, u.profile_image, u.first_name, u.middle_name, u.last_name, u.created_at, u.updated_at, u.blocked_at, u.blocked_until, u.deleted_at, u.role_name, i.id AS new_login_identity_id FROM new_user AS u, new_identity AS i
+func (q *Queries) LoginIdentityCreateNewUserAndGuestLoginIdentity(ctx context.Context, arg LoginIdentityCreateNewUserAndGuestLoginIdentityParams) (LoginIdentityCreateNewUserAndGuestLoginIdentityRow, error) {
+	row := q.db.QueryRow(ctx, loginIdentityCreateNewUserAndGuestLoginIdentity, arg.UserUsername, arg.UserFirstName, arg.GuestDeviceID)
+	var i LoginIdentityCreateNewUserAndGuestLoginIdentityRow
+	err := row.Scan(
+		&i.UserID,
+		&i.Username,
+		&i.ProfileImage,
+		&i.FirstName,
+		&i.MiddleName,
+		&i.LastName,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.BlockedAt,
+		&i.BlockedUntil,
+		&i.DeletedAt,
+		&i.RoleName,
+		&i.NewLoginIdentityID,
+	)
+	return i, err
+}
+
+const loginIdentityCreateNewUserAndOIDCLoginIdentity = `-- name: LoginIdentityCreateNewUserAndOIDCLoginIdentity :one
 WITH new_user AS (
   INSERT INTO users (
     username,
@@ -319,40 +431,7 @@ type LoginIdentityCreateNewUserAndOIDCLoginIdentityRow struct {
 	NewLoginIdentityID int32              `json:"new_login_identity_id"`
 }
 
-// WITH new_user AS (
-//
-//	INSERT INTO users (username, first_name)
-//	VALUES (concat('guest_', gen_random_uuid()), 'Guest')
-//	RETURNING id
-//
-// ),
-// new_identity AS (
-//
-//	INSERT INTO login_identity (user_id)
-//	SELECT id FROM new_user
-//	RETURNING id, user_id
-//
-// ),
-// new_guest AS (
-//
-//	INSERT INTO guest_login_option (login_identity_id, device_id)
-//	SELECT id, $1 FROM new_identity
-//	RETURNING login_identity_id
-//
-// ),
-// new_session AS (
-//
-//	INSERT INTO session (token, originated_from, used_installation, expires_at)
-//	VALUES (
-//	    $2,                        -- token
-//	    (SELECT login_identity_id FROM new_guest),
-//	    $3,                        -- installation_id
-//	    NOW() + INTERVAL '30 days' -- expires
-//	)
-//	RETURNING token
-//
-// )
-// SELECT token FROM new_session;
+// LoginIdentityCreateNewUserAndOIDCLoginIdentity
 //
 //	WITH new_user AS (
 //	  INSERT INTO users (
@@ -699,6 +778,306 @@ func (q *Queries) LoginIdentityCreateNewUserAndPasswordLoginIdentity(ctx context
 	return i, err
 }
 
+const loginIdentityCreateOIDCLoginIdentityForUser = `-- name: LoginIdentityCreateOIDCLoginIdentityForUser :one
+WITH new_identity AS (
+  INSERT INTO login_identity (
+    user_id,
+    identity_type
+  )
+  VALUES (
+    $9::int,
+    'oidc'
+  )
+  RETURNING id
+),
+oauth_provider_record AS (
+    SELECT
+        $10::text AS provider_name,
+        $11::bool AS is_oidc_capable
+),
+oauth_provider_record_merge_op AS (
+    MERGE INTO oauth_provider AS target
+    USING oauth_provider_record AS r
+    ON target.name = r.provider_name AND target.is_oidc_capable = r.is_oidc_capable
+    WHEN NOT MATCHED THEN
+        INSERT (name, is_oidc_capable)
+        VALUES (r.provider_name, r.is_oidc_capable)
+),
+oauth_connection_record AS (
+    SELECT
+        (SELECT provider_name from oauth_provider_record) AS provider_name,
+        $12::text[] AS scopes
+),
+oauth_connection_record_merge_op AS (
+    MERGE INTO oauth_connection AS target
+    USING oauth_connection_record AS r
+    ON target.provider_name = r.provider_name AND target.scopes = r.scopes
+    WHEN NOT MATCHED THEN
+        INSERT (provider_name, scopes)
+        VALUES (r.provider_name, r.scopes)
+    RETURNING target.*
+),
+oauth_connection_row AS (
+    SELECT id, provider_name, scopes, created_at, updated_at, deleted_at FROM oauth_connection_record_merge_op
+    UNION ALL
+    SELECT id, provider_name, scopes, created_at, updated_at, deleted_at from oauth_connection
+        WHERE provider_name = (SELECT provider_name from oauth_provider_record)
+            AND scopes = (SELECT scopes from oauth_connection_record)
+),
+new_oauth_integration AS (
+    INSERT INTO oauth_integration (
+        oauth_connection_id,
+        integration_type
+    )
+    VALUES (
+        (SELECT id FROM oauth_connection_row),
+        'user'
+    )
+    RETURNING id
+),
+new_oauth_token AS (
+	INSERT INTO oauth_token (
+	    oauth_integration_id,
+	    access_token,
+	    refresh_token,
+	    token_type,
+	    expires_at,
+	    issued_at
+	)
+	SELECT
+	    (SELECT id FROM new_oauth_integration),
+	    $1::text,
+	    $2::text,
+	    $3::text,
+	    $13::timestamp,
+	    $14::timestamp
+	WHERE (
+	    $1::text IS NOT NULL AND $1::text <> ''
+	) OR (
+	    $2::text IS NOT NULL AND $2::text <> ''
+	)
+),
+new_user_integration AS (
+    INSERT INTO user_integration (
+        oauth_integration_id,
+        user_id
+    )
+    VALUES (
+        (SELECT id FROM new_oauth_integration),
+        $9::int
+    )
+    RETURNING id
+),
+new_oidc_data AS (
+    INSERT INTO oidc_data (
+        provider_name,
+        sub,
+        email,
+        iss,
+        aud,
+        given_name,
+        family_name,
+        name,
+        picture
+    )
+    VALUES (
+        (SELECT provider_name from oauth_provider_record),
+        $15::text,
+        $4::text,
+        $16::text,
+        $17::text,
+        $5::text,
+        $6::text,
+        $7::text,
+        $8::text
+    )
+    RETURNING id
+),
+new_oidc_login_identity AS (
+    INSERT INTO oidc_login_identity (
+        login_identity_id,
+        oidc_data_id
+    )
+    VALUES (
+        (SELECT id FROM new_identity),
+        (SELECT id FROM new_oidc_data)
+    )
+)
+SELECT id AS new_login_identity_id FROM new_identity
+`
+
+type LoginIdentityCreateOIDCLoginIdentityForUserParams struct {
+	OauthAccessToken           pgtype.Text      `json:"oauth_access_token"`
+	OauthRefreshToken          pgtype.Text      `json:"oauth_refresh_token"`
+	OauthTokenType             pgtype.Text      `json:"oauth_token_type"`
+	OidcEmail                  pgtype.Text      `json:"oidc_email"`
+	OidcGivenName              pgtype.Text      `json:"oidc_given_name"`
+	OidcFamilyName             pgtype.Text      `json:"oidc_family_name"`
+	OidcName                   pgtype.Text      `json:"oidc_name"`
+	OidcPicture                pgtype.Text      `json:"oidc_picture"`
+	UserID                     int32            `json:"user_id"`
+	OauthProviderName          string           `json:"oauth_provider_name"`
+	OauthProviderIsOidcCapable bool             `json:"oauth_provider_is_oidc_capable"`
+	OauthScopes                []string         `json:"oauth_scopes"`
+	OauthTokenExpiresAt        pgtype.Timestamp `json:"oauth_token_expires_at"`
+	OauthTokenIssuedAt         pgtype.Timestamp `json:"oauth_token_issued_at"`
+	OidcSub                    string           `json:"oidc_sub"`
+	OidcIss                    string           `json:"oidc_iss"`
+	OidcAud                    string           `json:"oidc_aud"`
+}
+
+// LoginIdentityCreateOIDCLoginIdentityForUser
+//
+//	WITH new_identity AS (
+//	  INSERT INTO login_identity (
+//	    user_id,
+//	    identity_type
+//	  )
+//	  VALUES (
+//	    $9::int,
+//	    'oidc'
+//	  )
+//	  RETURNING id
+//	),
+//	oauth_provider_record AS (
+//	    SELECT
+//	        $10::text AS provider_name,
+//	        $11::bool AS is_oidc_capable
+//	),
+//	oauth_provider_record_merge_op AS (
+//	    MERGE INTO oauth_provider AS target
+//	    USING oauth_provider_record AS r
+//	    ON target.name = r.provider_name AND target.is_oidc_capable = r.is_oidc_capable
+//	    WHEN NOT MATCHED THEN
+//	        INSERT (name, is_oidc_capable)
+//	        VALUES (r.provider_name, r.is_oidc_capable)
+//	),
+//	oauth_connection_record AS (
+//	    SELECT
+//	        (SELECT provider_name from oauth_provider_record) AS provider_name,
+//	        $12::text[] AS scopes
+//	),
+//	oauth_connection_record_merge_op AS (
+//	    MERGE INTO oauth_connection AS target
+//	    USING oauth_connection_record AS r
+//	    ON target.provider_name = r.provider_name AND target.scopes = r.scopes
+//	    WHEN NOT MATCHED THEN
+//	        INSERT (provider_name, scopes)
+//	        VALUES (r.provider_name, r.scopes)
+//	    RETURNING target.*
+//	),
+//	oauth_connection_row AS (
+//	    SELECT id, provider_name, scopes, created_at, updated_at, deleted_at FROM oauth_connection_record_merge_op
+//	    UNION ALL
+//	    SELECT id, provider_name, scopes, created_at, updated_at, deleted_at from oauth_connection
+//	        WHERE provider_name = (SELECT provider_name from oauth_provider_record)
+//	            AND scopes = (SELECT scopes from oauth_connection_record)
+//	),
+//	new_oauth_integration AS (
+//	    INSERT INTO oauth_integration (
+//	        oauth_connection_id,
+//	        integration_type
+//	    )
+//	    VALUES (
+//	        (SELECT id FROM oauth_connection_row),
+//	        'user'
+//	    )
+//	    RETURNING id
+//	),
+//	new_oauth_token AS (
+//		INSERT INTO oauth_token (
+//		    oauth_integration_id,
+//		    access_token,
+//		    refresh_token,
+//		    token_type,
+//		    expires_at,
+//		    issued_at
+//		)
+//		SELECT
+//		    (SELECT id FROM new_oauth_integration),
+//		    $1::text,
+//		    $2::text,
+//		    $3::text,
+//		    $13::timestamp,
+//		    $14::timestamp
+//		WHERE (
+//		    $1::text IS NOT NULL AND $1::text <> ''
+//		) OR (
+//		    $2::text IS NOT NULL AND $2::text <> ''
+//		)
+//	),
+//	new_user_integration AS (
+//	    INSERT INTO user_integration (
+//	        oauth_integration_id,
+//	        user_id
+//	    )
+//	    VALUES (
+//	        (SELECT id FROM new_oauth_integration),
+//	        $9::int
+//	    )
+//	    RETURNING id
+//	),
+//	new_oidc_data AS (
+//	    INSERT INTO oidc_data (
+//	        provider_name,
+//	        sub,
+//	        email,
+//	        iss,
+//	        aud,
+//	        given_name,
+//	        family_name,
+//	        name,
+//	        picture
+//	    )
+//	    VALUES (
+//	        (SELECT provider_name from oauth_provider_record),
+//	        $15::text,
+//	        $4::text,
+//	        $16::text,
+//	        $17::text,
+//	        $5::text,
+//	        $6::text,
+//	        $7::text,
+//	        $8::text
+//	    )
+//	    RETURNING id
+//	),
+//	new_oidc_login_identity AS (
+//	    INSERT INTO oidc_login_identity (
+//	        login_identity_id,
+//	        oidc_data_id
+//	    )
+//	    VALUES (
+//	        (SELECT id FROM new_identity),
+//	        (SELECT id FROM new_oidc_data)
+//	    )
+//	)
+//	SELECT id AS new_login_identity_id FROM new_identity
+func (q *Queries) LoginIdentityCreateOIDCLoginIdentityForUser(ctx context.Context, arg LoginIdentityCreateOIDCLoginIdentityForUserParams) (int32, error) {
+	row := q.db.QueryRow(ctx, loginIdentityCreateOIDCLoginIdentityForUser,
+		arg.OauthAccessToken,
+		arg.OauthRefreshToken,
+		arg.OauthTokenType,
+		arg.OidcEmail,
+		arg.OidcGivenName,
+		arg.OidcFamilyName,
+		arg.OidcName,
+		arg.OidcPicture,
+		arg.UserID,
+		arg.OauthProviderName,
+		arg.OauthProviderIsOidcCapable,
+		arg.OauthScopes,
+		arg.OauthTokenExpiresAt,
+		arg.OauthTokenIssuedAt,
+		arg.OidcSub,
+		arg.OidcIss,
+		arg.OidcAud,
+	)
+	var newLoginIdentityID int32
+	err := row.Scan(&newLoginIdentityID)
+	return newLoginIdentityID, err
+}
+
 const loginIdentityGetAllByUserId = `-- name: LoginIdentityGetAllByUserId :many
 SELECT
   li.id AS login_identity_id,
@@ -949,6 +1328,136 @@ func (q *Queries) LoginIdentityGetAllPasswordLoginIdentitiesByUserId(ctx context
 	return items, nil
 }
 
+const loginIdentityGetGuestLoginIdentityByUserId = `-- name: LoginIdentityGetGuestLoginIdentityByUserId :one
+SELECT
+    li.id AS login_identity_id,
+    gli.id AS guest_login_identity_id,
+    gli.device_id AS guest_device_id
+FROM active_login_identity AS li
+    JOIN active_guest_login_identity AS gli
+        ON li.id = gli.login_identity_id
+WHERE li.user_id = $1::int
+    AND li.identity_type = 'guest'
+LIMIT 1
+`
+
+type LoginIdentityGetGuestLoginIdentityByUserIdRow struct {
+	LoginIdentityID      int32  `json:"login_identity_id"`
+	GuestLoginIdentityID int32  `json:"guest_login_identity_id"`
+	GuestDeviceID        string `json:"guest_device_id"`
+}
+
+// LoginIdentityGetGuestLoginIdentityByUserId
+//
+//	SELECT
+//	    li.id AS login_identity_id,
+//	    gli.id AS guest_login_identity_id,
+//	    gli.device_id AS guest_device_id
+//	FROM active_login_identity AS li
+//	    JOIN active_guest_login_identity AS gli
+//	        ON li.id = gli.login_identity_id
+//	WHERE li.user_id = $1::int
+//	    AND li.identity_type = 'guest'
+//	LIMIT 1
+func (q *Queries) LoginIdentityGetGuestLoginIdentityByUserId(ctx context.Context, userID int32) (LoginIdentityGetGuestLoginIdentityByUserIdRow, error) {
+	row := q.db.QueryRow(ctx, loginIdentityGetGuestLoginIdentityByUserId, userID)
+	var i LoginIdentityGetGuestLoginIdentityByUserIdRow
+	err := row.Scan(&i.LoginIdentityID, &i.GuestLoginIdentityID, &i.GuestDeviceID)
+	return i, err
+}
+
+const loginIdentityGetGuestLoginIdentityWithUser = `-- name: LoginIdentityGetGuestLoginIdentityWithUser :one
+SELECT
+    li.id AS login_identity_id,
+    gli.id AS guest_login_identity_id,
+    gli.device_id AS guest_device_id,
+
+    u.id AS user_id,
+    u.username AS user_username,
+    u.profile_image AS user_profile_image,
+    u.first_name AS user_first_name,
+    u.middle_name AS user_middle_name,
+    u.last_name AS user_last_name,
+    u.created_at AS user_created_at,
+    u.updated_at AS user_updated_at,
+    u.blocked_at AS user_blocked_at,
+    u.blocked_until AS user_blocked_until,
+    u.role_name AS user_role_name
+FROM not_deleted_users AS u
+    JOIN active_login_identity AS li
+        ON u.id = li.user_id
+    JOIN active_guest_login_identity AS gli
+        ON li.id = gli.login_identity_id
+WHERE gli.device_id = $1::text
+    AND li.identity_type = 'guest'
+LIMIT 1
+`
+
+type LoginIdentityGetGuestLoginIdentityWithUserRow struct {
+	LoginIdentityID      int32              `json:"login_identity_id"`
+	GuestLoginIdentityID int32              `json:"guest_login_identity_id"`
+	GuestDeviceID        string             `json:"guest_device_id"`
+	UserID               int32              `json:"user_id"`
+	UserUsername         string             `json:"user_username"`
+	UserProfileImage     pgtype.Text        `json:"user_profile_image"`
+	UserFirstName        string             `json:"user_first_name"`
+	UserMiddleName       pgtype.Text        `json:"user_middle_name"`
+	UserLastName         pgtype.Text        `json:"user_last_name"`
+	UserCreatedAt        pgtype.Timestamptz `json:"user_created_at"`
+	UserUpdatedAt        pgtype.Timestamptz `json:"user_updated_at"`
+	UserBlockedAt        pgtype.Timestamptz `json:"user_blocked_at"`
+	UserBlockedUntil     pgtype.Timestamptz `json:"user_blocked_until"`
+	UserRoleName         pgtype.Text        `json:"user_role_name"`
+}
+
+// LoginIdentityGetGuestLoginIdentityWithUser
+//
+//	SELECT
+//	    li.id AS login_identity_id,
+//	    gli.id AS guest_login_identity_id,
+//	    gli.device_id AS guest_device_id,
+//
+//	    u.id AS user_id,
+//	    u.username AS user_username,
+//	    u.profile_image AS user_profile_image,
+//	    u.first_name AS user_first_name,
+//	    u.middle_name AS user_middle_name,
+//	    u.last_name AS user_last_name,
+//	    u.created_at AS user_created_at,
+//	    u.updated_at AS user_updated_at,
+//	    u.blocked_at AS user_blocked_at,
+//	    u.blocked_until AS user_blocked_until,
+//	    u.role_name AS user_role_name
+//	FROM not_deleted_users AS u
+//	    JOIN active_login_identity AS li
+//	        ON u.id = li.user_id
+//	    JOIN active_guest_login_identity AS gli
+//	        ON li.id = gli.login_identity_id
+//	WHERE gli.device_id = $1::text
+//	    AND li.identity_type = 'guest'
+//	LIMIT 1
+func (q *Queries) LoginIdentityGetGuestLoginIdentityWithUser(ctx context.Context, deviceID string) (LoginIdentityGetGuestLoginIdentityWithUserRow, error) {
+	row := q.db.QueryRow(ctx, loginIdentityGetGuestLoginIdentityWithUser, deviceID)
+	var i LoginIdentityGetGuestLoginIdentityWithUserRow
+	err := row.Scan(
+		&i.LoginIdentityID,
+		&i.GuestLoginIdentityID,
+		&i.GuestDeviceID,
+		&i.UserID,
+		&i.UserUsername,
+		&i.UserProfileImage,
+		&i.UserFirstName,
+		&i.UserMiddleName,
+		&i.UserLastName,
+		&i.UserCreatedAt,
+		&i.UserUpdatedAt,
+		&i.UserBlockedAt,
+		&i.UserBlockedUntil,
+		&i.UserRoleName,
+	)
+	return i, err
+}
+
 const loginIdentityGetOIDCDataBySub = `-- name: LoginIdentityGetOIDCDataBySub :one
 SELECT
     u.id AS user_id,
@@ -1316,6 +1825,38 @@ func (q *Queries) LoginIdentityIsPhoneUsed(ctx context.Context, phone pgtype.Tex
 	return count, err
 }
 
+const loginIdentitySoftDeleteGuestLoginIdentity = `-- name: LoginIdentitySoftDeleteGuestLoginIdentity :exec
+WITH soft_deleted_guest_login_identity AS (
+    UPDATE guest_login_identity
+    SET deleted_at = NOW()
+    WHERE login_identity_id = $1::int
+        AND deleted_at IS NULL
+)
+UPDATE login_identity
+SET deleted_at = NOW()
+WHERE id = $1::int
+    AND identity_type = 'guest'
+    AND deleted_at IS NULL
+`
+
+// LoginIdentitySoftDeleteGuestLoginIdentity
+//
+//	WITH soft_deleted_guest_login_identity AS (
+//	    UPDATE guest_login_identity
+//	    SET deleted_at = NOW()
+//	    WHERE login_identity_id = $1::int
+//	        AND deleted_at IS NULL
+//	)
+//	UPDATE login_identity
+//	SET deleted_at = NOW()
+//	WHERE id = $1::int
+//	    AND identity_type = 'guest'
+//	    AND deleted_at IS NULL
+func (q *Queries) LoginIdentitySoftDeleteGuestLoginIdentity(ctx context.Context, loginIdentityID int32) error {
+	_, err := q.db.Exec(ctx, loginIdentitySoftDeleteGuestLoginIdentity, loginIdentityID)
+	return err
+}
+
 const loginIdentityUpdateLastUsedAtToNow = `-- name: LoginIdentityUpdateLastUsedAtToNow :exec
 UPDATE login_identity SET
 last_used_at = NOW()
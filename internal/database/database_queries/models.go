@@ -117,6 +117,7 @@ type ActiveSession struct {
 	DeletedAt        pgtype.Timestamptz `json:"deleted_at"`
 	OriginatedFrom   int32              `json:"originated_from"`
 	UsedInstallation int32              `json:"used_installation"`
+	LastActiveAt     pgtype.Timestamptz `json:"last_active_at"`
 }
 
 type ActiveSystemIntegration struct {
@@ -127,6 +128,14 @@ type ActiveSystemIntegration struct {
 	DeletedAt          pgtype.Timestamptz `json:"deleted_at"`
 }
 
+type ActiveTodoListMember struct {
+	ListID    int32              `json:"list_id"`
+	UserID    int32              `json:"user_id"`
+	Role      string             `json:"role"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
 type ActiveUserIntegration struct {
 	ID                 int32              `json:"id"`
 	OauthIntegrationID int32              `json:"oauth_integration_id"`
@@ -203,6 +212,7 @@ type NotDeletedUser struct {
 	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
 	BlockedAt    pgtype.Timestamptz `json:"blocked_at"`
 	BlockedUntil pgtype.Timestamptz `json:"blocked_until"`
+	MfaEnforced  pgtype.Bool        `json:"mfa_enforced"`
 	DeletedAt    pgtype.Timestamptz `json:"deleted_at"`
 	RoleName     pgtype.Text        `json:"role_name"`
 }
@@ -321,6 +331,13 @@ type Session struct {
 	DeletedAt        pgtype.Timestamptz `json:"deleted_at"`
 	OriginatedFrom   int32              `json:"originated_from"`
 	UsedInstallation int32              `json:"used_installation"`
+	LastActiveAt     pgtype.Timestamptz `json:"last_active_at"`
+}
+
+type SessionUsedRefreshToken struct {
+	TokenHash string             `json:"token_hash"`
+	SessionID int32              `json:"session_id"`
+	UsedAt    pgtype.Timestamptz `json:"used_at"`
 }
 
 type Setting struct {
@@ -339,14 +356,138 @@ type SystemIntegration struct {
 }
 
 type Todo struct {
+	ID                 int32              `json:"id"`
+	Title              string             `json:"title"`
+	Body               string             `json:"body"`
+	Status             string             `json:"status"`
+	CreatedAt          pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt          pgtype.Timestamptz `json:"updated_at"`
+	DeletedAt          pgtype.Timestamptz `json:"deleted_at"`
+	UserID             int32              `json:"user_id"`
+	DueAt              pgtype.Timestamptz `json:"due_at"`
+	RemindAt           pgtype.Timestamptz `json:"remind_at"`
+	ReminderSentAt     pgtype.Timestamptz `json:"reminder_sent_at"`
+	ListID             pgtype.Int4        `json:"list_id"`
+	RecurrenceRule     pgtype.Text        `json:"recurrence_rule"`
+	RecurrenceTimezone string             `json:"recurrence_timezone"`
+	RecurrenceStart    pgtype.Timestamptz `json:"recurrence_start"`
+	Version            int32              `json:"version"`
+	ReminderAttempts   int16              `json:"reminder_attempts"`
+}
+
+type TodoActivity struct {
 	ID        int32              `json:"id"`
-	Title     string             `json:"title"`
+	TodoID    int32              `json:"todo_id"`
+	UserID    pgtype.Int4        `json:"user_id"`
+	Action    string             `json:"action"`
+	Changes   []byte             `json:"changes"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type TodoAttachment struct {
+	ID          int32              `json:"id"`
+	TodoID      int32              `json:"todo_id"`
+	UserID      pgtype.Int4        `json:"user_id"`
+	StorageKey  string             `json:"storage_key"`
+	Filename    string             `json:"filename"`
+	ContentType string             `json:"content_type"`
+	SizeBytes   int64              `json:"size_bytes"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+}
+
+type TodoAttachmentDeletedBlob struct {
+	StorageKey string             `json:"storage_key"`
+	DeletedAt  pgtype.Timestamptz `json:"deleted_at"`
+}
+
+type TodoChecklistItem struct {
+	ID        int32              `json:"id"`
+	TodoID    int32              `json:"todo_id"`
+	Text      string             `json:"text"`
+	IsDone    bool               `json:"is_done"`
+	Position  int32              `json:"position"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+type TodoComment struct {
+	ID        int32              `json:"id"`
+	TodoID    int32              `json:"todo_id"`
+	UserID    pgtype.Int4        `json:"user_id"`
 	Body      string             `json:"body"`
-	Status    string             `json:"status"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
-	DeletedAt pgtype.Timestamptz `json:"deleted_at"`
+}
+
+type TodoList struct {
+	ID         int32              `json:"id"`
+	UserID     int32              `json:"user_id"`
+	Name       string             `json:"name"`
+	Color      pgtype.Text        `json:"color"`
+	Position   int32              `json:"position"`
+	IsArchived bool               `json:"is_archived"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt  pgtype.Timestamptz `json:"updated_at"`
+	DeletedAt  pgtype.Timestamptz `json:"deleted_at"`
+}
+
+type TodoListInvitation struct {
+	ID          int32              `json:"id"`
+	ListID      int32              `json:"list_id"`
+	InviterID   int32              `json:"inviter_id"`
+	InviteeID   int32              `json:"invitee_id"`
+	Role        string             `json:"role"`
+	Status      string             `json:"status"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+	RespondedAt pgtype.Timestamptz `json:"responded_at"`
+}
+
+type TodoListMember struct {
+	ListID    int32              `json:"list_id"`
+	UserID    int32              `json:"user_id"`
+	Role      string             `json:"role"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+type TodoSyncChange struct {
+	TodoID int32 `json:"todo_id"`
+	Xid    int64 `json:"xid"`
+}
+
+type TodoSyncTombstone struct {
+	ID        int32              `json:"id"`
+	TodoID    int32              `json:"todo_id"`
 	UserID    int32              `json:"user_id"`
+	ListID    pgtype.Int4        `json:"list_id"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	Xid       int64              `json:"xid"`
+}
+
+type TodoTag struct {
+	ID        int32              `json:"id"`
+	UserID    int32              `json:"user_id"`
+	Name      string             `json:"name"`
+	Color     pgtype.Text        `json:"color"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+type TodoTagLink struct {
+	TodoID    int32              `json:"todo_id"`
+	TagID     int32              `json:"tag_id"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type TodoWorkflowStatus struct {
+	ID          int32              `json:"id"`
+	UserID      int32              `json:"user_id"`
+	Name        string             `json:"name"`
+	Position    int32              `json:"position"`
+	IsCompleted bool               `json:"is_completed"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
 }
 
 type User struct {
@@ -360,10 +501,21 @@ type User struct {
 	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
 	BlockedAt    pgtype.Timestamptz `json:"blocked_at"`
 	BlockedUntil pgtype.Timestamptz `json:"blocked_until"`
+	MfaEnforced  pgtype.Bool        `json:"mfa_enforced"`
 	DeletedAt    pgtype.Timestamptz `json:"deleted_at"`
 	RoleName     pgtype.Text        `json:"role_name"`
 }
 
+type UserBlock struct {
+	ID           int32              `json:"id"`
+	UserID       int32              `json:"user_id"`
+	BlockedBy    pgtype.Int4        `json:"blocked_by"`
+	Reason       string             `json:"reason"`
+	BlockedUntil pgtype.Timestamptz `json:"blocked_until"`
+	UnblockedAt  pgtype.Timestamptz `json:"unblocked_at"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+}
+
 type UserIntegration struct {
 	ID                 int32              `json:"id"`
 	OauthIntegrationID int32              `json:"oauth_integration_id"`
@@ -372,3 +524,21 @@ type UserIntegration struct {
 	UpdatedAt          pgtype.Timestamptz `json:"updated_at"`
 	DeletedAt          pgtype.Timestamptz `json:"deleted_at"`
 }
+
+type UserMfaRecoveryCode struct {
+	ID        int32              `json:"id"`
+	UserID    int32              `json:"user_id"`
+	CodeHash  string             `json:"code_hash"`
+	UsedAt    pgtype.Timestamptz `json:"used_at"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type UserTotp struct {
+	ID           int32              `json:"id"`
+	UserID       int32              `json:"user_id"`
+	Secret       string             `json:"secret"`
+	ConfirmedAt  pgtype.Timestamptz `json:"confirmed_at"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+	LastUsedStep pgtype.Int8        `json:"last_used_step"`
+}
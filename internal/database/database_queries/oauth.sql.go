@@ -164,3 +164,36 @@ func (q *Queries) OauthCreateConnectionWithIntegrationDataAndTokens(ctx context.
 	)
 	return err
 }
+
+const oauthCreateProvider = `-- name: OauthCreateProvider :exec
+INSERT INTO oauth_provider (
+    name,
+    is_oidc_capable
+)
+VALUES (
+    $1::text,
+    $2::bool
+)
+ON CONFLICT (name) DO NOTHING
+`
+
+type OauthCreateProviderParams struct {
+	Name          string `json:"name"`
+	IsOidcCapable bool   `json:"is_oidc_capable"`
+}
+
+// OauthCreateProvider
+//
+//	INSERT INTO oauth_provider (
+//	    name,
+//	    is_oidc_capable
+//	)
+//	VALUES (
+//	    $1::text,
+//	    $2::bool
+//	)
+//	ON CONFLICT (name) DO NOTHING
+func (q *Queries) OauthCreateProvider(ctx context.Context, arg OauthCreateProviderParams) error {
+	_, err := q.db.Exec(ctx, oauthCreateProvider, arg.Name, arg.IsOidcCapable)
+	return err
+}
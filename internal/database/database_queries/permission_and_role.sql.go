@@ -13,7 +13,7 @@ import (
 
 const permAddPermissionToRole = `-- name: PermAddPermissionToRole :exec
 INSERT INTO role_permission(role_name, permission_name)
-VALUES($1, $2)
+VALUES($1, $2) ON CONFLICT (role_name, permission_name) DO NOTHING
 `
 
 type PermAddPermissionToRoleParams struct {
@@ -24,7 +24,7 @@ type PermAddPermissionToRoleParams struct {
 // PermAddPermissionToRole
 //
 //	INSERT INTO role_permission(role_name, permission_name)
-//	VALUES($1, $2)
+//	VALUES($1, $2) ON CONFLICT (role_name, permission_name) DO NOTHING
 func (q *Queries) PermAddPermissionToRole(ctx context.Context, arg PermAddPermissionToRoleParams) error {
 	_, err := q.db.Exec(ctx, permAddPermissionToRole, arg.RoleName, arg.PermissionName)
 	return err
@@ -37,14 +37,20 @@ type PermAddPermissionsToRolesParams struct {
 
 const permCreateNewPermission = `-- name: PermCreateNewPermission :one
 INSERT INTO permission(name)
-VALUES($1)
+VALUES($1) ON CONFLICT (name) DO
+UPDATE
+SET deleted_at = NULL
+WHERE permission.deleted_at IS NOT NULL
 RETURNING name, created_at, updated_at, deleted_at
 `
 
 // PermCreateNewPermission
 //
 //	INSERT INTO permission(name)
-//	VALUES($1)
+//	VALUES($1) ON CONFLICT (name) DO
+//	UPDATE
+//	SET deleted_at = NULL
+//	WHERE permission.deleted_at IS NOT NULL
 //	RETURNING name, created_at, updated_at, deleted_at
 func (q *Queries) PermCreateNewPermission(ctx context.Context, name string) (Permission, error) {
 	row := q.db.QueryRow(ctx, permCreateNewPermission, name)
@@ -60,14 +66,20 @@ func (q *Queries) PermCreateNewPermission(ctx context.Context, name string) (Per
 
 const permCreateNewRole = `-- name: PermCreateNewRole :one
 INSERT INTO role(name)
-VALUES($1)
+VALUES($1) ON CONFLICT (name) DO
+UPDATE
+SET deleted_at = NULL
+WHERE role.deleted_at IS NOT NULL
 RETURNING name, created_at, updated_at, deleted_at
 `
 
 // PermCreateNewRole
 //
 //	INSERT INTO role(name)
-//	VALUES($1)
+//	VALUES($1) ON CONFLICT (name) DO
+//	UPDATE
+//	SET deleted_at = NULL
+//	WHERE role.deleted_at IS NOT NULL
 //	RETURNING name, created_at, updated_at, deleted_at
 func (q *Queries) PermCreateNewRole(ctx context.Context, name string) (Role, error) {
 	row := q.db.QueryRow(ctx, permCreateNewRole, name)
@@ -87,6 +99,8 @@ SELECT
     created_at,
     updated_at
 FROM permission
+WHERE deleted_at IS NULL
+ORDER BY name
 `
 
 type PermGetAllPermissionsRow struct {
@@ -102,6 +116,8 @@ type PermGetAllPermissionsRow struct {
 //	    created_at,
 //	    updated_at
 //	FROM permission
+//	WHERE deleted_at IS NULL
+//	ORDER BY name
 func (q *Queries) PermGetAllPermissions(ctx context.Context) ([]PermGetAllPermissionsRow, error) {
 	rows, err := q.db.Query(ctx, permGetAllPermissions)
 	if err != nil {
@@ -128,6 +144,8 @@ SELECT
     created_at,
     updated_at
 FROM role
+WHERE deleted_at IS NULL
+ORDER BY name
 `
 
 type PermGetAllRolesRow struct {
@@ -143,6 +161,8 @@ type PermGetAllRolesRow struct {
 //	    created_at,
 //	    updated_at
 //	FROM role
+//	WHERE deleted_at IS NULL
+//	ORDER BY name
 func (q *Queries) PermGetAllRoles(ctx context.Context) ([]PermGetAllRolesRow, error) {
 	rows, err := q.db.Query(ctx, permGetAllRoles)
 	if err != nil {
@@ -163,6 +183,70 @@ func (q *Queries) PermGetAllRoles(ctx context.Context) ([]PermGetAllRolesRow, er
 	return items, nil
 }
 
+const permGetPermissionByName = `-- name: PermGetPermissionByName :one
+SELECT
+    name,
+    created_at,
+    updated_at
+FROM permission
+WHERE name = $1
+    AND deleted_at IS NULL
+`
+
+type PermGetPermissionByNameRow struct {
+	Name      string             `json:"name"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+// PermGetPermissionByName
+//
+//	SELECT
+//	    name,
+//	    created_at,
+//	    updated_at
+//	FROM permission
+//	WHERE name = $1
+//	    AND deleted_at IS NULL
+func (q *Queries) PermGetPermissionByName(ctx context.Context, name string) (PermGetPermissionByNameRow, error) {
+	row := q.db.QueryRow(ctx, permGetPermissionByName, name)
+	var i PermGetPermissionByNameRow
+	err := row.Scan(&i.Name, &i.CreatedAt, &i.UpdatedAt)
+	return i, err
+}
+
+const permGetRoleByName = `-- name: PermGetRoleByName :one
+SELECT
+    name,
+    created_at,
+    updated_at
+FROM role
+WHERE name = $1
+    AND deleted_at IS NULL
+`
+
+type PermGetRoleByNameRow struct {
+	Name      string             `json:"name"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+// PermGetRoleByName
+//
+//	SELECT
+//	    name,
+//	    created_at,
+//	    updated_at
+//	FROM role
+//	WHERE name = $1
+//	    AND deleted_at IS NULL
+func (q *Queries) PermGetRoleByName(ctx context.Context, name string) (PermGetRoleByNameRow, error) {
+	row := q.db.QueryRow(ctx, permGetRoleByName, name)
+	var i PermGetRoleByNameRow
+	err := row.Scan(&i.Name, &i.CreatedAt, &i.UpdatedAt)
+	return i, err
+}
+
 const permGetRoleWithItsPermissions = `-- name: PermGetRoleWithItsPermissions :many
 SELECT
     r.name as role_name,
@@ -171,6 +255,8 @@ FROM role AS r
     JOIN role_permission AS rp ON r.name = rp.role_name
     JOIN permission AS p ON p.name = rp.permission_name
 WHERE r.name = $1
+    AND r.deleted_at IS NULL
+    AND p.deleted_at IS NULL
 `
 
 type PermGetRoleWithItsPermissionsRow struct {
@@ -187,6 +273,8 @@ type PermGetRoleWithItsPermissionsRow struct {
 //	    JOIN role_permission AS rp ON r.name = rp.role_name
 //	    JOIN permission AS p ON p.name = rp.permission_name
 //	WHERE r.name = $1
+//	    AND r.deleted_at IS NULL
+//	    AND p.deleted_at IS NULL
 func (q *Queries) PermGetRoleWithItsPermissions(ctx context.Context, name string) ([]PermGetRoleWithItsPermissionsRow, error) {
 	rows, err := q.db.Query(ctx, permGetRoleWithItsPermissions, name)
 	if err != nil {
@@ -207,7 +295,52 @@ func (q *Queries) PermGetRoleWithItsPermissions(ctx context.Context, name string
 	return items, nil
 }
 
-const permRemovePermissionFromRole = `-- name: PermRemovePermissionFromRole :exec
+const permRemoveAllPermissionsFromRole = `-- name: PermRemoveAllPermissionsFromRole :exec
+DELETE FROM role_permission
+WHERE role_name = $1
+`
+
+// PermRemoveAllPermissionsFromRole
+//
+//	DELETE FROM role_permission
+//	WHERE role_name = $1
+func (q *Queries) PermRemoveAllPermissionsFromRole(ctx context.Context, roleName string) error {
+	_, err := q.db.Exec(ctx, permRemoveAllPermissionsFromRole, roleName)
+	return err
+}
+
+const permRemovePermissionFromAllRoles = `-- name: PermRemovePermissionFromAllRoles :many
+DELETE FROM role_permission
+WHERE permission_name = $1
+RETURNING role_name
+`
+
+// PermRemovePermissionFromAllRoles
+//
+//	DELETE FROM role_permission
+//	WHERE permission_name = $1
+//	RETURNING role_name
+func (q *Queries) PermRemovePermissionFromAllRoles(ctx context.Context, permissionName string) ([]string, error) {
+	rows, err := q.db.Query(ctx, permRemovePermissionFromAllRoles, permissionName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var roleName string
+		if err := rows.Scan(&roleName); err != nil {
+			return nil, err
+		}
+		items = append(items, roleName)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const permRemovePermissionFromRole = `-- name: PermRemovePermissionFromRole :execrows
 DELETE FROM role_permission
 WHERE role_name = $1
     AND permission_name = $2
@@ -223,15 +356,19 @@ type PermRemovePermissionFromRoleParams struct {
 //	DELETE FROM role_permission
 //	WHERE role_name = $1
 //	    AND permission_name = $2
-func (q *Queries) PermRemovePermissionFromRole(ctx context.Context, arg PermRemovePermissionFromRoleParams) error {
-	_, err := q.db.Exec(ctx, permRemovePermissionFromRole, arg.RoleName, arg.PermissionName)
-	return err
+func (q *Queries) PermRemovePermissionFromRole(ctx context.Context, arg PermRemovePermissionFromRoleParams) (int64, error) {
+	result, err := q.db.Exec(ctx, permRemovePermissionFromRole, arg.RoleName, arg.PermissionName)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
-const permSoftDeletePermission = `-- name: PermSoftDeletePermission :exec
+const permSoftDeletePermission = `-- name: PermSoftDeletePermission :execrows
 UPDATE permission
 SET deleted_at = NOW()
 WHERE name = $1
+    AND deleted_at IS NULL
 `
 
 // PermSoftDeletePermission
@@ -239,15 +376,20 @@ WHERE name = $1
 //	UPDATE permission
 //	SET deleted_at = NOW()
 //	WHERE name = $1
-func (q *Queries) PermSoftDeletePermission(ctx context.Context, name string) error {
-	_, err := q.db.Exec(ctx, permSoftDeletePermission, name)
-	return err
+//	    AND deleted_at IS NULL
+func (q *Queries) PermSoftDeletePermission(ctx context.Context, name string) (int64, error) {
+	result, err := q.db.Exec(ctx, permSoftDeletePermission, name)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
-const permSoftDeleteRole = `-- name: PermSoftDeleteRole :exec
+const permSoftDeleteRole = `-- name: PermSoftDeleteRole :execrows
 UPDATE role
 SET deleted_at = NOW()
 WHERE name = $1
+    AND deleted_at IS NULL
 `
 
 // PermSoftDeleteRole
@@ -255,7 +397,27 @@ WHERE name = $1
 //	UPDATE role
 //	SET deleted_at = NOW()
 //	WHERE name = $1
-func (q *Queries) PermSoftDeleteRole(ctx context.Context, name string) error {
-	_, err := q.db.Exec(ctx, permSoftDeleteRole, name)
+//	    AND deleted_at IS NULL
+func (q *Queries) PermSoftDeleteRole(ctx context.Context, name string) (int64, error) {
+	result, err := q.db.Exec(ctx, permSoftDeleteRole, name)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const permUnsetRoleForAllUsers = `-- name: PermUnsetRoleForAllUsers :exec
+UPDATE users
+SET role_name = NULL
+WHERE role_name = $1
+`
+
+// PermUnsetRoleForAllUsers
+//
+//	UPDATE users
+//	SET role_name = NULL
+//	WHERE role_name = $1
+func (q *Queries) PermUnsetRoleForAllUsers(ctx context.Context, roleName pgtype.Text) error {
+	_, err := q.db.Exec(ctx, permUnsetRoleForAllUsers, roleName)
 	return err
 }
@@ -12,6 +12,37 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const sessionAddUsedRefreshToken = `-- name: SessionAddUsedRefreshToken :exec
+INSERT INTO session_used_refresh_token (
+    token_hash,
+    session_id
+)
+VALUES (
+    $1::text,
+    $2::int
+)
+`
+
+type SessionAddUsedRefreshTokenParams struct {
+	TokenHash string `json:"token_hash"`
+	SessionID int32  `json:"session_id"`
+}
+
+// SessionAddUsedRefreshToken
+//
+//	INSERT INTO session_used_refresh_token (
+//	    token_hash,
+//	    session_id
+//	)
+//	VALUES (
+//	    $1::text,
+//	    $2::int
+//	)
+func (q *Queries) SessionAddUsedRefreshToken(ctx context.Context, arg SessionAddUsedRefreshTokenParams) error {
+	_, err := q.db.Exec(ctx, sessionAddUsedRefreshToken, arg.TokenHash, arg.SessionID)
+	return err
+}
+
 const sessionCreateNewSession = `-- name: SessionCreateNewSession :one
 INSERT INTO session (
         token,
@@ -57,7 +88,7 @@ func (q *Queries) SessionCreateNewSession(ctx context.Context, arg SessionCreate
 }
 
 const sessionGetActiveSessionById = `-- name: SessionGetActiveSessionById :one
-SELECT id, token, ip_address, created_at, updated_at, expires_at, deleted_at, originated_from, used_installation
+SELECT id, token, ip_address, created_at, updated_at, expires_at, deleted_at, originated_from, used_installation, last_active_at
 FROM active_session
 WHERE id = $1
 LIMIT 1
@@ -65,7 +96,7 @@ LIMIT 1
 
 // SessionGetActiveSessionById
 //
-//	SELECT id, token, ip_address, created_at, updated_at, expires_at, deleted_at, originated_from, used_installation
+//	SELECT id, token, ip_address, created_at, updated_at, expires_at, deleted_at, originated_from, used_installation, last_active_at
 //	FROM active_session
 //	WHERE id = $1
 //	LIMIT 1
@@ -82,12 +113,13 @@ func (q *Queries) SessionGetActiveSessionById(ctx context.Context, id int32) (Ac
 		&i.DeletedAt,
 		&i.OriginatedFrom,
 		&i.UsedInstallation,
+		&i.LastActiveAt,
 	)
 	return i, err
 }
 
 const sessionGetActiveSessionByToken = `-- name: SessionGetActiveSessionByToken :one
-SELECT id, token, ip_address, created_at, updated_at, expires_at, deleted_at, originated_from, used_installation
+SELECT id, token, ip_address, created_at, updated_at, expires_at, deleted_at, originated_from, used_installation, last_active_at
 FROM active_session
 WHERE token = $1
 LIMIT 1
@@ -95,7 +127,7 @@ LIMIT 1
 
 // SessionGetActiveSessionByToken
 //
-//	SELECT id, token, ip_address, created_at, updated_at, expires_at, deleted_at, originated_from, used_installation
+//	SELECT id, token, ip_address, created_at, updated_at, expires_at, deleted_at, originated_from, used_installation, last_active_at
 //	FROM active_session
 //	WHERE token = $1
 //	LIMIT 1
@@ -112,10 +144,222 @@ func (q *Queries) SessionGetActiveSessionByToken(ctx context.Context, token stri
 		&i.DeletedAt,
 		&i.OriginatedFrom,
 		&i.UsedInstallation,
+		&i.LastActiveAt,
+	)
+	return i, err
+}
+
+const sessionGetActiveSessionForUser = `-- name: SessionGetActiveSessionForUser :one
+SELECT s.id, s.token, s.ip_address, s.created_at, s.updated_at, s.expires_at, s.deleted_at, s.originated_from, s.used_installation, s.last_active_at
+FROM active_session AS s
+    JOIN active_login_identity AS li ON s.originated_from = li.id
+WHERE s.id = $1::int
+    AND li.user_id = $2::int
+LIMIT 1
+`
+
+type SessionGetActiveSessionForUserParams struct {
+	SessionID int32 `json:"session_id"`
+	UserID    int32 `json:"user_id"`
+}
+
+// SessionGetActiveSessionForUser
+//
+//	SELECT s.id, s.token, s.ip_address, s.created_at, s.updated_at, s.expires_at, s.deleted_at, s.originated_from, s.used_installation, s.last_active_at
+//	FROM active_session AS s
+//	    JOIN active_login_identity AS li ON s.originated_from = li.id
+//	WHERE s.id = $1::int
+//	    AND li.user_id = $2::int
+//	LIMIT 1
+func (q *Queries) SessionGetActiveSessionForUser(ctx context.Context, arg SessionGetActiveSessionForUserParams) (ActiveSession, error) {
+	row := q.db.QueryRow(ctx, sessionGetActiveSessionForUser, arg.SessionID, arg.UserID)
+	var i ActiveSession
+	err := row.Scan(
+		&i.ID,
+		&i.Token,
+		&i.IpAddress,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExpiresAt,
+		&i.DeletedAt,
+		&i.OriginatedFrom,
+		&i.UsedInstallation,
+		&i.LastActiveAt,
 	)
 	return i, err
 }
 
+const sessionGetActiveSessionsForUser = `-- name: SessionGetActiveSessionsForUser :many
+SELECT
+    s.id,
+    s.ip_address,
+    s.created_at,
+    s.expires_at,
+    s.last_active_at,
+    li.identity_type AS login_identity_type,
+    i.id AS installation_id,
+    i.device_os,
+    i.device_os_version,
+    i.device_manufacturer,
+    i.client_type,
+    i.app_version
+FROM active_session AS s
+    JOIN active_login_identity AS li ON s.originated_from = li.id
+    JOIN installation AS i ON s.used_installation = i.id
+WHERE li.user_id = $1::int
+ORDER BY s.last_active_at DESC
+`
+
+type SessionGetActiveSessionsForUserRow struct {
+	ID                 int32              `json:"id"`
+	IpAddress          netip.Addr         `json:"ip_address"`
+	CreatedAt          pgtype.Timestamptz `json:"created_at"`
+	ExpiresAt          pgtype.Timestamptz `json:"expires_at"`
+	LastActiveAt       pgtype.Timestamptz `json:"last_active_at"`
+	LoginIdentityType  string             `json:"login_identity_type"`
+	InstallationID     int32              `json:"installation_id"`
+	DeviceOs           string             `json:"device_os"`
+	DeviceOsVersion    pgtype.Text        `json:"device_os_version"`
+	DeviceManufacturer pgtype.Text        `json:"device_manufacturer"`
+	ClientType         string             `json:"client_type"`
+	AppVersion         string             `json:"app_version"`
+}
+
+// SessionGetActiveSessionsForUser
+//
+//	SELECT
+//	    s.id,
+//	    s.ip_address,
+//	    s.created_at,
+//	    s.expires_at,
+//	    s.last_active_at,
+//	    li.identity_type AS login_identity_type,
+//	    i.id AS installation_id,
+//	    i.device_os,
+//	    i.device_os_version,
+//	    i.device_manufacturer,
+//	    i.client_type,
+//	    i.app_version
+//	FROM active_session AS s
+//	    JOIN active_login_identity AS li ON s.originated_from = li.id
+//	    JOIN installation AS i ON s.used_installation = i.id
+//	WHERE li.user_id = $1::int
+//	ORDER BY s.last_active_at DESC
+func (q *Queries) SessionGetActiveSessionsForUser(ctx context.Context, userID int32) ([]SessionGetActiveSessionsForUserRow, error) {
+	rows, err := q.db.Query(ctx, sessionGetActiveSessionsForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SessionGetActiveSessionsForUserRow{}
+	for rows.Next() {
+		var i SessionGetActiveSessionsForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.IpAddress,
+			&i.CreatedAt,
+			&i.ExpiresAt,
+			&i.LastActiveAt,
+			&i.LoginIdentityType,
+			&i.InstallationID,
+			&i.DeviceOs,
+			&i.DeviceOsVersion,
+			&i.DeviceManufacturer,
+			&i.ClientType,
+			&i.AppVersion,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const sessionGetSessionIdByUsedRefreshToken = `-- name: SessionGetSessionIdByUsedRefreshToken :one
+SELECT session_id
+FROM session_used_refresh_token
+WHERE token_hash = $1::text
+LIMIT 1
+`
+
+// SessionGetSessionIdByUsedRefreshToken
+//
+//	SELECT session_id
+//	FROM session_used_refresh_token
+//	WHERE token_hash = $1::text
+//	LIMIT 1
+func (q *Queries) SessionGetSessionIdByUsedRefreshToken(ctx context.Context, tokenHash string) (int32, error) {
+	row := q.db.QueryRow(ctx, sessionGetSessionIdByUsedRefreshToken, tokenHash)
+	var sessionID int32
+	err := row.Scan(&sessionID)
+	return sessionID, err
+}
+
+const sessionMoveActiveSessionsToLoginIdentity = `-- name: SessionMoveActiveSessionsToLoginIdentity :exec
+UPDATE session
+SET originated_from = $1::int
+WHERE originated_from = $2::int
+    AND deleted_at IS NULL
+`
+
+type SessionMoveActiveSessionsToLoginIdentityParams struct {
+	ToLoginIdentityID   int32 `json:"to_login_identity_id"`
+	FromLoginIdentityID int32 `json:"from_login_identity_id"`
+}
+
+// SessionMoveActiveSessionsToLoginIdentity
+//
+//	UPDATE session
+//	SET originated_from = $1::int
+//	WHERE originated_from = $2::int
+//	    AND deleted_at IS NULL
+func (q *Queries) SessionMoveActiveSessionsToLoginIdentity(ctx context.Context, arg SessionMoveActiveSessionsToLoginIdentityParams) error {
+	_, err := q.db.Exec(ctx, sessionMoveActiveSessionsToLoginIdentity, arg.ToLoginIdentityID, arg.FromLoginIdentityID)
+	return err
+}
+
+const sessionRotateToken = `-- name: SessionRotateToken :execrows
+UPDATE session
+SET token = $1::text,
+    expires_at = $2::timestamptz
+WHERE id = $3::int
+    AND token = $4::text
+    AND expires_at > NOW()
+    AND deleted_at IS NULL
+`
+
+type SessionRotateTokenParams struct {
+	NewToken  string             `json:"new_token"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+	ID        int32              `json:"id"`
+	OldToken  string             `json:"old_token"`
+}
+
+// SessionRotateToken
+//
+//	UPDATE session
+//	SET token = $1::text,
+//	    expires_at = $2::timestamptz
+//	WHERE id = $3::int
+//	    AND token = $4::text
+//	    AND expires_at > NOW()
+//	    AND deleted_at IS NULL
+func (q *Queries) SessionRotateToken(ctx context.Context, arg SessionRotateTokenParams) (int64, error) {
+	result, err := q.db.Exec(ctx, sessionRotateToken,
+		arg.NewToken,
+		arg.ExpiresAt,
+		arg.ID,
+		arg.OldToken,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const sessionSoftDeleteAllActiveSessionsForUser = `-- name: SessionSoftDeleteAllActiveSessionsForUser :exec
 UPDATE active_session AS s
 SET deleted_at = NOW()
@@ -153,3 +397,21 @@ func (q *Queries) SessionSoftDeleteSession(ctx context.Context, id int32) error
 	_, err := q.db.Exec(ctx, sessionSoftDeleteSession, id)
 	return err
 }
+
+const sessionTouchLastActiveAt = `-- name: SessionTouchLastActiveAt :exec
+UPDATE session
+SET last_active_at = NOW()
+WHERE id = $1::int
+    AND last_active_at < NOW() - INTERVAL '1 minute'
+`
+
+// SessionTouchLastActiveAt
+//
+//	UPDATE session
+//	SET last_active_at = NOW()
+//	WHERE id = $1::int
+//	    AND last_active_at < NOW() - INTERVAL '1 minute'
+func (q *Queries) SessionTouchLastActiveAt(ctx context.Context, id int32) error {
+	_, err := q.db.Exec(ctx, sessionTouchLastActiveAt, id)
+	return err
+}
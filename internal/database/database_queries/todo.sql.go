@@ -11,37 +11,520 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const todoChangeStatusOfUserTodos = `-- name: TodoChangeStatusOfUserTodos :many
+UPDATE todo
+SET status = $1
+WHERE user_id = $2
+    AND status = $3
+RETURNING id
+`
+
+type TodoChangeStatusOfUserTodosParams struct {
+	ToStatus   string `json:"to_status"`
+	UserID     int32  `json:"user_id"`
+	FromStatus string `json:"from_status"`
+}
+
+// TodoChangeStatusOfUserTodos
+//
+//	UPDATE todo
+//	SET status = $1
+//	WHERE user_id = $2
+//	    AND status = $3
+//	RETURNING id
+func (q *Queries) TodoChangeStatusOfUserTodos(ctx context.Context, arg TodoChangeStatusOfUserTodosParams) ([]int32, error) {
+	rows, err := q.db.Query(ctx, todoChangeStatusOfUserTodos, arg.ToStatus, arg.UserID, arg.FromStatus)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int32{}
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const todoClaimDueReminders = `-- name: TodoClaimDueReminders :many
+UPDATE todo
+SET
+    reminder_sent_at = NOW(),
+    reminder_attempts = reminder_attempts + 1
+WHERE id IN (
+        SELECT t.id
+        FROM todo AS t
+        WHERE t.remind_at <= NOW()
+            AND t.reminder_sent_at IS NULL
+            AND t.deleted_at IS NULL
+        ORDER BY t.remind_at
+        LIMIT $1
+        FOR UPDATE SKIP LOCKED
+    )
+RETURNING id, title, due_at, remind_at, user_id
+`
+
+type TodoClaimDueRemindersRow struct {
+	ID       int32              `json:"id"`
+	Title    string             `json:"title"`
+	DueAt    pgtype.Timestamptz `json:"due_at"`
+	RemindAt pgtype.Timestamptz `json:"remind_at"`
+	UserID   int32              `json:"user_id"`
+}
+
+// TodoClaimDueReminders
+//
+//	UPDATE todo
+//	SET
+//	    reminder_sent_at = NOW(),
+//	    reminder_attempts = reminder_attempts + 1
+//	WHERE id IN (
+//	        SELECT t.id
+//	        FROM todo AS t
+//	        WHERE t.remind_at <= NOW()
+//	            AND t.reminder_sent_at IS NULL
+//	            AND t.deleted_at IS NULL
+//	        ORDER BY t.remind_at
+//	        LIMIT $1
+//	        FOR UPDATE SKIP LOCKED
+//	    )
+//	RETURNING id, title, due_at, remind_at, user_id
+func (q *Queries) TodoClaimDueReminders(ctx context.Context, limit int64) ([]TodoClaimDueRemindersRow, error) {
+	rows, err := q.db.Query(ctx, todoClaimDueReminders, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []TodoClaimDueRemindersRow{}
+	for rows.Next() {
+		var i TodoClaimDueRemindersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.DueAt,
+			&i.RemindAt,
+			&i.UserID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const todoClearRecurrence = `-- name: TodoClearRecurrence :one
+UPDATE todo
+SET
+    recurrence_rule = NULL,
+    recurrence_start = NULL
+WHERE id = $1
+RETURNING id, title, body, status, created_at, updated_at, deleted_at, user_id, due_at, remind_at, reminder_sent_at, list_id, recurrence_rule, recurrence_timezone, recurrence_start, version, reminder_attempts
+`
+
+// TodoClearRecurrence
+//
+//	UPDATE todo
+//	SET
+//	    recurrence_rule = NULL,
+//	    recurrence_start = NULL
+//	WHERE id = $1
+//	RETURNING id, title, body, status, created_at, updated_at, deleted_at, user_id, due_at, remind_at, reminder_sent_at, list_id, recurrence_rule, recurrence_timezone, recurrence_start, version, reminder_attempts
+func (q *Queries) TodoClearRecurrence(ctx context.Context, id int32) (Todo, error) {
+	row := q.db.QueryRow(ctx, todoClearRecurrence, id)
+	var i Todo
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Body,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.UserID,
+		&i.DueAt,
+		&i.RemindAt,
+		&i.ReminderSentAt,
+		&i.ListID,
+		&i.RecurrenceRule,
+		&i.RecurrenceTimezone,
+		&i.RecurrenceStart,
+		&i.Version,
+		&i.ReminderAttempts,
+	)
+	return i, err
+}
+
 const todoCreateTodo = `-- name: TodoCreateTodo :one
 INSERT INTO
-	todo (title, body, status, user_id)
+	todo (title, body, status, user_id, due_at, remind_at, list_id, recurrence_rule, recurrence_timezone, recurrence_start)
 VALUES
-	($1, $2, $3, $4)
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 RETURNING
-	id, title, body, status, created_at, updated_at, deleted_at, user_id
+	id, title, body, status, created_at, updated_at, deleted_at, user_id, due_at, remind_at, reminder_sent_at, list_id, recurrence_rule, recurrence_timezone, recurrence_start, version, reminder_attempts
 `
 
 type TodoCreateTodoParams struct {
-	Title  string `json:"title"`
-	Body   string `json:"body"`
-	Status string `json:"status"`
-	UserID int32  `json:"user_id"`
+	Title              string             `json:"title"`
+	Body               string             `json:"body"`
+	Status             string             `json:"status"`
+	UserID             int32              `json:"user_id"`
+	DueAt              pgtype.Timestamptz `json:"due_at"`
+	RemindAt           pgtype.Timestamptz `json:"remind_at"`
+	ListID             pgtype.Int4        `json:"list_id"`
+	RecurrenceRule     pgtype.Text        `json:"recurrence_rule"`
+	RecurrenceTimezone string             `json:"recurrence_timezone"`
+	RecurrenceStart    pgtype.Timestamptz `json:"recurrence_start"`
 }
 
 // TodoCreateTodo
 //
 //	INSERT INTO
-//		todo (title, body, status, user_id)
+//		todo (title, body, status, user_id, due_at, remind_at, list_id, recurrence_rule, recurrence_timezone, recurrence_start)
 //	VALUES
-//		($1, $2, $3, $4)
+//		($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 //	RETURNING
-//		id, title, body, status, created_at, updated_at, deleted_at, user_id
+//		id, title, body, status, created_at, updated_at, deleted_at, user_id, due_at, remind_at, reminder_sent_at, list_id, recurrence_rule, recurrence_timezone, recurrence_start, version, reminder_attempts
 func (q *Queries) TodoCreateTodo(ctx context.Context, arg TodoCreateTodoParams) (Todo, error) {
 	row := q.db.QueryRow(ctx, todoCreateTodo,
 		arg.Title,
 		arg.Body,
 		arg.Status,
 		arg.UserID,
+		arg.DueAt,
+		arg.RemindAt,
+		arg.ListID,
+		arg.RecurrenceRule,
+		arg.RecurrenceTimezone,
+		arg.RecurrenceStart,
+	)
+	var i Todo
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Body,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.UserID,
+		&i.DueAt,
+		&i.RemindAt,
+		&i.ReminderSentAt,
+		&i.ListID,
+		&i.RecurrenceRule,
+		&i.RecurrenceTimezone,
+		&i.RecurrenceStart,
+		&i.Version,
+		&i.ReminderAttempts,
+	)
+	return i, err
+}
+
+const todoGetDeletedTodosForUser = `-- name: TodoGetDeletedTodosForUser :many
+SELECT
+    id, title, body, status, created_at, updated_at, deleted_at, user_id, due_at, remind_at, reminder_sent_at, list_id, recurrence_rule, recurrence_timezone, recurrence_start, version, reminder_attempts
+FROM todo
+WHERE (
+        (list_id IS NULL AND user_id = $1)
+        OR list_id IN (
+            SELECT m.list_id
+            FROM active_todo_list_member AS m
+            WHERE m.user_id = $1
+        )
+    )
+    AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC, id DESC
+OFFSET $2
+LIMIT $3
+`
+
+type TodoGetDeletedTodosForUserParams struct {
+	UserID     int32 `json:"user_id"`
+	PageOffset int32 `json:"page_offset"`
+	PageLimit  int32 `json:"page_limit"`
+}
+
+// TodoGetDeletedTodosForUser
+//
+//	SELECT
+//	    id, title, body, status, created_at, updated_at, deleted_at, user_id, due_at, remind_at, reminder_sent_at, list_id, recurrence_rule, recurrence_timezone, recurrence_start, version, reminder_attempts
+//	FROM todo
+//	WHERE (
+//	        (list_id IS NULL AND user_id = $1)
+//	        OR list_id IN (
+//	            SELECT m.list_id
+//	            FROM active_todo_list_member AS m
+//	            WHERE m.user_id = $1
+//	        )
+//	    )
+//	    AND deleted_at IS NOT NULL
+//	ORDER BY deleted_at DESC, id DESC
+//	OFFSET $2
+//	LIMIT $3
+func (q *Queries) TodoGetDeletedTodosForUser(ctx context.Context, arg TodoGetDeletedTodosForUserParams) ([]Todo, error) {
+	rows, err := q.db.Query(ctx, todoGetDeletedTodosForUser, arg.UserID, arg.PageOffset, arg.PageLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Todo{}
+	for rows.Next() {
+		var i Todo
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Body,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.UserID,
+			&i.DueAt,
+			&i.RemindAt,
+			&i.ReminderSentAt,
+			&i.ListID,
+			&i.RecurrenceRule,
+			&i.RecurrenceTimezone,
+			&i.RecurrenceStart,
+			&i.Version,
+			&i.ReminderAttempts,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const todoGetDeletedTodosForUserAfterCursor = `-- name: TodoGetDeletedTodosForUserAfterCursor :many
+SELECT
+    id, title, body, status, created_at, updated_at, deleted_at, user_id, due_at, remind_at, reminder_sent_at, list_id, recurrence_rule, recurrence_timezone, recurrence_start, version, reminder_attempts
+FROM todo
+WHERE (
+        (list_id IS NULL AND user_id = $1)
+        OR list_id IN (
+            SELECT m.list_id
+            FROM active_todo_list_member AS m
+            WHERE m.user_id = $1
+        )
+    )
+    AND deleted_at IS NOT NULL
+    AND (
+        $2::TIMESTAMPTZ IS NULL
+        OR (deleted_at, id) < ($2, $3::INTEGER)
+    )
+ORDER BY deleted_at DESC, id DESC
+LIMIT $4
+`
+
+type TodoGetDeletedTodosForUserAfterCursorParams struct {
+	UserID          int32              `json:"user_id"`
+	CursorDeletedAt pgtype.Timestamptz `json:"cursor_deleted_at"`
+	CursorID        pgtype.Int4        `json:"cursor_id"`
+	PageLimit       int32              `json:"page_limit"`
+}
+
+// TodoGetDeletedTodosForUserAfterCursor
+//
+//	SELECT
+//	    id, title, body, status, created_at, updated_at, deleted_at, user_id, due_at, remind_at, reminder_sent_at, list_id, recurrence_rule, recurrence_timezone, recurrence_start, version, reminder_attempts
+//	FROM todo
+//	WHERE (
+//	        (list_id IS NULL AND user_id = $1)
+//	        OR list_id IN (
+//	            SELECT m.list_id
+//	            FROM active_todo_list_member AS m
+//	            WHERE m.user_id = $1
+//	        )
+//	    )
+//	    AND deleted_at IS NOT NULL
+//	    AND (
+//	        $2::TIMESTAMPTZ IS NULL
+//	        OR (deleted_at, id) < ($2, $3::INTEGER)
+//	    )
+//	ORDER BY deleted_at DESC, id DESC
+//	LIMIT $4
+func (q *Queries) TodoGetDeletedTodosForUserAfterCursor(ctx context.Context, arg TodoGetDeletedTodosForUserAfterCursorParams) ([]Todo, error) {
+	rows, err := q.db.Query(ctx, todoGetDeletedTodosForUserAfterCursor,
+		arg.UserID,
+		arg.CursorDeletedAt,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Todo{}
+	for rows.Next() {
+		var i Todo
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Body,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.UserID,
+			&i.DueAt,
+			&i.RemindAt,
+			&i.ReminderSentAt,
+			&i.ListID,
+			&i.RecurrenceRule,
+			&i.RecurrenceTimezone,
+			&i.RecurrenceStart,
+			&i.Version,
+			&i.ReminderAttempts,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const todoGetReminderTargetForUser = `-- name: TodoGetReminderTargetForUser :one
+SELECT
+    u.id AS user_id,
+    contact.email,
+    contact.phone,
+    inst.locale,
+    inst.timezone_offset_in_minutes
+FROM users AS u
+    LEFT JOIN LATERAL (
+        SELECT
+            pli.email,
+            pli.phone
+        FROM active_login_identity AS li
+            JOIN active_password_login_identity AS pli ON pli.login_identity_id = li.id
+        WHERE li.user_id = u.id
+        ORDER BY li.is_primary DESC NULLS LAST, li.last_used_at DESC
+        LIMIT 1
+    ) AS contact ON TRUE
+    LEFT JOIN LATERAL (
+        SELECT
+            i.locale,
+            i.timezone_offset_in_minutes
+        FROM not_deleted_installation AS i
+            JOIN active_session AS s ON s.id = i.attach_to
+            JOIN login_identity AS li ON li.id = s.originated_from
+        WHERE li.user_id = u.id
+        ORDER BY s.last_active_at DESC
+        LIMIT 1
+    ) AS inst ON TRUE
+WHERE u.id = $1
+    AND u.deleted_at IS NULL
+`
+
+type TodoGetReminderTargetForUserRow struct {
+	UserID                  int32       `json:"user_id"`
+	Email                   pgtype.Text `json:"email"`
+	Phone                   pgtype.Text `json:"phone"`
+	Locale                  pgtype.Text `json:"locale"`
+	TimezoneOffsetInMinutes pgtype.Int4 `json:"timezone_offset_in_minutes"`
+}
+
+// TodoGetReminderTargetForUser
+//
+//	SELECT
+//	    u.id AS user_id,
+//	    contact.email,
+//	    contact.phone,
+//	    inst.locale,
+//	    inst.timezone_offset_in_minutes
+//	FROM users AS u
+//	    LEFT JOIN LATERAL (
+//	        SELECT
+//	            pli.email,
+//	            pli.phone
+//	        FROM active_login_identity AS li
+//	            JOIN active_password_login_identity AS pli ON pli.login_identity_id = li.id
+//	        WHERE li.user_id = u.id
+//	        ORDER BY li.is_primary DESC NULLS LAST, li.last_used_at DESC
+//	        LIMIT 1
+//	    ) AS contact ON TRUE
+//	    LEFT JOIN LATERAL (
+//	        SELECT
+//	            i.locale,
+//	            i.timezone_offset_in_minutes
+//	        FROM not_deleted_installation AS i
+//	            JOIN active_session AS s ON s.id = i.attach_to
+//	            JOIN login_identity AS li ON li.id = s.originated_from
+//	        WHERE li.user_id = u.id
+//	        ORDER BY s.last_active_at DESC
+//	        LIMIT 1
+//	    ) AS inst ON TRUE
+//	WHERE u.id = $1
+//	    AND u.deleted_at IS NULL
+func (q *Queries) TodoGetReminderTargetForUser(ctx context.Context, id int32) (TodoGetReminderTargetForUserRow, error) {
+	row := q.db.QueryRow(ctx, todoGetReminderTargetForUser, id)
+	var i TodoGetReminderTargetForUserRow
+	err := row.Scan(
+		&i.UserID,
+		&i.Email,
+		&i.Phone,
+		&i.Locale,
+		&i.TimezoneOffsetInMinutes,
 	)
+	return i, err
+}
+
+const todoGetTodoForWriteLinkedToUser = `-- name: TodoGetTodoForWriteLinkedToUser :one
+SELECT id, title, body, status, created_at, updated_at, deleted_at, user_id, due_at, remind_at, reminder_sent_at, list_id, recurrence_rule, recurrence_timezone, recurrence_start, version, reminder_attempts FROM todo
+WHERE id = $1
+    AND (
+        (list_id IS NULL AND user_id = $2)
+        OR list_id IN (
+            SELECT m.list_id
+            FROM active_todo_list_member AS m
+            WHERE m.user_id = $2
+                AND m.role IN ('editor', 'owner')
+        )
+    )
+    AND deleted_at IS NULL
+LIMIT 1
+FOR UPDATE
+`
+
+type TodoGetTodoForWriteLinkedToUserParams struct {
+	ID     int32 `json:"id"`
+	UserID int32 `json:"user_id"`
+}
+
+// TodoGetTodoForWriteLinkedToUser
+//
+//	SELECT id, title, body, status, created_at, updated_at, deleted_at, user_id, due_at, remind_at, reminder_sent_at, list_id, recurrence_rule, recurrence_timezone, recurrence_start, version, reminder_attempts FROM todo
+//	WHERE id = $1
+//	    AND (
+//	        (list_id IS NULL AND user_id = $2)
+//	        OR list_id IN (
+//	            SELECT m.list_id
+//	            FROM active_todo_list_member AS m
+//	            WHERE m.user_id = $2
+//	                AND m.role IN ('editor', 'owner')
+//	        )
+//	    )
+//	    AND deleted_at IS NULL
+//	LIMIT 1
+//	FOR UPDATE
+func (q *Queries) TodoGetTodoForWriteLinkedToUser(ctx context.Context, arg TodoGetTodoForWriteLinkedToUserParams) (Todo, error) {
+	row := q.db.QueryRow(ctx, todoGetTodoForWriteLinkedToUser, arg.ID, arg.UserID)
 	var i Todo
 	err := row.Scan(
 		&i.ID,
@@ -52,14 +535,30 @@ func (q *Queries) TodoCreateTodo(ctx context.Context, arg TodoCreateTodoParams)
 		&i.UpdatedAt,
 		&i.DeletedAt,
 		&i.UserID,
+		&i.DueAt,
+		&i.RemindAt,
+		&i.ReminderSentAt,
+		&i.ListID,
+		&i.RecurrenceRule,
+		&i.RecurrenceTimezone,
+		&i.RecurrenceStart,
+		&i.Version,
+		&i.ReminderAttempts,
 	)
 	return i, err
 }
 
 const todoGetTodoLinkedToUser = `-- name: TodoGetTodoLinkedToUser :one
-SELECT id, title, body, status, created_at, updated_at, deleted_at, user_id FROM todo
+SELECT id, title, body, status, created_at, updated_at, deleted_at, user_id, due_at, remind_at, reminder_sent_at, list_id, recurrence_rule, recurrence_timezone, recurrence_start, version, reminder_attempts FROM todo
 WHERE id = $1
-    AND user_id = $2
+    AND (
+        (list_id IS NULL AND user_id = $2)
+        OR list_id IN (
+            SELECT m.list_id
+            FROM active_todo_list_member AS m
+            WHERE m.user_id = $2
+        )
+    )
     AND deleted_at IS NULL
 LIMIT 1
 `
@@ -71,9 +570,16 @@ type TodoGetTodoLinkedToUserParams struct {
 
 // TodoGetTodoLinkedToUser
 //
-//	SELECT id, title, body, status, created_at, updated_at, deleted_at, user_id FROM todo
+//	SELECT id, title, body, status, created_at, updated_at, deleted_at, user_id, due_at, remind_at, reminder_sent_at, list_id, recurrence_rule, recurrence_timezone, recurrence_start, version, reminder_attempts FROM todo
 //	WHERE id = $1
-//	    AND user_id = $2
+//	    AND (
+//	        (list_id IS NULL AND user_id = $2)
+//	        OR list_id IN (
+//	            SELECT m.list_id
+//	            FROM active_todo_list_member AS m
+//	            WHERE m.user_id = $2
+//	        )
+//	    )
 //	    AND deleted_at IS NULL
 //	LIMIT 1
 func (q *Queries) TodoGetTodoLinkedToUser(ctx context.Context, arg TodoGetTodoLinkedToUserParams) (Todo, error) {
@@ -88,39 +594,216 @@ func (q *Queries) TodoGetTodoLinkedToUser(ctx context.Context, arg TodoGetTodoLi
 		&i.UpdatedAt,
 		&i.DeletedAt,
 		&i.UserID,
+		&i.DueAt,
+		&i.RemindAt,
+		&i.ReminderSentAt,
+		&i.ListID,
+		&i.RecurrenceRule,
+		&i.RecurrenceTimezone,
+		&i.RecurrenceStart,
+		&i.Version,
+		&i.ReminderAttempts,
 	)
 	return i, err
 }
 
 const todoGetTodosForUser = `-- name: TodoGetTodosForUser :many
 SELECT
-    id, title, body, status, created_at, updated_at, deleted_at, user_id
+    id, title, body, status, created_at, updated_at, deleted_at, user_id, due_at, remind_at, reminder_sent_at, list_id, recurrence_rule, recurrence_timezone, recurrence_start, version, reminder_attempts
 FROM todo
-WHERE user_id = $1
-   AND deleted_at IS NULL
-ORDER BY created_at DESC
-OFFSET $2
-LIMIT $3
+WHERE (
+        (list_id IS NULL AND user_id = $1)
+        OR list_id IN (
+            SELECT m.list_id
+            FROM active_todo_list_member AS m
+            WHERE m.user_id = $1
+        )
+    )
+    AND deleted_at IS NULL
+    AND (
+        $2::TEXT IS NULL
+        OR status = $2
+    )
+    AND (
+        $3::INTEGER IS NULL
+        OR list_id = $3
+    )
+    AND (
+        $4::TEXT IS NULL
+        OR to_tsvector('simple', title || ' ' || body) @@ websearch_to_tsquery('simple', $4)
+    )
+    AND (
+        $5::INTEGER[] IS NULL
+        OR (
+            SELECT COUNT(*)
+            FROM todo_tag_link AS tl
+                JOIN todo_tag AS tt ON tt.id = tl.tag_id
+            WHERE tl.todo_id = todo.id
+                AND tt.user_id = $1
+                AND tl.tag_id = ANY($5::INTEGER[])
+        ) >= CASE
+            WHEN $6::BOOLEAN THEN cardinality($5::INTEGER[])
+            ELSE 1
+        END
+    )
+    AND (
+        $7::TIMESTAMPTZ IS NULL
+        OR created_at >= $7
+    )
+    AND (
+        $8::TIMESTAMPTZ IS NULL
+        OR created_at <= $8
+    )
+    AND (
+        $9::TIMESTAMPTZ IS NULL
+        OR updated_at >= $9
+    )
+    AND (
+        $10::TIMESTAMPTZ IS NULL
+        OR updated_at <= $10
+    )
+    AND (
+        $11::TIMESTAMPTZ IS NULL
+        OR (
+            $12::TEXT = 'created_at'
+            AND (created_at, id) > ($11, $13::INTEGER)
+        )
+        OR (
+            $12::TEXT = '-created_at'
+            AND (created_at, id) < ($11, $13::INTEGER)
+        )
+    )
+ORDER BY
+    CASE WHEN $12::TEXT = 'created_at' THEN created_at END ASC,
+    CASE WHEN $12::TEXT = '-created_at' THEN created_at END DESC,
+    CASE WHEN $12::TEXT = 'updated_at' THEN updated_at END ASC,
+    CASE WHEN $12::TEXT = '-updated_at' THEN updated_at END DESC,
+    CASE WHEN $12::TEXT = 'due_at' THEN due_at END ASC NULLS LAST,
+    CASE WHEN $12::TEXT = '-due_at' THEN due_at END DESC NULLS LAST,
+    CASE WHEN $12::TEXT = 'title' THEN title END ASC,
+    CASE WHEN $12::TEXT = '-title' THEN title END DESC,
+    CASE WHEN $12::TEXT = 'created_at' THEN id END ASC,
+    id DESC
+OFFSET $14
+LIMIT $15
 `
 
 type TodoGetTodosForUserParams struct {
-	UserID int32 `json:"user_id"`
-	Offset int64 `json:"offset"`
-	Limit  int64 `json:"limit"`
+	UserID          int32              `json:"user_id"`
+	Status          pgtype.Text        `json:"status"`
+	ListID          pgtype.Int4        `json:"list_id"`
+	Query           pgtype.Text        `json:"query"`
+	TagIds          []int32            `json:"tag_ids"`
+	TagMatchAll     bool               `json:"tag_match_all"`
+	CreatedAfter    pgtype.Timestamptz `json:"created_after"`
+	CreatedBefore   pgtype.Timestamptz `json:"created_before"`
+	UpdatedAfter    pgtype.Timestamptz `json:"updated_after"`
+	UpdatedBefore   pgtype.Timestamptz `json:"updated_before"`
+	CursorCreatedAt pgtype.Timestamptz `json:"cursor_created_at"`
+	Sort            string             `json:"sort"`
+	CursorID        pgtype.Int4        `json:"cursor_id"`
+	PageOffset      int32              `json:"page_offset"`
+	PageLimit       int32              `json:"page_limit"`
 }
 
 // TodoGetTodosForUser
 //
 //	SELECT
-//	    id, title, body, status, created_at, updated_at, deleted_at, user_id
+//	    id, title, body, status, created_at, updated_at, deleted_at, user_id, due_at, remind_at, reminder_sent_at, list_id, recurrence_rule, recurrence_timezone, recurrence_start, version, reminder_attempts
 //	FROM todo
-//	WHERE user_id = $1
-//	   AND deleted_at IS NULL
-//	ORDER BY created_at DESC
-//	OFFSET $2
-//	LIMIT $3
+//	WHERE (
+//	        (list_id IS NULL AND user_id = $1)
+//	        OR list_id IN (
+//	            SELECT m.list_id
+//	            FROM active_todo_list_member AS m
+//	            WHERE m.user_id = $1
+//	        )
+//	    )
+//	    AND deleted_at IS NULL
+//	    AND (
+//	        $2::TEXT IS NULL
+//	        OR status = $2
+//	    )
+//	    AND (
+//	        $3::INTEGER IS NULL
+//	        OR list_id = $3
+//	    )
+//	    AND (
+//	        $4::TEXT IS NULL
+//	        OR to_tsvector('simple', title || ' ' || body) @@ websearch_to_tsquery('simple', $4)
+//	    )
+//	    AND (
+//	        $5::INTEGER[] IS NULL
+//	        OR (
+//	            SELECT COUNT(*)
+//	            FROM todo_tag_link AS tl
+//	                JOIN todo_tag AS tt ON tt.id = tl.tag_id
+//	            WHERE tl.todo_id = todo.id
+//	                AND tt.user_id = $1
+//	                AND tl.tag_id = ANY($5::INTEGER[])
+//	        ) >= CASE
+//	            WHEN $6::BOOLEAN THEN cardinality($5::INTEGER[])
+//	            ELSE 1
+//	        END
+//	    )
+//	    AND (
+//	        $7::TIMESTAMPTZ IS NULL
+//	        OR created_at >= $7
+//	    )
+//	    AND (
+//	        $8::TIMESTAMPTZ IS NULL
+//	        OR created_at <= $8
+//	    )
+//	    AND (
+//	        $9::TIMESTAMPTZ IS NULL
+//	        OR updated_at >= $9
+//	    )
+//	    AND (
+//	        $10::TIMESTAMPTZ IS NULL
+//	        OR updated_at <= $10
+//	    )
+//	    AND (
+//	        $11::TIMESTAMPTZ IS NULL
+//	        OR (
+//	            $12::TEXT = 'created_at'
+//	            AND (created_at, id) > ($11, $13::INTEGER)
+//	        )
+//	        OR (
+//	            $12::TEXT = '-created_at'
+//	            AND (created_at, id) < ($11, $13::INTEGER)
+//	        )
+//	    )
+//	ORDER BY
+//	    CASE WHEN $12::TEXT = 'created_at' THEN created_at END ASC,
+//	    CASE WHEN $12::TEXT = '-created_at' THEN created_at END DESC,
+//	    CASE WHEN $12::TEXT = 'updated_at' THEN updated_at END ASC,
+//	    CASE WHEN $12::TEXT = '-updated_at' THEN updated_at END DESC,
+//	    CASE WHEN $12::TEXT = 'due_at' THEN due_at END ASC NULLS LAST,
+//	    CASE WHEN $12::TEXT = '-due_at' THEN due_at END DESC NULLS LAST,
+//	    CASE WHEN $12::TEXT = 'title' THEN title END ASC,
+//	    CASE WHEN $12::TEXT = '-title' THEN title END DESC,
+//	    CASE WHEN $12::TEXT = 'created_at' THEN id END ASC,
+//	    id DESC
+//	OFFSET $14
+//	LIMIT $15
 func (q *Queries) TodoGetTodosForUser(ctx context.Context, arg TodoGetTodosForUserParams) ([]Todo, error) {
-	rows, err := q.db.Query(ctx, todoGetTodosForUser, arg.UserID, arg.Offset, arg.Limit)
+	rows, err := q.db.Query(ctx, todoGetTodosForUser,
+		arg.UserID,
+		arg.Status,
+		arg.ListID,
+		arg.Query,
+		arg.TagIds,
+		arg.TagMatchAll,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.UpdatedAfter,
+		arg.UpdatedBefore,
+		arg.CursorCreatedAt,
+		arg.Sort,
+		arg.CursorID,
+		arg.PageOffset,
+		arg.PageLimit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -137,6 +820,15 @@ func (q *Queries) TodoGetTodosForUser(ctx context.Context, arg TodoGetTodosForUs
 			&i.UpdatedAt,
 			&i.DeletedAt,
 			&i.UserID,
+			&i.DueAt,
+			&i.RemindAt,
+			&i.ReminderSentAt,
+			&i.ListID,
+			&i.RecurrenceRule,
+			&i.RecurrenceTimezone,
+			&i.RecurrenceStart,
+			&i.Version,
+			&i.ReminderAttempts,
 		); err != nil {
 			return nil, err
 		}
@@ -148,11 +840,220 @@ func (q *Queries) TodoGetTodosForUser(ctx context.Context, arg TodoGetTodosForUs
 	return items, nil
 }
 
-const todoSoftDeleteTodoLinkedToUser = `-- name: TodoSoftDeleteTodoLinkedToUser :exec
+const todoMoveTodosOfList = `-- name: TodoMoveTodosOfList :many
+UPDATE todo
+SET list_id = $1
+WHERE list_id = $2
+    AND deleted_at IS NULL
+RETURNING id
+`
+
+type TodoMoveTodosOfListParams struct {
+	ToListID   pgtype.Int4 `json:"to_list_id"`
+	FromListID pgtype.Int4 `json:"from_list_id"`
+}
+
+// TodoMoveTodosOfList
+//
+//	UPDATE todo
+//	SET list_id = $1
+//	WHERE list_id = $2
+//	    AND deleted_at IS NULL
+//	RETURNING id
+func (q *Queries) TodoMoveTodosOfList(ctx context.Context, arg TodoMoveTodosOfListParams) ([]int32, error) {
+	rows, err := q.db.Query(ctx, todoMoveTodosOfList, arg.ToListID, arg.FromListID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int32{}
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const todoPurgeDeletedTodos = `-- name: TodoPurgeDeletedTodos :execrows
+DELETE FROM todo
+WHERE id IN (
+        SELECT t.id
+        FROM todo AS t
+        WHERE t.deleted_at < $1
+        LIMIT $2
+    )
+`
+
+type TodoPurgeDeletedTodosParams struct {
+	DeletedBefore pgtype.Timestamptz `json:"deleted_before"`
+	BatchSize     int32              `json:"batch_size"`
+}
+
+// TodoPurgeDeletedTodos
+//
+//	DELETE FROM todo
+//	WHERE id IN (
+//	        SELECT t.id
+//	        FROM todo AS t
+//	        WHERE t.deleted_at < $1
+//	        LIMIT $2
+//	    )
+func (q *Queries) TodoPurgeDeletedTodos(ctx context.Context, arg TodoPurgeDeletedTodosParams) (int64, error) {
+	result, err := q.db.Exec(ctx, todoPurgeDeletedTodos, arg.DeletedBefore, arg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const todoPurgeTodoLinkedToUser = `-- name: TodoPurgeTodoLinkedToUser :execrows
+DELETE FROM todo
+WHERE id = $1
+    AND (
+        (list_id IS NULL AND user_id = $2)
+        OR list_id IN (
+            SELECT m.list_id
+            FROM active_todo_list_member AS m
+            WHERE m.user_id = $2
+                AND m.role IN ('editor', 'owner')
+        )
+    )
+    AND deleted_at IS NOT NULL
+`
+
+type TodoPurgeTodoLinkedToUserParams struct {
+	ID     int32 `json:"id"`
+	UserID int32 `json:"user_id"`
+}
+
+// TodoPurgeTodoLinkedToUser
+//
+//	DELETE FROM todo
+//	WHERE id = $1
+//	    AND (
+//	        (list_id IS NULL AND user_id = $2)
+//	        OR list_id IN (
+//	            SELECT m.list_id
+//	            FROM active_todo_list_member AS m
+//	            WHERE m.user_id = $2
+//	                AND m.role IN ('editor', 'owner')
+//	        )
+//	    )
+//	    AND deleted_at IS NOT NULL
+func (q *Queries) TodoPurgeTodoLinkedToUser(ctx context.Context, arg TodoPurgeTodoLinkedToUserParams) (int64, error) {
+	result, err := q.db.Exec(ctx, todoPurgeTodoLinkedToUser, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const todoReleaseFailedReminder = `-- name: TodoReleaseFailedReminder :exec
+UPDATE todo
+SET reminder_sent_at = NULL
+WHERE id = $1
+    AND reminder_attempts < $2::SMALLINT
+`
+
+type TodoReleaseFailedReminderParams struct {
+	ID          int32 `json:"id"`
+	MaxAttempts int16 `json:"max_attempts"`
+}
+
+// TodoReleaseFailedReminder
+//
+//	UPDATE todo
+//	SET reminder_sent_at = NULL
+//	WHERE id = $1
+//	    AND reminder_attempts < $2::SMALLINT
+func (q *Queries) TodoReleaseFailedReminder(ctx context.Context, arg TodoReleaseFailedReminderParams) error {
+	_, err := q.db.Exec(ctx, todoReleaseFailedReminder, arg.ID, arg.MaxAttempts)
+	return err
+}
+
+const todoRestoreTodoLinkedToUser = `-- name: TodoRestoreTodoLinkedToUser :one
+UPDATE todo
+SET deleted_at = NULL
+WHERE id = $1
+    AND (
+        (list_id IS NULL AND user_id = $2)
+        OR list_id IN (
+            SELECT m.list_id
+            FROM active_todo_list_member AS m
+            WHERE m.user_id = $2
+                AND m.role IN ('editor', 'owner')
+        )
+    )
+    AND deleted_at IS NOT NULL
+RETURNING id, title, body, status, created_at, updated_at, deleted_at, user_id, due_at, remind_at, reminder_sent_at, list_id, recurrence_rule, recurrence_timezone, recurrence_start, version, reminder_attempts
+`
+
+type TodoRestoreTodoLinkedToUserParams struct {
+	ID     int32 `json:"id"`
+	UserID int32 `json:"user_id"`
+}
+
+// TodoRestoreTodoLinkedToUser
+//
+//	UPDATE todo
+//	SET deleted_at = NULL
+//	WHERE id = $1
+//	    AND (
+//	        (list_id IS NULL AND user_id = $2)
+//	        OR list_id IN (
+//	            SELECT m.list_id
+//	            FROM active_todo_list_member AS m
+//	            WHERE m.user_id = $2
+//	                AND m.role IN ('editor', 'owner')
+//	        )
+//	    )
+//	    AND deleted_at IS NOT NULL
+//	RETURNING id, title, body, status, created_at, updated_at, deleted_at, user_id, due_at, remind_at, reminder_sent_at, list_id, recurrence_rule, recurrence_timezone, recurrence_start, version, reminder_attempts
+func (q *Queries) TodoRestoreTodoLinkedToUser(ctx context.Context, arg TodoRestoreTodoLinkedToUserParams) (Todo, error) {
+	row := q.db.QueryRow(ctx, todoRestoreTodoLinkedToUser, arg.ID, arg.UserID)
+	var i Todo
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Body,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.UserID,
+		&i.DueAt,
+		&i.RemindAt,
+		&i.ReminderSentAt,
+		&i.ListID,
+		&i.RecurrenceRule,
+		&i.RecurrenceTimezone,
+		&i.RecurrenceStart,
+		&i.Version,
+		&i.ReminderAttempts,
+	)
+	return i, err
+}
+
+const todoSoftDeleteTodoLinkedToUser = `-- name: TodoSoftDeleteTodoLinkedToUser :execrows
 UPDATE todo
 SET deleted_at = NOW()
 WHERE id = $1
-    AND user_id = $2
+    AND (
+        (list_id IS NULL AND user_id = $2)
+        OR list_id IN (
+            SELECT m.list_id
+            FROM active_todo_list_member AS m
+            WHERE m.user_id = $2
+                AND m.role IN ('editor', 'owner')
+        )
+    )
+    AND deleted_at IS NULL
 `
 
 type TodoSoftDeleteTodoLinkedToUserParams struct {
@@ -165,9 +1066,130 @@ type TodoSoftDeleteTodoLinkedToUserParams struct {
 //	UPDATE todo
 //	SET deleted_at = NOW()
 //	WHERE id = $1
-//	    AND user_id = $2
-func (q *Queries) TodoSoftDeleteTodoLinkedToUser(ctx context.Context, arg TodoSoftDeleteTodoLinkedToUserParams) error {
-	_, err := q.db.Exec(ctx, todoSoftDeleteTodoLinkedToUser, arg.ID, arg.UserID)
+//	    AND (
+//	        (list_id IS NULL AND user_id = $2)
+//	        OR list_id IN (
+//	            SELECT m.list_id
+//	            FROM active_todo_list_member AS m
+//	            WHERE m.user_id = $2
+//	                AND m.role IN ('editor', 'owner')
+//	        )
+//	    )
+//	    AND deleted_at IS NULL
+func (q *Queries) TodoSoftDeleteTodoLinkedToUser(ctx context.Context, arg TodoSoftDeleteTodoLinkedToUserParams) (int64, error) {
+	result, err := q.db.Exec(ctx, todoSoftDeleteTodoLinkedToUser, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const todoSoftDeleteTodosOfList = `-- name: TodoSoftDeleteTodosOfList :many
+UPDATE todo
+SET deleted_at = NOW(),
+    list_id = NULL
+WHERE list_id = $1
+    AND deleted_at IS NULL
+RETURNING id
+`
+
+// TodoSoftDeleteTodosOfList
+//
+//	UPDATE todo
+//	SET deleted_at = NOW(),
+//	    list_id = NULL
+//	WHERE list_id = $1
+//	    AND deleted_at IS NULL
+//	RETURNING id
+func (q *Queries) TodoSoftDeleteTodosOfList(ctx context.Context, listID pgtype.Int4) ([]int32, error) {
+	rows, err := q.db.Query(ctx, todoSoftDeleteTodosOfList, listID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int32{}
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const todoTouchTodo = `-- name: TodoTouchTodo :exec
+UPDATE todo
+SET updated_at = NOW()
+WHERE id = $1
+`
+
+// TodoTouchTodo
+//
+//	UPDATE todo
+//	SET updated_at = NOW()
+//	WHERE id = $1
+func (q *Queries) TodoTouchTodo(ctx context.Context, id int32) error {
+	_, err := q.db.Exec(ctx, todoTouchTodo, id)
+	return err
+}
+
+const todoTouchTodosOfTag = `-- name: TodoTouchTodosOfTag :exec
+UPDATE todo
+SET updated_at = NOW()
+WHERE id IN (
+        SELECT l.todo_id
+        FROM todo_tag_link AS l
+            JOIN todo_tag AS t ON t.id = l.tag_id
+        WHERE l.tag_id = $1
+            AND t.user_id = $2
+    )
+`
+
+type TodoTouchTodosOfTagParams struct {
+	TagID  int32 `json:"tag_id"`
+	UserID int32 `json:"user_id"`
+}
+
+// TodoTouchTodosOfTag
+//
+//	UPDATE todo
+//	SET updated_at = NOW()
+//	WHERE id IN (
+//	        SELECT l.todo_id
+//	        FROM todo_tag_link AS l
+//	            JOIN todo_tag AS t ON t.id = l.tag_id
+//	        WHERE l.tag_id = $1
+//	            AND t.user_id = $2
+//	    )
+func (q *Queries) TodoTouchTodosOfTag(ctx context.Context, arg TodoTouchTodosOfTagParams) error {
+	_, err := q.db.Exec(ctx, todoTouchTodosOfTag, arg.TagID, arg.UserID)
+	return err
+}
+
+const todoTouchTodosOfUserWithStatuses = `-- name: TodoTouchTodosOfUserWithStatuses :exec
+UPDATE todo
+SET updated_at = NOW()
+WHERE user_id = $1
+    AND status = ANY($2::TEXT[])
+`
+
+type TodoTouchTodosOfUserWithStatusesParams struct {
+	UserID   int32    `json:"user_id"`
+	Statuses []string `json:"statuses"`
+}
+
+// TodoTouchTodosOfUserWithStatuses
+//
+//	UPDATE todo
+//	SET updated_at = NOW()
+//	WHERE user_id = $1
+//	    AND status = ANY($2::TEXT[])
+func (q *Queries) TodoTouchTodosOfUserWithStatuses(ctx context.Context, arg TodoTouchTodosOfUserWithStatusesParams) error {
+	_, err := q.db.Exec(ctx, todoTouchTodosOfUserWithStatuses, arg.UserID, arg.Statuses)
 	return err
 }
 
@@ -176,21 +1198,68 @@ UPDATE todo
 SET
 	title = COALESCE($3, title),
 	body = COALESCE($4, body),
-	status = COALESCE($5, status)
+	status = COALESCE($5, status),
+	due_at = CASE
+		WHEN $6::BOOLEAN THEN NULL
+		ELSE COALESCE($7, due_at)
+	END,
+	remind_at = CASE
+		WHEN $8::BOOLEAN THEN NULL
+		ELSE COALESCE($9, remind_at)
+	END,
+	reminder_sent_at = CASE
+		WHEN $8::BOOLEAN OR $9 IS NOT NULL THEN NULL
+		ELSE reminder_sent_at
+	END,
+	reminder_attempts = CASE
+		WHEN $8::BOOLEAN OR $9 IS NOT NULL THEN 0
+		ELSE reminder_attempts
+	END,
+	list_id = CASE
+		WHEN $10::BOOLEAN THEN NULL
+		ELSE COALESCE($11, list_id)
+	END,
+	recurrence_rule = CASE
+		WHEN $12::BOOLEAN THEN NULL
+		ELSE COALESCE($13, recurrence_rule)
+	END,
+	recurrence_timezone = COALESCE($14, recurrence_timezone),
+	recurrence_start = CASE
+		WHEN $12::BOOLEAN THEN NULL
+		ELSE COALESCE($15, recurrence_start)
+	END
 WHERE
 	id = $1
-	AND user_id = $2
+    AND (
+        (list_id IS NULL AND user_id = $2)
+        OR list_id IN (
+            SELECT m.list_id
+            FROM active_todo_list_member AS m
+            WHERE m.user_id = $2
+                AND m.role IN ('editor', 'owner')
+        )
+    )
     AND deleted_at IS NULL
 RETURNING
-	id, title, body, status, created_at, updated_at, deleted_at, user_id
+	id, title, body, status, created_at, updated_at, deleted_at, user_id, due_at, remind_at, reminder_sent_at, list_id, recurrence_rule, recurrence_timezone, recurrence_start, version, reminder_attempts
 `
 
 type TodoUpdateTodoParams struct {
-	ID     int32       `json:"id"`
-	UserID int32       `json:"user_id"`
-	Title  pgtype.Text `json:"title"`
-	Body   pgtype.Text `json:"body"`
-	Status pgtype.Text `json:"status"`
+	ID                 int32              `json:"id"`
+	UserID             int32              `json:"user_id"`
+	Title              pgtype.Text        `json:"title"`
+	Body               pgtype.Text        `json:"body"`
+	Status             pgtype.Text        `json:"status"`
+	ClearDueAt         bool               `json:"clear_due_at"`
+	DueAt              pgtype.Timestamptz `json:"due_at"`
+	ClearRemindAt      bool               `json:"clear_remind_at"`
+	RemindAt           pgtype.Timestamptz `json:"remind_at"`
+	ClearListID        bool               `json:"clear_list_id"`
+	ListID             pgtype.Int4        `json:"list_id"`
+	ClearRecurrence    bool               `json:"clear_recurrence"`
+	RecurrenceRule     pgtype.Text        `json:"recurrence_rule"`
+	RecurrenceTimezone pgtype.Text        `json:"recurrence_timezone"`
+	RecurrenceStart    pgtype.Timestamptz `json:"recurrence_start"`
 }
 
 // TodoUpdateTodo
@@ -199,13 +1268,50 @@ type TodoUpdateTodoParams struct {
 //	SET
 //		title = COALESCE($3, title),
 //		body = COALESCE($4, body),
-//		status = COALESCE($5, status)
+//		status = COALESCE($5, status),
+//		due_at = CASE
+//			WHEN $6::BOOLEAN THEN NULL
+//			ELSE COALESCE($7, due_at)
+//		END,
+//		remind_at = CASE
+//			WHEN $8::BOOLEAN THEN NULL
+//			ELSE COALESCE($9, remind_at)
+//		END,
+//		reminder_sent_at = CASE
+//			WHEN $8::BOOLEAN OR $9 IS NOT NULL THEN NULL
+//			ELSE reminder_sent_at
+//		END,
+//		reminder_attempts = CASE
+//			WHEN $8::BOOLEAN OR $9 IS NOT NULL THEN 0
+//			ELSE reminder_attempts
+//		END,
+//		list_id = CASE
+//			WHEN $10::BOOLEAN THEN NULL
+//			ELSE COALESCE($11, list_id)
+//		END,
+//		recurrence_rule = CASE
+//			WHEN $12::BOOLEAN THEN NULL
+//			ELSE COALESCE($13, recurrence_rule)
+//		END,
+//		recurrence_timezone = COALESCE($14, recurrence_timezone),
+//		recurrence_start = CASE
+//			WHEN $12::BOOLEAN THEN NULL
+//			ELSE COALESCE($15, recurrence_start)
+//		END
 //	WHERE
 //		id = $1
-//		AND user_id = $2
+//	    AND (
+//	        (list_id IS NULL AND user_id = $2)
+//	        OR list_id IN (
+//	            SELECT m.list_id
+//	            FROM active_todo_list_member AS m
+//	            WHERE m.user_id = $2
+//	                AND m.role IN ('editor', 'owner')
+//	        )
+//	    )
 //	    AND deleted_at IS NULL
 //	RETURNING
-//		id, title, body, status, created_at, updated_at, deleted_at, user_id
+//		id, title, body, status, created_at, updated_at, deleted_at, user_id, due_at, remind_at, reminder_sent_at, list_id, recurrence_rule, recurrence_timezone, recurrence_start, version, reminder_attempts
 func (q *Queries) TodoUpdateTodo(ctx context.Context, arg TodoUpdateTodoParams) (Todo, error) {
 	row := q.db.QueryRow(ctx, todoUpdateTodo,
 		arg.ID,
@@ -213,6 +1319,16 @@ func (q *Queries) TodoUpdateTodo(ctx context.Context, arg TodoUpdateTodoParams)
 		arg.Title,
 		arg.Body,
 		arg.Status,
+		arg.ClearDueAt,
+		arg.DueAt,
+		arg.ClearRemindAt,
+		arg.RemindAt,
+		arg.ClearListID,
+		arg.ListID,
+		arg.ClearRecurrence,
+		arg.RecurrenceRule,
+		arg.RecurrenceTimezone,
+		arg.RecurrenceStart,
 	)
 	var i Todo
 	err := row.Scan(
@@ -224,6 +1340,15 @@ func (q *Queries) TodoUpdateTodo(ctx context.Context, arg TodoUpdateTodoParams)
 		&i.UpdatedAt,
 		&i.DeletedAt,
 		&i.UserID,
+		&i.DueAt,
+		&i.RemindAt,
+		&i.ReminderSentAt,
+		&i.ListID,
+		&i.RecurrenceRule,
+		&i.RecurrenceTimezone,
+		&i.RecurrenceStart,
+		&i.Version,
+		&i.ReminderAttempts,
 	)
 	return i, err
 }
@@ -11,6 +11,34 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const usersBlockUser = `-- name: UsersBlockUser :execrows
+UPDATE users
+SET blocked_at = NOW(),
+    blocked_until = $1::timestamptz
+WHERE id = $2::int
+    AND deleted_at IS NULL
+`
+
+type UsersBlockUserParams struct {
+	BlockedUntil pgtype.Timestamptz `json:"blocked_until"`
+	ID           int32              `json:"id"`
+}
+
+// UsersBlockUser
+//
+//	UPDATE users
+//	SET blocked_at = NOW(),
+//	    blocked_until = $1::timestamptz
+//	WHERE id = $2::int
+//	    AND deleted_at IS NULL
+func (q *Queries) UsersBlockUser(ctx context.Context, arg UsersBlockUserParams) (int64, error) {
+	result, err := q.db.Exec(ctx, usersBlockUser, arg.BlockedUntil, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const usersCreateNewUser = `-- name: UsersCreateNewUser :one
 INSERT INTO users (
         username,
@@ -20,7 +48,7 @@ INSERT INTO users (
         role_name
     )
 VALUES ($1, $2, $3, $4, $5)
-RETURNING id, username, profile_image, first_name, middle_name, last_name, created_at, updated_at, blocked_at, blocked_until, deleted_at, role_name
+RETURNING id, username, profile_image, first_name, middle_name, last_name, created_at, updated_at, blocked_at, blocked_until, mfa_enforced, deleted_at, role_name
 `
 
 type UsersCreateNewUserParams struct {
@@ -41,7 +69,7 @@ type UsersCreateNewUserParams struct {
 //	        role_name
 //	    )
 //	VALUES ($1, $2, $3, $4, $5)
-//	RETURNING id, username, profile_image, first_name, middle_name, last_name, created_at, updated_at, blocked_at, blocked_until, deleted_at, role_name
+//	RETURNING id, username, profile_image, first_name, middle_name, last_name, created_at, updated_at, blocked_at, blocked_until, mfa_enforced, deleted_at, role_name
 func (q *Queries) UsersCreateNewUser(ctx context.Context, arg UsersCreateNewUserParams) (User, error) {
 	row := q.db.QueryRow(ctx, usersCreateNewUser,
 		arg.Username,
@@ -62,17 +90,82 @@ func (q *Queries) UsersCreateNewUser(ctx context.Context, arg UsersCreateNewUser
 		&i.UpdatedAt,
 		&i.BlockedAt,
 		&i.BlockedUntil,
+		&i.MfaEnforced,
 		&i.DeletedAt,
 		&i.RoleName,
 	)
 	return i, err
 }
 
-const usersGetUserAndSessionDataBySessionToken = `-- name: UsersGetUserAndSessionDataBySessionToken :one
+const usersCreateUserBlock = `-- name: UsersCreateUserBlock :exec
+INSERT INTO user_block (
+    user_id,
+    blocked_by,
+    reason,
+    blocked_until
+)
+VALUES (
+    $1::int,
+    $2::int,
+    $3::text,
+    $4::timestamptz
+)
+`
+
+type UsersCreateUserBlockParams struct {
+	UserID       int32              `json:"user_id"`
+	BlockedBy    pgtype.Int4        `json:"blocked_by"`
+	Reason       string             `json:"reason"`
+	BlockedUntil pgtype.Timestamptz `json:"blocked_until"`
+}
+
+// UsersCreateUserBlock
+//
+//	INSERT INTO user_block (
+//	    user_id,
+//	    blocked_by,
+//	    reason,
+//	    blocked_until
+//	)
+//	VALUES (
+//	    $1::int,
+//	    $2::int,
+//	    $3::text,
+//	    $4::timestamptz
+//	)
+func (q *Queries) UsersCreateUserBlock(ctx context.Context, arg UsersCreateUserBlockParams) error {
+	_, err := q.db.Exec(ctx, usersCreateUserBlock,
+		arg.UserID,
+		arg.BlockedBy,
+		arg.Reason,
+		arg.BlockedUntil,
+	)
+	return err
+}
+
+const usersEndUserBlocks = `-- name: UsersEndUserBlocks :exec
+UPDATE user_block
+SET unblocked_at = NOW()
+WHERE user_id = $1::int
+    AND unblocked_at IS NULL
+`
+
+// UsersEndUserBlocks
+//
+//	UPDATE user_block
+//	SET unblocked_at = NOW()
+//	WHERE user_id = $1::int
+//	    AND unblocked_at IS NULL
+func (q *Queries) UsersEndUserBlocks(ctx context.Context, userID int32) error {
+	_, err := q.db.Exec(ctx, usersEndUserBlocks, userID)
+	return err
+}
+
+const usersGetUserAndSessionDataBySessionId = `-- name: UsersGetUserAndSessionDataBySessionId :one
 SELECT s.id as session_id,
-    s.token as session_token,
     s.originated_from as session_originated_from,
     s.used_installation as session_used_installation,
+    s.expires_at as session_expires_at,
 
     u.id as user_id,
     u.username as user_username,
@@ -86,15 +179,15 @@ SELECT s.id as session_id,
 FROM active_session AS s
     JOIN active_login_identity AS li ON s.originated_from = li.id
     JOIN not_deleted_users AS u ON u.id = li.user_id
-WHERE s.token = $1
+WHERE s.id = $1
 LIMIT 1
 `
 
-type UsersGetUserAndSessionDataBySessionTokenRow struct {
+type UsersGetUserAndSessionDataBySessionIdRow struct {
 	SessionID               int32              `json:"session_id"`
-	SessionToken            string             `json:"session_token"`
 	SessionOriginatedFrom   int32              `json:"session_originated_from"`
 	SessionUsedInstallation int32              `json:"session_used_installation"`
+	SessionExpiresAt        pgtype.Timestamptz `json:"session_expires_at"`
 	UserID                  int32              `json:"user_id"`
 	UserUsername            string             `json:"user_username"`
 	UserProfileImage        pgtype.Text        `json:"user_profile_image"`
@@ -106,12 +199,12 @@ type UsersGetUserAndSessionDataBySessionTokenRow struct {
 	UserRoleName            pgtype.Text        `json:"user_role_name"`
 }
 
-// UsersGetUserAndSessionDataBySessionToken
+// UsersGetUserAndSessionDataBySessionId
 //
 //	SELECT s.id as session_id,
-//	    s.token as session_token,
 //	    s.originated_from as session_originated_from,
 //	    s.used_installation as session_used_installation,
+//	    s.expires_at as session_expires_at,
 //
 //	    u.id as user_id,
 //	    u.username as user_username,
@@ -125,16 +218,16 @@ type UsersGetUserAndSessionDataBySessionTokenRow struct {
 //	FROM active_session AS s
 //	    JOIN active_login_identity AS li ON s.originated_from = li.id
 //	    JOIN not_deleted_users AS u ON u.id = li.user_id
-//	WHERE s.token = $1
+//	WHERE s.id = $1
 //	LIMIT 1
-func (q *Queries) UsersGetUserAndSessionDataBySessionToken(ctx context.Context, token string) (UsersGetUserAndSessionDataBySessionTokenRow, error) {
-	row := q.db.QueryRow(ctx, usersGetUserAndSessionDataBySessionToken, token)
-	var i UsersGetUserAndSessionDataBySessionTokenRow
+func (q *Queries) UsersGetUserAndSessionDataBySessionId(ctx context.Context, id int32) (UsersGetUserAndSessionDataBySessionIdRow, error) {
+	row := q.db.QueryRow(ctx, usersGetUserAndSessionDataBySessionId, id)
+	var i UsersGetUserAndSessionDataBySessionIdRow
 	err := row.Scan(
 		&i.SessionID,
-		&i.SessionToken,
 		&i.SessionOriginatedFrom,
 		&i.SessionUsedInstallation,
+		&i.SessionExpiresAt,
 		&i.UserID,
 		&i.UserUsername,
 		&i.UserProfileImage,
@@ -216,6 +309,98 @@ func (q *Queries) UsersGetUserById(ctx context.Context, id int32) (UsersGetUserB
 	return i, err
 }
 
+const usersGetUserForAdmin = `-- name: UsersGetUserForAdmin :one
+SELECT
+    u.id,
+    u.username,
+    u.profile_image,
+    u.first_name,
+    u.middle_name,
+    u.last_name,
+    u.role_name,
+    u.mfa_enforced,
+    u.blocked_at,
+    u.blocked_until,
+    u.deleted_at,
+    u.created_at,
+    b.reason AS block_reason
+FROM users AS u
+    LEFT JOIN LATERAL (
+        SELECT ub.reason
+        FROM user_block AS ub
+        WHERE ub.user_id = u.id
+            AND ub.unblocked_at IS NULL
+        ORDER BY ub.id DESC
+        LIMIT 1
+    ) AS b ON u.blocked_at IS NOT NULL
+WHERE u.id = $1::int
+LIMIT 1
+`
+
+type UsersGetUserForAdminRow struct {
+	ID           int32              `json:"id"`
+	Username     string             `json:"username"`
+	ProfileImage pgtype.Text        `json:"profile_image"`
+	FirstName    string             `json:"first_name"`
+	MiddleName   pgtype.Text        `json:"middle_name"`
+	LastName     pgtype.Text        `json:"last_name"`
+	RoleName     pgtype.Text        `json:"role_name"`
+	MfaEnforced  pgtype.Bool        `json:"mfa_enforced"`
+	BlockedAt    pgtype.Timestamptz `json:"blocked_at"`
+	BlockedUntil pgtype.Timestamptz `json:"blocked_until"`
+	DeletedAt    pgtype.Timestamptz `json:"deleted_at"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	BlockReason  pgtype.Text        `json:"block_reason"`
+}
+
+// UsersGetUserForAdmin
+//
+//	SELECT
+//	    u.id,
+//	    u.username,
+//	    u.profile_image,
+//	    u.first_name,
+//	    u.middle_name,
+//	    u.last_name,
+//	    u.role_name,
+//	    u.mfa_enforced,
+//	    u.blocked_at,
+//	    u.blocked_until,
+//	    u.deleted_at,
+//	    u.created_at,
+//	    b.reason AS block_reason
+//	FROM users AS u
+//	    LEFT JOIN LATERAL (
+//	        SELECT ub.reason
+//	        FROM user_block AS ub
+//	        WHERE ub.user_id = u.id
+//	            AND ub.unblocked_at IS NULL
+//	        ORDER BY ub.id DESC
+//	        LIMIT 1
+//	    ) AS b ON u.blocked_at IS NOT NULL
+//	WHERE u.id = $1::int
+//	LIMIT 1
+func (q *Queries) UsersGetUserForAdmin(ctx context.Context, id int32) (UsersGetUserForAdminRow, error) {
+	row := q.db.QueryRow(ctx, usersGetUserForAdmin, id)
+	var i UsersGetUserForAdminRow
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.ProfileImage,
+		&i.FirstName,
+		&i.MiddleName,
+		&i.LastName,
+		&i.RoleName,
+		&i.MfaEnforced,
+		&i.BlockedAt,
+		&i.BlockedUntil,
+		&i.DeletedAt,
+		&i.CreatedAt,
+		&i.BlockReason,
+	)
+	return i, err
+}
+
 const usersIsUsernameUsed = `-- name: UsersIsUsernameUsed :one
 SELECT COUNT(*)
 FROM users
@@ -234,10 +419,198 @@ func (q *Queries) UsersIsUsernameUsed(ctx context.Context, username string) (int
 	return count, err
 }
 
-const usersSoftDeleteUser = `-- name: UsersSoftDeleteUser :exec
+const usersSearchUsers = `-- name: UsersSearchUsers :many
+SELECT
+    u.id,
+    u.username,
+    u.profile_image,
+    u.first_name,
+    u.middle_name,
+    u.last_name,
+    u.role_name,
+    u.mfa_enforced,
+    u.blocked_at,
+    u.blocked_until,
+    u.deleted_at,
+    u.created_at
+FROM users AS u
+WHERE ($1::bool OR u.deleted_at IS NULL)
+    AND ($2::text IS NULL OR u.role_name = $2::text)
+    AND (
+        $3::text IS NULL
+        OR u.username ILIKE '%' || $3::text || '%' ESCAPE '\'
+        OR (u.first_name || ' ' || COALESCE(u.last_name, '')) ILIKE '%' || $3::text || '%' ESCAPE '\'
+        OR EXISTS (
+            SELECT 1
+            FROM login_identity AS li
+                JOIN password_login_identity AS pli ON pli.login_identity_id = li.id
+            WHERE li.user_id = u.id
+                AND (pli.email ILIKE '%' || $3::text || '%' ESCAPE '\' OR pli.phone LIKE '%' || $3::text || '%' ESCAPE '\')
+        )
+    )
+ORDER BY u.id DESC
+LIMIT $4::int OFFSET $5::int
+`
+
+type UsersSearchUsersParams struct {
+	IncludeDeleted bool        `json:"include_deleted"`
+	RoleName       pgtype.Text `json:"role_name"`
+	Query          pgtype.Text `json:"query"`
+	PageLimit      int32       `json:"page_limit"`
+	PageOffset     int32       `json:"page_offset"`
+}
+
+type UsersSearchUsersRow struct {
+	ID           int32              `json:"id"`
+	Username     string             `json:"username"`
+	ProfileImage pgtype.Text        `json:"profile_image"`
+	FirstName    string             `json:"first_name"`
+	MiddleName   pgtype.Text        `json:"middle_name"`
+	LastName     pgtype.Text        `json:"last_name"`
+	RoleName     pgtype.Text        `json:"role_name"`
+	MfaEnforced  pgtype.Bool        `json:"mfa_enforced"`
+	BlockedAt    pgtype.Timestamptz `json:"blocked_at"`
+	BlockedUntil pgtype.Timestamptz `json:"blocked_until"`
+	DeletedAt    pgtype.Timestamptz `json:"deleted_at"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+}
+
+// UsersSearchUsers
+//
+//	SELECT
+//	    u.id,
+//	    u.username,
+//	    u.profile_image,
+//	    u.first_name,
+//	    u.middle_name,
+//	    u.last_name,
+//	    u.role_name,
+//	    u.mfa_enforced,
+//	    u.blocked_at,
+//	    u.blocked_until,
+//	    u.deleted_at,
+//	    u.created_at
+//	FROM users AS u
+//	WHERE ($1::bool OR u.deleted_at IS NULL)
+//	    AND ($2::text IS NULL OR u.role_name = $2::text)
+//	    AND (
+//	        $3::text IS NULL
+//	        OR u.username ILIKE '%' || $3::text || '%' ESCAPE '\'
+//	        OR (u.first_name || ' ' || COALESCE(u.last_name, '')) ILIKE '%' || $3::text || '%' ESCAPE '\'
+//	        OR EXISTS (
+//	            SELECT 1
+//	            FROM login_identity AS li
+//	                JOIN password_login_identity AS pli ON pli.login_identity_id = li.id
+//	            WHERE li.user_id = u.id
+//	                AND (pli.email ILIKE '%' || $3::text || '%' ESCAPE '\' OR pli.phone LIKE '%' || $3::text || '%' ESCAPE '\')
+//	        )
+//	    )
+//	ORDER BY u.id DESC
+//	LIMIT $4::int OFFSET $5::int
+func (q *Queries) UsersSearchUsers(ctx context.Context, arg UsersSearchUsersParams) ([]UsersSearchUsersRow, error) {
+	rows, err := q.db.Query(ctx, usersSearchUsers,
+		arg.IncludeDeleted,
+		arg.RoleName,
+		arg.Query,
+		arg.PageLimit,
+		arg.PageOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []UsersSearchUsersRow{}
+	for rows.Next() {
+		var i UsersSearchUsersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.ProfileImage,
+			&i.FirstName,
+			&i.MiddleName,
+			&i.LastName,
+			&i.RoleName,
+			&i.MfaEnforced,
+			&i.BlockedAt,
+			&i.BlockedUntil,
+			&i.DeletedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const usersSetMfaEnforcedForUser = `-- name: UsersSetMfaEnforcedForUser :execrows
+UPDATE users
+SET mfa_enforced = $1::bool
+WHERE id = $2::int
+    AND deleted_at IS NULL
+`
+
+type UsersSetMfaEnforcedForUserParams struct {
+	MfaEnforced bool  `json:"mfa_enforced"`
+	ID          int32 `json:"id"`
+}
+
+// UsersSetMfaEnforcedForUser
+//
+//	UPDATE users
+//	SET mfa_enforced = $1::bool
+//	WHERE id = $2::int
+//	    AND deleted_at IS NULL
+func (q *Queries) UsersSetMfaEnforcedForUser(ctx context.Context, arg UsersSetMfaEnforcedForUserParams) (int64, error) {
+	result, err := q.db.Exec(ctx, usersSetMfaEnforcedForUser, arg.MfaEnforced, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const usersSetRoleForUser = `-- name: UsersSetRoleForUser :execrows
+UPDATE users
+SET role_name = $1::text
+WHERE id = $2::int
+    AND deleted_at IS NULL
+    AND (
+        $1::text IS NULL
+        OR EXISTS (SELECT 1 FROM role WHERE name = $1::text AND deleted_at IS NULL)
+    )
+`
+
+type UsersSetRoleForUserParams struct {
+	RoleName pgtype.Text `json:"role_name"`
+	ID       int32       `json:"id"`
+}
+
+// UsersSetRoleForUser
+//
+//	UPDATE users
+//	SET role_name = $1::text
+//	WHERE id = $2::int
+//	    AND deleted_at IS NULL
+//	    AND (
+//	        $1::text IS NULL
+//	        OR EXISTS (SELECT 1 FROM role WHERE name = $1::text AND deleted_at IS NULL)
+//	    )
+func (q *Queries) UsersSetRoleForUser(ctx context.Context, arg UsersSetRoleForUserParams) (int64, error) {
+	result, err := q.db.Exec(ctx, usersSetRoleForUser, arg.RoleName, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const usersSoftDeleteUser = `-- name: UsersSoftDeleteUser :execrows
 UPDATE users
 SET deleted_at = NOW()
 WHERE id = $1
+    AND deleted_at IS NULL
 `
 
 // UsersSoftDeleteUser
@@ -245,8 +618,59 @@ WHERE id = $1
 //	UPDATE users
 //	SET deleted_at = NOW()
 //	WHERE id = $1
-func (q *Queries) UsersSoftDeleteUser(ctx context.Context, id int32) error {
-	_, err := q.db.Exec(ctx, usersSoftDeleteUser, id)
+//	    AND deleted_at IS NULL
+func (q *Queries) UsersSoftDeleteUser(ctx context.Context, id int32) (int64, error) {
+	result, err := q.db.Exec(ctx, usersSoftDeleteUser, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const usersUnblockUser = `-- name: UsersUnblockUser :execrows
+UPDATE users
+SET blocked_at = NULL,
+    blocked_until = NULL
+WHERE id = $1::int
+    AND deleted_at IS NULL
+`
+
+// UsersUnblockUser
+//
+//	UPDATE users
+//	SET blocked_at = NULL,
+//	    blocked_until = NULL
+//	WHERE id = $1::int
+//	    AND deleted_at IS NULL
+func (q *Queries) UsersUnblockUser(ctx context.Context, id int32) (int64, error) {
+	result, err := q.db.Exec(ctx, usersUnblockUser, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const usersUpdateNamesForUser = `-- name: UsersUpdateNamesForUser :exec
+UPDATE users
+SET first_name = $1::text,
+    last_name = $2::text
+WHERE id = $3::int
+`
+
+type UsersUpdateNamesForUserParams struct {
+	FirstName string      `json:"first_name"`
+	LastName  pgtype.Text `json:"last_name"`
+	ID        int32       `json:"id"`
+}
+
+// UsersUpdateNamesForUser
+//
+//	UPDATE users
+//	SET first_name = $1::text,
+//	    last_name = $2::text
+//	WHERE id = $3::int
+func (q *Queries) UsersUpdateNamesForUser(ctx context.Context, arg UsersUpdateNamesForUserParams) error {
+	_, err := q.db.Exec(ctx, usersUpdateNamesForUser, arg.FirstName, arg.LastName, arg.ID)
 	return err
 }
 
@@ -258,7 +682,7 @@ SET username = $2,
     last_name = $5,
     role_name = $6
 WHERE id = $1
-RETURNING id, username, profile_image, first_name, middle_name, last_name, created_at, updated_at, blocked_at, blocked_until, deleted_at, role_name
+RETURNING id, username, profile_image, first_name, middle_name, last_name, created_at, updated_at, blocked_at, blocked_until, mfa_enforced, deleted_at, role_name
 `
 
 type UsersUpdateUserDataParams struct {
@@ -279,7 +703,7 @@ type UsersUpdateUserDataParams struct {
 //	    last_name = $5,
 //	    role_name = $6
 //	WHERE id = $1
-//	RETURNING id, username, profile_image, first_name, middle_name, last_name, created_at, updated_at, blocked_at, blocked_until, deleted_at, role_name
+//	RETURNING id, username, profile_image, first_name, middle_name, last_name, created_at, updated_at, blocked_at, blocked_until, mfa_enforced, deleted_at, role_name
 func (q *Queries) UsersUpdateUserData(ctx context.Context, arg UsersUpdateUserDataParams) (User, error) {
 	row := q.db.QueryRow(ctx, usersUpdateUserData,
 		arg.ID,
@@ -301,6 +725,7 @@ func (q *Queries) UsersUpdateUserData(ctx context.Context, arg UsersUpdateUserDa
 		&i.UpdatedAt,
 		&i.BlockedAt,
 		&i.BlockedUntil,
+		&i.MfaEnforced,
 		&i.DeletedAt,
 		&i.RoleName,
 	)
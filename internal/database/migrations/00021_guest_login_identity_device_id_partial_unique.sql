@@ -0,0 +1,11 @@
+-- +goose Up
+-- an upgraded guest keeps its soft-deleted guest identity, so the device
+-- must be free to create a new guest account afterward
+ALTER TABLE guest_login_identity DROP CONSTRAINT guest_login_identity_device_id_key;
+CREATE UNIQUE INDEX guest_login_identity_device_id_active_unique_idx
+    ON guest_login_identity (device_id)
+    WHERE deleted_at IS NULL;
+
+-- +goose Down
+DROP INDEX guest_login_identity_device_id_active_unique_idx;
+ALTER TABLE guest_login_identity ADD CONSTRAINT guest_login_identity_device_id_key UNIQUE (device_id);
@@ -0,0 +1,26 @@
+-- +goose Up
+CREATE TABLE user_totp (
+    id SERIAL PRIMARY KEY NOT NULL,
+    user_id INTEGER NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    secret TEXT NOT NULL CHECK (char_length(secret) > 0),
+    -- NULL until the user proves the authenticator app is set up by sending a valid code
+    confirmed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW() NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT NOW() NOT NULL
+);
+
+CREATE TRIGGER update_user_totp_updated_at_column BEFORE
+UPDATE ON user_totp FOR EACH ROW EXECUTE PROCEDURE trigger_set_updated_at_column ();
+
+CREATE TABLE user_mfa_recovery_code (
+    id SERIAL PRIMARY KEY NOT NULL,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash TEXT NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW() NOT NULL,
+    UNIQUE (user_id, code_hash)
+);
+
+-- +goose Down
+DROP TABLE user_mfa_recovery_code;
+DROP TABLE user_totp;
@@ -0,0 +1,26 @@
+-- +goose Up
+ALTER TABLE session ADD last_active_at TIMESTAMPTZ DEFAULT NOW() NOT NULL;
+
+-- the view must be recreated to include the new column
+DROP VIEW active_session;
+CREATE VIEW active_session AS
+SELECT
+    *
+FROM
+    session
+WHERE
+    expires_at > NOW ()
+    AND deleted_at IS NULL;
+
+-- +goose Down
+DROP VIEW active_session;
+ALTER TABLE session
+DROP COLUMN last_active_at;
+CREATE VIEW active_session AS
+SELECT
+    *
+FROM
+    session
+WHERE
+    expires_at > NOW ()
+    AND deleted_at IS NULL;
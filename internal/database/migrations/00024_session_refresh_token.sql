@@ -0,0 +1,13 @@
+-- +goose Up
+-- session.token now holds the hash of the current refresh token of the session,
+-- the previous ones are kept here to detect the reuse of a rotated refresh token
+CREATE TABLE session_used_refresh_token (
+    token_hash VARCHAR(2048) PRIMARY KEY NOT NULL,
+    session_id INTEGER NOT NULL REFERENCES session(id) ON DELETE CASCADE,
+    used_at TIMESTAMPTZ DEFAULT NOW() NOT NULL
+);
+
+CREATE INDEX index_session_used_refresh_token_session_id ON session_used_refresh_token (session_id);
+
+-- +goose Down
+DROP TABLE session_used_refresh_token;
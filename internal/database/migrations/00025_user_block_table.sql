@@ -0,0 +1,18 @@
+-- +goose Up
+-- keeps the history of blocking a user by the admins, users.blocked_at and
+-- users.blocked_until hold the current state
+CREATE TABLE user_block (
+    id SERIAL PRIMARY KEY NOT NULL,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    blocked_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    reason TEXT NOT NULL,
+    -- NULL means blocked until unblocked by an admin
+    blocked_until TIMESTAMPTZ,
+    unblocked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW() NOT NULL
+);
+
+CREATE INDEX index_user_block_user_id ON user_block (user_id);
+
+-- +goose Down
+DROP TABLE user_block;
@@ -0,0 +1,19 @@
+-- +goose Up
+ALTER TABLE todo ADD due_at TIMESTAMPTZ;
+ALTER TABLE todo ADD remind_at TIMESTAMPTZ;
+-- set by the reminder scheduler once the reminder is dispatched
+ALTER TABLE todo ADD reminder_sent_at TIMESTAMPTZ;
+
+CREATE INDEX index_todo_pending_reminders ON todo (remind_at)
+WHERE
+    reminder_sent_at IS NULL
+    AND deleted_at IS NULL;
+
+-- +goose Down
+DROP INDEX index_todo_pending_reminders;
+ALTER TABLE todo
+DROP COLUMN reminder_sent_at;
+ALTER TABLE todo
+DROP COLUMN remind_at;
+ALTER TABLE todo
+DROP COLUMN due_at;
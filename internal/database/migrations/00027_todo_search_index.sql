@@ -0,0 +1,7 @@
+-- +goose Up
+-- the 'simple' configuration does not stem the words, so it works for all the supported languages.
+-- the expression must match the one used in the todo search query to use the index
+CREATE INDEX index_todo_search ON todo USING GIN (to_tsvector('simple', title || ' ' || body));
+
+-- +goose Down
+DROP INDEX index_todo_search;
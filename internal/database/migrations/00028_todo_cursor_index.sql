@@ -0,0 +1,8 @@
+-- +goose Up
+-- for the keyset (cursor) pagination of the todos of a user
+CREATE INDEX index_todo_user_id_created_at_id ON todo (user_id, created_at, id)
+WHERE
+    deleted_at IS NULL;
+
+-- +goose Down
+DROP INDEX index_todo_user_id_created_at_id;
@@ -0,0 +1,30 @@
+-- +goose Up
+CREATE TABLE todo_list (
+    id SERIAL PRIMARY KEY NOT NULL,
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name TEXT NOT NULL CHECK (char_length(name) >= 1 AND char_length(name) <= 100),
+    -- hex color e.g. #1E90FF
+    color VARCHAR(7) CHECK (color ~ '^#[0-9a-fA-F]{6}$'),
+    position INTEGER DEFAULT 0 NOT NULL,
+    is_archived BOOLEAN DEFAULT FALSE NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW () NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT NOW () NOT NULL,
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX index_todo_list_user_id ON todo_list (user_id)
+WHERE
+    deleted_at IS NULL;
+
+CREATE TRIGGER update_todo_list_updated_at_column BEFORE
+UPDATE ON todo_list FOR EACH ROW EXECUTE PROCEDURE trigger_set_updated_at_column ();
+
+ALTER TABLE todo ADD list_id INTEGER REFERENCES todo_list (id) ON DELETE SET NULL;
+
+CREATE INDEX index_todo_list_id ON todo (list_id);
+
+-- +goose Down
+DROP INDEX index_todo_list_id;
+ALTER TABLE todo
+DROP COLUMN list_id;
+DROP TABLE todo_list;
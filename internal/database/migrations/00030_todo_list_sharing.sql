@@ -0,0 +1,63 @@
+-- +goose Up
+CREATE TABLE todo_list_member (
+    list_id INTEGER NOT NULL REFERENCES todo_list (id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role VARCHAR(10) NOT NULL CHECK (role IN ('viewer', 'editor', 'owner')),
+    created_at TIMESTAMPTZ DEFAULT NOW () NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT NOW () NOT NULL,
+    PRIMARY KEY (list_id, user_id)
+);
+
+CREATE INDEX index_todo_list_member_user_id ON todo_list_member (user_id);
+
+CREATE TRIGGER update_todo_list_member_updated_at_column BEFORE
+UPDATE ON todo_list_member FOR EACH ROW EXECUTE PROCEDURE trigger_set_updated_at_column ();
+
+-- the members of the not deleted lists
+CREATE VIEW active_todo_list_member AS
+SELECT
+    m.*
+FROM
+    todo_list_member AS m
+    JOIN todo_list AS l ON l.id = m.list_id
+WHERE
+    l.deleted_at IS NULL;
+
+-- the creators of the existing lists are their owners
+INSERT INTO
+    todo_list_member (list_id, user_id, role)
+SELECT
+    id,
+    user_id,
+    'owner'
+FROM
+    todo_list;
+
+CREATE TABLE todo_list_invitation (
+    id SERIAL PRIMARY KEY NOT NULL,
+    list_id INTEGER NOT NULL REFERENCES todo_list (id) ON DELETE CASCADE,
+    inviter_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    invitee_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role VARCHAR(10) NOT NULL CHECK (role IN ('viewer', 'editor', 'owner')),
+    status VARCHAR(10) DEFAULT 'pending' NOT NULL CHECK (status IN ('pending', 'accepted', 'declined')),
+    created_at TIMESTAMPTZ DEFAULT NOW () NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT NOW () NOT NULL,
+    responded_at TIMESTAMPTZ
+);
+
+-- one pending invitation per user and list
+CREATE UNIQUE INDEX unique_pending_todo_list_invitation ON todo_list_invitation (list_id, invitee_id)
+WHERE
+    status = 'pending';
+
+CREATE INDEX index_todo_list_invitation_invitee_id ON todo_list_invitation (invitee_id)
+WHERE
+    status = 'pending';
+
+CREATE TRIGGER update_todo_list_invitation_updated_at_column BEFORE
+UPDATE ON todo_list_invitation FOR EACH ROW EXECUTE PROCEDURE trigger_set_updated_at_column ();
+
+-- +goose Down
+DROP TABLE todo_list_invitation;
+DROP VIEW active_todo_list_member;
+DROP TABLE todo_list_member;
@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE todo_checklist_item (
+    id SERIAL PRIMARY KEY NOT NULL,
+    todo_id INTEGER NOT NULL REFERENCES todo (id) ON DELETE CASCADE,
+    text TEXT NOT NULL CHECK (char_length(text) >= 1 AND char_length(text) <= 500),
+    is_done BOOLEAN DEFAULT FALSE NOT NULL,
+    position INTEGER DEFAULT 0 NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW () NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT NOW () NOT NULL
+);
+
+CREATE INDEX index_todo_checklist_item_todo_id ON todo_checklist_item (todo_id, position);
+
+CREATE TRIGGER update_todo_checklist_item_updated_at_column BEFORE
+UPDATE ON todo_checklist_item FOR EACH ROW EXECUTE PROCEDURE trigger_set_updated_at_column ();
+
+-- +goose Down
+DROP TABLE todo_checklist_item;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- RFC 5545 recurrence rule e.g. FREQ=WEEKLY;BYDAY=MO,WE
+ALTER TABLE todo ADD recurrence_rule TEXT;
+
+-- the IANA timezone that the occurrences are expanded in e.g. Europe/Berlin
+ALTER TABLE todo ADD recurrence_timezone TEXT DEFAULT 'UTC' NOT NULL;
+
+-- the first occurrence (DTSTART), it is copied to the next occurrences
+ALTER TABLE todo ADD recurrence_start TIMESTAMPTZ;
+
+-- +goose Down
+ALTER TABLE todo
+DROP COLUMN recurrence_start;
+ALTER TABLE todo
+DROP COLUMN recurrence_timezone;
+ALTER TABLE todo
+DROP COLUMN recurrence_rule;
@@ -0,0 +1,30 @@
+-- +goose Up
+CREATE TABLE todo_tag (
+    id SERIAL PRIMARY KEY NOT NULL,
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name TEXT NOT NULL CHECK (char_length(name) >= 1 AND char_length(name) <= 50),
+    -- hex color e.g. #1E90FF
+    color VARCHAR(7) CHECK (color ~ '^#[0-9a-fA-F]{6}$'),
+    created_at TIMESTAMPTZ DEFAULT NOW () NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT NOW () NOT NULL
+);
+
+-- the tag names are unique per user, ignoring the case
+CREATE UNIQUE INDEX index_todo_tag_user_id_name ON todo_tag (user_id, lower(name));
+
+CREATE TRIGGER update_todo_tag_updated_at_column BEFORE
+UPDATE ON todo_tag FOR EACH ROW EXECUTE PROCEDURE trigger_set_updated_at_column ();
+
+CREATE TABLE todo_tag_link (
+    todo_id INTEGER NOT NULL REFERENCES todo (id) ON DELETE CASCADE,
+    tag_id INTEGER NOT NULL REFERENCES todo_tag (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ DEFAULT NOW () NOT NULL,
+    PRIMARY KEY (todo_id, tag_id)
+);
+
+-- for the tag filter of the todos
+CREATE INDEX index_todo_tag_link_tag_id ON todo_tag_link (tag_id);
+
+-- +goose Down
+DROP TABLE todo_tag_link;
+DROP TABLE todo_tag;
@@ -0,0 +1,55 @@
+-- +goose Up
+CREATE TABLE todo_workflow_status (
+    id SERIAL PRIMARY KEY NOT NULL,
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    -- the value of todo.status
+    name TEXT NOT NULL CHECK (char_length(name) >= 1 AND char_length(name) <= 50),
+    position INTEGER DEFAULT 0 NOT NULL,
+    is_completed BOOLEAN DEFAULT FALSE NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW () NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT NOW () NOT NULL
+);
+
+CREATE UNIQUE INDEX index_todo_workflow_status_user_id_name ON todo_workflow_status (user_id, name);
+
+-- at most one completed status per user
+CREATE UNIQUE INDEX index_todo_workflow_status_user_id_completed ON todo_workflow_status (user_id)
+WHERE
+    is_completed;
+
+CREATE TRIGGER update_todo_workflow_status_updated_at_column BEFORE
+UPDATE ON todo_workflow_status FOR EACH ROW EXECUTE PROCEDURE trigger_set_updated_at_column ();
+
+-- the default workflow for the users that already have todos,
+-- the other users get it when they use the workflow for the first time
+INSERT INTO
+    todo_workflow_status (user_id, name, position, is_completed)
+SELECT
+    u.user_id,
+    d.name,
+    d.position,
+    d.is_completed
+FROM (
+        SELECT DISTINCT user_id
+        FROM todo
+    ) AS u
+    CROSS JOIN (
+        VALUES
+            ('pending', 0, FALSE),
+            ('in_progress', 1, FALSE),
+            ('done', 2, TRUE)
+    ) AS d (name, position, is_completed);
+
+-- keep any other status used by the todos, after the default ones
+INSERT INTO
+    todo_workflow_status (user_id, name, position)
+SELECT DISTINCT
+    user_id,
+    status,
+    3
+FROM todo
+WHERE
+    status NOT IN ('pending', 'in_progress', 'done');
+
+-- +goose Down
+DROP TABLE todo_workflow_status;
@@ -0,0 +1,44 @@
+-- +goose Up
+CREATE TABLE todo_attachment (
+    id SERIAL PRIMARY KEY NOT NULL,
+    todo_id INTEGER NOT NULL REFERENCES todo (id) ON DELETE CASCADE,
+    -- the user who uploaded the file
+    user_id INTEGER REFERENCES users (id) ON DELETE SET NULL,
+    -- the key of the file in the blob store
+    storage_key TEXT NOT NULL UNIQUE,
+    filename TEXT NOT NULL CHECK (char_length(filename) >= 1 AND char_length(filename) <= 255),
+    content_type TEXT NOT NULL,
+    size_bytes BIGINT NOT NULL CHECK (size_bytes >= 0),
+    created_at TIMESTAMPTZ DEFAULT NOW () NOT NULL
+);
+
+CREATE INDEX index_todo_attachment_todo_id ON todo_attachment (todo_id);
+
+-- the files of the deleted attachments (including the ones deleted with their todos),
+-- they are removed from the blob store in the background
+CREATE TABLE todo_attachment_deleted_blob (
+    storage_key TEXT PRIMARY KEY NOT NULL,
+    deleted_at TIMESTAMPTZ DEFAULT NOW () NOT NULL
+);
+
+-- +goose statementbegin
+CREATE OR REPLACE FUNCTION todo_attachment_queue_deleted_blob_fn()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO todo_attachment_deleted_blob (storage_key)
+    VALUES (OLD.storage_key)
+    ON CONFLICT DO NOTHING;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose statementend
+
+CREATE TRIGGER todo_attachment_queue_deleted_blob AFTER
+DELETE ON todo_attachment FOR EACH ROW EXECUTE PROCEDURE todo_attachment_queue_deleted_blob_fn ();
+
+-- +goose Down
+DROP TABLE todo_attachment;
+
+DROP FUNCTION todo_attachment_queue_deleted_blob_fn;
+
+DROP TABLE todo_attachment_deleted_blob;
@@ -0,0 +1,32 @@
+-- +goose Up
+CREATE TABLE todo_activity (
+    id SERIAL PRIMARY KEY NOT NULL,
+    todo_id INTEGER NOT NULL REFERENCES todo (id) ON DELETE CASCADE,
+    -- the user who did the change, null if the user is deleted
+    user_id INTEGER REFERENCES users (id) ON DELETE SET NULL,
+    action TEXT NOT NULL,
+    -- the changed fields with their old and new values e.g. {"title": {"old": "a", "new": "b"}}
+    changes JSONB DEFAULT '{}' NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW () NOT NULL
+);
+
+CREATE INDEX index_todo_activity_todo_id_created_at ON todo_activity (todo_id, created_at);
+
+CREATE TABLE todo_comment (
+    id SERIAL PRIMARY KEY NOT NULL,
+    todo_id INTEGER NOT NULL REFERENCES todo (id) ON DELETE CASCADE,
+    user_id INTEGER REFERENCES users (id) ON DELETE SET NULL,
+    body TEXT NOT NULL CHECK (char_length(body) >= 1 AND char_length(body) <= 5000),
+    created_at TIMESTAMPTZ DEFAULT NOW () NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT NOW () NOT NULL
+);
+
+CREATE INDEX index_todo_comment_todo_id_created_at ON todo_comment (todo_id, created_at);
+
+CREATE TRIGGER update_todo_comment_updated_at_column BEFORE
+UPDATE ON todo_comment FOR EACH ROW EXECUTE PROCEDURE trigger_set_updated_at_column ();
+
+-- +goose Down
+DROP TABLE todo_comment;
+
+DROP TABLE todo_activity;
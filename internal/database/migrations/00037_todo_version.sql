@@ -0,0 +1,28 @@
+-- +goose Up
+-- incremented on every change of the todo, used as the ETag of the todo
+-- for the conditional requests (If-Match/If-None-Match)
+ALTER TABLE todo ADD version INTEGER DEFAULT 1 NOT NULL;
+
+-- +goose statementbegin
+CREATE OR REPLACE FUNCTION todo_increment_version_fn()
+RETURNS TRIGGER AS $$
+BEGIN
+    -- the sent reminders are not a change of the todo, and the no-op updates
+    -- should not invalidate the versions that the clients have
+    IF (to_jsonb(NEW) - 'version' - 'updated_at' - 'reminder_sent_at')
+        IS DISTINCT FROM (to_jsonb(OLD) - 'version' - 'updated_at' - 'reminder_sent_at') THEN
+        NEW.version = OLD.version + 1;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose statementend
+
+CREATE TRIGGER todo_increment_version BEFORE
+UPDATE ON todo FOR EACH ROW EXECUTE PROCEDURE todo_increment_version_fn ();
+
+-- +goose Down
+DROP TRIGGER todo_increment_version ON todo;
+DROP FUNCTION todo_increment_version_fn;
+ALTER TABLE todo
+DROP COLUMN version;
@@ -0,0 +1,40 @@
+-- +goose Up
+-- the todos that are gone for the members of a list without a soft-delete, so the
+-- delta sync can tell the clients to remove them: the purged todos, and the todos
+-- that are moved out of a list. They are purged with the trash after its retention
+CREATE TABLE todo_sync_tombstone (
+    id SERIAL PRIMARY KEY NOT NULL,
+    -- not a foreign key, the todo may not exist anymore
+    todo_id INTEGER NOT NULL,
+    -- the creator and the list of the todo before the change, for the visibility checks
+    user_id INTEGER NOT NULL,
+    list_id INTEGER,
+    created_at TIMESTAMPTZ DEFAULT clock_timestamp() NOT NULL
+);
+
+CREATE INDEX index_todo_sync_tombstone_created_at_todo_id ON todo_sync_tombstone (created_at, todo_id);
+
+-- +goose statementbegin
+CREATE OR REPLACE FUNCTION todo_sync_record_tombstone_fn()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' OR OLD.list_id IS DISTINCT FROM NEW.list_id THEN
+        INSERT INTO todo_sync_tombstone (todo_id, user_id, list_id)
+        VALUES (OLD.id, OLD.user_id, OLD.list_id);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose statementend
+
+CREATE TRIGGER todo_sync_record_tombstone AFTER
+UPDATE OF list_id OR DELETE ON todo FOR EACH ROW EXECUTE PROCEDURE todo_sync_record_tombstone_fn ();
+
+-- the delta sync reads the todos in the order of their changes
+CREATE INDEX index_todo_updated_at_id ON todo (updated_at, id);
+
+-- +goose Down
+DROP INDEX index_todo_updated_at_id;
+DROP TRIGGER todo_sync_record_tombstone ON todo;
+DROP FUNCTION todo_sync_record_tombstone_fn;
+DROP TABLE todo_sync_tombstone;
@@ -0,0 +1,48 @@
+-- +goose Up
+-- the delta sync reads the changes in the order of the transactions that made them. The
+-- timestamps are taken before the commit, so a slower transaction can commit changes below
+-- the position that a sync already returned. The transactions with an id below the oldest
+-- running one (pg_snapshot_xmin) are done, so the changes below it can not change anymore
+CREATE TABLE todo_sync_change (
+    todo_id INTEGER PRIMARY KEY NOT NULL REFERENCES todo (id) ON DELETE CASCADE,
+    -- the id of the last transaction that inserted or updated the todo
+    xid BIGINT NOT NULL
+);
+
+CREATE INDEX index_todo_sync_change_xid_todo_id ON todo_sync_change (xid, todo_id);
+
+INSERT INTO
+    todo_sync_change (todo_id, xid)
+SELECT id, pg_current_xact_id()::TEXT::BIGINT
+FROM todo;
+
+-- +goose statementbegin
+CREATE OR REPLACE FUNCTION todo_sync_record_change_fn()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO todo_sync_change (todo_id, xid)
+    VALUES (NEW.id, pg_current_xact_id()::TEXT::BIGINT)
+    ON CONFLICT (todo_id) DO UPDATE
+    SET xid = EXCLUDED.xid
+    WHERE todo_sync_change.xid <> EXCLUDED.xid;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose statementend
+
+CREATE TRIGGER todo_sync_record_change AFTER INSERT OR UPDATE ON todo FOR EACH ROW EXECUTE PROCEDURE todo_sync_record_change_fn ();
+
+ALTER TABLE todo_sync_tombstone
+ADD COLUMN xid BIGINT DEFAULT pg_current_xact_id()::TEXT::BIGINT NOT NULL;
+
+CREATE INDEX index_todo_sync_tombstone_xid_todo_id ON todo_sync_tombstone (xid, todo_id);
+
+DROP INDEX index_todo_updated_at_id;
+
+-- +goose Down
+CREATE INDEX index_todo_updated_at_id ON todo (updated_at, id);
+DROP INDEX index_todo_sync_tombstone_xid_todo_id;
+ALTER TABLE todo_sync_tombstone DROP COLUMN xid;
+DROP TRIGGER todo_sync_record_change ON todo;
+DROP FUNCTION todo_sync_record_change_fn;
+DROP TABLE todo_sync_change;
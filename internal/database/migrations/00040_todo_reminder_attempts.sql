@@ -0,0 +1,38 @@
+-- +goose Up
+-- the number of the times that the reminder scheduler claimed the reminder, a failed
+-- reminder is released to be retried until it reaches the max attempts
+ALTER TABLE todo ADD reminder_attempts SMALLINT DEFAULT 0 NOT NULL;
+
+-- +goose statementbegin
+CREATE OR REPLACE FUNCTION todo_increment_version_fn()
+RETURNS TRIGGER AS $$
+BEGIN
+    -- the sent reminders are not a change of the todo, and the no-op updates
+    -- should not invalidate the versions that the clients have
+    IF (to_jsonb(NEW) - 'version' - 'updated_at' - 'reminder_sent_at' - 'reminder_attempts')
+        IS DISTINCT FROM (to_jsonb(OLD) - 'version' - 'updated_at' - 'reminder_sent_at' - 'reminder_attempts') THEN
+        NEW.version = OLD.version + 1;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose statementend
+
+-- +goose Down
+-- +goose statementbegin
+CREATE OR REPLACE FUNCTION todo_increment_version_fn()
+RETURNS TRIGGER AS $$
+BEGIN
+    -- the sent reminders are not a change of the todo, and the no-op updates
+    -- should not invalidate the versions that the clients have
+    IF (to_jsonb(NEW) - 'version' - 'updated_at' - 'reminder_sent_at')
+        IS DISTINCT FROM (to_jsonb(OLD) - 'version' - 'updated_at' - 'reminder_sent_at') THEN
+        NEW.version = OLD.version + 1;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose statementend
+
+ALTER TABLE todo
+DROP COLUMN reminder_attempts;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- the time step of the last accepted code, every code is accepted only once
+-- so an intercepted code can not be replayed in the same time window
+ALTER TABLE user_totp ADD last_used_step BIGINT;
+
+-- +goose Down
+ALTER TABLE user_totp
+DROP COLUMN last_used_step;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- the activity of the purged todos is kept, e.g. who purged the todo, until the
+-- trash purger removes it with the sync tombstone of the todo after the trash retention
+ALTER TABLE todo_activity
+DROP CONSTRAINT todo_activity_todo_id_fkey;
+
+-- +goose Down
+DELETE FROM todo_activity AS a
+WHERE NOT EXISTS (
+        SELECT 1
+        FROM todo AS t
+        WHERE t.id = a.todo_id
+    );
+
+ALTER TABLE todo_activity
+ADD CONSTRAINT todo_activity_todo_id_fkey FOREIGN KEY (todo_id) REFERENCES todo (id) ON DELETE CASCADE;
@@ -6,6 +6,7 @@ import (
 	"slices"
 
 	"github.com/Nidal-Bakir/go-todo-backend/internal/database/database_queries"
+	oauth "github.com/Nidal-Bakir/go-todo-backend/internal/feat/auth/oauth/utils"
 	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/perm/baseperm"
 	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/settings/labels"
 	dbutils "github.com/Nidal-Bakir/go-todo-backend/internal/utils/db_utils"
@@ -23,6 +24,10 @@ type seeder struct {
 var seeders = []seeder{
 	v1_baseRollsAndPermission,
 	v2_settingsClientApiToken,
+	v3_enforceUserMfaPermission,
+	v4_oauthProviders,
+	v5_manageUsersPermissions,
+	v6_manageRolesPermissions,
 }
 
 func seed(ctx context.Context, db *Service) (err error) {
@@ -149,3 +154,126 @@ var v2_settingsClientApiToken = seeder{
 		return nil
 	},
 }
+
+var v3_enforceUserMfaPermission = seeder{
+	version: 3,
+	seederFn: func(ctx context.Context, dbTx database_queries.DBTX, queries *database_queries.Queries) error {
+		baseRols := []string{
+			baseperm.BaseRollAdmin,
+			baseperm.BaseRollSystem,
+		}
+
+		_, err := queries.PermCreateNewPermissions(ctx, []string{baseperm.BasePermEnforceUserMfa})
+		if err != nil {
+			return err
+		}
+
+		rolePerms := make([]database_queries.PermAddPermissionsToRolesParams, 0, len(baseRols))
+		for _, r := range baseRols {
+			rolePerms = append(rolePerms, database_queries.PermAddPermissionsToRolesParams{
+				RoleName:       r,
+				PermissionName: baseperm.BasePermEnforceUserMfa,
+			})
+		}
+		_, err = queries.PermAddPermissionsToRoles(ctx, rolePerms)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var v4_oauthProviders = seeder{
+	version: 4,
+	seederFn: func(ctx context.Context, dbTx database_queries.DBTX, queries *database_queries.Queries) error {
+		for _, p := range oauth.SupportedOauthProviders() {
+			err := queries.OauthCreateProvider(
+				ctx,
+				database_queries.OauthCreateProviderParams{
+					Name:          p.String(),
+					IsOidcCapable: p.IsOidcCapable(),
+				},
+			)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+var v5_manageUsersPermissions = seeder{
+	version: 5,
+	seederFn: func(ctx context.Context, dbTx database_queries.DBTX, queries *database_queries.Queries) error {
+		baseRols := []string{
+			baseperm.BaseRollAdmin,
+			baseperm.BaseRollSystem,
+		}
+
+		basePerms := []string{
+			baseperm.BasePermReadUsers,
+			baseperm.BasePermBlockUsers,
+			baseperm.BasePermChangeUsersRole,
+			baseperm.BasePermDeleteUsers,
+		}
+
+		_, err := queries.PermCreateNewPermissions(ctx, basePerms)
+		if err != nil {
+			return err
+		}
+
+		rolePerms := make([]database_queries.PermAddPermissionsToRolesParams, 0, len(baseRols)*len(basePerms))
+		for _, r := range baseRols {
+			for _, p := range basePerms {
+				rolePerms = append(rolePerms, database_queries.PermAddPermissionsToRolesParams{
+					RoleName:       r,
+					PermissionName: p,
+				})
+			}
+		}
+		_, err = queries.PermAddPermissionsToRoles(ctx, rolePerms)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var v6_manageRolesPermissions = seeder{
+	version: 6,
+	seederFn: func(ctx context.Context, dbTx database_queries.DBTX, queries *database_queries.Queries) error {
+		baseRols := []string{
+			baseperm.BaseRollAdmin,
+			baseperm.BaseRollSystem,
+		}
+
+		basePerms := []string{
+			baseperm.BasePermReadRoles,
+			baseperm.BasePermWriteRoles,
+			baseperm.BasePermDeleteRoles,
+		}
+
+		_, err := queries.PermCreateNewPermissions(ctx, basePerms)
+		if err != nil {
+			return err
+		}
+
+		rolePerms := make([]database_queries.PermAddPermissionsToRolesParams, 0, len(baseRols)*len(basePerms))
+		for _, r := range baseRols {
+			for _, p := range basePerms {
+				rolePerms = append(rolePerms, database_queries.PermAddPermissionsToRolesParams{
+					RoleName:       r,
+					PermissionName: p,
+				})
+			}
+		}
+		_, err = queries.PermAddPermissionsToRoles(ctx, rolePerms)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
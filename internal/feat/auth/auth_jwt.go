@@ -9,8 +9,11 @@ import (
 )
 
 const (
-	authSubject = "auth"
-	userIdKey   = "user_id"
+	// tokens issued with the old "auth" subject were long-lived, using a new
+	// subject makes sure they are no longer accepted as access tokens
+	accessSubject = "access"
+	userIdKey     = "user_id"
+	sessionIdKey  = "session_id"
 
 	installationSubject = "installation"
 	installationIdKey   = "installation_id"
@@ -27,23 +30,25 @@ func NewAuthJWT(appjwt *appjwt.AppJWT) *AuthJWT {
 // ---------------------------------------------------------------------
 
 type AuthClaims struct {
-	UserId int32
+	UserId    int32
+	SessionId int32
 	jwt.RegisteredClaims
 }
 
 func (a AuthClaims) toMap() map[string]string {
 	m := make(map[string]string)
 	m[userIdKey] = strconv.Itoa(int(a.UserId))
+	m[sessionIdKey] = strconv.Itoa(int(a.SessionId))
 	return m
 }
 
-func (authJWT AuthJWT) GenWithClaimsForUser(userId int32, expiresAt time.Time) (string, error) {
-	authClaims := AuthClaims{UserId: userId}
-	return authJWT.appjwt.GenWithClaims(expiresAt, authClaims.toMap(), authSubject)
+func (authJWT AuthJWT) GenAccessToken(userId, sessionId int32, expiresAt time.Time) (string, error) {
+	authClaims := AuthClaims{UserId: userId, SessionId: sessionId}
+	return authJWT.appjwt.GenWithClaims(expiresAt, authClaims.toMap(), accessSubject)
 }
 
-func (authJWT AuthJWT) VerifyTokenForUser(token string) (*AuthClaims, error) {
-	c, err := authJWT.appjwt.VerifyToken(token, authSubject)
+func (authJWT AuthJWT) VerifyAccessToken(token string) (*AuthClaims, error) {
+	c, err := authJWT.appjwt.VerifyToken(token, accessSubject)
 	if err != nil {
 		return nil, err
 	}
@@ -53,7 +58,16 @@ func (authJWT AuthJWT) VerifyTokenForUser(token string) (*AuthClaims, error) {
 		return nil, err
 	}
 
-	return &AuthClaims{UserId: int32(userId), RegisteredClaims: c.RegisteredClaims}, nil
+	sessionId, err := strconv.Atoi(c.Claims[sessionIdKey])
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuthClaims{
+		UserId:           int32(userId),
+		SessionId:        int32(sessionId),
+		RegisteredClaims: c.RegisteredClaims,
+	}, nil
 }
 
 // ---------------------------------------------------------------------
@@ -21,14 +21,23 @@ import (
 const (
 	expirationForTempUser               = time.Minute * 30
 	expirationForForgetPasswordTempData = time.Minute * 15
+	expirationForMfaChallengeTempData   = time.Minute * 5
+	expirationForSessionCache           = time.Minute * 5
+	// the session last active at is updated at most once in this duration
+	expirationForSessionTouch = time.Minute * 5
 )
 
+// errSkipSessionCreation is returned from the login token generators to
+// complete the login transaction without creating a session, e.g. when the
+// user must pass an MFA challenge first.
+var errSkipSessionCreation = errors.New("skip session creation")
+
 type DataSource interface {
 	// Query ---
 
 	GetUserById(ctx context.Context, id int32) (database_queries.UsersGetUserByIdRow, error)
 
-	GetUserAndSessionDataBySessionToken(ctx context.Context, sessionToken string) (database_queries.UsersGetUserAndSessionDataBySessionTokenRow, error)
+	GetUserAndSessionDataBySessionId(ctx context.Context, sessionId int32) (database_queries.UsersGetUserAndSessionDataBySessionIdRow, error)
 
 	GetUserFromTempCache(ctx context.Context, tempUserId uuid.UUID) (*TempPasswordUser, error)
 	GetForgetPasswordDataFromTempCache(ctx context.Context, dataId uuid.UUID) (*ForgetPasswordTmpDataStore, error)
@@ -45,29 +54,71 @@ type DataSource interface {
 	IsPhoneUsedInPasswordLoginIdentity(ctx context.Context, phone string) (bool, error)
 	IsEmailUsedInOidcLoginIdentity(ctx context.Context, email string) (bool, error)
 
+	GetGuestLoginIdentityForUser(ctx context.Context, userId int32) (database_queries.LoginIdentityGetGuestLoginIdentityByUserIdRow, error)
+
+	GetUserMfaStatus(ctx context.Context, userId int32) (database_queries.MfaGetUserMfaStatusRow, error)
+	GetTotpForUser(ctx context.Context, userId int32) (database_queries.UserTotp, error)
+	GetMfaChallengeFromTempCache(ctx context.Context, challengeId uuid.UUID) (*MfaChallengeTmpDataStore, error)
+
+	SearchUsers(ctx context.Context, params SearchUsersParams, offset, limit int32) ([]database_queries.UsersSearchUsersRow, error)
+	GetUserForAdmin(ctx context.Context, userId int32) (database_queries.UsersGetUserForAdminRow, error)
+
+	GetActiveSessionsForUser(ctx context.Context, userId int32) ([]database_queries.SessionGetActiveSessionsForUserRow, error)
+	GetActiveSessionById(ctx context.Context, sessionId int32) (database_queries.ActiveSession, error)
+	GetActiveSessionByToken(ctx context.Context, sessionToken string) (database_queries.ActiveSession, error)
+	GetSessionIdByUsedRefreshToken(ctx context.Context, refreshTokenHash string) (int32, error)
+	GetActiveSessionForUser(ctx context.Context, sessionId, userId int32) (database_queries.ActiveSession, error)
+
 	// Create ---
 
 	StoreUserInTempCache(ctx context.Context, tUser TempPasswordUser) error
 	StoreForgetPasswordDataInTempCache(ctx context.Context, forgetPassData ForgetPasswordTmpDataStore) error
 	CreatePasswordUser(ctx context.Context, userArgs CreatePasswordUserArgs) (user database_queries.User, err error)
-	CreateNewSessionAndAttachUserToInstallation(ctx context.Context, loginIdentityId, installationId int32, token string, ipAddress netip.Addr, expiresAt time.Time) error
+	CreateNewSessionAndAttachUserToInstallation(ctx context.Context, loginIdentityId, installationId int32, token string, ipAddress netip.Addr, expiresAt time.Time) (sessionId int32, err error)
 	CreateInstallation(ctx context.Context, data CreateInstallationData, installationToken string) error
+	StoreMfaChallengeInTempCache(ctx context.Context, challenge MfaChallengeTmpDataStore) error
+	// returns false if the user already has a confirmed TOTP
+	UpsertUnconfirmedTotpForUser(ctx context.Context, userId int32, secret string) (bool, error)
+	UseTotpStepForUser(ctx context.Context, userId int32, step uint64) (bool, error)
+
+	LoginOrCreateUserWithOidc(ctx context.Context, data LoginOrCreateUserWithOidcData, tokenGenerator func(userId, loginIdentityId int32) (string, time.Time, error)) (user database_queries.User, sessionId int32, err error)
+	LoginOrCreateGuestUser(ctx context.Context, data LoginOrCreateGuestUserData, tokenGenerator func(userId, loginIdentityId int32) (string, time.Time, error)) (user database_queries.User, sessionId int32, isNewUser bool, err error)
 
-	LoginOrCreateUserWithOidc(ctx context.Context, data LoginOrCreateUserWithOidcData, tokenGenerator func(userId int32) (string, time.Time, error)) (database_queries.User, error)
+	UpgradeGuestUserToPasswordUser(ctx context.Context, guestUserId int32, userArgs CreatePasswordUserArgs) (database_queries.User, error)
+	UpgradeGuestUserToOidcUser(ctx context.Context, guestUserId int32, data LoginOrCreateUserWithOidcData) (database_queries.User, error)
 
 	// Update ---
 
 	UpdateusernameForUser(ctx context.Context, userId int32, newUsername string) error
+	TouchSessionLastActiveAt(ctx context.Context, sessionId int32) error
 
 	UpdateInstallation(ctx context.Context, installationToken string, data UpdateInstallationData) error
 	ExpTokenAndUnlinkFromInstallation(ctx context.Context, installationId, tokenId int) error
+	// returns false if the session is no longer active or the old token was already rotated
+	RotateSessionToken(ctx context.Context, sessionId int32, oldToken, newToken string, expiresAt time.Time) (bool, error)
 	ExpAllTokensAndUnlinkThemFromInstallation(ctx context.Context, userId int) error
 
 	ChangePasswordLoginIdentityForUser(ctx context.Context, userId int32, HashedPass, PassSalt string) error
 
+	IncrMfaChallengeAttempts(ctx context.Context, challengeId uuid.UUID) (int64, error)
+	ConfirmTotpAndReplaceRecoveryCodesForUser(ctx context.Context, userId int32, recoveryCodeHashes []string) error
+	ReplaceRecoveryCodesForUser(ctx context.Context, userId int32, recoveryCodeHashes []string) error
+	// returns false if the code is not found or already used
+	UseRecoveryCodeForUser(ctx context.Context, userId int32, recoveryCodeHash string) (bool, error)
+	SetMfaEnforcedForUser(ctx context.Context, userId int32, enforced bool) error
+	BlockUser(ctx context.Context, userId, blockedBy int32, reason string, blockedUntil pgtype.Timestamptz) error
+	UnblockUser(ctx context.Context, userId int32) error
+	// returns false if the role does not exist
+	SetRoleForUser(ctx context.Context, userId int32, roleName pgtype.Text) (bool, error)
+	SoftDeleteUser(ctx context.Context, userId int32) error
+
 	// Delete ---
 	DeleteUserFromTempCache(ctx context.Context, tempUserId uuid.UUID) error
+	DeleteSessionFromCache(ctx context.Context, sessionId int32) error
+	DeleteAllSessionsOfUserFromCache(ctx context.Context, userId int32) error
 	DeleteForgetPasswordDataFromTempCache(ctx context.Context, dataId uuid.UUID) error
+	DeleteMfaChallengeFromTempCache(ctx context.Context, challengeId uuid.UUID) error
+	DeleteTotpAndRecoveryCodesForUser(ctx context.Context, userId int32) error
 }
 
 type dataSourceImpl struct {
@@ -90,8 +141,26 @@ func (ds dataSourceImpl) GetUserById(ctx context.Context, id int32) (database_qu
 	return dbUser, nil
 }
 
-func (ds dataSourceImpl) GetUserAndSessionDataBySessionToken(ctx context.Context, sessionToken string) (database_queries.UsersGetUserAndSessionDataBySessionTokenRow, error) {
-	userWithSessionData, err := ds.db.Queries.UsersGetUserAndSessionDataBySessionToken(ctx, sessionToken)
+// GetUserAndSessionDataBySessionId is called by the auth middleware on every request,
+// so the result is cached until the session or the user data changes.
+func (ds dataSourceImpl) GetUserAndSessionDataBySessionId(ctx context.Context, sessionId int32) (database_queries.UsersGetUserAndSessionDataBySessionIdRow, error) {
+	zlog := zerolog.Ctx(ctx)
+
+	cached, err := ds.redis.HGetAll(ctx, genSessionCacheKey(sessionId)).Result()
+	if err != nil {
+		zlog.Err(err).Msg("error while getting the session from the cache, falling back to the database")
+	} else if len(cached) != 0 {
+		return userAndSessionRowFromMap(cached), nil
+	}
+
+	// read before the database, so an invalidation in between is detected when storing
+	cacheVersion, err := ds.getSessionCacheVersion(ctx)
+	canStoreInCache := err == nil
+	if err != nil {
+		zlog.Err(err).Msg("error while getting the session cache version, the session will not be cached")
+	}
+
+	userWithSessionData, err := ds.db.Queries.UsersGetUserAndSessionDataBySessionId(ctx, sessionId)
 	if err != nil {
 		if dbutils.IsErrPgxNoRows(err) {
 			return userWithSessionData, apperr.ErrNoResult
@@ -99,7 +168,122 @@ func (ds dataSourceImpl) GetUserAndSessionDataBySessionToken(ctx context.Context
 		return userWithSessionData, err
 	}
 
-	return userWithSessionData, err
+	if canStoreInCache {
+		if err := ds.storeSessionInCache(ctx, userWithSessionData, cacheVersion); err != nil {
+			zlog.Err(err).Msg("error while storing the session in the cache. igonoring this error")
+		}
+	}
+
+	return userWithSessionData, nil
+}
+
+func genSessionCacheKey(sessionId int32) string {
+	return fmt.Sprint("session:cache:", sessionId)
+}
+
+// holds the ids of the cached sessions of the user, to invalidate them
+// all when the user data changes
+func genUserSessionsCacheKey(userId int32) string {
+	return fmt.Sprint("session:cache:user:", userId)
+}
+
+// incremented on every invalidation of the session cache. A session read from the database
+// while an invalidation happened can be stale, so it is stored only if the version did not change
+const sessionCacheVersionKey = "session:cache:version"
+
+func (ds dataSourceImpl) getSessionCacheVersion(ctx context.Context) (int64, error) {
+	version, err := ds.redis.Get(ctx, sessionCacheVersionKey).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return version, err
+}
+
+func (ds dataSourceImpl) storeSessionInCache(ctx context.Context, userWithSessionData database_queries.UsersGetUserAndSessionDataBySessionIdRow, cacheVersion int64) error {
+	// never keep an expired session in the cache
+	expiration := min(expirationForSessionCache, time.Until(userWithSessionData.SessionExpiresAt.Time))
+	if expiration <= 0 {
+		return nil
+	}
+
+	key := genSessionCacheKey(userWithSessionData.SessionID)
+	userKey := genUserSessionsCacheKey(userWithSessionData.UserID)
+
+	err := ds.redis.Watch(
+		ctx,
+		func(tx *redis.Tx) error {
+			version, err := tx.Get(ctx, sessionCacheVersionKey).Int64()
+			if err != nil && !errors.Is(err, redis.Nil) {
+				return err
+			}
+			if version != cacheVersion {
+				// invalidated after reading the session from the database
+				return nil
+			}
+
+			_, err = tx.TxPipelined(ctx, func(pip redis.Pipeliner) error {
+				pip.Del(ctx, key)
+				pip.HSet(ctx, key, userAndSessionRowToMap(userWithSessionData))
+				pip.Expire(ctx, key, expiration)
+				pip.SAdd(ctx, userKey, userWithSessionData.SessionID)
+				pip.Expire(ctx, userKey, expirationForSessionCache)
+				return nil
+			})
+			return err
+		},
+		sessionCacheVersionKey,
+	)
+
+	// invalidated between the version check and the store
+	if errors.Is(err, redis.TxFailedErr) {
+		return nil
+	}
+	return err
+}
+
+func (ds dataSourceImpl) DeleteSessionFromCache(ctx context.Context, sessionId int32) error {
+	pip := ds.redis.TxPipeline()
+	pip.Incr(ctx, sessionCacheVersionKey)
+	pip.Del(ctx, genSessionCacheKey(sessionId))
+	_, err := pip.Exec(ctx)
+	return err
+}
+
+func (ds dataSourceImpl) DeleteAllSessionsOfUserFromCache(ctx context.Context, userId int32) error {
+	userKey := genUserSessionsCacheKey(userId)
+
+	// before reading the cached sessions of the user, so a session stored after
+	// reading them is not stored with the stale data
+	if err := ds.redis.Incr(ctx, sessionCacheVersionKey).Err(); err != nil {
+		return err
+	}
+
+	sessionIds, err := ds.redis.SMembers(ctx, userKey).Result()
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(sessionIds)+1)
+	keys = append(keys, userKey)
+	for _, id := range sessionIds {
+		keys = append(keys, fmt.Sprint("session:cache:", id))
+	}
+
+	return ds.redis.Del(ctx, keys...).Err()
+}
+
+// invalidateSessionCache is called after the session data is changed in the database,
+// a failure only delays the change until the cached value expires.
+func (ds dataSourceImpl) invalidateSessionCache(ctx context.Context, sessionId int32) {
+	if err := ds.DeleteSessionFromCache(ctx, sessionId); err != nil {
+		zerolog.Ctx(ctx).Err(err).Int32("session_id", sessionId).Msg("error while deleting the session from the cache. igonoring this error")
+	}
+}
+
+func (ds dataSourceImpl) invalidateUserSessionsCache(ctx context.Context, userId int32) {
+	if err := ds.DeleteAllSessionsOfUserFromCache(ctx, userId); err != nil {
+		zerolog.Ctx(ctx).Err(err).Int32("user_id", userId).Msg("error while deleting the sessions of the user from the cache. igonoring this error")
+	}
 }
 
 func genTempUserId(id uuid.UUID) string {
@@ -229,11 +413,11 @@ func (ds dataSourceImpl) CreateNewSessionAndAttachUserToInstallation(
 	token string,
 	ipAddress netip.Addr,
 	expiresAt time.Time,
-) (err error) {
-	return ds.usingTransaction(
+) (sessionId int32, err error) {
+	err = ds.usingTransaction(
 		ctx,
 		func(queries *database_queries.Queries) error {
-			return ds.createNewSessionAndAttachUserToInstallation(
+			sessionId, err = ds.createNewSessionAndAttachUserToInstallation(
 				ctx,
 				loginIdentityId,
 				installationId,
@@ -242,8 +426,10 @@ func (ds dataSourceImpl) CreateNewSessionAndAttachUserToInstallation(
 				expiresAt,
 				queries,
 			)
+			return err
 		},
 	)
+	return sessionId, err
 }
 
 func (ds dataSourceImpl) createNewSessionAndAttachUserToInstallation(
@@ -254,8 +440,8 @@ func (ds dataSourceImpl) createNewSessionAndAttachUserToInstallation(
 	ipAddress netip.Addr,
 	expiresAt time.Time,
 	queries *database_queries.Queries,
-) (err error) {
-	sessionId, err := queries.SessionCreateNewSession(
+) (sessionId int32, err error) {
+	sessionId, err = queries.SessionCreateNewSession(
 		ctx,
 		database_queries.SessionCreateNewSessionParams{
 			Token:            token,
@@ -266,7 +452,7 @@ func (ds dataSourceImpl) createNewSessionAndAttachUserToInstallation(
 		},
 	)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	affectedRows, err := queries.InstallationAttachSessionToInstallationById(
@@ -277,21 +463,21 @@ func (ds dataSourceImpl) createNewSessionAndAttachUserToInstallation(
 		},
 	)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	if affectedRows == 0 {
 		err = apperr.ErrInstallationTokenInUse
-		return err
+		return 0, err
 	}
 
 	err = queries.LoginIdentityUpdateLastUsedAtToNow(ctx, loginIdentityId)
 	if err != nil {
 		zerolog.Ctx(ctx).Err(err).Int32("login_identity_id", loginIdentityId).Msg("can not update the last used at for login identitiy")
-		return err
+		return 0, err
 	}
 
-	return nil
+	return sessionId, nil
 }
 
 func (ds dataSourceImpl) GetInstallationUsingTokenAndWhereAttachTo(ctx context.Context, installationToken string, attachedToSessionId int32) (database_queries.Installation, error) {
@@ -348,13 +534,19 @@ func (ds dataSourceImpl) IsEmailUsedInOidcLoginIdentity(ctx context.Context, ema
 }
 
 func (ds dataSourceImpl) ChangePasswordLoginIdentityForUser(ctx context.Context, userId int32, HashedPass, PassSalt string) error {
-	return ds.db.Queries.LoginIdentityChangePasswordLoginIdentityByUserId(
+	err := ds.db.Queries.LoginIdentityChangePasswordLoginIdentityByUserId(
 		ctx, database_queries.LoginIdentityChangePasswordLoginIdentityByUserIdParams{
 			UserID:     userId,
 			HashedPass: HashedPass,
 			PassSalt:   PassSalt,
 		},
 	)
+	if err != nil {
+		return err
+	}
+
+	ds.invalidateUserSessionsCache(ctx, userId)
+	return nil
 }
 
 func (ds dataSourceImpl) GetAllPasswordLoginIdentitiesForUser(ctx context.Context, userId int32) ([]database_queries.LoginIdentityGetAllPasswordLoginIdentitiesByUserIdRow, error) {
@@ -454,6 +646,12 @@ func (ds dataSourceImpl) DeleteForgetPasswordDataFromTempCache(ctx context.Conte
 }
 
 func (ds dataSourceImpl) ExpTokenAndUnlinkFromInstallation(ctx context.Context, installationId, tokenId int) (err error) {
+	defer func() {
+		if err == nil {
+			ds.invalidateSessionCache(ctx, int32(tokenId))
+		}
+	}()
+
 	return ds.usingTransaction(
 		ctx,
 		func(queries *database_queries.Queries) error {
@@ -479,7 +677,7 @@ func (ds dataSourceImpl) ExpTokenAndUnlinkFromInstallation(ctx context.Context,
 }
 
 func (ds dataSourceImpl) ExpAllTokensAndUnlinkThemFromInstallation(ctx context.Context, userId int) error {
-	return ds.usingTransaction(
+	err := ds.usingTransaction(
 		ctx,
 		func(queries *database_queries.Queries) error {
 			err := queries.InstallationDetachSessionFromInstallationByUserId(ctx, int32(userId))
@@ -495,15 +693,72 @@ func (ds dataSourceImpl) ExpAllTokensAndUnlinkThemFromInstallation(ctx context.C
 			return nil
 		},
 	)
+	if err != nil {
+		return err
+	}
+
+	ds.invalidateUserSessionsCache(ctx, int32(userId))
+	return nil
+}
+
+func (ds dataSourceImpl) RotateSessionToken(ctx context.Context, sessionId int32, oldToken, newToken string, expiresAt time.Time) (bool, error) {
+	var rotated bool
+
+	err := ds.usingTransaction(
+		ctx,
+		func(queries *database_queries.Queries) error {
+			affectedRows, err := queries.SessionRotateToken(
+				ctx,
+				database_queries.SessionRotateTokenParams{
+					NewToken:  newToken,
+					ExpiresAt: pgtype.Timestamptz{Time: expiresAt, Valid: true},
+					ID:        sessionId,
+					OldToken:  oldToken,
+				},
+			)
+			if err != nil {
+				return err
+			}
+
+			if affectedRows == 0 {
+				return nil
+			}
+
+			// keep the old token so any later use of it is detected as a reuse
+			err = queries.SessionAddUsedRefreshToken(
+				ctx,
+				database_queries.SessionAddUsedRefreshTokenParams{
+					TokenHash: oldToken,
+					SessionID: sessionId,
+				},
+			)
+			if err != nil {
+				return err
+			}
+
+			rotated = true
+			return nil
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	if rotated {
+		ds.invalidateSessionCache(ctx, sessionId)
+	}
+
+	return rotated, nil
 }
 
 func (ds dataSourceImpl) LoginOrCreateUserWithOidc(
 	ctx context.Context,
 	oidcParamData LoginOrCreateUserWithOidcData,
-	tokenGenerator func(userId int32) (string, time.Time, error),
-) (database_queries.User, error) {
+	tokenGenerator func(userId, loginIdentityId int32) (string, time.Time, error),
+) (database_queries.User, int32, error) {
 
 	var user database_queries.User
+	var sessionId int32
 
 	fn := func(queries *database_queries.Queries) error {
 		var loginIdentityId int32 = -1
@@ -537,11 +792,14 @@ func (ds dataSourceImpl) LoginOrCreateUserWithOidc(
 		}
 
 		{
-			token, expiresAt, err := tokenGenerator(userId)
+			token, expiresAt, err := tokenGenerator(userId, loginIdentityId)
 			if err != nil {
+				if errors.Is(err, errSkipSessionCreation) {
+					return nil
+				}
 				return err
 			}
-			err = ds.createNewSessionAndAttachUserToInstallation(
+			sessionId, err = ds.createNewSessionAndAttachUserToInstallation(
 				ctx,
 				loginIdentityId,
 				oidcParamData.InstallationId,
@@ -560,10 +818,10 @@ func (ds dataSourceImpl) LoginOrCreateUserWithOidc(
 
 	err := ds.usingTransaction(ctx, fn)
 	if err != nil {
-		return database_queries.User{}, err
+		return database_queries.User{}, 0, err
 	}
 
-	return user, nil
+	return user, sessionId, nil
 }
 
 func oidcCreateAccountAndLogin(ctx context.Context, queries *database_queries.Queries, oidcParamData LoginOrCreateUserWithOidcData) (loginIdentityId int32, user database_queries.User, err error) {
@@ -577,7 +835,7 @@ func oidcCreateAccountAndLogin(ctx context.Context, queries *database_queries.Qu
 			UserLastName:               oidcParamData.UserLastName,
 			UserRoleName:               oidcParamData.UserRoleName,
 			OauthProviderName:          oidcParamData.oauthProvider.String(),
-			OauthProviderIsOidcCapable: true,
+			OauthProviderIsOidcCapable: oidcParamData.oauthProvider.IsOidcCapable(),
 			OauthScopes:                oidcParamData.OauthScopes.Array(),
 			OauthAccessToken:           oidcParamData.OauthAccessToken,
 			OauthRefreshToken:          oidcParamData.OauthRefreshToken,
@@ -752,6 +1010,464 @@ func oidcLoginOnly(
 	return user, nil
 }
 
+func (ds dataSourceImpl) LoginOrCreateGuestUser(
+	ctx context.Context,
+	guestParamData LoginOrCreateGuestUserData,
+	tokenGenerator func(userId, loginIdentityId int32) (string, time.Time, error),
+) (database_queries.User, int32, bool, error) {
+
+	var user database_queries.User
+	var sessionId int32
+	var isNewUser bool
+
+	fn := func(queries *database_queries.Queries) error {
+		var loginIdentityId int32 = -1
+
+		guestUser, err := queries.LoginIdentityGetGuestLoginIdentityWithUser(ctx, guestParamData.DeviceId)
+		if err != nil {
+			if !dbutils.IsErrPgxNoRows(err) {
+				return err
+			}
+
+			result, err := queries.LoginIdentityCreateNewUserAndGuestLoginIdentity(
+				ctx,
+				database_queries.LoginIdentityCreateNewUserAndGuestLoginIdentityParams{
+					UserUsername:  guestParamData.UserUsername,
+					UserFirstName: guestParamData.UserFirstName,
+					GuestDeviceID: guestParamData.DeviceId,
+				},
+			)
+			if err != nil {
+				return err
+			}
+
+			isNewUser = true
+			loginIdentityId = result.NewLoginIdentityID
+			user = database_queries.User{
+				ID:           result.UserID,
+				Username:     result.Username,
+				FirstName:    result.FirstName,
+				ProfileImage: result.ProfileImage,
+				MiddleName:   result.MiddleName,
+				LastName:     result.LastName,
+				CreatedAt:    result.CreatedAt,
+				UpdatedAt:    result.UpdatedAt,
+				BlockedAt:    result.BlockedAt,
+				BlockedUntil: result.BlockedUntil,
+				RoleName:     result.RoleName,
+			}
+		} else {
+			loginIdentityId = guestUser.LoginIdentityID
+			user = database_queries.User{
+				ID:           guestUser.UserID,
+				Username:     guestUser.UserUsername,
+				FirstName:    guestUser.UserFirstName,
+				ProfileImage: guestUser.UserProfileImage,
+				MiddleName:   guestUser.UserMiddleName,
+				LastName:     guestUser.UserLastName,
+				CreatedAt:    guestUser.UserCreatedAt,
+				UpdatedAt:    guestUser.UserUpdatedAt,
+				BlockedAt:    guestUser.UserBlockedAt,
+				BlockedUntil: guestUser.UserBlockedUntil,
+				RoleName:     guestUser.UserRoleName,
+			}
+		}
+
+		token, expiresAt, err := tokenGenerator(user.ID, loginIdentityId)
+		if err != nil {
+			if errors.Is(err, errSkipSessionCreation) {
+				return nil
+			}
+			return err
+		}
+
+		sessionId, err = ds.createNewSessionAndAttachUserToInstallation(
+			ctx,
+			loginIdentityId,
+			guestParamData.InstallationId,
+			token,
+			guestParamData.IpAddress,
+			expiresAt,
+			queries,
+		)
+		return err
+	}
+
+	err := ds.usingTransaction(ctx, fn)
+	if err != nil {
+		return database_queries.User{}, 0, false, err
+	}
+
+	return user, sessionId, isNewUser, nil
+}
+
+func (ds dataSourceImpl) GetGuestLoginIdentityForUser(ctx context.Context, userId int32) (database_queries.LoginIdentityGetGuestLoginIdentityByUserIdRow, error) {
+	guestLoginIdentity, err := ds.db.Queries.LoginIdentityGetGuestLoginIdentityByUserId(ctx, userId)
+	if err != nil {
+		if dbutils.IsErrPgxNoRows(err) {
+			return guestLoginIdentity, apperr.ErrNoResult
+		}
+		return guestLoginIdentity, err
+	}
+	return guestLoginIdentity, nil
+}
+
+func (ds dataSourceImpl) UpgradeGuestUserToPasswordUser(ctx context.Context, guestUserId int32, userArgs CreatePasswordUserArgs) (database_queries.User, error) {
+	var user database_queries.User
+
+	fn := func(queries *database_queries.Queries) error {
+		newLoginIdentity, err := queries.LoginIdentityCreateNewPasswordLoginIdentity(
+			ctx,
+			database_queries.LoginIdentityCreateNewPasswordLoginIdentityParams{
+				IdentityUserID:     guestUserId,
+				IdentityType:       userArgs.LoginIdentityType.String(),
+				PasswordEmail:      dbutils.ToPgTypeText(userArgs.Email),
+				PasswordPhone:      dbutils.ToPgTypeText(userArgs.Phone),
+				PasswordHashedPass: userArgs.HashedPass,
+				PasswordPassSalt:   userArgs.PassSalt,
+				PasswordVerifiedAt: pgtype.Timestamptz{Time: userArgs.VerifiedAt, Valid: !userArgs.VerifiedAt.IsZero()},
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		err = queries.UsersUpdateNamesForUser(
+			ctx,
+			database_queries.UsersUpdateNamesForUserParams{
+				ID:        guestUserId,
+				FirstName: userArgs.Fname,
+				LastName:  dbutils.ToPgTypeText(userArgs.Lname),
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		user, err = ds.replaceGuestLoginIdentity(ctx, queries, guestUserId, newLoginIdentity.LoginIdentityID)
+		return err
+	}
+
+	err := ds.usingTransaction(ctx, fn)
+	if err != nil {
+		return database_queries.User{}, err
+	}
+
+	// the sessions are moved to the new login identity
+	ds.invalidateUserSessionsCache(ctx, guestUserId)
+
+	return user, nil
+}
+
+func (ds dataSourceImpl) UpgradeGuestUserToOidcUser(ctx context.Context, guestUserId int32, oidcParamData LoginOrCreateUserWithOidcData) (database_queries.User, error) {
+	var user database_queries.User
+
+	fn := func(queries *database_queries.Queries) error {
+		_, err := queries.LoginIdentityGetOIDCDataBySub(
+			ctx,
+			database_queries.LoginIdentityGetOIDCDataBySubParams{
+				OidcSub:          oidcParamData.OidcSub,
+				OidcProviderName: oidcParamData.oauthProvider.String(),
+			},
+		)
+		if err == nil {
+			return apperr.ErrOidcAccountAlreadyLinked
+		}
+		if !dbutils.IsErrPgxNoRows(err) {
+			return err
+		}
+
+		newLoginIdentityId, err := queries.LoginIdentityCreateOIDCLoginIdentityForUser(
+			ctx,
+			database_queries.LoginIdentityCreateOIDCLoginIdentityForUserParams{
+				UserID:                     guestUserId,
+				OauthProviderName:          oidcParamData.oauthProvider.String(),
+				OauthProviderIsOidcCapable: oidcParamData.oauthProvider.IsOidcCapable(),
+				OauthScopes:                oidcParamData.OauthScopes.Array(),
+				OauthAccessToken:           oidcParamData.OauthAccessToken,
+				OauthRefreshToken:          oidcParamData.OauthRefreshToken,
+				OauthTokenType:             oidcParamData.OauthTokenType,
+				OauthTokenExpiresAt:        oidcParamData.OauthTokenExpiresAt,
+				OauthTokenIssuedAt:         oidcParamData.OauthTokenIssuedAt,
+				OidcSub:                    oidcParamData.OidcSub,
+				OidcEmail:                  oidcParamData.OidcEmail,
+				OidcIss:                    oidcParamData.OidcIss,
+				OidcAud:                    oidcParamData.OidcAud,
+				OidcGivenName:              oidcParamData.OidcGivenName,
+				OidcFamilyName:             oidcParamData.OidcFamilyName,
+				OidcName:                   oidcParamData.OidcName,
+				OidcPicture:                oidcParamData.OidcPicture,
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		// keep the guest name if the provider did not give us one
+		if len(oidcParamData.UserFirstName) != 0 {
+			err = queries.UsersUpdateNamesForUser(
+				ctx,
+				database_queries.UsersUpdateNamesForUserParams{
+					ID:        guestUserId,
+					FirstName: oidcParamData.UserFirstName,
+					LastName:  oidcParamData.UserLastName,
+				},
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		user, err = ds.replaceGuestLoginIdentity(ctx, queries, guestUserId, newLoginIdentityId)
+		return err
+	}
+
+	err := ds.usingTransaction(ctx, fn)
+	if err != nil {
+		return database_queries.User{}, err
+	}
+
+	// the sessions are moved to the new login identity
+	ds.invalidateUserSessionsCache(ctx, guestUserId)
+
+	return user, nil
+}
+
+// replaceGuestLoginIdentity moves the active sessions of the guest login identity
+// to the new login identity and soft-deletes the guest one, so the user keeps
+// their installation, sessions and todos.
+func (ds dataSourceImpl) replaceGuestLoginIdentity(
+	ctx context.Context,
+	queries *database_queries.Queries,
+	guestUserId int32,
+	newLoginIdentityId int32,
+) (database_queries.User, error) {
+	guestLoginIdentity, err := queries.LoginIdentityGetGuestLoginIdentityByUserId(ctx, guestUserId)
+	if err != nil {
+		if dbutils.IsErrPgxNoRows(err) {
+			return database_queries.User{}, apperr.ErrNotGuestUser
+		}
+		return database_queries.User{}, err
+	}
+
+	err = queries.SessionMoveActiveSessionsToLoginIdentity(
+		ctx,
+		database_queries.SessionMoveActiveSessionsToLoginIdentityParams{
+			FromLoginIdentityID: guestLoginIdentity.LoginIdentityID,
+			ToLoginIdentityID:   newLoginIdentityId,
+		},
+	)
+	if err != nil {
+		return database_queries.User{}, err
+	}
+
+	err = queries.LoginIdentitySoftDeleteGuestLoginIdentity(ctx, guestLoginIdentity.LoginIdentityID)
+	if err != nil {
+		return database_queries.User{}, err
+	}
+
+	dbUser, err := queries.UsersGetUserById(ctx, guestUserId)
+	if err != nil {
+		return database_queries.User{}, err
+	}
+
+	user := database_queries.User{
+		ID:           dbUser.ID,
+		Username:     dbUser.Username,
+		ProfileImage: dbUser.ProfileImage,
+		FirstName:    dbUser.FirstName,
+		MiddleName:   dbUser.MiddleName,
+		LastName:     dbUser.LastName,
+		CreatedAt:    dbUser.CreatedAt,
+		UpdatedAt:    dbUser.UpdatedAt,
+		BlockedAt:    dbUser.BlockedAt,
+		BlockedUntil: dbUser.BlockedUntil,
+		RoleName:     dbUser.RoleName,
+	}
+	return user, nil
+}
+
+func genTempMfaChallengeId(id uuid.UUID) string {
+	return fmt.Sprint("mfa:challenge:", id.String())
+}
+
+func (ds dataSourceImpl) StoreMfaChallengeInTempCache(ctx context.Context, challenge MfaChallengeTmpDataStore) error {
+	key := genTempMfaChallengeId(challenge.Id)
+
+	pip := ds.redis.TxPipeline()
+	pip.Del(ctx, key)
+	pip.HSet(ctx, key, challenge.ToMap())
+	pip.Expire(ctx, key, expirationForMfaChallengeTempData)
+	resultArray, err := pip.Exec(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	for _, cmdResult := range resultArray {
+		if cmdResult.Err() != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (ds dataSourceImpl) GetMfaChallengeFromTempCache(ctx context.Context, challengeId uuid.UUID) (*MfaChallengeTmpDataStore, error) {
+	key := genTempMfaChallengeId(challengeId)
+
+	result, err := ds.redis.HGetAll(ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result) == 0 {
+		return nil, apperr.ErrNoResult
+	}
+
+	challenge := new(MfaChallengeTmpDataStore).FromMap(result)
+	return challenge, err
+}
+
+func (ds dataSourceImpl) IncrMfaChallengeAttempts(ctx context.Context, challengeId uuid.UUID) (int64, error) {
+	return ds.redis.HIncrBy(ctx, genTempMfaChallengeId(challengeId), "attempts", 1).Result()
+}
+
+func (ds dataSourceImpl) DeleteMfaChallengeFromTempCache(ctx context.Context, challengeId uuid.UUID) error {
+	return ds.redis.Del(ctx, genTempMfaChallengeId(challengeId)).Err()
+}
+
+func (ds dataSourceImpl) GetUserMfaStatus(ctx context.Context, userId int32) (database_queries.MfaGetUserMfaStatusRow, error) {
+	status, err := ds.db.Queries.MfaGetUserMfaStatus(ctx, userId)
+	if err != nil {
+		if dbutils.IsErrPgxNoRows(err) {
+			return status, apperr.ErrNoResult
+		}
+		return status, err
+	}
+	return status, nil
+}
+
+func (ds dataSourceImpl) GetTotpForUser(ctx context.Context, userId int32) (database_queries.UserTotp, error) {
+	userTotp, err := ds.db.Queries.MfaGetTotpForUser(ctx, userId)
+	if err != nil {
+		if dbutils.IsErrPgxNoRows(err) {
+			return userTotp, apperr.ErrNoResult
+		}
+		return userTotp, err
+	}
+	return userTotp, nil
+}
+
+func (ds dataSourceImpl) UpsertUnconfirmedTotpForUser(ctx context.Context, userId int32, secret string) (bool, error) {
+	affectedRows, err := ds.db.Queries.MfaUpsertUnconfirmedTotpForUser(
+		ctx,
+		database_queries.MfaUpsertUnconfirmedTotpForUserParams{
+			UserID: userId,
+			Secret: secret,
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+	return affectedRows != 0, nil
+}
+
+// UseTotpStepForUser returns false if a code of the same or a later time step was already accepted
+func (ds dataSourceImpl) UseTotpStepForUser(ctx context.Context, userId int32, step uint64) (bool, error) {
+	affectedRows, err := ds.db.Queries.MfaUseTotpStepForUser(
+		ctx,
+		database_queries.MfaUseTotpStepForUserParams{
+			Step:   int64(step),
+			UserID: userId,
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+	return affectedRows != 0, nil
+}
+
+func (ds dataSourceImpl) ConfirmTotpAndReplaceRecoveryCodesForUser(ctx context.Context, userId int32, recoveryCodeHashes []string) error {
+	return ds.usingTransaction(
+		ctx,
+		func(queries *database_queries.Queries) error {
+			err := queries.MfaConfirmTotpForUser(ctx, userId)
+			if err != nil {
+				return err
+			}
+			return replaceRecoveryCodesForUser(ctx, queries, userId, recoveryCodeHashes)
+		},
+	)
+}
+
+func (ds dataSourceImpl) ReplaceRecoveryCodesForUser(ctx context.Context, userId int32, recoveryCodeHashes []string) error {
+	return ds.usingTransaction(
+		ctx,
+		func(queries *database_queries.Queries) error {
+			return replaceRecoveryCodesForUser(ctx, queries, userId, recoveryCodeHashes)
+		},
+	)
+}
+
+func replaceRecoveryCodesForUser(ctx context.Context, queries *database_queries.Queries, userId int32, recoveryCodeHashes []string) error {
+	err := queries.MfaDeleteRecoveryCodesForUser(ctx, userId)
+	if err != nil {
+		return err
+	}
+	return queries.MfaCreateRecoveryCodesForUser(
+		ctx,
+		database_queries.MfaCreateRecoveryCodesForUserParams{
+			UserID:     userId,
+			CodeHashes: recoveryCodeHashes,
+		},
+	)
+}
+
+func (ds dataSourceImpl) UseRecoveryCodeForUser(ctx context.Context, userId int32, recoveryCodeHash string) (bool, error) {
+	affectedRows, err := ds.db.Queries.MfaUseRecoveryCodeForUser(
+		ctx,
+		database_queries.MfaUseRecoveryCodeForUserParams{
+			UserID:   userId,
+			CodeHash: recoveryCodeHash,
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+	return affectedRows != 0, nil
+}
+
+func (ds dataSourceImpl) DeleteTotpAndRecoveryCodesForUser(ctx context.Context, userId int32) error {
+	return ds.usingTransaction(
+		ctx,
+		func(queries *database_queries.Queries) error {
+			err := queries.MfaDeleteTotpForUser(ctx, userId)
+			if err != nil {
+				return err
+			}
+			return queries.MfaDeleteRecoveryCodesForUser(ctx, userId)
+		},
+	)
+}
+
+func (ds dataSourceImpl) SetMfaEnforcedForUser(ctx context.Context, userId int32, enforced bool) error {
+	affectedRows, err := ds.db.Queries.UsersSetMfaEnforcedForUser(
+		ctx,
+		database_queries.UsersSetMfaEnforcedForUserParams{
+			ID:          userId,
+			MfaEnforced: enforced,
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if affectedRows == 0 {
+		return apperr.ErrNoResult
+	}
+	return nil
+}
+
 func (ds dataSourceImpl) usingTransaction(ctx context.Context, fn func(queries *database_queries.Queries) error) error {
 	tx, err := ds.db.ConnPool.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
@@ -784,3 +1500,187 @@ func (ds dataSourceImpl) usingTransaction(ctx context.Context, fn func(queries *
 	err = fn(queries)
 	return err
 }
+
+func (ds dataSourceImpl) GetActiveSessionsForUser(ctx context.Context, userId int32) ([]database_queries.SessionGetActiveSessionsForUserRow, error) {
+	return ds.db.Queries.SessionGetActiveSessionsForUser(ctx, userId)
+}
+
+func (ds dataSourceImpl) GetActiveSessionById(ctx context.Context, sessionId int32) (database_queries.ActiveSession, error) {
+	session, err := ds.db.Queries.SessionGetActiveSessionById(ctx, sessionId)
+	if dbutils.IsErrPgxNoRows(err) {
+		err = apperr.ErrNoResult
+	}
+	return session, err
+}
+
+func (ds dataSourceImpl) GetActiveSessionByToken(ctx context.Context, sessionToken string) (database_queries.ActiveSession, error) {
+	session, err := ds.db.Queries.SessionGetActiveSessionByToken(ctx, sessionToken)
+	if dbutils.IsErrPgxNoRows(err) {
+		err = apperr.ErrNoResult
+	}
+	return session, err
+}
+
+func (ds dataSourceImpl) GetSessionIdByUsedRefreshToken(ctx context.Context, refreshTokenHash string) (int32, error) {
+	sessionId, err := ds.db.Queries.SessionGetSessionIdByUsedRefreshToken(ctx, refreshTokenHash)
+	if dbutils.IsErrPgxNoRows(err) {
+		err = apperr.ErrNoResult
+	}
+	return sessionId, err
+}
+
+func (ds dataSourceImpl) GetActiveSessionForUser(ctx context.Context, sessionId, userId int32) (database_queries.ActiveSession, error) {
+	session, err := ds.db.Queries.SessionGetActiveSessionForUser(
+		ctx,
+		database_queries.SessionGetActiveSessionForUserParams{
+			SessionID: sessionId,
+			UserID:    userId,
+		},
+	)
+	if err != nil {
+		if dbutils.IsErrPgxNoRows(err) {
+			return session, apperr.ErrNoResult
+		}
+		return session, err
+	}
+	return session, nil
+}
+
+// TouchSessionLastActiveAt updates the session last active at at most once in expirationForSessionTouch,
+// tracked in redis so the requests in between do not write to the database
+func (ds dataSourceImpl) TouchSessionLastActiveAt(ctx context.Context, sessionId int32) error {
+	isFirstTouch, err := ds.redis.SetNX(ctx, genSessionTouchKey(sessionId), 1, expirationForSessionTouch).Result()
+	if err != nil {
+		zerolog.Ctx(ctx).Err(err).Msg("error while checking the last touch of the session, falling back to the database")
+	} else if !isFirstTouch {
+		return nil
+	}
+
+	return ds.db.Queries.SessionTouchLastActiveAt(ctx, sessionId)
+}
+
+func genSessionTouchKey(sessionId int32) string {
+	return fmt.Sprint("session:touch:", sessionId)
+}
+
+func (ds dataSourceImpl) SearchUsers(ctx context.Context, params SearchUsersParams, offset, limit int32) ([]database_queries.UsersSearchUsersRow, error) {
+	return ds.db.Queries.UsersSearchUsers(
+		ctx,
+		database_queries.UsersSearchUsersParams{
+			IncludeDeleted: params.IncludeDeleted,
+			RoleName:       dbutils.ToPgTypeText(params.RoleName),
+			Query:          dbutils.ToPgTypeText(dbutils.EscapeLikePattern(params.Query)),
+			PageLimit:      limit,
+			PageOffset:     offset,
+		},
+	)
+}
+
+func (ds dataSourceImpl) GetUserForAdmin(ctx context.Context, userId int32) (database_queries.UsersGetUserForAdminRow, error) {
+	user, err := ds.db.Queries.UsersGetUserForAdmin(ctx, userId)
+	if err != nil {
+		if dbutils.IsErrPgxNoRows(err) {
+			return user, apperr.ErrNoResult
+		}
+		return user, err
+	}
+	return user, nil
+}
+
+func (ds dataSourceImpl) BlockUser(ctx context.Context, userId, blockedBy int32, reason string, blockedUntil pgtype.Timestamptz) error {
+	err := ds.usingTransaction(
+		ctx,
+		func(queries *database_queries.Queries) error {
+			affectedRows, err := queries.UsersBlockUser(
+				ctx,
+				database_queries.UsersBlockUserParams{
+					BlockedUntil: blockedUntil,
+					ID:           userId,
+				},
+			)
+			if err != nil {
+				return err
+			}
+			if affectedRows == 0 {
+				return apperr.ErrNoResult
+			}
+
+			// a new block replaces the current one
+			err = queries.UsersEndUserBlocks(ctx, userId)
+			if err != nil {
+				return err
+			}
+
+			return queries.UsersCreateUserBlock(
+				ctx,
+				database_queries.UsersCreateUserBlockParams{
+					UserID:       userId,
+					BlockedBy:    pgtype.Int4{Int32: blockedBy, Valid: true},
+					Reason:       reason,
+					BlockedUntil: blockedUntil,
+				},
+			)
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	ds.invalidateUserSessionsCache(ctx, userId)
+	return nil
+}
+
+func (ds dataSourceImpl) UnblockUser(ctx context.Context, userId int32) error {
+	err := ds.usingTransaction(
+		ctx,
+		func(queries *database_queries.Queries) error {
+			affectedRows, err := queries.UsersUnblockUser(ctx, userId)
+			if err != nil {
+				return err
+			}
+			if affectedRows == 0 {
+				return apperr.ErrNoResult
+			}
+
+			return queries.UsersEndUserBlocks(ctx, userId)
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	ds.invalidateUserSessionsCache(ctx, userId)
+	return nil
+}
+
+func (ds dataSourceImpl) SetRoleForUser(ctx context.Context, userId int32, roleName pgtype.Text) (bool, error) {
+	affectedRows, err := ds.db.Queries.UsersSetRoleForUser(
+		ctx,
+		database_queries.UsersSetRoleForUserParams{
+			RoleName: roleName,
+			ID:       userId,
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+	if affectedRows == 0 {
+		return false, nil
+	}
+
+	ds.invalidateUserSessionsCache(ctx, userId)
+	return true, nil
+}
+
+func (ds dataSourceImpl) SoftDeleteUser(ctx context.Context, userId int32) error {
+	affectedRows, err := ds.db.Queries.UsersSoftDeleteUser(ctx, userId)
+	if err != nil {
+		return err
+	}
+	if affectedRows == 0 {
+		return apperr.ErrNoResult
+	}
+
+	ds.invalidateUserSessionsCache(ctx, userId)
+	return nil
+}
@@ -0,0 +1,159 @@
+//go:build integration
+
+// The tests run against the database and the redis of the .env, with at least one active session
+// (log in once). The package needs the google client secret file, so they are behind a build tag:
+//
+//	go test -tags integration -run SessionCache -bench UserAndSessionData ./internal/feat/auth
+
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Nidal-Bakir/go-todo-backend/internal/database"
+	redisdb "github.com/Nidal-Bakir/go-todo-backend/internal/redis_db"
+	_ "github.com/Nidal-Bakir/go-todo-backend/testing_init"
+)
+
+func newTestDataSource(tb testing.TB) (dataSourceImpl, int32) {
+	ctx := context.Background()
+	ds := dataSourceImpl{db: database.NewConnection(ctx), redis: redisdb.NewRedisClient(ctx)}
+
+	var sessionId int32
+	err := ds.db.ConnPool.QueryRow(ctx, "SELECT id FROM active_session ORDER BY id DESC LIMIT 1").Scan(&sessionId)
+	if err != nil {
+		tb.Skip("no active session in the database: ", err)
+	}
+
+	tb.Cleanup(func() {
+		ds.DeleteSessionFromCache(ctx, sessionId)
+	})
+	return ds, sessionId
+}
+
+func isSessionCached(tb testing.TB, ds dataSourceImpl, sessionId int32) bool {
+	n, err := ds.redis.Exists(context.Background(), genSessionCacheKey(sessionId)).Result()
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return n == 1
+}
+
+func TestSessionCacheReadThrough(t *testing.T) {
+	ctx := context.Background()
+	ds, sessionId := newTestDataSource(t)
+
+	if err := ds.DeleteSessionFromCache(ctx, sessionId); err != nil {
+		t.Fatal(err)
+	}
+
+	fromDB, err := ds.GetUserAndSessionDataBySessionId(ctx, sessionId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isSessionCached(t, ds, sessionId) {
+		t.Fatal("the session should be cached after reading it")
+	}
+
+	fromCache, err := ds.GetUserAndSessionDataBySessionId(ctx, sessionId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fromCache.SessionID != fromDB.SessionID || fromCache.UserID != fromDB.UserID || fromCache.UserRoleName != fromDB.UserRoleName {
+		t.Errorf("cached %+v, want %+v", fromCache, fromDB)
+	}
+
+	if err := ds.DeleteAllSessionsOfUserFromCache(ctx, fromDB.UserID); err != nil {
+		t.Fatal(err)
+	}
+	if isSessionCached(t, ds, sessionId) {
+		t.Error("the session should not be cached after invalidating the sessions of the user")
+	}
+}
+
+func TestSessionCacheFillAfterInvalidation(t *testing.T) {
+	ctx := context.Background()
+	ds, sessionId := newTestDataSource(t)
+
+	// a request reads the version and the session from the database...
+	version, err := ds.getSessionCacheVersion(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	row, err := ds.db.Queries.UsersGetUserAndSessionDataBySessionId(ctx, sessionId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// ...while another request changes the session and invalidates it
+	if err := ds.DeleteSessionFromCache(ctx, sessionId); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ds.storeSessionInCache(ctx, row, version); err != nil {
+		t.Fatal(err)
+	}
+	if isSessionCached(t, ds, sessionId) {
+		t.Fatal("the session read before the invalidation should not be cached")
+	}
+}
+
+func BenchmarkGetUserAndSessionDataBySessionId(b *testing.B) {
+	ctx := context.Background()
+	ds, sessionId := newTestDataSource(b)
+
+	b.Run("uncached", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := ds.db.Queries.UsersGetUserAndSessionDataBySessionId(ctx, sessionId); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("cache miss", func(b *testing.B) {
+		for b.Loop() {
+			b.StopTimer()
+			if err := ds.DeleteSessionFromCache(ctx, sessionId); err != nil {
+				b.Fatal(err)
+			}
+			b.StartTimer()
+
+			if _, err := ds.GetUserAndSessionDataBySessionId(ctx, sessionId); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("cached", func(b *testing.B) {
+		if _, err := ds.GetUserAndSessionDataBySessionId(ctx, sessionId); err != nil {
+			b.Fatal(err)
+		}
+		for b.Loop() {
+			if _, err := ds.GetUserAndSessionDataBySessionId(ctx, sessionId); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkTouchSessionLastActiveAt(b *testing.B) {
+	ctx := context.Background()
+	ds, sessionId := newTestDataSource(b)
+
+	b.Run("database", func(b *testing.B) {
+		for b.Loop() {
+			if err := ds.db.Queries.SessionTouchLastActiveAt(ctx, sessionId); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("throttled", func(b *testing.B) {
+		for b.Loop() {
+			if err := ds.TouchSessionLastActiveAt(ctx, sessionId); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -58,6 +58,9 @@ func (l *LoginIdentityType) FromString(str string) (*LoginIdentityType, error) {
 	case LoginIdentityTypePhone.String() == str:
 		*l = LoginIdentityTypePhone
 
+	case LoginIdentityTypeGuest.String() == str:
+		*l = LoginIdentityTypeGuest
+
 	default:
 		l = nil
 		return l, apperr.ErrUnsupportedLoginIdentityType
@@ -208,10 +211,11 @@ type TempPasswordUser struct {
 	Phone             *phonenumber.PhoneNumber
 	SentOTP           string
 	Password          string
+	GuestUserId       int32 // set when a guest is upgrading to a password account, otherwise 0
 }
 
 func (tu TempPasswordUser) ToMap() map[string]string {
-	m := make(map[string]string, 8)
+	m := make(map[string]string, 10)
 	m["id"] = tu.Id.String()
 	m["username"] = tu.Username
 	m["login_identity_type"] = tu.LoginIdentityType.String()
@@ -221,6 +225,7 @@ func (tu TempPasswordUser) ToMap() map[string]string {
 	m["phone_number"] = tu.Phone.ToE164()
 	m["sent_otp"] = tu.SentOTP
 	m["password"] = tu.Password
+	m["guest_user_id"] = strconv.Itoa(int(tu.GuestUserId))
 	return m
 }
 
@@ -239,6 +244,9 @@ func (tu *TempPasswordUser) FromMap(m map[string]string) *TempPasswordUser {
 	tu.Lname = m["l_name"]
 	tu.SentOTP = m["sent_otp"]
 	tu.Password = m["password"]
+	if guestUserId, err := strconv.Atoi(m["guest_user_id"]); err == nil {
+		tu.GuestUserId = int32(guestUserId)
+	}
 	return tu
 }
 
@@ -319,7 +327,6 @@ type UserAndSession struct {
 	UserRoleName     pgtype.Text        `json:"user_role_name"`
 
 	SessionID               int32              `json:"session_id"`
-	SessionToken            string             `json:"session_token"`
 	SessionCreatedAt        pgtype.Timestamptz `json:"session_created_at"`
 	SessionUpdatedAt        pgtype.Timestamptz `json:"session_updated_at"`
 	SessionExpiresAt        pgtype.Timestamptz `json:"session_expires_at"`
@@ -328,7 +335,7 @@ type UserAndSession struct {
 	SessionUsedInstallation int32              `json:"session_used_installation"`
 }
 
-func NewUserAndSessionFromDatabaseUserAndSessionRow(u database_queries.UsersGetUserAndSessionDataBySessionTokenRow) UserAndSession {
+func NewUserAndSessionFromDatabaseUserAndSessionRow(u database_queries.UsersGetUserAndSessionDataBySessionIdRow) UserAndSession {
 	return UserAndSession{
 		UserID:           u.UserID,
 		UserUsername:     u.UserUsername,
@@ -341,12 +348,81 @@ func NewUserAndSessionFromDatabaseUserAndSessionRow(u database_queries.UsersGetU
 		UserRoleName:     u.UserRoleName,
 
 		SessionID:               u.SessionID,
-		SessionToken:            u.SessionToken,
+		SessionExpiresAt:        u.SessionExpiresAt,
 		SessionOriginatedFrom:   u.SessionOriginatedFrom,
 		SessionUsedInstallation: u.SessionUsedInstallation,
 	}
 }
 
+// IsUserBlocked reports if the user is blocked right now, a block without
+// blocked_until lasts until an admin unblocks the user.
+func IsUserBlocked(blockedAt, blockedUntil pgtype.Timestamptz) bool {
+	return blockedAt.Valid && (!blockedUntil.Valid || blockedUntil.Time.After(time.Now()))
+}
+
+// userAndSessionRowToMap is used to cache the user and session data checked by
+// the auth middleware on every request. The null values are not stored.
+func userAndSessionRowToMap(u database_queries.UsersGetUserAndSessionDataBySessionIdRow) map[string]string {
+	m := make(map[string]string, 13)
+	m["session_id"] = strconv.Itoa(int(u.SessionID))
+	m["session_originated_from"] = strconv.Itoa(int(u.SessionOriginatedFrom))
+	m["session_used_installation"] = strconv.Itoa(int(u.SessionUsedInstallation))
+	putTimestamptzInMap(m, "session_expires_at", u.SessionExpiresAt)
+	m["user_id"] = strconv.Itoa(int(u.UserID))
+	m["user_username"] = u.UserUsername
+	putTextInMap(m, "user_profile_image", u.UserProfileImage)
+	m["user_first_name"] = u.UserFirstName
+	putTextInMap(m, "user_middle_name", u.UserMiddleName)
+	putTextInMap(m, "user_last_name", u.UserLastName)
+	putTimestamptzInMap(m, "user_blocked_at", u.UserBlockedAt)
+	putTimestamptzInMap(m, "user_blocked_until", u.UserBlockedUntil)
+	putTextInMap(m, "user_role_name", u.UserRoleName)
+	return m
+}
+
+func userAndSessionRowFromMap(m map[string]string) database_queries.UsersGetUserAndSessionDataBySessionIdRow {
+	return database_queries.UsersGetUserAndSessionDataBySessionIdRow{
+		SessionID:               int32(utils.Must(strconv.Atoi(m["session_id"]))),
+		SessionOriginatedFrom:   int32(utils.Must(strconv.Atoi(m["session_originated_from"]))),
+		SessionUsedInstallation: int32(utils.Must(strconv.Atoi(m["session_used_installation"]))),
+		SessionExpiresAt:        timestamptzFromMap(m, "session_expires_at"),
+		UserID:                  int32(utils.Must(strconv.Atoi(m["user_id"]))),
+		UserUsername:            m["user_username"],
+		UserProfileImage:        textFromMap(m, "user_profile_image"),
+		UserFirstName:           m["user_first_name"],
+		UserMiddleName:          textFromMap(m, "user_middle_name"),
+		UserLastName:            textFromMap(m, "user_last_name"),
+		UserBlockedAt:           timestamptzFromMap(m, "user_blocked_at"),
+		UserBlockedUntil:        timestamptzFromMap(m, "user_blocked_until"),
+		UserRoleName:            textFromMap(m, "user_role_name"),
+	}
+}
+
+func putTextInMap(m map[string]string, key string, t pgtype.Text) {
+	if t.Valid {
+		m[key] = t.String
+	}
+}
+
+func textFromMap(m map[string]string, key string) pgtype.Text {
+	v, ok := m[key]
+	return pgtype.Text{String: v, Valid: ok}
+}
+
+func putTimestamptzInMap(m map[string]string, key string, t pgtype.Timestamptz) {
+	if t.Valid {
+		m[key] = t.Time.Format(time.RFC3339Nano)
+	}
+}
+
+func timestamptzFromMap(m map[string]string, key string) pgtype.Timestamptz {
+	v, ok := m[key]
+	if !ok {
+		return pgtype.Timestamptz{}
+	}
+	return pgtype.Timestamptz{Time: utils.Must(time.Parse(time.RFC3339Nano, v)), Valid: true}
+}
+
 type ForgetPasswordTmpDataStore struct {
 	Id uuid.UUID // used as a key
 
@@ -369,6 +445,63 @@ func (f *ForgetPasswordTmpDataStore) FromMap(m map[string]string) *ForgetPasswor
 	return f
 }
 
+// SessionTokens are issued on login and on every refresh. The access token is
+// a short-lived JWT used to authenticate the requests, the refresh token is an
+// opaque token used once to get a new pair, only its hash is stored in the session.
+type SessionTokens struct {
+	AccessToken           string
+	AccessTokenExpiresAt  time.Time
+	RefreshToken          string
+	RefreshTokenExpiresAt time.Time
+}
+
+// MfaChallenge is returned from the login flows instead of the session token
+// when the user has MFA on, the client should exchange it with the session
+// token by sending the TOTP or a recovery code.
+type MfaChallenge struct {
+	Token              uuid.UUID `json:"challenge_token"`
+	EnrollmentRequired bool      `json:"enrollment_required"` // MFA is enforced but the user did not set up the TOTP yet
+}
+
+type MfaChallengeTmpDataStore struct {
+	Id uuid.UUID // used as a key
+
+	UserId             int32
+	LoginIdentityId    int32
+	InstallationId     int32
+	IpAddress          netip.Addr
+	EnrollmentRequired bool
+	Attempts           int
+}
+
+func (m MfaChallengeTmpDataStore) ToMap() map[string]string {
+	res := make(map[string]string, 7)
+	res["id"] = m.Id.String()
+	res["user_id"] = strconv.Itoa(int(m.UserId))
+	res["login_identity_id"] = strconv.Itoa(int(m.LoginIdentityId))
+	res["installation_id"] = strconv.Itoa(int(m.InstallationId))
+	res["ip_address"] = m.IpAddress.String()
+	res["enrollment_required"] = strconv.FormatBool(m.EnrollmentRequired)
+	res["attempts"] = strconv.Itoa(m.Attempts)
+	return res
+}
+
+func (m *MfaChallengeTmpDataStore) FromMap(res map[string]string) *MfaChallengeTmpDataStore {
+	m.Id = uuid.MustParse(res["id"])
+	m.UserId = int32(utils.Must(strconv.Atoi(res["user_id"])))
+	m.LoginIdentityId = int32(utils.Must(strconv.Atoi(res["login_identity_id"])))
+	m.InstallationId = int32(utils.Must(strconv.Atoi(res["installation_id"])))
+	m.IpAddress = utils.Must(netip.ParseAddr(res["ip_address"]))
+	m.EnrollmentRequired = utils.Must(strconv.ParseBool(res["enrollment_required"]))
+	m.Attempts = utils.Must(strconv.Atoi(res["attempts"]))
+	return m
+}
+
+type TotpEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
 type PasswordLoginAccessKey struct {
 	Phone             *phonenumber.PhoneNumber
 	Email             string
@@ -413,6 +546,96 @@ type PublicLoginOptionForProfile struct {
 	OidcProvider      string
 }
 
+// UserForAdmin is the user data shown in the admin users management, including the deleted users.
+type UserForAdmin struct {
+	ID           int32
+	Username     string
+	ProfileImage pgtype.Text
+	FirstName    string
+	MiddleName   pgtype.Text
+	LastName     pgtype.Text
+	RoleName     pgtype.Text
+	MfaEnforced  bool
+	BlockedAt    pgtype.Timestamptz
+	BlockedUntil pgtype.Timestamptz
+	BlockReason  pgtype.Text // only set when getting a single user
+	DeletedAt    pgtype.Timestamptz
+	CreatedAt    pgtype.Timestamptz
+}
+
+func NewUserForAdminFromSearchRow(u database_queries.UsersSearchUsersRow) UserForAdmin {
+	return UserForAdmin{
+		ID:           u.ID,
+		Username:     u.Username,
+		ProfileImage: u.ProfileImage,
+		FirstName:    u.FirstName,
+		MiddleName:   u.MiddleName,
+		LastName:     u.LastName,
+		RoleName:     u.RoleName,
+		MfaEnforced:  u.MfaEnforced.Bool,
+		BlockedAt:    u.BlockedAt,
+		BlockedUntil: u.BlockedUntil,
+		DeletedAt:    u.DeletedAt,
+		CreatedAt:    u.CreatedAt,
+	}
+}
+
+func NewUserForAdminFromDatabaseRow(u database_queries.UsersGetUserForAdminRow) UserForAdmin {
+	return UserForAdmin{
+		ID:           u.ID,
+		Username:     u.Username,
+		ProfileImage: u.ProfileImage,
+		FirstName:    u.FirstName,
+		MiddleName:   u.MiddleName,
+		LastName:     u.LastName,
+		RoleName:     u.RoleName,
+		MfaEnforced:  u.MfaEnforced.Bool,
+		BlockedAt:    u.BlockedAt,
+		BlockedUntil: u.BlockedUntil,
+		BlockReason:  u.BlockReason,
+		DeletedAt:    u.DeletedAt,
+		CreatedAt:    u.CreatedAt,
+	}
+}
+
+type SearchUsersParams struct {
+	Query          string // matches the username, the name, the email or the phone number
+	RoleName       string
+	IncludeDeleted bool
+}
+
+type UserSession struct {
+	ID                 int32
+	IpAddress          netip.Addr
+	CreatedAt          pgtype.Timestamptz
+	ExpiresAt          pgtype.Timestamptz
+	LastActiveAt       pgtype.Timestamptz
+	LoginIdentityType  LoginIdentityType
+	InstallationID     int32
+	DeviceOs           DeviceOS
+	DeviceOsVersion    pgtype.Text
+	DeviceManufacturer pgtype.Text
+	ClientType         ClientType
+	AppVersion         string
+}
+
+func NewUserSessionFromDatabaseRow(s database_queries.SessionGetActiveSessionsForUserRow) UserSession {
+	return UserSession{
+		ID:                 s.ID,
+		IpAddress:          s.IpAddress,
+		CreatedAt:          s.CreatedAt,
+		ExpiresAt:          s.ExpiresAt,
+		LastActiveAt:       s.LastActiveAt,
+		LoginIdentityType:  *utils.Must(new(LoginIdentityType).FromString(s.LoginIdentityType)),
+		InstallationID:     s.InstallationID,
+		DeviceOs:           *utils.Must(new(DeviceOS).FromString(s.DeviceOs)),
+		DeviceOsVersion:    s.DeviceOsVersion,
+		DeviceManufacturer: s.DeviceManufacturer,
+		ClientType:         *utils.Must(new(ClientType).FromString(s.ClientType)),
+		AppVersion:         s.AppVersion,
+	}
+}
+
 type LoginOrCreateUserWithOidcData struct {
 	oauthProvider      oauth.OauthProvider
 	OauthTokenIssuedAt pgtype.Timestamp
@@ -423,6 +646,14 @@ type LoginOrCreateUserWithOidcData struct {
 	oidc.OidcData
 }
 
+type LoginOrCreateGuestUserData struct {
+	DeviceId       string
+	UserUsername   string
+	UserFirstName  string
+	InstallationId int32
+	IpAddress      netip.Addr
+}
+
 type LoginOrCreateUserWithOidcRepoParam struct {
 	OauthProvider oauth.OauthProvider
 	Code          string
@@ -0,0 +1,226 @@
+package facebook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	oauth "github.com/Nidal-Bakir/go-todo-backend/internal/feat/auth/oauth/utils"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+)
+
+const (
+	graphApiURL = "https://graph.facebook.com/v21.0"
+	Issuer      = "https://www.facebook.com"
+	jwksURL     = "https://www.facebook.com/.well-known/oauth/openid/jwks/"
+)
+
+var (
+	facebookOpenIdConnectConfig = &oauth2.Config{
+		ClientID:     os.Getenv("FACEBOOK_CLIENT_ID"),
+		ClientSecret: os.Getenv("FACEBOOK_CLIENT_SECRET"),
+		RedirectURL:  os.Getenv("FACEBOOK_REDIRECT_URL"),
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   "https://www.facebook.com/v21.0/dialog/oauth",
+			TokenURL:  graphApiURL + "/oauth/access_token",
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+	}
+	facebookJwks  = oauth.NewJwksKeySet(jwksURL)
+	OidcWebScopes = []string{
+		"openid",
+		"public_profile",
+		"email",
+	}
+	OidcOpenIdScope = []string{"openid"}
+)
+
+func ClientID() string {
+	return facebookOpenIdConnectConfig.ClientID
+}
+
+func AuthCodeURL(ctx context.Context, state, verifier string) string {
+	facebookOpenIdConnectConfig.Scopes = OidcWebScopes
+	authUrl := facebookOpenIdConnectConfig.AuthCodeURL(
+		state,
+		oauth2.S256ChallengeOption(verifier),
+	)
+	return authUrl
+}
+
+func AuthCodeExchange(ctx context.Context, code, verifier string) (*oauth2.Token, error) {
+	authCodeOption := make([]oauth2.AuthCodeOption, 0)
+	if len(verifier) != 0 {
+		authCodeOption = append(authCodeOption, oauth2.VerifierOption(verifier))
+	}
+	oauth2Token, err := facebookOpenIdConnectConfig.Exchange(
+		ctx,
+		code,
+		authCodeOption...,
+	)
+	return oauth2Token, err
+}
+
+// IsIdToken reports whether the token is a jwt (Limited Login id token)
+// and not an opaque graph api access token (Classic Login)
+func IsIdToken(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+// parse the idTokne without validating it with facebook
+func ParseIdToken(ctx context.Context, idToken string) (*FacebookOidcIdTokenClaims, error) {
+	claims := new(FacebookOidcIdTokenClaims)
+	_, _, err := jwt.NewParser().ParseUnverified(idToken, claims)
+	if err != nil {
+		return claims, err
+	}
+	return claims, nil
+}
+
+func ValidateIdToken(ctx context.Context, idToken string) (*FacebookOidcIdTokenClaims, error) {
+	claims := new(FacebookOidcIdTokenClaims)
+
+	_, err := jwt.ParseWithClaims(
+		idToken,
+		claims,
+		facebookJwks.Keyfunc(ctx),
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}),
+		jwt.WithIssuer(Issuer),
+		jwt.WithAudience(facebookOpenIdConnectConfig.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	if err != nil {
+		return claims, err
+	}
+
+	return claims, nil
+}
+
+// ValidateAccessToken checks that the access token is valid and issued for our app
+// using the graph api debug_token endpoint, and returns the facebook user id
+func ValidateAccessToken(ctx context.Context, accessToken string) (userId string, err error) {
+	query := url.Values{}
+	query.Set("input_token", accessToken)
+	query.Set("access_token", facebookOpenIdConnectConfig.ClientID+"|"+facebookOpenIdConnectConfig.ClientSecret)
+
+	var res struct {
+		Data struct {
+			AppId   string `json:"app_id"`
+			UserId  string `json:"user_id"`
+			IsValid bool   `json:"is_valid"`
+		} `json:"data"`
+	}
+	err = graphApiGet(ctx, "/debug_token", query, &res)
+	if err != nil {
+		return "", err
+	}
+
+	if !res.Data.IsValid {
+		return "", errors.New("invalid facebook access token")
+	}
+	if res.Data.AppId != facebookOpenIdConnectConfig.ClientID {
+		return "", errors.New("the facebook access token is issued for another app")
+	}
+
+	return res.Data.UserId, nil
+}
+
+func GetUserInfo(ctx context.Context, accessToken string) (*FacebookUserInfo, error) {
+	query := url.Values{}
+	query.Set("fields", "id,name,email,first_name,last_name,picture.type(large)")
+	query.Set("access_token", accessToken)
+
+	var res struct {
+		Id        string `json:"id"`
+		Name      string `json:"name"`
+		Email     string `json:"email"`
+		FirstName string `json:"first_name"`
+		LastName  string `json:"last_name"`
+		Picture   struct {
+			Data struct {
+				Url string `json:"url"`
+			} `json:"data"`
+		} `json:"picture"`
+	}
+	err := graphApiGet(ctx, "/me", query, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &FacebookUserInfo{
+		Id:        res.Id,
+		Name:      res.Name,
+		Email:     res.Email,
+		FirstName: res.FirstName,
+		LastName:  res.LastName,
+		Picture:   res.Picture.Data.Url,
+	}
+	return info, nil
+}
+
+func graphApiGet(ctx context.Context, path string, query url.Values, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, graphApiURL+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		var errRes struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		json.NewDecoder(res.Body).Decode(&errRes)
+		return fmt.Errorf("facebook graph api %s responded with %d: %s", path, res.StatusCode, errRes.Error.Message)
+	}
+
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+type FacebookOidcIdTokenClaims struct {
+	Email      string `json:"email"`
+	Name       string `json:"name"`
+	FamilyName string `json:"family_name"`
+	GivenName  string `json:"given_name"`
+	Picture    string `json:"picture"`
+
+	jwt.RegisteredClaims
+}
+
+// the first audience, facebook issues the id token for a single app
+func (c FacebookOidcIdTokenClaims) Aud() string {
+	if len(c.Audience) == 0 {
+		return ""
+	}
+	return c.Audience[0]
+}
+
+func (c FacebookOidcIdTokenClaims) IssuedAtTime() time.Time {
+	if c.IssuedAt == nil {
+		return time.Now()
+	}
+	return c.IssuedAt.Time
+}
+
+type FacebookUserInfo struct {
+	Id        string
+	Name      string
+	Email     string
+	FirstName string
+	LastName  string
+	Picture   string
+}
@@ -0,0 +1,271 @@
+package genericoidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Nidal-Bakir/go-todo-backend/internal/appenv"
+	oauth "github.com/Nidal-Bakir/go-todo-backend/internal/feat/auth/oauth/utils"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+)
+
+// Any standard oidc provider (e.g. Keycloak, Microsoft Entra) can be added
+// without new code, just list it in the env:
+//
+//	OIDC_PROVIDERS_LIST=[keycloak]
+//	OIDC_KEYCLOAK_ISSUER=https://sso.example.com/realms/main
+//	OIDC_KEYCLOAK_CLIENT_ID=
+//	OIDC_KEYCLOAK_CLIENT_SECRET=
+//	OIDC_KEYCLOAK_REDIRECT_URL=https://example.com/auth/oidc/keycloak/callback
+//	OIDC_KEYCLOAK_SCOPES=[openid, profile, email] // optional
+
+var (
+	DefaultScopes   = []string{"openid", "profile", "email"}
+	OidcOpenIdScope = []string{"openid"}
+
+	registry   = map[string]*Provider{}
+	registryMu sync.RWMutex
+)
+
+type Config struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+func ConfigsFromEnv() ([]Config, error) {
+	names := appenv.DecodeEnvList(os.Getenv("OIDC_PROVIDERS_LIST"))
+
+	configs := make([]Config, 0, len(names))
+	for _, name := range names {
+		if len(name) == 0 {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		config := Config{
+			Name:         name,
+			Issuer:       os.Getenv(prefix + "ISSUER"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  os.Getenv(prefix + "REDIRECT_URL"),
+			Scopes:       appenv.DecodeEnvList(os.Getenv(prefix + "SCOPES")),
+		}
+
+		if len(config.Issuer) == 0 || len(config.ClientID) == 0 {
+			return nil, fmt.Errorf("missing %sISSUER or %sCLIENT_ID env var", prefix, prefix)
+		}
+
+		configs = append(configs, config)
+	}
+
+	return configs, nil
+}
+
+// RegisterProvidersFromEnv discovers the oidc providers listed in the env
+// and registers them as supported oauth providers
+func RegisterProvidersFromEnv(ctx context.Context) error {
+	configs, err := ConfigsFromEnv()
+	if err != nil {
+		return err
+	}
+	for _, config := range configs {
+		if err := RegisterProvider(ctx, config); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func RegisterProvider(ctx context.Context, config Config) error {
+	p, err := Discover(ctx, config)
+	if err != nil {
+		return fmt.Errorf("can not discover the oidc provider %s: %w", config.Name, err)
+	}
+
+	if _, err := oauth.RegisterGenericOidcProvider(config.Name); err != nil {
+		return err
+	}
+
+	registryMu.Lock()
+	registry[config.Name] = p
+	registryMu.Unlock()
+	return nil
+}
+
+func GetProvider(name string) (*Provider, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	p, ok := registry[name]
+	return p, ok
+}
+
+// ---------------------------------------------------------------------------------
+
+type DiscoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+}
+
+type Provider struct {
+	config       Config
+	discovery    DiscoveryDocument
+	oauth2Config *oauth2.Config
+	jwks         *oauth.JwksKeySet
+}
+
+// Discover fetches the provider configuration from {issuer}/.well-known/openid-configuration
+func Discover(ctx context.Context, config Config) (*Provider, error) {
+	discoveryURL := strings.TrimSuffix(config.Issuer, "/") + "/.well-known/openid-configuration"
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, discoveryURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d from %s", res.StatusCode, discoveryURL)
+	}
+
+	var doc DiscoveryDocument
+	if err := json.NewDecoder(res.Body).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	// as required by the OpenID Connect Discovery spec section 4.3
+	if strings.TrimSuffix(doc.Issuer, "/") != strings.TrimSuffix(config.Issuer, "/") {
+		return nil, fmt.Errorf("the discovery issuer %s does not match the configured issuer %s", doc.Issuer, config.Issuer)
+	}
+	if len(doc.AuthorizationEndpoint) == 0 || len(doc.TokenEndpoint) == 0 || len(doc.JwksURI) == 0 {
+		return nil, errors.New("incomplete oidc discovery document")
+	}
+
+	if len(config.Scopes) == 0 {
+		config.Scopes = DefaultScopes
+	}
+
+	p := &Provider{
+		config:    config,
+		discovery: doc,
+		oauth2Config: &oauth2.Config{
+			ClientID:     config.ClientID,
+			ClientSecret: config.ClientSecret,
+			RedirectURL:  config.RedirectURL,
+			Scopes:       config.Scopes,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  doc.AuthorizationEndpoint,
+				TokenURL: doc.TokenEndpoint,
+			},
+		},
+		jwks: oauth.NewJwksKeySet(doc.JwksURI),
+	}
+	return p, nil
+}
+
+func (p *Provider) Name() string {
+	return p.config.Name
+}
+
+func (p *Provider) Scopes() []string {
+	return p.config.Scopes
+}
+
+func (p *Provider) AuthCodeURL(ctx context.Context, state, verifier string) string {
+	return p.oauth2Config.AuthCodeURL(
+		state,
+		oauth2.AccessTypeOffline,
+		oauth2.S256ChallengeOption(verifier),
+	)
+}
+
+func (p *Provider) AuthCodeExchange(ctx context.Context, code, verifier string) (*oauth2.Token, error) {
+	authCodeOption := make([]oauth2.AuthCodeOption, 0)
+	if len(verifier) != 0 {
+		authCodeOption = append(authCodeOption, oauth2.VerifierOption(verifier))
+	}
+	return p.oauth2Config.Exchange(ctx, code, authCodeOption...)
+}
+
+// ValidateIdToken checks the signature using the provider JWKS, the issuer, the audience and the expiration
+func (p *Provider) ValidateIdToken(ctx context.Context, idToken string) (*IdTokenClaims, error) {
+	claims := new(IdTokenClaims)
+
+	_, err := jwt.ParseWithClaims(
+		idToken,
+		claims,
+		p.jwks.Keyfunc(ctx),
+		jwt.WithValidMethods([]string{
+			jwt.SigningMethodRS256.Alg(),
+			jwt.SigningMethodRS384.Alg(),
+			jwt.SigningMethodRS512.Alg(),
+		}),
+		jwt.WithIssuer(p.discovery.Issuer),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	if err != nil {
+		return claims, err
+	}
+
+	return claims, nil
+}
+
+type IdTokenClaims struct {
+	Email             string `json:"email"`
+	Name              string `json:"name"`
+	FamilyName        string `json:"family_name"`
+	GivenName         string `json:"given_name"`
+	Picture           string `json:"picture"`
+	PreferredUsername string `json:"preferred_username"`
+
+	jwt.RegisteredClaims
+}
+
+// the first audience, the client id is checked in the validation
+func (c IdTokenClaims) Aud() string {
+	if len(c.Audience) == 0 {
+		return ""
+	}
+	return c.Audience[0]
+}
+
+func (c IdTokenClaims) IssuedAtTime() time.Time {
+	if c.IssuedAt == nil {
+		return time.Now()
+	}
+	return c.IssuedAt.Time
+}
+
+// FirstName falls back to the name claims when the given_name is missing (e.g. Microsoft Entra)
+func (c IdTokenClaims) FirstName() string {
+	if len(c.GivenName) != 0 {
+		return c.GivenName
+	}
+	if len(c.Name) != 0 {
+		return c.Name
+	}
+	return c.PreferredUsername
+}
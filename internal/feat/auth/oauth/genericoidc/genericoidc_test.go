@@ -0,0 +1,203 @@
+package genericoidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	_ "github.com/Nidal-Bakir/go-todo-backend/testing_init"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// newTestIssuer serves the discovery document and the key set of an oidc provider,
+// issuer overrides the issuer of the discovery document when it is not empty
+func newTestIssuer(t *testing.T, issuer string, key *rsa.PrivateKey) *httptest.Server {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	if len(issuer) == 0 {
+		issuer = server.URL
+	}
+
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(DiscoveryDocument{
+			Issuer:                issuer,
+			AuthorizationEndpoint: server.URL + "/authorize",
+			TokenEndpoint:         server.URL + "/token",
+			UserinfoEndpoint:      server.URL + "/userinfo",
+			JwksURI:               server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "kid",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+
+	return server
+}
+
+func newTestKey(t *testing.T) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestDiscover(t *testing.T) {
+	server := newTestIssuer(t, "", newTestKey(t))
+
+	p, err := Discover(context.Background(), Config{
+		Name:     "test",
+		Issuer:   server.URL + "/",
+		ClientID: "client",
+	})
+	if err != nil {
+		t.Fatalf("Discover error: %v", err)
+	}
+
+	if p.oauth2Config.Endpoint.AuthURL != server.URL+"/authorize" {
+		t.Errorf("AuthURL = %s", p.oauth2Config.Endpoint.AuthURL)
+	}
+	if p.oauth2Config.Endpoint.TokenURL != server.URL+"/token" {
+		t.Errorf("TokenURL = %s", p.oauth2Config.Endpoint.TokenURL)
+	}
+	if !slices.Equal(p.Scopes(), DefaultScopes) {
+		t.Errorf("Scopes = %v, want the default scopes", p.Scopes())
+	}
+}
+
+func TestDiscoverIssuerMismatch(t *testing.T) {
+	server := newTestIssuer(t, "https://attacker.example.com", newTestKey(t))
+
+	_, err := Discover(context.Background(), Config{
+		Name:     "test",
+		Issuer:   server.URL,
+		ClientID: "client",
+	})
+	if err == nil {
+		t.Fatal("the discovery document of another issuer should not be accepted")
+	}
+}
+
+func TestDiscoverNotFound(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(server.Close)
+
+	_, err := Discover(context.Background(), Config{
+		Name:     "test",
+		Issuer:   server.URL,
+		ClientID: "client",
+	})
+	if err == nil {
+		t.Fatal("the discovery should fail without a discovery document")
+	}
+}
+
+func TestValidateIdToken(t *testing.T) {
+	key := newTestKey(t)
+	server := newTestIssuer(t, "", key)
+
+	p, err := Discover(context.Background(), Config{
+		Name:     "test",
+		Issuer:   server.URL,
+		ClientID: "client",
+	})
+	if err != nil {
+		t.Fatalf("Discover error: %v", err)
+	}
+
+	now := time.Now()
+	validClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":        server.URL,
+			"aud":        "client",
+			"sub":        "user-1",
+			"email":      "user@example.com",
+			"given_name": "User",
+			"iat":        now.Unix(),
+			"exp":        now.Add(time.Hour).Unix(),
+		}
+	}
+
+	tests := []struct {
+		name    string
+		claims  func() jwt.MapClaims
+		key     *rsa.PrivateKey
+		wantErr bool
+	}{
+		{name: "valid", claims: validClaims, key: key},
+		{
+			name: "wrong audience",
+			claims: func() jwt.MapClaims {
+				c := validClaims()
+				c["aud"] = "other-client"
+				return c
+			},
+			key:     key,
+			wantErr: true,
+		},
+		{
+			name: "wrong issuer",
+			claims: func() jwt.MapClaims {
+				c := validClaims()
+				c["iss"] = "https://attacker.example.com"
+				return c
+			},
+			key:     key,
+			wantErr: true,
+		},
+		{
+			name: "expired",
+			claims: func() jwt.MapClaims {
+				c := validClaims()
+				c["exp"] = now.Add(-time.Minute).Unix()
+				return c
+			},
+			key:     key,
+			wantErr: true,
+		},
+		{name: "signed with another key", claims: validClaims, key: newTestKey(t), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := jwt.NewWithClaims(jwt.SigningMethodRS256, tt.claims())
+			token.Header["kid"] = "kid"
+			signed, err := token.SignedString(tt.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			claims, err := p.ValidateIdToken(context.Background(), signed)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("the token should not be valid")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ValidateIdToken error: %v", err)
+			}
+			if claims.Subject != "user-1" || claims.Email != "user@example.com" || claims.FirstName() != "User" {
+				t.Errorf("unexpected claims: %+v", claims)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package oidc
+
+import (
+	"context"
+	"time"
+
+	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/auth/oauth/facebook"
+	oauth "github.com/Nidal-Bakir/go-todo-backend/internal/feat/auth/oauth/utils"
+	dbutils "github.com/Nidal-Bakir/go-todo-backend/internal/utils/db_utils"
+	"github.com/rs/zerolog"
+	"golang.org/x/oauth2"
+)
+
+// the mobile sdk gives us either an id token (Limited Login)
+// or an opaque access token (Classic Login) in the oidcToken
+func facebookOidcFunc(ctx context.Context, code, codeVerifier, oidcToken string) (data OidcData, err error) {
+	zlog := zerolog.Ctx(ctx)
+
+	var oauthToken *oauth2.Token
+	var claims *facebook.FacebookOidcIdTokenClaims
+	var userInfo *facebook.FacebookUserInfo
+
+	exchangeCode := func() error {
+		t, err := facebook.AuthCodeExchange(ctx, code, codeVerifier)
+		if err != nil {
+			zlog.Err(err).Msg("can not exchange code with tokens")
+			return err
+		}
+
+		if scopes := oauth.NewScopesFromOauthToken(t); scopes.Len() == 0 {
+			data.OauthScopes = *oauth.NewScopes(facebook.OidcWebScopes)
+		} else {
+			data.OauthScopes = *scopes
+		}
+
+		oauthToken = t
+		return nil
+	}
+
+	validateIdTokenFn := func(idToken string) error {
+		res, err := facebook.ValidateIdToken(ctx, idToken)
+		if err != nil {
+			zlog.Err(err).Msg("can not validate facebook id token")
+			return err
+		}
+		claims = res
+		return nil
+	}
+
+	parseIdTokenFn := func(idToken string) error {
+		res, err := facebook.ParseIdToken(ctx, idToken)
+		if err != nil {
+			zlog.Err(err).Msg("can not parse facebook id token")
+			return err
+		}
+		claims = res
+		return nil
+	}
+
+	getUserInfoFn := func(accessToken string) error {
+		res, err := facebook.GetUserInfo(ctx, accessToken)
+		if err != nil {
+			zlog.Err(err).Msg("can not get the facebook user info")
+			return err
+		}
+		userInfo = res
+		return nil
+	}
+
+	if len(oidcToken) == 0 {
+		err := exchangeCode()
+		if err != nil {
+			return data, err
+		}
+		// facebook only returns the id_token when the openid scope is granted
+		if idToken, ok := oauthToken.Extra("id_token").(string); ok && len(idToken) != 0 {
+			err = parseIdTokenFn(idToken)
+		} else {
+			err = getUserInfoFn(oauthToken.AccessToken)
+		}
+		if err != nil {
+			return data, err
+		}
+	} else if facebook.IsIdToken(oidcToken) {
+		err := validateIdTokenFn(oidcToken)
+		if err != nil {
+			return data, err
+		}
+		data.OauthScopes = *oauth.NewScopes(facebook.OidcOpenIdScope)
+		if len(code) != 0 {
+			err = exchangeCode()
+			if err != nil {
+				return data, err
+			}
+		}
+	} else {
+		_, err := facebook.ValidateAccessToken(ctx, oidcToken)
+		if err != nil {
+			zlog.Err(err).Msg("can not validate facebook access token")
+			return data, err
+		}
+		err = getUserInfoFn(oidcToken)
+		if err != nil {
+			return data, err
+		}
+		data.OauthScopes = *oauth.NewScopes(facebook.OidcWebScopes)
+		data.OauthAccessToken = dbutils.ToPgTypeText(oidcToken)
+		data.OauthTokenType = dbutils.ToPgTypeText("Bearer")
+	}
+
+	if oauthToken != nil {
+		data.OauthAccessToken = dbutils.ToPgTypeText(oauthToken.AccessToken)
+		data.OauthRefreshToken = dbutils.ToPgTypeText(oauthToken.RefreshToken)
+		data.OauthTokenType = dbutils.ToPgTypeText(oauthToken.TokenType)
+		data.OauthTokenExpiresAt = dbutils.ToPgTypeTimestamp(oauthToken.Expiry)
+	}
+
+	if claims != nil {
+		data.OidcAud = claims.Aud()
+		data.OidcIss = claims.Issuer
+		data.OidcIat = dbutils.ToPgTypeTimestamp(claims.IssuedAtTime())
+		data.OidcSub = claims.Subject
+		data.OidcEmail = dbutils.ToPgTypeText(claims.Email)
+		data.OidcGivenName = dbutils.ToPgTypeText(claims.GivenName)
+		data.OidcFamilyName = dbutils.ToPgTypeText(claims.FamilyName)
+		data.OidcName = dbutils.ToPgTypeText(claims.Name)
+		data.OidcPicture = dbutils.ToPgTypeText(claims.Picture)
+		data.UserFirstName = claims.GivenName
+		data.UserLastName = dbutils.ToPgTypeText(claims.FamilyName)
+		data.UserProfileImage = dbutils.ToPgTypeText(claims.Picture)
+	} else {
+		// the graph api user info is not signed, the access token is validated instead
+		data.OidcAud = facebook.ClientID()
+		data.OidcIss = facebook.Issuer
+		data.OidcIat = dbutils.ToPgTypeTimestamp(time.Now())
+		data.OidcSub = userInfo.Id
+		data.OidcEmail = dbutils.ToPgTypeText(userInfo.Email)
+		data.OidcGivenName = dbutils.ToPgTypeText(userInfo.FirstName)
+		data.OidcFamilyName = dbutils.ToPgTypeText(userInfo.LastName)
+		data.OidcName = dbutils.ToPgTypeText(userInfo.Name)
+		data.OidcPicture = dbutils.ToPgTypeText(userInfo.Picture)
+		data.UserFirstName = userInfo.FirstName
+		data.UserLastName = dbutils.ToPgTypeText(userInfo.LastName)
+		data.UserProfileImage = dbutils.ToPgTypeText(userInfo.Picture)
+	}
+
+	return data, nil
+}
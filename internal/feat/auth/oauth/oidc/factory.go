@@ -2,6 +2,9 @@ package oidc
 
 import (
 	"context"
+	"fmt"
+
+	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/auth/oauth/genericoidc"
 
 	oauth "github.com/Nidal-Bakir/go-todo-backend/internal/feat/auth/oauth/utils"
 )
@@ -12,15 +15,30 @@ func (f OidcFunc) Exec(ctx context.Context, code, codeVerifier, oidcToken string
 	return f(ctx, code, codeVerifier, oidcToken)
 }
 
-func NewOidc(provider oauth.OauthProvider) OidcFunc {
+func NewOidc(provider oauth.OauthProvider) (OidcFunc, error) {
 	var fn OidcFunc
-	provider.Fold(
+	err := provider.Fold(
 		oauth.OauthProviderFoldActions{
 			OnGoogle: func() error {
 				fn = googleOidcFunc
 				return nil
 			},
+			OnFacebook: func() error {
+				fn = facebookOidcFunc
+				return nil
+			},
+			OnGenericOidc: func() error {
+				p, ok := genericoidc.GetProvider(provider.String())
+				if !ok {
+					return fmt.Errorf("the oidc provider %s is not registered", provider.String())
+				}
+				fn = genericOidcFunc(p)
+				return nil
+			},
 		},
 	)
-	return fn
+	if err != nil {
+		return nil, err
+	}
+	return fn, nil
 }
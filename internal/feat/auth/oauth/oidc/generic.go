@@ -0,0 +1,89 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/auth/oauth/genericoidc"
+	oauth "github.com/Nidal-Bakir/go-todo-backend/internal/feat/auth/oauth/utils"
+	dbutils "github.com/Nidal-Bakir/go-todo-backend/internal/utils/db_utils"
+	"github.com/rs/zerolog"
+	"golang.org/x/oauth2"
+)
+
+func genericOidcFunc(provider *genericoidc.Provider) OidcFunc {
+	return func(ctx context.Context, code, codeVerifier, oidcToken string) (data OidcData, err error) {
+		zlog := zerolog.Ctx(ctx)
+
+		var oauthToken *oauth2.Token
+
+		exchangeCode := func() error {
+			t, err := provider.AuthCodeExchange(ctx, code, codeVerifier)
+			if err != nil {
+				zlog.Err(err).Msg("can not exchange code with tokens")
+				return err
+			}
+
+			if scopes := oauth.NewScopesFromOauthToken(t); scopes.Len() == 0 {
+				data.OauthScopes = *oauth.NewScopes(provider.Scopes())
+			} else {
+				data.OauthScopes = *scopes
+			}
+
+			oauthToken = t
+			return nil
+		}
+
+		if len(oidcToken) == 0 {
+			err := exchangeCode()
+			if err != nil {
+				return data, err
+			}
+			idToken, ok := oauthToken.Extra("id_token").(string)
+			if !ok {
+				missingIdTokenErr := errors.New("missing id_token from " + provider.Name())
+				zlog.Err(missingIdTokenErr).Msg("can not get the id token from the payload")
+				return data, missingIdTokenErr
+			}
+			oidcToken = idToken
+		} else {
+			data.OauthScopes = *oauth.NewScopes(genericoidc.OidcOpenIdScope)
+			if len(code) != 0 {
+				err = exchangeCode()
+				if err != nil {
+					return data, err
+				}
+			}
+		}
+
+		// unlike google, the id token is always validated using the provider JWKS
+		// since there is no sdk that did the validation for us
+		claims, err := provider.ValidateIdToken(ctx, oidcToken)
+		if err != nil {
+			zlog.Err(err).Msg("can not validate the id token")
+			return data, err
+		}
+
+		if oauthToken != nil {
+			data.OauthAccessToken = dbutils.ToPgTypeText(oauthToken.AccessToken)
+			data.OauthRefreshToken = dbutils.ToPgTypeText(oauthToken.RefreshToken)
+			data.OauthTokenType = dbutils.ToPgTypeText(oauthToken.TokenType)
+			data.OauthTokenExpiresAt = dbutils.ToPgTypeTimestamp(oauthToken.Expiry)
+		}
+
+		data.OidcAud = claims.Aud()
+		data.OidcIss = claims.Issuer
+		data.OidcIat = dbutils.ToPgTypeTimestamp(claims.IssuedAtTime())
+		data.OidcSub = claims.Subject
+		data.OidcEmail = dbutils.ToPgTypeText(claims.Email)
+		data.OidcGivenName = dbutils.ToPgTypeText(claims.GivenName)
+		data.OidcFamilyName = dbutils.ToPgTypeText(claims.FamilyName)
+		data.OidcName = dbutils.ToPgTypeText(claims.Name)
+		data.OidcPicture = dbutils.ToPgTypeText(claims.Picture)
+		data.UserFirstName = claims.FirstName()
+		data.UserLastName = dbutils.ToPgTypeText(claims.FamilyName)
+		data.UserProfileImage = dbutils.ToPgTypeText(claims.Picture)
+
+		return data, nil
+	}
+}
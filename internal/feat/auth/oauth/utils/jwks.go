@@ -0,0 +1,141 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// the min time between two fetches of the key set,
+// so a token with an unknown kid can not make us spam the provider
+const jwksMinRefetchInterval = time.Minute
+
+// JwksKeySet caches the RSA public keys of an oidc provider
+// and refetches them when a token is signed with an unknown key.
+type JwksKeySet struct {
+	jwksURL string
+
+	mu        sync.RWMutex
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+}
+
+func NewJwksKeySet(jwksURL string) *JwksKeySet {
+	return &JwksKeySet{jwksURL: jwksURL, keys: map[string]*rsa.PublicKey{}}
+}
+
+// Keyfunc to be used with jwt.Parse
+func (s *JwksKeySet) Keyfunc(ctx context.Context) jwt.Keyfunc {
+	return func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return s.key(ctx, kid)
+	}
+}
+
+func (s *JwksKeySet) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	s.mu.RLock()
+	key, ok := s.keys[kid]
+	fetchedAt := s.fetchedAt
+	s.mu.RUnlock()
+
+	if ok {
+		return key, nil
+	}
+
+	if time.Since(fetchedAt) < jwksMinRefetchInterval {
+		return nil, fmt.Errorf("unknown jwks key id: %s", kid)
+	}
+
+	if err := s.refetch(ctx); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	key, ok = s.keys[kid]
+	s.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown jwks key id: %s", kid)
+	}
+	return key, nil
+}
+
+func (s *JwksKeySet) refetch(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// another goroutine did the job while we were waiting for the lock
+	if time.Since(s.fetchedAt) < jwksMinRefetchInterval {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.jwksURL, nil)
+	if err != nil {
+		return err
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d while fetching jwks from %s", res.StatusCode, s.jwksURL)
+	}
+
+	var body struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return err
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(body.Keys))
+	for _, k := range body.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		key, err := newRSAPublicKey(k.N, k.E)
+		if err != nil {
+			return err
+		}
+		keys[k.Kid] = key
+	}
+	if len(keys) == 0 {
+		return errors.New("no RSA signing keys found in jwks")
+	}
+
+	s.keys = keys
+	s.fetchedAt = time.Now()
+	return nil
+}
+
+func newRSAPublicKey(n, e string) (*rsa.PublicKey, error) {
+	nBytes, err := base64.RawURLEncoding.DecodeString(n)
+	if err != nil {
+		return nil, err
+	}
+	eBytes, err := base64.RawURLEncoding.DecodeString(e)
+	if err != nil {
+		return nil, err
+	}
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(nBytes),
+		E: int(new(big.Int).SetBytes(eBytes).Int64()),
+	}, nil
+}
@@ -0,0 +1,149 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type testJwksServer struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	keys    map[string]*rsa.PrivateKey
+	fetches int
+}
+
+func newTestJwksServer(t *testing.T) *testJwksServer {
+	s := &testJwksServer{keys: map[string]*rsa.PrivateKey{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.fetches++
+
+		keys := make([]map[string]string, 0, len(s.keys))
+		for kid, key := range s.keys {
+			keys = append(keys, map[string]string{
+				"kty": "RSA",
+				"kid": kid,
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		}
+		json.NewEncoder(w).Encode(map[string]any{"keys": keys})
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// setKeys replaces the keys of the server with new keys of the given ids
+func (s *testJwksServer) setKeys(t *testing.T, kids ...string) map[string]*rsa.PrivateKey {
+	keys := make(map[string]*rsa.PrivateKey, len(kids))
+	for _, kid := range kids {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[kid] = key
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.mu.Unlock()
+	return keys
+}
+
+func (s *testJwksServer) fetchCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fetches
+}
+
+func signTestToken(t *testing.T, kid string, key *rsa.PrivateKey) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{"sub": "1"})
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestJwksKeySetKeyRotation(t *testing.T) {
+	ctx := context.Background()
+	server := newTestJwksServer(t)
+	keySet := NewJwksKeySet(server.URL)
+
+	parse := func(token string) error {
+		_, err := jwt.Parse(token, keySet.Keyfunc(ctx), jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}))
+		return err
+	}
+
+	oldKeys := server.setKeys(t, "old")
+	oldToken := signTestToken(t, "old", oldKeys["old"])
+
+	if err := parse(oldToken); err != nil {
+		t.Fatalf("the token of the old key: %v", err)
+	}
+	if err := parse(oldToken); err != nil {
+		t.Fatalf("the token of the cached old key: %v", err)
+	}
+	if got := server.fetchCount(); got != 1 {
+		t.Fatalf("the key set should be fetched once, got %d fetches", got)
+	}
+
+	newKeys := server.setKeys(t, "new")
+	newToken := signTestToken(t, "new", newKeys["new"])
+
+	// fetched less than jwksMinRefetchInterval ago, so the unknown key does not refetch
+	if err := parse(newToken); err == nil {
+		t.Fatal("the unknown key should not be accepted before the refetch interval")
+	}
+	if got := server.fetchCount(); got != 1 {
+		t.Fatalf("the unknown key should not refetch the key set, got %d fetches", got)
+	}
+
+	keySet.mu.Lock()
+	keySet.fetchedAt = time.Now().Add(-jwksMinRefetchInterval)
+	keySet.mu.Unlock()
+
+	if err := parse(newToken); err != nil {
+		t.Fatalf("the token of the rotated key: %v", err)
+	}
+	if got := server.fetchCount(); got != 2 {
+		t.Fatalf("the unknown key should refetch the key set, got %d fetches", got)
+	}
+
+	// the rotated out key is dropped with the refetch
+	if err := parse(oldToken); err == nil {
+		t.Fatal("the token of the removed key should not be accepted")
+	}
+}
+
+func TestJwksKeySetWrongSignature(t *testing.T) {
+	server := newTestJwksServer(t)
+	server.setKeys(t, "kid")
+	keySet := NewJwksKeySet(server.URL)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := signTestToken(t, "kid", otherKey)
+
+	_, err = jwt.Parse(token, keySet.Keyfunc(context.Background()), jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}))
+	if err == nil {
+		t.Fatal("the token signed with another key should not be accepted")
+	}
+}
@@ -1,6 +1,7 @@
 package oauth
 
 import (
+	"errors"
 	"fmt"
 	"slices"
 
@@ -10,6 +11,8 @@ import (
 type OauthProvider struct {
 	idName        string
 	isOidcCapable bool
+	// configured at startup and driven by the oidc discovery document
+	isGenericOidc bool
 }
 
 var (
@@ -21,6 +24,10 @@ func (o OauthProvider) String() string {
 	return o.idName
 }
 
+func (o OauthProvider) IsOidcCapable() bool {
+	return o.isOidcCapable
+}
+
 var (
 	supportedOauthProviders = []OauthProvider{Google, Facebook}
 )
@@ -29,6 +36,20 @@ func SupportedOauthProviders() []OauthProvider {
 	return supportedOauthProviders
 }
 
+// RegisterGenericOidcProvider adds a new oidc provider to the supported providers.
+// Must be called at startup before serving any request.
+func RegisterGenericOidcProvider(name string) (OauthProvider, error) {
+	if len(name) == 0 {
+		return OauthProvider{}, errors.New("empty oidc provider name")
+	}
+	if IsOauthProviderSupported(name) {
+		return OauthProvider{}, fmt.Errorf("the oidc provider %s is already registered", name)
+	}
+	p := OauthProvider{idName: name, isOidcCapable: true, isGenericOidc: true}
+	supportedOauthProviders = append(supportedOauthProviders, p)
+	return p, nil
+}
+
 func ProviderFromString(provider string) *OauthProvider {
 	index := slices.IndexFunc(supportedOauthProviders, func(o OauthProvider) bool {
 		return o.idName == provider
@@ -58,8 +79,9 @@ func GenerateVerifier() string {
 }
 
 type OauthProviderFoldActions struct {
-	OnGoogle   func() error
-	OnFacebook func() error
+	OnGoogle      func() error
+	OnFacebook    func() error
+	OnGenericOidc func() error
 }
 
 func (o *OauthProvider) Fold(actions OauthProviderFoldActions) error {
@@ -94,6 +116,9 @@ func (o *OauthProvider) FoldOr(actions OauthProviderFoldActions, orElse func() e
 		return actionOrElse(actions.OnFacebook)()
 
 	default:
+		if o.isGenericOidc {
+			return actionOrElse(actions.OnGenericOidc)()
+		}
 		return orElse()
 	}
 }
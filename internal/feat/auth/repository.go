@@ -2,14 +2,23 @@ package auth
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"math/big"
 	"net/netip"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Nidal-Bakir/go-todo-backend/internal/apperr"
 	"github.com/Nidal-Bakir/go-todo-backend/internal/database/database_queries"
 	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/auth/oauth/oidc"
 	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/otp"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/perm"
 	"github.com/Nidal-Bakir/go-todo-backend/internal/gateway"
 	"github.com/Nidal-Bakir/go-todo-backend/internal/utils"
 
@@ -17,8 +26,10 @@ import (
 	"github.com/Nidal-Bakir/go-todo-backend/internal/utils/emailvalidator"
 	"github.com/Nidal-Bakir/go-todo-backend/internal/utils/password_hasher"
 	"github.com/Nidal-Bakir/go-todo-backend/internal/utils/phonenumber"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/utils/totp"
 	usernaemgen "github.com/Nidal-Bakir/username_r_gen/v2"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 
 	"github.com/rs/zerolog"
 )
@@ -27,22 +38,38 @@ const (
 	aDay                         = time.Hour * 24
 	aMounth                      = aDay * 30
 	aYear                        = aMounth * 12
-	AuthTokenExpDuration         = aYear
+	AccessTokenExpDuration       = time.Minute * 15
+	RefreshTokenExpDuration      = aMounth * 3
 	InstallationTokenExpDuration = aYear
 
 	OtpCodeLength             = 6
 	PasswordRecommendedLength = 8
+
+	guestUserFirstName = "Guest"
+
+	mfaRecoveryCodesCount         = 10
+	mfaMaxChallengeVerifyAttempts = 5
+
+	refreshTokenBytesLength = 32
 )
 
+// shown in the authenticator apps next to the account name
+var mfaIssuer = os.Getenv("APP_NAME")
+
 type Repository interface {
 	GetUserById(ctx context.Context, id int) (User, error)
-	GetUserAndSessionDataBySessionToken(ctx context.Context, sessionToken string) (UserAndSession, error)
+	GetUserAndSessionDataBySessionId(ctx context.Context, sessionId int) (UserAndSession, error)
+	GetActiveSessionsForUser(ctx context.Context, userId int) ([]UserSession, error)
+	RevokeSessionForUser(ctx context.Context, userId, sessionId int) error
+	// must be called after changing the user data checked by the auth middleware, e.g. blocking or changing the role
+	InvalidateSessionsCacheForUser(ctx context.Context, userId int) error
 	CreateTempPasswordUser(ctx context.Context, tUser *TempPasswordUser) (*TempPasswordUser, error)
 	CreatePasswordUser(ctx context.Context, tempUserId uuid.UUID, otp string) (User, error)
-	PasswordLogin(ctx context.Context, accessKey PasswordLoginAccessKey, password string, ipAddress netip.Addr, installation Installation) (user User, token string, err error)
+	PasswordLogin(ctx context.Context, accessKey PasswordLoginAccessKey, password string, ipAddress netip.Addr, installation Installation) (user User, tokens SessionTokens, mfaChallenge *MfaChallenge, err error)
+	RefreshSession(ctx context.Context, refreshToken string, installation Installation) (SessionTokens, error)
 	GetInstallationUsingToken(ctx context.Context, installationToken string, attachedToSessionId *int32) (Installation, error)
 	ChangePasswordForAllPasswordLoginIdentities(ctx context.Context, userID int, oldPassword, newPassword string) error
-	VerifyAuthToken(token string) (*AuthClaims, error)
+	VerifyAccessToken(token string) (*AuthClaims, error)
 	VerifyTokenForInstallation(token string) (*InstallationClaims, error)
 	CreateInstallation(ctx context.Context, data CreateInstallationData) (installationToken string, err error)
 	UpdateInstallation(ctx context.Context, installationToken string, data UpdateInstallationData) error
@@ -50,11 +77,31 @@ type Repository interface {
 	ForgetPassword(ctx context.Context, accessKey PasswordLoginAccessKey) (uuid.UUID, error)
 	ResetPassword(ctx context.Context, id uuid.UUID, providedOTP, newPassword string) error
 	GetAllLoginIdentitiesForUser(ctx context.Context, userId int) ([]PublicLoginOptionForProfile, error)
-	LoginOrCreateUserWithOidc(ctx context.Context, ipAddress netip.Addr, installation Installation, data LoginOrCreateUserWithOidcRepoParam) (user User, token string, err error)
+	LoginOrCreateUserWithOidc(ctx context.Context, ipAddress netip.Addr, installation Installation, data LoginOrCreateUserWithOidcRepoParam) (user User, tokens SessionTokens, mfaChallenge *MfaChallenge, err error)
+	GuestLogin(ctx context.Context, ipAddress netip.Addr, installation Installation) (user User, tokens SessionTokens, mfaChallenge *MfaChallenge, err error)
+	CreateTempPasswordUserForGuest(ctx context.Context, guestUserId int, tUser *TempPasswordUser) (*TempPasswordUser, error)
+	UpgradeGuestWithOidc(ctx context.Context, guestUserId int, data LoginOrCreateUserWithOidcRepoParam) (User, error)
+	EnrollTotp(ctx context.Context, userId int) (TotpEnrollment, error)
+	ConfirmTotp(ctx context.Context, userId int, code string) (recoveryCodes []string, err error)
+	DisableTotp(ctx context.Context, userId int, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userId int, code string) (recoveryCodes []string, err error)
+	SetMfaEnforcedForUser(ctx context.Context, userId int, enforced bool) error
+	SearchUsers(ctx context.Context, params SearchUsersParams, offset, limit int) ([]UserForAdmin, error)
+	GetUserForAdmin(ctx context.Context, userId int) (UserForAdmin, error)
+	// BlockUser, SetRoleForUser and SoftDeleteUser reject the callers that do not have all the
+	// permissions of the user, or of the new role (see perm.Repository.CheckCanManageRoles).
+	// blockedUntil is optional, without it the user stays blocked until unblocked
+	BlockUser(ctx context.Context, adminUserId int, adminRole string, userId int, reason string, blockedUntil *time.Time) error
+	UnblockUser(ctx context.Context, userId int) error
+	// an empty roleName removes the role of the user
+	SetRoleForUser(ctx context.Context, adminUserId int, adminRole string, userId int, roleName string) error
+	SoftDeleteUser(ctx context.Context, adminUserId int, adminRole string, userId int) error
+	EnrollTotpForMfaChallenge(ctx context.Context, challengeId uuid.UUID, installation Installation) (TotpEnrollment, error)
+	VerifyMfaChallenge(ctx context.Context, challengeId uuid.UUID, code, recoveryCode string, ipAddress netip.Addr, installation Installation) (user User, tokens SessionTokens, recoveryCodes []string, err error)
 }
 
-func NewRepository(ds DataSource, gatewaysProvider gateway.Provider, passwordHasher password_hasher.PasswordHasher, authJWT *AuthJWT) Repository {
-	return repositoryImpl{dataSource: ds, gatewaysProvider: gatewaysProvider, passwordHasher: passwordHasher, authJWT: authJWT}
+func NewRepository(ds DataSource, gatewaysProvider gateway.Provider, passwordHasher password_hasher.PasswordHasher, authJWT *AuthJWT, permRepo perm.Repository) Repository {
+	return repositoryImpl{dataSource: ds, gatewaysProvider: gatewaysProvider, passwordHasher: passwordHasher, authJWT: authJWT, permRepo: permRepo}
 }
 
 // ---------------------------------------------------------------------------------
@@ -64,6 +111,7 @@ type repositoryImpl struct {
 	gatewaysProvider gateway.Provider
 	passwordHasher   password_hasher.PasswordHasher
 	authJWT          *AuthJWT
+	permRepo         perm.Repository
 }
 
 func (repo repositoryImpl) GetUserById(ctx context.Context, id int) (User, error) {
@@ -97,21 +145,97 @@ func (repo repositoryImpl) GetUserById(ctx context.Context, id int) (User, error
 	return user, nil
 }
 
-func (repo repositoryImpl) GetUserAndSessionDataBySessionToken(ctx context.Context, sessionToken string) (UserAndSession, error) {
+func (repo repositoryImpl) GetUserAndSessionDataBySessionId(ctx context.Context, sessionId int) (UserAndSession, error) {
 	zlog := zerolog.Ctx(ctx)
 
-	userAndSessionDataFromDB, err := repo.dataSource.GetUserAndSessionDataBySessionToken(ctx, sessionToken)
+	id, err := utils.SafeIntToInt32(sessionId)
+	if err != nil {
+		return UserAndSession{}, err
+	}
+
+	userAndSessionDataFromDB, err := repo.dataSource.GetUserAndSessionDataBySessionId(ctx, id)
 	if err != nil {
 		if !errors.Is(err, apperr.ErrNoResult) {
-			zlog.Err(err).Msg("error geting the user by session token")
+			zlog.Err(err).Msg("error geting the user by session id")
 		}
 		return UserAndSession{}, err
 	}
 
 	userAndSession := NewUserAndSessionFromDatabaseUserAndSessionRow(userAndSessionDataFromDB)
+
+	// used to show the last activity in the sessions list, do not fail the request for it
+	if err := repo.dataSource.TouchSessionLastActiveAt(ctx, userAndSession.SessionID); err != nil {
+		zlog.Err(err).Msg("error while updating the session last active at. igonoring this error")
+	}
+
 	return userAndSession, nil
 }
 
+func (repo repositoryImpl) GetActiveSessionsForUser(ctx context.Context, userId int) ([]UserSession, error) {
+	id, err := utils.SafeIntToInt32(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	dbSessions, err := repo.dataSource.GetActiveSessionsForUser(ctx, id)
+	if err != nil {
+		zerolog.Ctx(ctx).Err(err).Msg("error while getting the active sessions for user")
+		return nil, err
+	}
+
+	sessions := make([]UserSession, len(dbSessions))
+	for i, s := range dbSessions {
+		sessions[i] = NewUserSessionFromDatabaseRow(s)
+	}
+
+	return sessions, nil
+}
+
+func (repo repositoryImpl) RevokeSessionForUser(ctx context.Context, userId, sessionId int) error {
+	uId, err := utils.SafeIntToInt32(userId)
+	if err != nil {
+		return err
+	}
+	sId, err := utils.SafeIntToInt32(sessionId)
+	if err != nil {
+		return err
+	}
+
+	zlog := zerolog.Ctx(ctx).With().Int32("revoked_session_id", sId).Logger()
+
+	// make sure the session belongs to the user before revoking it
+	session, err := repo.dataSource.GetActiveSessionForUser(ctx, sId, uId)
+	if err != nil {
+		if !errors.Is(err, apperr.ErrNoResult) {
+			zlog.Err(err).Msg("error while getting the active session for user")
+		}
+		return err
+	}
+
+	err = repo.dataSource.ExpTokenAndUnlinkFromInstallation(ctx, int(session.UsedInstallation), int(session.ID))
+	if err != nil {
+		zlog.Err(err).Msg("error while revoking the session")
+		return err
+	}
+
+	return nil
+}
+
+func (repo repositoryImpl) InvalidateSessionsCacheForUser(ctx context.Context, userId int) error {
+	id, err := utils.SafeIntToInt32(userId)
+	if err != nil {
+		return err
+	}
+
+	err = repo.dataSource.DeleteAllSessionsOfUserFromCache(ctx, id)
+	if err != nil {
+		zerolog.Ctx(ctx).Err(err).Msg("error while deleting the sessions of the user from the cache")
+		return err
+	}
+
+	return nil
+}
+
 func (repo repositoryImpl) CreateTempPasswordUser(ctx context.Context, tUser *TempPasswordUser) (*TempPasswordUser, error) {
 	zlog := zerolog.Ctx(ctx)
 
@@ -283,6 +407,17 @@ func (repo repositoryImpl) storPasswordUser(ctx context.Context, tUser *TempPass
 		return User{}, err
 	}
 
+	if tUser.GuestUserId != 0 {
+		dbUser, err := repo.dataSource.UpgradeGuestUserToPasswordUser(ctx, tUser.GuestUserId, createUserArgs)
+		if err != nil {
+			if !apperr.IsAppErr(err) {
+				zlog.Err(err).Int32("guest_user_id", tUser.GuestUserId).Msg("error while upgrading guest user to password user")
+			}
+			return User{}, err
+		}
+		return NewUserFromDatabaseUser(dbUser), nil
+	}
+
 	dbUser, err := repo.dataSource.CreatePasswordUser(ctx, createUserArgs)
 	if err != nil {
 		zlog.Err(err).Msg("error while create new user in the database")
@@ -352,7 +487,7 @@ func (repo repositoryImpl) PasswordLogin(
 	password string,
 	ipAddress netip.Addr,
 	installation Installation,
-) (user User, token string, err error) {
+) (user User, tokens SessionTokens, mfaChallenge *MfaChallenge, err error) {
 	zlog := zerolog.Ctx(ctx)
 
 	userWithLoginIdentity, err := repo.dataSource.GetPasswordLoginIdentityWithUser(
@@ -366,7 +501,7 @@ func (repo repositoryImpl) PasswordLogin(
 		} else {
 			zlog.Err(err).Msg("error geting active login option with user data")
 		}
-		return User{}, "", err
+		return User{}, SessionTokens{}, nil, err
 	}
 
 	checkPassword := func() error {
@@ -383,20 +518,33 @@ func (repo repositoryImpl) PasswordLogin(
 	err = checkPassword()
 	if err != nil {
 		zlog.Err(err).Msg("error while checking the password for user to login")
-		return User{}, "", err
+		return User{}, SessionTokens{}, nil, err
+	}
+
+	mfaChallenge, err = repo.mfaChallengeIfRequired(ctx, userWithLoginIdentity.UserID, userWithLoginIdentity.LoginIdentityID, installation, ipAddress)
+	if err != nil {
+		return User{}, SessionTokens{}, nil, err
+	}
+	if mfaChallenge != nil {
+		return User{}, SessionTokens{}, mfaChallenge, nil
 	}
 
-	token, expiresAt, err := repo.generateAuthToken(ctx, userWithLoginIdentity.UserID)
+	refreshToken, refreshTokenHash, refreshTokenExpiresAt, err := repo.generateRefreshToken(ctx)
 	if err != nil {
-		return User{}, "", err
+		return User{}, SessionTokens{}, nil, err
 	}
 
-	err = repo.dataSource.CreateNewSessionAndAttachUserToInstallation(ctx, userWithLoginIdentity.LoginIdentityID, installation.ID, token, ipAddress, expiresAt)
+	sessionId, err := repo.dataSource.CreateNewSessionAndAttachUserToInstallation(ctx, userWithLoginIdentity.LoginIdentityID, installation.ID, refreshTokenHash, ipAddress, refreshTokenExpiresAt)
 	if err != nil {
 		if !apperr.IsAppErr(err) {
 			zlog.Err(err).Msg("error creating new session for user to login")
 		}
-		return User{}, "", err
+		return User{}, SessionTokens{}, nil, err
+	}
+
+	tokens, err = repo.generateSessionTokens(ctx, userWithLoginIdentity.UserID, sessionId, refreshToken, refreshTokenExpiresAt)
+	if err != nil {
+		return User{}, SessionTokens{}, nil, err
 	}
 
 	user = User{
@@ -410,18 +558,126 @@ func (repo repositoryImpl) PasswordLogin(
 		BlockedAt:    userWithLoginIdentity.UserBlockedAt,
 	}
 
-	return user, token, nil
+	return user, tokens, nil, nil
+}
+
+// generateRefreshToken returns an opaque refresh token for the client, and its
+// hash to be stored as the session token.
+func (repo repositoryImpl) generateRefreshToken(ctx context.Context) (token, tokenHash string, expiresAt time.Time, err error) {
+	b := make([]byte, refreshTokenBytesLength)
+	if _, err = rand.Read(b); err != nil {
+		zerolog.Ctx(ctx).Err(err).Msg("error while generating a new refresh token")
+		return "", "", time.Time{}, err
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, hashRefreshToken(token), time.Now().Add(RefreshTokenExpDuration), nil
+}
+
+func (repo repositoryImpl) generateSessionTokens(ctx context.Context, userId, sessionId int32, refreshToken string, refreshTokenExpiresAt time.Time) (SessionTokens, error) {
+	accessTokenExpiresAt := time.Now().Add(AccessTokenExpDuration)
+	accessToken, err := repo.authJWT.GenAccessToken(userId, sessionId, accessTokenExpiresAt)
+	if err != nil {
+		zerolog.Ctx(ctx).Err(err).Msg("error while generating a new access token using jwt")
+		return SessionTokens{}, err
+	}
+
+	tokens := SessionTokens{
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessTokenExpiresAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: refreshTokenExpiresAt,
+	}
+	return tokens, nil
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// RefreshSession rotates the refresh token of the session and issues a new access token.
+// Using an already rotated refresh token means it was leaked, so the whole session is revoked.
+func (repo repositoryImpl) RefreshSession(ctx context.Context, refreshToken string, installation Installation) (SessionTokens, error) {
+	zlog := zerolog.Ctx(ctx).With().Int32("installation_id", installation.ID).Logger()
+
+	refreshTokenHash := hashRefreshToken(refreshToken)
+
+	session, err := repo.dataSource.GetActiveSessionByToken(ctx, refreshTokenHash)
+	if err != nil {
+		if !errors.Is(err, apperr.ErrNoResult) {
+			zlog.Err(err).Msg("error while getting the session by refresh token")
+			return SessionTokens{}, err
+		}
+		return SessionTokens{}, repo.revokeSessionIfRefreshTokenReused(ctx, refreshTokenHash)
+	}
+
+	if session.UsedInstallation != installation.ID {
+		return SessionTokens{}, apperr.ErrInvalidRefreshToken
+	}
+
+	userAndSession, err := repo.dataSource.GetUserAndSessionDataBySessionId(ctx, session.ID)
+	if err != nil {
+		if errors.Is(err, apperr.ErrNoResult) {
+			return SessionTokens{}, apperr.ErrInvalidRefreshToken
+		}
+		zlog.Err(err).Msg("error while getting the user of the session")
+		return SessionTokens{}, err
+	}
+
+	if IsUserBlocked(userAndSession.UserBlockedAt, userAndSession.UserBlockedUntil) {
+		return SessionTokens{}, apperr.ErrBlockedUser
+	}
+
+	newRefreshToken, newRefreshTokenHash, refreshTokenExpiresAt, err := repo.generateRefreshToken(ctx)
+	if err != nil {
+		return SessionTokens{}, err
+	}
+
+	rotated, err := repo.dataSource.RotateSessionToken(ctx, session.ID, refreshTokenHash, newRefreshTokenHash, refreshTokenExpiresAt)
+	if err != nil {
+		zlog.Err(err).Msg("error while rotating the session refresh token")
+		return SessionTokens{}, err
+	}
+	if !rotated {
+		// another request rotated the same token first
+		return SessionTokens{}, repo.revokeSessionIfRefreshTokenReused(ctx, refreshTokenHash)
+	}
+
+	return repo.generateSessionTokens(ctx, userAndSession.UserID, session.ID, newRefreshToken, refreshTokenExpiresAt)
 }
 
-func (repo repositoryImpl) generateAuthToken(ctx context.Context, userId int32) (token string, expiresAt time.Time, err error) {
+// revokeSessionIfRefreshTokenReused always returns an error, ErrRefreshTokenReused
+// if the token was already rotated, or ErrInvalidRefreshToken if it is unknown.
+func (repo repositoryImpl) revokeSessionIfRefreshTokenReused(ctx context.Context, refreshTokenHash string) error {
 	zlog := zerolog.Ctx(ctx)
-	expiresAt = time.Now().Add(AuthTokenExpDuration)
-	token, err = repo.authJWT.GenWithClaimsForUser(userId, expiresAt)
+
+	sessionId, err := repo.dataSource.GetSessionIdByUsedRefreshToken(ctx, refreshTokenHash)
+	if err != nil {
+		if errors.Is(err, apperr.ErrNoResult) {
+			return apperr.ErrInvalidRefreshToken
+		}
+		zlog.Err(err).Msg("error while checking the used refresh tokens")
+		return err
+	}
+
+	zlog.Warn().Int32("session_id", sessionId).Msg("refresh token reuse detected, revoking the session")
+
+	session, err := repo.dataSource.GetActiveSessionById(ctx, sessionId)
+	if err != nil {
+		if errors.Is(err, apperr.ErrNoResult) { // already revoked or expired
+			return apperr.ErrRefreshTokenReused
+		}
+		zlog.Err(err).Msg("error while getting the session of the reused refresh token")
+		return err
+	}
+
+	err = repo.dataSource.ExpTokenAndUnlinkFromInstallation(ctx, int(session.UsedInstallation), int(session.ID))
 	if err != nil {
-		zlog.Err(err).Msg("error while generating a new session token using jwt, for login")
-		return "", expiresAt, err
+		zlog.Err(err).Msg("error while revoking the session of the reused refresh token")
+		return err
 	}
-	return token, expiresAt, err
+
+	return apperr.ErrRefreshTokenReused
 }
 
 func (repo repositoryImpl) GetInstallationUsingToken(ctx context.Context, installationToken string, attachedToSessionId *int32) (installation Installation, err error) {
@@ -486,8 +742,8 @@ func (repo repositoryImpl) changePasswordForAllPasswordLoginIdentities(ctx conte
 	return nil
 }
 
-func (repo repositoryImpl) VerifyAuthToken(token string) (*AuthClaims, error) {
-	return repo.authJWT.VerifyTokenForUser(token)
+func (repo repositoryImpl) VerifyAccessToken(token string) (*AuthClaims, error) {
+	return repo.authJWT.VerifyAccessToken(token)
 }
 
 func (repo repositoryImpl) VerifyTokenForInstallation(token string) (*InstallationClaims, error) {
@@ -641,6 +897,7 @@ func (repo repositoryImpl) GetAllLoginIdentitiesForUser(ctx context.Context, use
 				OnEmail: func() { email = v.PasswordEmail.String },
 				OnPhone: func() { phone, err = phonenumber.Parse(v.PasswordPhone.String) },
 				OnOcid:  func() { email = v.OidcDataEmail.String },
+				OnGuest: func() {},
 			},
 		)
 		if err != nil {
@@ -681,17 +938,23 @@ func (repo repositoryImpl) LoginOrCreateUserWithOidc(
 	ipAddress netip.Addr,
 	installation Installation,
 	params LoginOrCreateUserWithOidcRepoParam,
-) (User, string, error) {
+) (User, SessionTokens, *MfaChallenge, error) {
 	zlog := zerolog.Ctx(ctx)
 
-	oidcData, err := oidc.NewOidc(params.OauthProvider).Exec(ctx, params.Code, params.CodeVerifier, params.OidcToken)
+	oidcFunc, err := oidc.NewOidc(params.OauthProvider)
+	if err != nil {
+		zlog.Err(err).Msgf("can not create the oidc action for provider: %s", params.OauthProvider.String())
+		return User{}, SessionTokens{}, nil, err
+	}
+
+	oidcData, err := oidcFunc.Exec(ctx, params.Code, params.CodeVerifier, params.OidcToken)
 	if err != nil {
 		zlog.Err(err).Msgf("error while running oidc action for provider: %s", params.OauthProvider.String())
-		return User{}, "", err
+		return User{}, SessionTokens{}, nil, err
 	}
 
 	if err = repo.checkIfOidcEmailIsUsedInNormalPasswordLoginIdentity(ctx, oidcData.OidcEmail.String); err != nil {
-		return User{}, "", err
+		return User{}, SessionTokens{}, nil, err
 	}
 
 	data := LoginOrCreateUserWithOidcData{
@@ -703,23 +966,739 @@ func (repo repositoryImpl) LoginOrCreateUserWithOidc(
 		OidcData:           oidcData,
 	}
 
-	var token string
-	dbUser, err := repo.dataSource.LoginOrCreateUserWithOidc(
+	var refreshToken string
+	var refreshTokenExpiresAt time.Time
+	var mfaChallenge *MfaChallenge
+	dbUser, sessionId, err := repo.dataSource.LoginOrCreateUserWithOidc(
 		ctx,
 		data,
-		func(userId int32) (string, time.Time, error) {
-			t, exp, err := repo.generateAuthToken(ctx, userId)
-			token = t
-			return token, exp, err
+		func(userId, loginIdentityId int32) (string, time.Time, error) {
+			challenge, err := repo.mfaChallengeIfRequired(ctx, userId, loginIdentityId, installation, ipAddress)
+			if err != nil {
+				return "", time.Time{}, err
+			}
+			if challenge != nil {
+				mfaChallenge = challenge
+				return "", time.Time{}, errSkipSessionCreation
+			}
+			t, tHash, exp, err := repo.generateRefreshToken(ctx)
+			refreshToken, refreshTokenExpiresAt = t, exp
+			return tHash, exp, err
 		},
 	)
 	if err != nil {
 		zlog.Err(err).Msg("error while runing LoginOrCreateUserWithOidc fn")
-		return User{}, "", err
+		return User{}, SessionTokens{}, nil, err
+	}
+
+	if mfaChallenge != nil {
+		return User{}, SessionTokens{}, mfaChallenge, nil
 	}
 
 	repo.updateDbUserUsername(ctx, &dbUser)
 	user := NewUserFromDatabaseUser(dbUser)
 
-	return user, token, nil
+	tokens, err := repo.generateSessionTokens(ctx, dbUser.ID, sessionId, refreshToken, refreshTokenExpiresAt)
+	if err != nil {
+		return User{}, SessionTokens{}, nil, err
+	}
+
+	return user, tokens, nil, nil
+}
+
+func (repo repositoryImpl) GuestLogin(ctx context.Context, ipAddress netip.Addr, installation Installation) (User, SessionTokens, *MfaChallenge, error) {
+	zlog := zerolog.Ctx(ctx).With().Int32("installation_id", installation.ID).Logger()
+
+	// the guest account is bound to the installation, so the same device
+	// will always get back the same guest account until it gets upgraded
+	data := LoginOrCreateGuestUserData{
+		DeviceId:       strconv.Itoa(int(installation.ID)),
+		UserUsername:   uuid.NewString(),
+		UserFirstName:  guestUserFirstName,
+		InstallationId: installation.ID,
+		IpAddress:      ipAddress,
+	}
+
+	var refreshToken string
+	var refreshTokenExpiresAt time.Time
+	var mfaChallenge *MfaChallenge
+	dbUser, sessionId, isNewUser, err := repo.dataSource.LoginOrCreateGuestUser(
+		ctx,
+		data,
+		func(userId, loginIdentityId int32) (string, time.Time, error) {
+			challenge, err := repo.mfaChallengeIfRequired(ctx, userId, loginIdentityId, installation, ipAddress)
+			if err != nil {
+				return "", time.Time{}, err
+			}
+			if challenge != nil {
+				mfaChallenge = challenge
+				return "", time.Time{}, errSkipSessionCreation
+			}
+			t, tHash, exp, err := repo.generateRefreshToken(ctx)
+			refreshToken, refreshTokenExpiresAt = t, exp
+			return tHash, exp, err
+		},
+	)
+	if err != nil {
+		if !apperr.IsAppErr(err) {
+			zlog.Err(err).Msg("error while runing LoginOrCreateGuestUser fn")
+		}
+		return User{}, SessionTokens{}, nil, err
+	}
+
+	if mfaChallenge != nil {
+		return User{}, SessionTokens{}, mfaChallenge, nil
+	}
+
+	if isNewUser {
+		repo.updateDbUserUsername(ctx, &dbUser)
+	}
+	user := NewUserFromDatabaseUser(dbUser)
+
+	tokens, err := repo.generateSessionTokens(ctx, dbUser.ID, sessionId, refreshToken, refreshTokenExpiresAt)
+	if err != nil {
+		return User{}, SessionTokens{}, nil, err
+	}
+
+	return user, tokens, nil, nil
+}
+
+func (repo repositoryImpl) checkIsGuestUser(ctx context.Context, userId int32) error {
+	_, err := repo.dataSource.GetGuestLoginIdentityForUser(ctx, userId)
+	if err != nil {
+		if errors.Is(err, apperr.ErrNoResult) {
+			return apperr.ErrNotGuestUser
+		}
+		zerolog.Ctx(ctx).Err(err).Int32("user_id", userId).Msg("error while getting the guest login identity for user")
+		return err
+	}
+	return nil
+}
+
+func (repo repositoryImpl) CreateTempPasswordUserForGuest(ctx context.Context, guestUserId int, tUser *TempPasswordUser) (*TempPasswordUser, error) {
+	userId, err := utils.SafeIntToInt32(guestUserId)
+	if err != nil {
+		return tUser, err
+	}
+
+	if err := repo.checkIsGuestUser(ctx, userId); err != nil {
+		return tUser, err
+	}
+
+	// the account gets attached to the guest user in the verify-account step
+	tUser.GuestUserId = userId
+	return repo.CreateTempPasswordUser(ctx, tUser)
+}
+
+func (repo repositoryImpl) UpgradeGuestWithOidc(ctx context.Context, guestUserId int, params LoginOrCreateUserWithOidcRepoParam) (User, error) {
+	userId, err := utils.SafeIntToInt32(guestUserId)
+	if err != nil {
+		return User{}, err
+	}
+
+	zlog := zerolog.Ctx(ctx).With().Int32("guest_user_id", userId).Logger()
+
+	if err := repo.checkIsGuestUser(ctx, userId); err != nil {
+		return User{}, err
+	}
+
+	oidcFunc, err := oidc.NewOidc(params.OauthProvider)
+	if err != nil {
+		zlog.Err(err).Msgf("can not create the oidc action for provider: %s", params.OauthProvider.String())
+		return User{}, err
+	}
+
+	oidcData, err := oidcFunc.Exec(ctx, params.Code, params.CodeVerifier, params.OidcToken)
+	if err != nil {
+		zlog.Err(err).Msgf("error while running oidc action for provider: %s", params.OauthProvider.String())
+		return User{}, err
+	}
+
+	if err = repo.checkIfOidcEmailIsUsedInNormalPasswordLoginIdentity(ctx, oidcData.OidcEmail.String); err != nil {
+		return User{}, err
+	}
+
+	data := LoginOrCreateUserWithOidcData{
+		oauthProvider:      params.OauthProvider,
+		OauthTokenIssuedAt: dbutils.ToPgTypeTimestamp(time.Now()),
+		OidcData:           oidcData,
+	}
+
+	dbUser, err := repo.dataSource.UpgradeGuestUserToOidcUser(ctx, userId, data)
+	if err != nil {
+		if !apperr.IsAppErr(err) {
+			zlog.Err(err).Msg("error while upgrading guest user to oidc user")
+		}
+		return User{}, err
+	}
+
+	return NewUserFromDatabaseUser(dbUser), nil
+}
+
+// ---------------------------------------------------------------------------------
+// MFA
+// ---------------------------------------------------------------------------------
+
+// mfaChallengeIfRequired returns nil if the user can login without MFA,
+// otherwise it stores a new challenge that must be verified to get the session token.
+func (repo repositoryImpl) mfaChallengeIfRequired(
+	ctx context.Context,
+	userId, loginIdentityId int32,
+	installation Installation,
+	ipAddress netip.Addr,
+) (*MfaChallenge, error) {
+	zlog := zerolog.Ctx(ctx).With().Int32("user_id", userId).Logger()
+
+	status, err := repo.dataSource.GetUserMfaStatus(ctx, userId)
+	if err != nil {
+		// the user is not visible outside of the login transaction
+		// when it is a newly created one, so there is no MFA for it yet
+		if errors.Is(err, apperr.ErrNoResult) {
+			return nil, nil
+		}
+		zlog.Err(err).Msg("error while getting the mfa status for user")
+		return nil, err
+	}
+
+	if !status.MfaEnforced && !status.TotpEnabled {
+		return nil, nil
+	}
+
+	challenge := MfaChallengeTmpDataStore{
+		Id:                 uuid.New(),
+		UserId:             userId,
+		LoginIdentityId:    loginIdentityId,
+		InstallationId:     installation.ID,
+		IpAddress:          ipAddress,
+		EnrollmentRequired: !status.TotpEnabled,
+	}
+	err = repo.dataSource.StoreMfaChallengeInTempCache(ctx, challenge)
+	if err != nil {
+		zlog.Err(err).Msg("error while storing the mfa challenge in temp cache")
+		return nil, err
+	}
+
+	return &MfaChallenge{Token: challenge.Id, EnrollmentRequired: challenge.EnrollmentRequired}, nil
+}
+
+func (repo repositoryImpl) getMfaChallenge(ctx context.Context, challengeId uuid.UUID, installation Installation) (*MfaChallengeTmpDataStore, error) {
+	challenge, err := repo.dataSource.GetMfaChallengeFromTempCache(ctx, challengeId)
+	if err != nil {
+		if errors.Is(err, apperr.ErrNoResult) {
+			return nil, apperr.ErrInvalidMfaChallenge
+		}
+		zerolog.Ctx(ctx).Err(err).Msg("error while getting the mfa challenge from temp cache")
+		return nil, err
+	}
+
+	// the challenge can only be completed from the installation that started the login
+	if challenge.InstallationId != installation.ID {
+		return nil, apperr.ErrInvalidMfaChallenge
+	}
+
+	return challenge, nil
+}
+
+func (repo repositoryImpl) EnrollTotpForMfaChallenge(ctx context.Context, challengeId uuid.UUID, installation Installation) (TotpEnrollment, error) {
+	challenge, err := repo.getMfaChallenge(ctx, challengeId, installation)
+	if err != nil {
+		return TotpEnrollment{}, err
+	}
+
+	if !challenge.EnrollmentRequired {
+		return TotpEnrollment{}, apperr.ErrMfaAlreadyEnabled
+	}
+
+	return repo.enrollTotp(ctx, challenge.UserId)
+}
+
+func (repo repositoryImpl) VerifyMfaChallenge(
+	ctx context.Context,
+	challengeId uuid.UUID,
+	code, recoveryCode string,
+	ipAddress netip.Addr,
+	installation Installation,
+) (user User, tokens SessionTokens, recoveryCodes []string, err error) {
+	zlog := zerolog.Ctx(ctx)
+
+	challenge, err := repo.getMfaChallenge(ctx, challengeId, installation)
+	if err != nil {
+		return User{}, SessionTokens{}, nil, err
+	}
+
+	attempts, err := repo.dataSource.IncrMfaChallengeAttempts(ctx, challenge.Id)
+	if err != nil {
+		zlog.Err(err).Msg("error while incrementing the mfa challenge attempts")
+		return User{}, SessionTokens{}, nil, err
+	}
+	if attempts > mfaMaxChallengeVerifyAttempts {
+		repo.deleteMfaChallengeFromTempCache(ctx, challenge)
+		return User{}, SessionTokens{}, nil, apperr.ErrTooManyRequests
+	}
+
+	if challenge.EnrollmentRequired {
+		// the user is setting up the TOTP as part of the login,
+		// so there are no recovery codes to use yet
+		recoveryCodes, err = repo.confirmTotp(ctx, challenge.UserId, code)
+	} else {
+		err = repo.checkMfaCode(ctx, challenge.UserId, code, recoveryCode)
+	}
+	if err != nil {
+		return User{}, SessionTokens{}, nil, err
+	}
+
+	repo.deleteMfaChallengeFromTempCache(ctx, challenge)
+
+	refreshToken, refreshTokenHash, refreshTokenExpiresAt, err := repo.generateRefreshToken(ctx)
+	if err != nil {
+		return User{}, SessionTokens{}, nil, err
+	}
+
+	sessionId, err := repo.dataSource.CreateNewSessionAndAttachUserToInstallation(ctx, challenge.LoginIdentityId, installation.ID, refreshTokenHash, ipAddress, refreshTokenExpiresAt)
+	if err != nil {
+		if !apperr.IsAppErr(err) {
+			zlog.Err(err).Msg("error creating new session for user after mfa challenge")
+		}
+		return User{}, SessionTokens{}, nil, err
+	}
+
+	user, err = repo.GetUserById(ctx, int(challenge.UserId))
+	if err != nil {
+		return User{}, SessionTokens{}, nil, err
+	}
+
+	tokens, err = repo.generateSessionTokens(ctx, challenge.UserId, sessionId, refreshToken, refreshTokenExpiresAt)
+	if err != nil {
+		return User{}, SessionTokens{}, nil, err
+	}
+
+	return user, tokens, recoveryCodes, nil
+}
+
+func (repo repositoryImpl) deleteMfaChallengeFromTempCache(ctx context.Context, challenge *MfaChallengeTmpDataStore) {
+	zlog := zerolog.Ctx(ctx)
+	// ignore any error because the challenge will be auto cleand by redis after sometime
+	if err := repo.dataSource.DeleteMfaChallengeFromTempCache(ctx, challenge.Id); err != nil {
+		zlog.Err(err).Msg("error while deleting mfa challenge form temp cache. igonoring this error")
+	}
+}
+
+func (repo repositoryImpl) EnrollTotp(ctx context.Context, userId int) (TotpEnrollment, error) {
+	id, err := utils.SafeIntToInt32(userId)
+	if err != nil {
+		return TotpEnrollment{}, err
+	}
+	return repo.enrollTotp(ctx, id)
+}
+
+func (repo repositoryImpl) enrollTotp(ctx context.Context, userId int32) (TotpEnrollment, error) {
+	zlog := zerolog.Ctx(ctx).With().Int32("user_id", userId).Logger()
+
+	dbUser, err := repo.dataSource.GetUserById(ctx, userId)
+	if err != nil {
+		if !errors.Is(err, apperr.ErrNoResult) {
+			zlog.Err(err).Msg("error geting the user by user id")
+		}
+		return TotpEnrollment{}, err
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		zlog.Err(err).Msg("error while generating totp secret")
+		return TotpEnrollment{}, err
+	}
+
+	// replaces any previous unconfirmed secret, so the user can restart the enrollment
+	ok, err := repo.dataSource.UpsertUnconfirmedTotpForUser(ctx, userId, secret)
+	if err != nil {
+		zlog.Err(err).Msg("error while storing the unconfirmed totp for user")
+		return TotpEnrollment{}, err
+	}
+	if !ok {
+		return TotpEnrollment{}, apperr.ErrMfaAlreadyEnabled
+	}
+
+	enrollment := TotpEnrollment{
+		Secret: secret,
+		URI:    totp.KeyURI(mfaIssuer, dbUser.Username, secret),
+	}
+	return enrollment, nil
+}
+
+func (repo repositoryImpl) ConfirmTotp(ctx context.Context, userId int, code string) ([]string, error) {
+	id, err := utils.SafeIntToInt32(userId)
+	if err != nil {
+		return nil, err
+	}
+	return repo.confirmTotp(ctx, id, code)
+}
+
+func (repo repositoryImpl) confirmTotp(ctx context.Context, userId int32, code string) ([]string, error) {
+	zlog := zerolog.Ctx(ctx).With().Int32("user_id", userId).Logger()
+
+	userTotp, err := repo.dataSource.GetTotpForUser(ctx, userId)
+	if err != nil {
+		if errors.Is(err, apperr.ErrNoResult) {
+			return nil, apperr.ErrMfaNotEnabled
+		}
+		zlog.Err(err).Msg("error while getting the totp for user")
+		return nil, err
+	}
+
+	if userTotp.ConfirmedAt.Valid {
+		return nil, apperr.ErrMfaAlreadyEnabled
+	}
+
+	if err := repo.validateTotpCode(ctx, userId, code, userTotp.Secret); err != nil {
+		return nil, err
+	}
+
+	recoveryCodes, recoveryCodeHashes, err := generateMfaRecoveryCodes()
+	if err != nil {
+		zlog.Err(err).Msg("error while generating mfa recovery codes")
+		return nil, err
+	}
+
+	err = repo.dataSource.ConfirmTotpAndReplaceRecoveryCodesForUser(ctx, userId, recoveryCodeHashes)
+	if err != nil {
+		zlog.Err(err).Msg("error while confirming the totp for user")
+		return nil, err
+	}
+
+	return recoveryCodes, nil
+}
+
+func (repo repositoryImpl) RegenerateRecoveryCodes(ctx context.Context, userId int, code string) ([]string, error) {
+	id, err := utils.SafeIntToInt32(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	zlog := zerolog.Ctx(ctx).With().Int32("user_id", id).Logger()
+
+	// only the TOTP is accepted here, the recovery codes are about to be replaced
+	if err := repo.checkMfaCode(ctx, id, code, ""); err != nil {
+		return nil, err
+	}
+
+	recoveryCodes, recoveryCodeHashes, err := generateMfaRecoveryCodes()
+	if err != nil {
+		zlog.Err(err).Msg("error while generating mfa recovery codes")
+		return nil, err
+	}
+
+	err = repo.dataSource.ReplaceRecoveryCodesForUser(ctx, id, recoveryCodeHashes)
+	if err != nil {
+		zlog.Err(err).Msg("error while replacing the mfa recovery codes for user")
+		return nil, err
+	}
+
+	return recoveryCodes, nil
+}
+
+func (repo repositoryImpl) DisableTotp(ctx context.Context, userId int, code string) error {
+	id, err := utils.SafeIntToInt32(userId)
+	if err != nil {
+		return err
+	}
+
+	zlog := zerolog.Ctx(ctx).With().Int32("user_id", id).Logger()
+
+	status, err := repo.dataSource.GetUserMfaStatus(ctx, id)
+	if err != nil {
+		if !errors.Is(err, apperr.ErrNoResult) {
+			zlog.Err(err).Msg("error while getting the mfa status for user")
+		}
+		return err
+	}
+	if status.MfaEnforced {
+		return apperr.ErrMfaEnforced
+	}
+
+	if err := repo.checkMfaCode(ctx, id, code, ""); err != nil {
+		return err
+	}
+
+	err = repo.dataSource.DeleteTotpAndRecoveryCodesForUser(ctx, id)
+	if err != nil {
+		zlog.Err(err).Msg("error while deleting the totp and recovery codes for user")
+		return err
+	}
+
+	return nil
+}
+
+func (repo repositoryImpl) SetMfaEnforcedForUser(ctx context.Context, userId int, enforced bool) error {
+	id, err := utils.SafeIntToInt32(userId)
+	if err != nil {
+		return err
+	}
+
+	err = repo.dataSource.SetMfaEnforcedForUser(ctx, id, enforced)
+	if err != nil {
+		if !errors.Is(err, apperr.ErrNoResult) {
+			zerolog.Ctx(ctx).Err(err).Int32("user_id", id).Msg("error while setting mfa enforced for user")
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (repo repositoryImpl) SearchUsers(ctx context.Context, params SearchUsersParams, offset, limit int) ([]UserForAdmin, error) {
+	offset32, err := utils.SafeIntToInt32(offset)
+	if err != nil {
+		return nil, err
+	}
+	limit32, err := utils.SafeIntToInt32(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	dbUsers, err := repo.dataSource.SearchUsers(ctx, params, offset32, limit32)
+	if err != nil {
+		zerolog.Ctx(ctx).Err(err).Msg("error while searching the users")
+		return nil, err
+	}
+
+	users := make([]UserForAdmin, len(dbUsers))
+	for i, u := range dbUsers {
+		users[i] = NewUserForAdminFromSearchRow(u)
+	}
+
+	return users, nil
+}
+
+func (repo repositoryImpl) GetUserForAdmin(ctx context.Context, userId int) (UserForAdmin, error) {
+	id, err := utils.SafeIntToInt32(userId)
+	if err != nil {
+		return UserForAdmin{}, err
+	}
+
+	dbUser, err := repo.dataSource.GetUserForAdmin(ctx, id)
+	if err != nil {
+		if !errors.Is(err, apperr.ErrNoResult) {
+			zerolog.Ctx(ctx).Err(err).Int32("user_id", id).Msg("error while getting the user for admin")
+		}
+		return UserForAdmin{}, err
+	}
+
+	return NewUserForAdminFromDatabaseRow(dbUser), nil
+}
+
+func (repo repositoryImpl) BlockUser(ctx context.Context, adminUserId int, adminRole string, userId int, reason string, blockedUntil *time.Time) error {
+	id, err := utils.SafeIntToInt32(userId)
+	if err != nil {
+		return err
+	}
+	adminId, err := utils.SafeIntToInt32(adminUserId)
+	if err != nil {
+		return err
+	}
+
+	var until pgtype.Timestamptz
+	if blockedUntil != nil {
+		if !blockedUntil.After(time.Now()) {
+			return apperr.ErrInvalidBlockedUntil
+		}
+		until = dbutils.ToPgTypeTimestamptz(*blockedUntil)
+	}
+
+	if err := repo.checkCanManageUser(ctx, adminUserId, adminRole, userId); err != nil {
+		return err
+	}
+
+	zlog := zerolog.Ctx(ctx).With().Int32("blocked_user_id", id).Logger()
+
+	err = repo.dataSource.BlockUser(ctx, id, adminId, reason, until)
+	if err != nil {
+		if !errors.Is(err, apperr.ErrNoResult) {
+			zlog.Err(err).Msg("error while blocking the user")
+		}
+		return err
+	}
+
+	// the auth middleware rejects the blocked users anyway, but
+	// their sessions should not come back after the block ends
+	err = repo.dataSource.ExpAllTokensAndUnlinkThemFromInstallation(ctx, userId)
+	if err != nil {
+		zlog.Err(err).Msg("error while revoking the sessions of the blocked user")
+		return err
+	}
+
+	return nil
+}
+
+func (repo repositoryImpl) UnblockUser(ctx context.Context, userId int) error {
+	id, err := utils.SafeIntToInt32(userId)
+	if err != nil {
+		return err
+	}
+
+	err = repo.dataSource.UnblockUser(ctx, id)
+	if err != nil {
+		if !errors.Is(err, apperr.ErrNoResult) {
+			zerolog.Ctx(ctx).Err(err).Int32("user_id", id).Msg("error while unblocking the user")
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (repo repositoryImpl) SetRoleForUser(ctx context.Context, adminUserId int, adminRole string, userId int, roleName string) error {
+	id, err := utils.SafeIntToInt32(userId)
+	if err != nil {
+		return err
+	}
+
+	// it also tells a missing user from a missing role
+	if err := repo.checkCanManageUser(ctx, adminUserId, adminRole, userId, roleName); err != nil {
+		return err
+	}
+
+	ok, err := repo.dataSource.SetRoleForUser(ctx, id, dbutils.ToPgTypeText(roleName))
+	if err != nil {
+		zerolog.Ctx(ctx).Err(err).Int32("user_id", id).Msg("error while setting the role for user")
+		return err
+	}
+	if !ok {
+		return apperr.ErrInvalidRole
+	}
+
+	return nil
+}
+
+func (repo repositoryImpl) SoftDeleteUser(ctx context.Context, adminUserId int, adminRole string, userId int) error {
+	id, err := utils.SafeIntToInt32(userId)
+	if err != nil {
+		return err
+	}
+
+	if err := repo.checkCanManageUser(ctx, adminUserId, adminRole, userId); err != nil {
+		return err
+	}
+
+	zlog := zerolog.Ctx(ctx).With().Int32("deleted_user_id", id).Logger()
+
+	err = repo.dataSource.SoftDeleteUser(ctx, id)
+	if err != nil {
+		if !errors.Is(err, apperr.ErrNoResult) {
+			zlog.Err(err).Msg("error while soft deleting the user")
+		}
+		return err
+	}
+
+	err = repo.dataSource.ExpAllTokensAndUnlinkThemFromInstallation(ctx, userId)
+	if err != nil {
+		zlog.Err(err).Msg("error while revoking the sessions of the deleted user")
+		return err
+	}
+
+	return nil
+}
+
+// checkCanManageUser stops the admins from acting on their own account, or on the users with
+// permissions they do not have. The roles are the other roles that the action gives to the user
+func (repo repositoryImpl) checkCanManageUser(ctx context.Context, adminUserId int, adminRole string, userId int, roles ...string) error {
+	if adminUserId == userId {
+		return apperr.ErrCannotManageOwnAccount
+	}
+
+	user, err := repo.GetUserForAdmin(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	err = repo.permRepo.CheckCanManageRoles(ctx, adminRole, append(roles, user.RoleName.String)...)
+	if err != nil && !errors.Is(err, apperr.ErrCannotManageHigherRole) {
+		zerolog.Ctx(ctx).Err(err).Int("user_id", userId).Msg("error while checking the roles that the admin can manage")
+	}
+	return err
+}
+
+// checkMfaCode accepts either the TOTP code or one of the unused recovery codes.
+func (repo repositoryImpl) checkMfaCode(ctx context.Context, userId int32, code, recoveryCode string) error {
+	zlog := zerolog.Ctx(ctx).With().Int32("user_id", userId).Logger()
+
+	userTotp, err := repo.dataSource.GetTotpForUser(ctx, userId)
+	if err != nil {
+		if errors.Is(err, apperr.ErrNoResult) {
+			return apperr.ErrMfaNotEnabled
+		}
+		zlog.Err(err).Msg("error while getting the totp for user")
+		return err
+	}
+	if !userTotp.ConfirmedAt.Valid {
+		return apperr.ErrMfaNotEnabled
+	}
+
+	if recoveryCode != "" {
+		ok, err := repo.dataSource.UseRecoveryCodeForUser(ctx, userId, hashMfaRecoveryCode(recoveryCode))
+		if err != nil {
+			zlog.Err(err).Msg("error while using mfa recovery code for user")
+			return err
+		}
+		if !ok {
+			return apperr.ErrInvalidMfaCode
+		}
+		return nil
+	}
+
+	return repo.validateTotpCode(ctx, userId, code, userTotp.Secret)
+}
+
+// validateTotpCode accepts every code only once, a code of the same or an earlier time step
+// than the last accepted one is rejected
+func (repo repositoryImpl) validateTotpCode(ctx context.Context, userId int32, code, secret string) error {
+	step, ok, err := totp.ValidateStep(code, secret)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return apperr.ErrInvalidMfaCode
+	}
+
+	ok, err = repo.dataSource.UseTotpStepForUser(ctx, userId, step)
+	if err != nil {
+		zerolog.Ctx(ctx).Err(err).Int32("user_id", userId).Msg("error while using the totp step for user")
+		return err
+	}
+	if !ok {
+		return apperr.ErrInvalidMfaCode
+	}
+	return nil
+}
+
+const mfaRecoveryCodeChars = "abcdefghjkmnpqrstuvwxyz23456789"
+
+// generateMfaRecoveryCodes returns the plain codes to show to the user once,
+// and their hashes to be stored in the database.
+func generateMfaRecoveryCodes() (codes []string, hashes []string, err error) {
+	codes = make([]string, mfaRecoveryCodesCount)
+	hashes = make([]string, mfaRecoveryCodesCount)
+
+	for i := range mfaRecoveryCodesCount {
+		var sb strings.Builder
+		for j := range 10 {
+			if j == 5 {
+				sb.WriteByte('-')
+			}
+			n, err := rand.Int(rand.Reader, big.NewInt(int64(len(mfaRecoveryCodeChars))))
+			if err != nil {
+				return nil, nil, err
+			}
+			sb.WriteByte(mfaRecoveryCodeChars[n.Int64()])
+		}
+		codes[i] = sb.String()
+		hashes[i] = hashMfaRecoveryCode(codes[i])
+	}
+
+	return codes, hashes, nil
+}
+
+func hashMfaRecoveryCode(code string) string {
+	code = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
 }
@@ -0,0 +1,121 @@
+//go:build integration
+
+// The package needs the google client secret file and the jwt keys of the .env, so the
+// tests are behind a build tag:
+//
+//	go test -tags integration -run CanManage ./internal/feat/auth
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/Nidal-Bakir/go-todo-backend/internal/apperr"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/database/database_queries"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/perm"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/perm/baseperm"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// the unused methods of the embedded interfaces panic
+type fakeAdminDataSource struct {
+	DataSource
+	user    database_queries.UsersGetUserForAdminRow
+	changed bool
+}
+
+func (ds *fakeAdminDataSource) GetUserForAdmin(ctx context.Context, userId int32) (database_queries.UsersGetUserForAdminRow, error) {
+	return ds.user, nil
+}
+
+func (ds *fakeAdminDataSource) SetRoleForUser(ctx context.Context, userId int32, roleName pgtype.Text) (bool, error) {
+	ds.changed = true
+	return true, nil
+}
+
+func (ds *fakeAdminDataSource) SoftDeleteUser(ctx context.Context, userId int32) error {
+	ds.changed = true
+	return nil
+}
+
+// fakePermRepo only lets the callers manage the roles in manageable
+type fakePermRepo struct {
+	perm.Repository
+	manageable []string
+	checked    []string
+}
+
+func (r *fakePermRepo) CheckCanManageRoles(ctx context.Context, callerRole string, roles ...string) error {
+	r.checked = append(r.checked, roles...)
+	for _, role := range roles {
+		if !slices.Contains(r.manageable, role) {
+			return apperr.ErrCannotManageHigherRole
+		}
+	}
+	return nil
+}
+
+func TestSetRoleForUserRejectsEscalation(t *testing.T) {
+	ctx := context.Background()
+	const adminId, userId = 1, 2
+
+	tests := []struct {
+		name     string
+		userRole string
+		newRole  string
+		wantErr  error
+	}{
+		{
+			name:     "giving the admin role to another account",
+			userRole: "editor",
+			newRole:  baseperm.BaseRollAdmin,
+			wantErr:  apperr.ErrCannotManageHigherRole,
+		},
+		{
+			name:     "demoting a user with more permissions",
+			userRole: baseperm.BaseRollAdmin,
+			newRole:  "editor",
+			wantErr:  apperr.ErrCannotManageHigherRole,
+		},
+		{
+			name:     "a role that the caller can manage",
+			userRole: "editor",
+			newRole:  "moderator",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ds := &fakeAdminDataSource{user: database_queries.UsersGetUserForAdminRow{ID: userId, RoleName: pgtype.Text{String: tt.userRole, Valid: true}}}
+			permRepo := &fakePermRepo{manageable: []string{"editor", "moderator"}}
+			repo := repositoryImpl{dataSource: ds, permRepo: permRepo}
+
+			err := repo.SetRoleForUser(ctx, adminId, "moderator", userId, tt.newRole)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+			if ds.changed != (tt.wantErr == nil) {
+				t.Errorf("the role is changed: %v", ds.changed)
+			}
+			if !slices.Contains(permRepo.checked, tt.userRole) || !slices.Contains(permRepo.checked, tt.newRole) {
+				t.Errorf("checked the roles %v, want the current and the new role", permRepo.checked)
+			}
+		})
+	}
+}
+
+func TestSoftDeleteUserRejectsHigherRole(t *testing.T) {
+	ds := &fakeAdminDataSource{user: database_queries.UsersGetUserForAdminRow{ID: 2, RoleName: pgtype.Text{String: baseperm.BaseRollAdmin, Valid: true}}}
+	repo := repositoryImpl{dataSource: ds, permRepo: &fakePermRepo{manageable: []string{"editor"}}}
+
+	err := repo.SoftDeleteUser(context.Background(), 1, "editor", 2)
+	if !errors.Is(err, apperr.ErrCannotManageHigherRole) {
+		t.Fatalf("got %v, want ErrCannotManageHigherRole", err)
+	}
+	if ds.changed {
+		t.Error("the admin is deleted by a user with less permissions")
+	}
+}
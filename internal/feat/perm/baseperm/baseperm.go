@@ -10,3 +10,41 @@ const (
 	BasePermWriteAppSettings  = "write_app_settings"
 	BasePermDeleteAppSettings = "delete_app_settings"
 )
+
+const (
+	BasePermEnforceUserMfa = "enforce_user_mfa"
+)
+
+const (
+	BasePermReadUsers       = "read_users"
+	BasePermBlockUsers      = "block_users"
+	BasePermChangeUsersRole = "change_users_role"
+	BasePermDeleteUsers     = "delete_users"
+)
+
+const (
+	BasePermReadRoles   = "read_roles"
+	BasePermWriteRoles  = "write_roles"
+	BasePermDeleteRoles = "delete_roles"
+)
+
+// the base roles and permissions are used by the code, so they can not be deleted at runtime
+
+func IsBaseRole(role string) bool {
+	switch role {
+	case BaseRollAdmin, BaseRollSystem:
+		return true
+	}
+	return false
+}
+
+func IsBasePermission(permission string) bool {
+	switch permission {
+	case BasePermReadAppSettings, BasePermWriteAppSettings, BasePermDeleteAppSettings,
+		BasePermEnforceUserMfa,
+		BasePermReadUsers, BasePermBlockUsers, BasePermChangeUsersRole, BasePermDeleteUsers,
+		BasePermReadRoles, BasePermWriteRoles, BasePermDeleteRoles:
+		return true
+	}
+	return false
+}
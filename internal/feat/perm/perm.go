@@ -3,16 +3,43 @@ package perm
 import (
 	"context"
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/Nidal-Bakir/go-todo-backend/internal/apperr"
 	"github.com/Nidal-Bakir/go-todo-backend/internal/database"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/database/database_queries"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/perm/baseperm"
+	dbutils "github.com/Nidal-Bakir/go-todo-backend/internal/utils/db_utils"
 	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
 )
 
+const expirationForRolePermsCache = time.Hour
+
 type Repository interface {
 	HasPermission(ctx context.Context, role string, requestedPermissions ...string) (bool, error)
 	HasPermissionErr(ctx context.Context, role string, requestedPermissions ...string) error
+
+	GetAllRoles(ctx context.Context) ([]database_queries.PermGetAllRolesRow, error)
+	GetAllPermissions(ctx context.Context) ([]database_queries.PermGetAllPermissionsRow, error)
+	GetPermissionsOfRole(ctx context.Context, role string) ([]string, error)
+
+	CreateRole(ctx context.Context, role string) (database_queries.Role, error)
+	CreatePermission(ctx context.Context, permission string) (database_queries.Permission, error)
+
+	// AttachPermissionToRole and DetachPermissionFromRole can not change the permissions of the
+	// callerRole or the base roles, and the caller can only grant the permissions it has
+	AttachPermissionToRole(ctx context.Context, callerRole, role, permission string) error
+	DetachPermissionFromRole(ctx context.Context, callerRole, role, permission string) error
+
+	// CheckCanManageRoles stops the callers from acting on the users of the roles with
+	// permissions they do not have, e.g. giving the admin role to another account they control.
+	// Only the admins can manage the base roles, the empty role (no role) has no permissions
+	CheckCanManageRoles(ctx context.Context, callerRole string, roles ...string) error
+
+	SoftDeleteRole(ctx context.Context, role string) error
+	SoftDeletePermission(ctx context.Context, permission string) error
 }
 
 func NewRepository(db *database.Service, redis *redis.Client) Repository {
@@ -41,26 +68,407 @@ func (r *repositoryImpl) HasPermissionErr(ctx context.Context, role string, requ
 	if role == "" {
 		return apperr.ErrPermissionDenied
 	}
-	zlog := zerolog.Ctx(ctx)
 
-	dbResult, err := r.db.Queries.PermGetRoleWithItsPermissions(ctx, role)
+	rolePerms, err := r.loadRolePerms(ctx, role)
 	if err != nil {
-		zlog.Err(err).
-			Str("role", role).
-			Msg("failed to load permissions for role")
 		return err
 	}
 
+	for _, requested := range requestedPermissions {
+		if _, ok := rolePerms[requested]; !ok {
+			return apperr.ErrPermissionDenied
+		}
+	}
+
+	return nil
+}
+
+func (r *repositoryImpl) loadRolePerms(ctx context.Context, role string) (map[string]struct{}, error) {
+	zlog := zerolog.Ctx(ctx).With().Str("role", role).Logger()
+
+	if rolePerms := r.readRolePermsFromCache(ctx, role, zlog); rolePerms != nil {
+		return rolePerms, nil
+	}
+
+	// read before the database, see genRolePermsCacheVersionKey
+	cacheVersion, err := r.getRolePermsCacheVersion(ctx, role)
+	if err != nil {
+		zlog.Err(err).Msg("could not read the role permissions cache version from redis")
+	}
+	canCache := err == nil
+
+	dbResult, err := r.db.Queries.PermGetRoleWithItsPermissions(ctx, role)
+	if err != nil {
+		zlog.Err(err).Msg("failed to load permissions for role")
+		return nil, err
+	}
+
 	rolePerms := make(map[string]struct{}, len(dbResult))
 	for _, res := range dbResult {
 		rolePerms[res.PermissionName] = struct{}{}
 	}
 
-	for _, requested := range requestedPermissions {
-		if _, ok := rolePerms[requested]; !ok {
-			return apperr.ErrPermissionDenied
+	if canCache {
+		r.addRolePermsToCache(ctx, role, rolePerms, cacheVersion, zlog)
+	}
+
+	return rolePerms, nil
+}
+
+func genRolePermsCacheKey(role string) string {
+	return fmt.Sprint("perm:role:", role)
+}
+
+// incremented on every invalidation of the role permissions. The permissions read from the
+// database while an invalidation happened can be stale (e.g. a detached permission), so they
+// are stored only if the version did not change
+func genRolePermsCacheVersionKey(role string) string {
+	return fmt.Sprint("perm:role:version:", role)
+}
+
+func (r *repositoryImpl) getRolePermsCacheVersion(ctx context.Context, role string) (int64, error) {
+	version, err := r.redis.Get(ctx, genRolePermsCacheVersionKey(role)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return version, err
+}
+
+// the permission names can not be empty (db check), so the empty member marks
+// the set as cached even when the role has no permissions at all
+const emptyRolePermsMarker = ""
+
+func (r *repositoryImpl) readRolePermsFromCache(ctx context.Context, role string, zlog zerolog.Logger) map[string]struct{} {
+	members, err := r.redis.SMembers(ctx, genRolePermsCacheKey(role)).Result()
+	if err != nil {
+		zlog.Err(err).Msg("could not read the role permissions from redis")
+		return nil
+	}
+	if len(members) == 0 {
+		return nil
+	}
+
+	rolePerms := make(map[string]struct{}, len(members))
+	for _, m := range members {
+		if m != emptyRolePermsMarker {
+			rolePerms[m] = struct{}{}
+		}
+	}
+	return rolePerms
+}
+
+func (r *repositoryImpl) addRolePermsToCache(ctx context.Context, role string, rolePerms map[string]struct{}, cacheVersion int64, zlog zerolog.Logger) {
+	key := genRolePermsCacheKey(role)
+	versionKey := genRolePermsCacheVersionKey(role)
+
+	members := make([]any, 0, len(rolePerms)+1)
+	members = append(members, emptyRolePermsMarker)
+	for p := range rolePerms {
+		members = append(members, p)
+	}
+
+	err := r.redis.Watch(
+		ctx,
+		func(tx *redis.Tx) error {
+			version, err := tx.Get(ctx, versionKey).Int64()
+			if err != nil && !errors.Is(err, redis.Nil) {
+				return err
+			}
+			if version != cacheVersion {
+				// invalidated after reading the permissions from the database
+				return nil
+			}
+
+			_, err = tx.TxPipelined(ctx, func(pip redis.Pipeliner) error {
+				pip.Del(ctx, key)
+				pip.SAdd(ctx, key, members...)
+				pip.Expire(ctx, key, expirationForRolePermsCache)
+				return nil
+			})
+			return err
+		},
+		versionKey,
+	)
+
+	// invalidated between the version check and the store
+	if err != nil && !errors.Is(err, redis.TxFailedErr) {
+		zlog.Err(err).Msg("can not set the role permissions in redis")
+	}
+}
+
+func (r *repositoryImpl) invalidateRolePermsCache(ctx context.Context, roles ...string) {
+	if len(roles) == 0 {
+		return
+	}
+
+	pip := r.redis.TxPipeline()
+	for _, role := range roles {
+		pip.Incr(ctx, genRolePermsCacheVersionKey(role))
+		pip.Del(ctx, genRolePermsCacheKey(role))
+	}
+
+	if _, err := pip.Exec(ctx); err != nil {
+		zerolog.Ctx(ctx).Err(err).Strs("roles", roles).Msg("could not delete the role permissions from cache")
+	}
+}
+
+func (r *repositoryImpl) GetAllRoles(ctx context.Context) ([]database_queries.PermGetAllRolesRow, error) {
+	roles, err := r.db.Queries.PermGetAllRoles(ctx)
+	if err != nil {
+		zerolog.Ctx(ctx).Err(err).Msg("can not get the roles")
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (r *repositoryImpl) GetAllPermissions(ctx context.Context) ([]database_queries.PermGetAllPermissionsRow, error) {
+	permissions, err := r.db.Queries.PermGetAllPermissions(ctx)
+	if err != nil {
+		zerolog.Ctx(ctx).Err(err).Msg("can not get the permissions")
+		return nil, err
+	}
+	return permissions, nil
+}
+
+func (r *repositoryImpl) GetPermissionsOfRole(ctx context.Context, role string) ([]string, error) {
+	if err := r.checkRoleExists(ctx, role); err != nil {
+		return nil, err
+	}
+
+	dbResult, err := r.db.Queries.PermGetRoleWithItsPermissions(ctx, role)
+	if err != nil {
+		zerolog.Ctx(ctx).Err(err).Str("role", role).Msg("failed to load permissions for role")
+		return nil, err
+	}
+
+	permissions := make([]string, len(dbResult))
+	for i, res := range dbResult {
+		permissions[i] = res.PermissionName
+	}
+	return permissions, nil
+}
+
+func (r *repositoryImpl) checkRoleExists(ctx context.Context, role string) error {
+	_, err := r.db.Queries.PermGetRoleByName(ctx, role)
+	if err != nil {
+		if dbutils.IsErrPgxNoRows(err) {
+			return apperr.ErrNoResult
 		}
+		zerolog.Ctx(ctx).Err(err).Str("role", role).Msg("can not get the role")
+		return err
 	}
+	return nil
+}
 
+func (r *repositoryImpl) checkPermissionExists(ctx context.Context, permission string) error {
+	_, err := r.db.Queries.PermGetPermissionByName(ctx, permission)
+	if err != nil {
+		if dbutils.IsErrPgxNoRows(err) {
+			return apperr.ErrNoResult
+		}
+		zerolog.Ctx(ctx).Err(err).Str("permission", permission).Msg("can not get the permission")
+		return err
+	}
 	return nil
 }
+
+func (r *repositoryImpl) CreateRole(ctx context.Context, role string) (database_queries.Role, error) {
+	// restores the role if it was soft deleted
+	newRole, err := r.db.Queries.PermCreateNewRole(ctx, role)
+	if err != nil {
+		if dbutils.IsErrPgxNoRows(err) {
+			return newRole, apperr.ErrRoleAlreadyExists
+		}
+		zerolog.Ctx(ctx).Err(err).Str("role", role).Msg("can not create the role")
+		return newRole, err
+	}
+
+	r.invalidateRolePermsCache(ctx, role)
+
+	return newRole, nil
+}
+
+func (r *repositoryImpl) CreatePermission(ctx context.Context, permission string) (database_queries.Permission, error) {
+	// restores the permission if it was soft deleted
+	newPermission, err := r.db.Queries.PermCreateNewPermission(ctx, permission)
+	if err != nil {
+		if dbutils.IsErrPgxNoRows(err) {
+			return newPermission, apperr.ErrPermissionAlreadyExists
+		}
+		zerolog.Ctx(ctx).Err(err).Str("permission", permission).Msg("can not create the permission")
+		return newPermission, err
+	}
+	return newPermission, nil
+}
+
+// checkCanChangeRolePermissions stops the callers from granting themselves
+// more permissions, directly or through the admin role
+func checkCanChangeRolePermissions(callerRole, role string) error {
+	if role == callerRole || baseperm.IsBaseRole(role) {
+		return apperr.ErrCannotChangeOwnOrBaseRolePermissions
+	}
+	return nil
+}
+
+func (r *repositoryImpl) AttachPermissionToRole(ctx context.Context, callerRole, role, permission string) error {
+	if err := checkCanChangeRolePermissions(callerRole, role); err != nil {
+		return err
+	}
+	if err := r.checkRoleExists(ctx, role); err != nil {
+		return err
+	}
+	if err := r.checkPermissionExists(ctx, permission); err != nil {
+		return err
+	}
+	// the caller can not grant a permission it does not have
+	if err := r.HasPermissionErr(ctx, callerRole, permission); err != nil {
+		return err
+	}
+
+	err := r.db.Queries.PermAddPermissionToRole(
+		ctx,
+		database_queries.PermAddPermissionToRoleParams{
+			RoleName:       role,
+			PermissionName: permission,
+		},
+	)
+	if err != nil {
+		zerolog.Ctx(ctx).Err(err).Str("role", role).Str("permission", permission).Msg("can not attach the permission to the role")
+		return err
+	}
+
+	r.invalidateRolePermsCache(ctx, role)
+
+	return nil
+}
+
+func (r *repositoryImpl) DetachPermissionFromRole(ctx context.Context, callerRole, role, permission string) error {
+	if err := checkCanChangeRolePermissions(callerRole, role); err != nil {
+		return err
+	}
+
+	rowsAffected, err := r.db.Queries.PermRemovePermissionFromRole(
+		ctx,
+		database_queries.PermRemovePermissionFromRoleParams{
+			RoleName:       role,
+			PermissionName: permission,
+		},
+	)
+	if err != nil {
+		zerolog.Ctx(ctx).Err(err).Str("role", role).Str("permission", permission).Msg("can not detach the permission from the role")
+		return err
+	}
+	if rowsAffected == 0 {
+		return apperr.ErrNoResult
+	}
+
+	r.invalidateRolePermsCache(ctx, role)
+
+	return nil
+}
+
+func (r *repositoryImpl) CheckCanManageRoles(ctx context.Context, callerRole string, roles ...string) error {
+	if callerRole == "" {
+		return apperr.ErrCannotManageHigherRole
+	}
+
+	callerPerms, err := r.loadRolePerms(ctx, callerRole)
+	if err != nil {
+		return err
+	}
+
+	for _, role := range roles {
+		if role == "" {
+			continue
+		}
+		rolePerms, err := r.loadRolePerms(ctx, role)
+		if err != nil {
+			return err
+		}
+		if err := checkCanManageRole(callerRole, callerPerms, role, rolePerms); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func checkCanManageRole(callerRole string, callerPerms map[string]struct{}, role string, rolePerms map[string]struct{}) error {
+	if baseperm.IsBaseRole(role) && callerRole != baseperm.BaseRollAdmin {
+		return apperr.ErrCannotManageHigherRole
+	}
+	for p := range rolePerms {
+		if _, ok := callerPerms[p]; !ok {
+			return apperr.ErrCannotManageHigherRole
+		}
+	}
+	return nil
+}
+
+func (r *repositoryImpl) SoftDeleteRole(ctx context.Context, role string) error {
+	if baseperm.IsBaseRole(role) {
+		return apperr.ErrCannotDeleteBaseRoleOrPerm
+	}
+
+	err := r.usingTransaction(ctx, func(queries *database_queries.Queries) error {
+		rowsAffected, err := queries.PermSoftDeleteRole(ctx, role)
+		if err != nil {
+			return err
+		}
+		if rowsAffected == 0 {
+			return apperr.ErrNoResult
+		}
+
+		// a restored role should start clean
+		err = queries.PermRemoveAllPermissionsFromRole(ctx, role)
+		if err != nil {
+			return err
+		}
+
+		return queries.PermUnsetRoleForAllUsers(ctx, dbutils.ToPgTypeText(role))
+	})
+	if err != nil {
+		if !apperr.IsAppErr(err) {
+			zerolog.Ctx(ctx).Err(err).Str("role", role).Msg("can not delete the role")
+		}
+		return err
+	}
+
+	r.invalidateRolePermsCache(ctx, role)
+
+	return nil
+}
+
+func (r *repositoryImpl) SoftDeletePermission(ctx context.Context, permission string) error {
+	if baseperm.IsBasePermission(permission) {
+		return apperr.ErrCannotDeleteBaseRoleOrPerm
+	}
+
+	var affectedRoles []string
+	err := r.usingTransaction(ctx, func(queries *database_queries.Queries) error {
+		rowsAffected, err := queries.PermSoftDeletePermission(ctx, permission)
+		if err != nil {
+			return err
+		}
+		if rowsAffected == 0 {
+			return apperr.ErrNoResult
+		}
+
+		affectedRoles, err = queries.PermRemovePermissionFromAllRoles(ctx, permission)
+		return err
+	})
+	if err != nil {
+		if !apperr.IsAppErr(err) {
+			zerolog.Ctx(ctx).Err(err).Str("permission", permission).Msg("can not delete the permission")
+		}
+		return err
+	}
+
+	r.invalidateRolePermsCache(ctx, affectedRoles...)
+
+	return nil
+}
+
+func (r *repositoryImpl) usingTransaction(ctx context.Context, fn func(queries *database_queries.Queries) error) error {
+	return dbutils.UsingTransaction(ctx, r.db.ConnPool, r.db.Queries, fn)
+}
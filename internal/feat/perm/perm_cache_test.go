@@ -0,0 +1,70 @@
+//go:build integration
+
+// The tests run against the redis of the .env:
+//
+//	go test -tags integration -run RolePermsCache ./internal/feat/perm
+
+package perm
+
+import (
+	"context"
+	"testing"
+
+	redisdb "github.com/Nidal-Bakir/go-todo-backend/internal/redis_db"
+	_ "github.com/Nidal-Bakir/go-todo-backend/testing_init"
+	"github.com/rs/zerolog"
+)
+
+// the cache tests do not read the database
+func newTestCacheRepo(t *testing.T, role string) *repositoryImpl {
+	ctx := context.Background()
+	r := &repositoryImpl{redis: redisdb.NewRedisClient(ctx)}
+
+	t.Cleanup(func() {
+		r.redis.Del(ctx, genRolePermsCacheKey(role), genRolePermsCacheVersionKey(role))
+	})
+	return r
+}
+
+func TestRolePermsCacheFill(t *testing.T) {
+	ctx := context.Background()
+	const role = "test_role_perms_cache_fill"
+	r := newTestCacheRepo(t, role)
+
+	version, err := r.getRolePermsCacheVersion(ctx, role)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.addRolePermsToCache(ctx, role, map[string]struct{}{"read_users": {}}, version, zerolog.Nop())
+
+	rolePerms := r.readRolePermsFromCache(ctx, role, zerolog.Nop())
+	if _, ok := rolePerms["read_users"]; !ok || len(rolePerms) != 1 {
+		t.Fatalf("cached %v, want the read_users permission", rolePerms)
+	}
+
+	r.invalidateRolePermsCache(ctx, role)
+	if rolePerms := r.readRolePermsFromCache(ctx, role, zerolog.Nop()); rolePerms != nil {
+		t.Errorf("cached %v after the invalidation, want nothing", rolePerms)
+	}
+}
+
+func TestRolePermsCacheFillAfterInvalidation(t *testing.T) {
+	ctx := context.Background()
+	const role = "test_role_perms_cache_invalidation"
+	r := newTestCacheRepo(t, role)
+
+	// a request reads the version and the permissions from the database...
+	version, err := r.getRolePermsCacheVersion(ctx, role)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stalePerms := map[string]struct{}{"delete_users": {}}
+
+	// ...while another request detaches the permission from the role
+	r.invalidateRolePermsCache(ctx, role)
+
+	r.addRolePermsToCache(ctx, role, stalePerms, version, zerolog.Nop())
+	if rolePerms := r.readRolePermsFromCache(ctx, role, zerolog.Nop()); rolePerms != nil {
+		t.Fatalf("cached %v, the permissions read before the invalidation should not be cached", rolePerms)
+	}
+}
@@ -0,0 +1,89 @@
+package perm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Nidal-Bakir/go-todo-backend/internal/apperr"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/perm/baseperm"
+)
+
+func TestCheckCanManageRole(t *testing.T) {
+	perms := func(names ...string) map[string]struct{} {
+		m := make(map[string]struct{}, len(names))
+		for _, n := range names {
+			m[n] = struct{}{}
+		}
+		return m
+	}
+
+	moderatorPerms := perms(baseperm.BasePermReadUsers, baseperm.BasePermChangeUsersRole)
+
+	tests := []struct {
+		name        string
+		callerRole  string
+		callerPerms map[string]struct{}
+		role        string
+		rolePerms   map[string]struct{}
+		wantErr     bool
+	}{
+		{
+			name:        "giving the admin role to another account is rejected",
+			callerRole:  "moderator",
+			callerPerms: moderatorPerms,
+			role:        baseperm.BaseRollAdmin,
+			rolePerms:   perms(baseperm.BasePermReadUsers, baseperm.BasePermChangeUsersRole, baseperm.BasePermWriteRoles),
+			wantErr:     true,
+		},
+		{
+			name:        "a base role is rejected even without more permissions",
+			callerRole:  "moderator",
+			callerPerms: moderatorPerms,
+			role:        baseperm.BaseRollSystem,
+			rolePerms:   perms(),
+			wantErr:     true,
+		},
+		{
+			name:        "a role with a permission that the caller does not have is rejected",
+			callerRole:  "moderator",
+			callerPerms: moderatorPerms,
+			role:        "support",
+			rolePerms:   perms(baseperm.BasePermReadUsers, baseperm.BasePermDeleteUsers),
+			wantErr:     true,
+		},
+		{
+			name:        "a role with the same permissions is allowed",
+			callerRole:  "moderator",
+			callerPerms: moderatorPerms,
+			role:        "moderator",
+			rolePerms:   moderatorPerms,
+		},
+		{
+			name:        "a role with less permissions is allowed",
+			callerRole:  "moderator",
+			callerPerms: moderatorPerms,
+			role:        "viewer",
+			rolePerms:   perms(baseperm.BasePermReadUsers),
+		},
+		{
+			name:        "the admin can manage the base roles",
+			callerRole:  baseperm.BaseRollAdmin,
+			callerPerms: moderatorPerms,
+			role:        baseperm.BaseRollAdmin,
+			rolePerms:   moderatorPerms,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkCanManageRole(tt.callerRole, tt.callerPerms, tt.role, tt.rolePerms)
+			if tt.wantErr {
+				if !errors.Is(err, apperr.ErrCannotManageHigherRole) {
+					t.Errorf("got %v, want ErrCannotManageHigherRole", err)
+				}
+			} else if err != nil {
+				t.Errorf("got %v, want no error", err)
+			}
+		})
+	}
+}
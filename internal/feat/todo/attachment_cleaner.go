@@ -0,0 +1,73 @@
+package todo
+
+import (
+	"context"
+	"time"
+
+	"github.com/Nidal-Bakir/go-todo-backend/internal/blobstore"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/database"
+	"github.com/rs/zerolog"
+)
+
+const (
+	attachmentCleanerInterval  = 10 * time.Minute
+	attachmentCleanerBatchSize = 100
+)
+
+// AttachmentCleaner deletes the files of the deleted attachments from the blob store.
+//
+// The deleted attachments (also the ones deleted with their todos) are queued by
+// a trigger in the database, and the failed deletions are retried on the next run.
+type AttachmentCleaner struct {
+	db        *database.Service
+	blobStore blobstore.Store
+}
+
+func NewAttachmentCleaner(db *database.Service, blobStore blobstore.Store) *AttachmentCleaner {
+	return &AttachmentCleaner{db: db, blobStore: blobStore}
+}
+
+// Start deletes the queued files every 10 minutes until the ctx is done
+func (c *AttachmentCleaner) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(attachmentCleanerInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.deleteQueuedBlobs(ctx)
+			}
+		}
+	}()
+}
+
+func (c *AttachmentCleaner) deleteQueuedBlobs(ctx context.Context) {
+	zlog := zerolog.Ctx(ctx)
+
+	for {
+		storageKeys, err := c.db.Queries.TodoAttachmentGetDeletedBlobs(ctx, attachmentCleanerBatchSize)
+		if err != nil {
+			zlog.Err(err).Msg("can not get the deleted attachment files")
+			return
+		}
+
+		for _, storageKey := range storageKeys {
+			if err := c.blobStore.Delete(ctx, storageKey); err != nil {
+				// kept in the queue, and retried on the next run
+				zlog.Err(err).Str("storage_key", storageKey).Msg("can not delete the attachment file")
+				return
+			}
+			if err := c.db.Queries.TodoAttachmentDeleteDeletedBlob(ctx, storageKey); err != nil {
+				zlog.Err(err).Str("storage_key", storageKey).Msg("can not remove the attachment file from the deletion queue")
+				return
+			}
+		}
+
+		if len(storageKeys) < attachmentCleanerBatchSize {
+			return
+		}
+	}
+}
@@ -1,14 +1,20 @@
 package todo
 
 import (
+	"encoding/json"
+	"io"
 	"time"
 
 	"github.com/Nidal-Bakir/go-todo-backend/internal/apperr"
 	"github.com/Nidal-Bakir/go-todo-backend/internal/database/database_queries"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/utils/rrule"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+// TodoStatus is the name of one of the statuses in the workflow of the todo owner
 type TodoStatus string
 
+// the statuses of the default workflow, that every user starts with
 const (
 	TodoStatusPending    TodoStatus = "pending"
 	TodoStatusInProgress TodoStatus = "in_progress"
@@ -19,59 +25,703 @@ func (s TodoStatus) String() string {
 	return string(s)
 }
 
-func (l *TodoStatus) FromString(str string) (*TodoStatus, error) {
-	switch {
-	case TodoStatusPending.String() == str:
-		*l = TodoStatusPending
-
-	case TodoStatusInProgress.String() == str:
-		*l = TodoStatusInProgress
-
-	case TodoStatusDone.String() == str:
-		*l = TodoStatusDone
-
-	default:
+// FromString validates the status against the workflow of the user
+func (l *TodoStatus) FromString(str string, workflow TodoWorkflow) (*TodoStatus, error) {
+	if !workflow.Has(TodoStatus(str)) {
 		l = nil
 		return l, apperr.ErrUnsupportedTodoStatus
 	}
 
+	*l = TodoStatus(str)
 	return l, nil
 }
 
-type TodoItem struct {
-	Id        int
-	Title     string
-	Body      string
-	Status    TodoStatus
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	DeletedAt *time.Time
+type TodoWorkflowStatus struct {
+	Id          int
+	Name        TodoStatus
+	Position    int
+	IsCompleted bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
 }
 
-func todoItemFromDataBase(td database_queries.Todo) (TodoItem, error) {
-	status, err := new(TodoStatus).FromString(td.Status)
-	if err != nil {
-		return TodoItem{}, err
+func todoWorkflowStatusFromDataBase(s database_queries.TodoWorkflowStatus) TodoWorkflowStatus {
+	return TodoWorkflowStatus{
+		Id:          int(s.ID),
+		Name:        TodoStatus(s.Name),
+		Position:    int(s.Position),
+		IsCompleted: s.IsCompleted,
+		CreatedAt:   s.CreatedAt.Time,
+		UpdatedAt:   s.UpdatedAt.Time,
 	}
+}
+
+// TodoWorkflow is the ordered statuses of a user. The first one is the status
+// of the new todos, and one of them is the completed status
+type TodoWorkflow []TodoWorkflowStatus
 
+func (w TodoWorkflow) Initial() TodoStatus {
+	return w[0].Name
+}
+
+func (w TodoWorkflow) Has(status TodoStatus) bool {
+	_, ok := w.find(func(s TodoWorkflowStatus) bool { return s.Name == status })
+	return ok
+}
+
+func (w TodoWorkflow) IsCompleted(status TodoStatus) bool {
+	s, ok := w.find(func(s TodoWorkflowStatus) bool { return s.Name == status })
+	return ok && s.IsCompleted
+}
+
+func (w TodoWorkflow) byId(id int) (TodoWorkflowStatus, bool) {
+	return w.find(func(s TodoWorkflowStatus) bool { return s.Id == id })
+}
+
+func (w TodoWorkflow) find(fn func(s TodoWorkflowStatus) bool) (TodoWorkflowStatus, bool) {
+	for _, s := range w {
+		if fn(s) {
+			return s, true
+		}
+	}
+	return TodoWorkflowStatus{}, false
+}
+
+type TodoWorkflowStatusData struct {
+	Name     *string
+	Position *int
+	// moves the completed flag to this status, there is always one completed status
+	IsCompleted bool
+}
+
+type TodoItem struct {
+	Id     int
+	Title  string
+	Body   string
+	Status TodoStatus
+	// the user who created the todo, the status follows the workflow of this user
+	UserId int
+	// the status is the completed status of the workflow
+	IsCompleted bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   *time.Time
+	DueAt       *time.Time
+	RemindAt    *time.Time
+	ListId      *int
+
+	// nil for the todos that do not repeat
+	Recurrence *TodoRecurrence
+
+	// the tags of the current user, the tags are private to the users
+	// that created them even on the todos of the shared lists
+	Tags []TodoTag
+
+	// incremented on every change of the todo fields, it is not changed by
+	// the checklist items, tags, comments or attachments of the todo
+	Version int
+
+	// the number of all the checklist items and the done ones
+	ChecklistTotal int
+	ChecklistDone  int
+
+	// only set by UpdateTodo, when the update completed a recurring todo
+	// and the next occurrence of it is created
+	NextOccurrence *TodoItem
+}
+
+type TodoRecurrence struct {
+	Rule rrule.Rule
+	// the occurrences are expanded in this timezone
+	Location *time.Location
+	// the first occurrence of the series
+	Start time.Time
+}
+
+// next returns the occurrence that comes after the given due date
+func (r TodoRecurrence) next(dueAt time.Time) (time.Time, bool) {
+	return r.Rule.Next(r.Start, r.Location, dueAt)
+}
+
+func todoItemFromDataBase(td database_queries.Todo) (TodoItem, error) {
 	var delectedAt *time.Time
 	if td.DeletedAt.Valid {
 		delectedAt = &td.DeletedAt.Time
 	}
 
+	var dueAt *time.Time
+	if td.DueAt.Valid {
+		dueAt = &td.DueAt.Time
+	}
+
+	var remindAt *time.Time
+	if td.RemindAt.Valid {
+		remindAt = &td.RemindAt.Time
+	}
+
+	var listId *int
+	if td.ListID.Valid {
+		id := int(td.ListID.Int32)
+		listId = &id
+	}
+
+	var recurrence *TodoRecurrence
+	if td.RecurrenceRule.Valid {
+		rule, err := rrule.Parse(td.RecurrenceRule.String)
+		if err != nil {
+			return TodoItem{}, err
+		}
+		loc, err := time.LoadLocation(td.RecurrenceTimezone)
+		if err != nil {
+			return TodoItem{}, err
+		}
+		recurrence = &TodoRecurrence{Rule: rule, Location: loc, Start: td.RecurrenceStart.Time}
+	}
+
 	return TodoItem{
-		Id:        int(td.ID),
-		Title:     td.Title,
-		Body:      td.Body,
-		Status:    *status,
-		CreatedAt: td.CreatedAt.Time,
-		UpdatedAt: td.UpdatedAt.Time,
-		DeletedAt: delectedAt,
+		Id:         int(td.ID),
+		Title:      td.Title,
+		Body:       td.Body,
+		Status:     TodoStatus(td.Status),
+		UserId:     int(td.UserID),
+		CreatedAt:  td.CreatedAt.Time,
+		UpdatedAt:  td.UpdatedAt.Time,
+		DeletedAt:  delectedAt,
+		DueAt:      dueAt,
+		RemindAt:   remindAt,
+		ListId:     listId,
+		Recurrence: recurrence,
+		Version:    int(td.Version),
 	}, nil
 }
 
 type TodoData struct {
-	Title  *string
-	Body   *string
+	Title    *string
+	Body     *string
+	Status   *TodoStatus
+	DueAt    *time.Time
+	RemindAt *time.Time
+	ListId   *int
+
+	// a recurring todo must have a due date, it is the first occurrence of the series
+	Recurrence *rrule.Rule
+	// defaults to UTC on create
+	RecurrenceLocation *time.Location
+
+	// used on update to remove the due date, the reminder, the list or the recurrence of the todo
+	ClearDueAt      bool
+	ClearRemindAt   bool
+	ClearListId     bool
+	ClearRecurrence bool
+
+	// used on update with the completed status to also complete all the checklist items
+	CompleteChecklistItems bool
+
+	// replaces the tags of the current user on the todo, ClearTags removes them all
+	TagIds    []int
+	ClearTags bool
+
+	// used on update to change the todo only if it still has this version,
+	// otherwise the update fails with apperr.ErrTodoVersionMismatch
+	IfVersion *int
+}
+
+type TodoSort string
+
+const (
+	TodoSortCreatedAtAsc  TodoSort = "created_at"
+	TodoSortCreatedAtDesc TodoSort = "-created_at"
+	TodoSortUpdatedAtAsc  TodoSort = "updated_at"
+	TodoSortUpdatedAtDesc TodoSort = "-updated_at"
+	TodoSortDueAtAsc      TodoSort = "due_at"
+	TodoSortDueAtDesc     TodoSort = "-due_at"
+	TodoSortTitleAsc      TodoSort = "title"
+	TodoSortTitleDesc     TodoSort = "-title"
+)
+
+const TodoSortDefault = TodoSortCreatedAtDesc
+
+func (s TodoSort) String() string {
+	return string(s)
+}
+
+func (s *TodoSort) FromString(str string) (*TodoSort, error) {
+	switch TodoSort(str) {
+	case TodoSortCreatedAtAsc, TodoSortCreatedAtDesc,
+		TodoSortUpdatedAtAsc, TodoSortUpdatedAtDesc,
+		TodoSortDueAtAsc, TodoSortDueAtDesc,
+		TodoSortTitleAsc, TodoSortTitleDesc:
+		*s = TodoSort(str)
+	default:
+		s = nil
+		return s, apperr.ErrUnsupportedTodoSort
+	}
+	return s, nil
+}
+
+type TodoFilters struct {
 	Status *TodoStatus
+	ListId *int
+	// full-text search over the title and the body
+	Query         string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	UpdatedAfter  *time.Time
+	UpdatedBefore *time.Time
+	// the todos with any of the tags, or with all of them with TagMatchAll
+	TagIds      []int
+	TagMatchAll bool
+	Sort        TodoSort
+}
+
+// TodoCursor is the keyset of the todo for the cursor pagination,
+// the cursor pagination only supports sorting by the creation date.
+// It is also the keyset of the activities and the comments of the todo
+type TodoCursor struct {
+	CreatedAt time.Time `json:"c"`
+	Id        int       `json:"i"`
+}
+
+func TodoCursorOf(item TodoItem) TodoCursor {
+	return TodoCursor{CreatedAt: item.CreatedAt, Id: item.Id}
+}
+
+func TodoActivityCursorOf(a TodoActivity) TodoCursor {
+	return TodoCursor{CreatedAt: a.CreatedAt, Id: a.Id}
+}
+
+func TodoCommentCursorOf(c TodoComment) TodoCursor {
+	return TodoCursor{CreatedAt: c.CreatedAt, Id: c.Id}
+}
+
+// TodoTrashCursor is the keyset of the deleted todo for the cursor pagination of the trash
+type TodoTrashCursor struct {
+	DeletedAt time.Time `json:"d"`
+	Id        int       `json:"i"`
+}
+
+func TodoTrashCursorOf(item TodoItem) TodoTrashCursor {
+	var deletedAt time.Time
+	if item.DeletedAt != nil {
+		deletedAt = *item.DeletedAt
+	}
+	return TodoTrashCursor{DeletedAt: deletedAt, Id: item.Id}
+}
+
+// TodoSyncToken is the position of a client in the changes of the todos
+type TodoSyncToken struct {
+	// the id of the transaction of the last returned change, the changes are
+	// ordered by the transactions that made them and then by the todo id
+	Xid int64 `json:"x"`
+	Id  int   `json:"i"`
+	// a hash of the lists that the user is a member of when the token is issued
+	Lists    string    `json:"l"`
+	IssuedAt time.Time `json:"t"`
+}
+
+// TodoTombstone is a deleted todo, or a todo that the user can not see anymore
+type TodoTombstone struct {
+	Id        int
+	DeletedAt time.Time
+}
+
+type TodoChanges struct {
+	// the created and the updated todos
+	Todos   []TodoItem
+	Deleted []TodoTombstone
+	// the changes start from the beginning, so the client should drop
+	// the todos that it has before applying them
+	Reset bool
+	// more changes are waiting, they are returned for the Next token
+	HasMore bool
+	Next    TodoSyncToken
+}
+
+type TodoList struct {
+	Id         int
+	Name       string
+	Color      *string
+	Position   int
+	IsArchived bool
+	// the role of the current user in the list
+	Role      TodoListRole
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func todoListFromDataBase(l database_queries.TodoList, role string) TodoList {
+	var color *string
+	if l.Color.Valid {
+		color = &l.Color.String
+	}
+
+	return TodoList{
+		Id:         int(l.ID),
+		Name:       l.Name,
+		Color:      color,
+		Position:   int(l.Position),
+		IsArchived: l.IsArchived,
+		Role:       TodoListRole(role),
+		CreatedAt:  l.CreatedAt.Time,
+		UpdatedAt:  l.UpdatedAt.Time,
+	}
+}
+
+type TodoListData struct {
+	Name       *string
+	Color      *string
+	Position   *int
+	IsArchived *bool
+
+	// used on update to remove the color of the list
+	ClearColor bool
+}
+
+type TodoTag struct {
+	Id        int
+	Name      string
+	Color     *string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func todoTagFromDataBase(t database_queries.TodoTag) TodoTag {
+	var color *string
+	if t.Color.Valid {
+		color = &t.Color.String
+	}
+
+	return TodoTag{
+		Id:        int(t.ID),
+		Name:      t.Name,
+		Color:     color,
+		CreatedAt: t.CreatedAt.Time,
+		UpdatedAt: t.UpdatedAt.Time,
+	}
+}
+
+type TodoTagData struct {
+	Name  *string
+	Color *string
+
+	// used on update to remove the color of the tag
+	ClearColor bool
+}
+
+// what to do with the todos of a deleted list
+type DeleteListTodosAction string
+
+const (
+	// move the todos to another list, or out of any list
+	DeleteListTodosActionMove DeleteListTodosAction = "move"
+	// move the todos to the trash
+	DeleteListTodosActionTrash DeleteListTodosAction = "trash"
+)
+
+// ---------------------------------------------------------------------------------
+
+// TodoListRole is the role of a member in a shared todo list
+type TodoListRole string
+
+const (
+	// can read the todos of the list
+	TodoListRoleViewer TodoListRole = "viewer"
+	// can read and write the todos of the list
+	TodoListRoleEditor TodoListRole = "editor"
+	// can also update and delete the list, and manage its members and invitations
+	TodoListRoleOwner TodoListRole = "owner"
+)
+
+func (r TodoListRole) String() string {
+	return string(r)
+}
+
+func (r *TodoListRole) FromString(str string) (*TodoListRole, error) {
+	switch {
+	case TodoListRoleViewer.String() == str:
+		*r = TodoListRoleViewer
+
+	case TodoListRoleEditor.String() == str:
+		*r = TodoListRoleEditor
+
+	case TodoListRoleOwner.String() == str:
+		*r = TodoListRoleOwner
+
+	default:
+		r = nil
+		return r, apperr.ErrUnsupportedTodoListRole
+	}
+
+	return r, nil
+}
+
+func (r TodoListRole) CanWrite() bool {
+	return r == TodoListRoleEditor || r == TodoListRoleOwner
+}
+
+type TodoListMember struct {
+	UserId       int
+	Username     string
+	FirstName    string
+	LastName     *string
+	ProfileImage *string
+	Role         TodoListRole
+	JoinedAt     time.Time
+}
+
+func todoListMemberFromDataBase(m database_queries.TodoListGetMembersRow) TodoListMember {
+	var lastName *string
+	if m.LastName.Valid {
+		lastName = &m.LastName.String
+	}
+
+	var profileImage *string
+	if m.ProfileImage.Valid {
+		profileImage = &m.ProfileImage.String
+	}
+
+	return TodoListMember{
+		UserId:       int(m.UserID),
+		Username:     m.Username,
+		FirstName:    m.FirstName,
+		LastName:     lastName,
+		ProfileImage: profileImage,
+		Role:         TodoListRole(m.Role),
+		JoinedAt:     m.CreatedAt.Time,
+	}
+}
+
+// TodoListInvitee is the user to invite, by the username or the email
+type TodoListInvitee struct {
+	Username *string
+	Email    *string
+}
+
+type TodoListInvitationStatus string
+
+const (
+	TodoListInvitationStatusPending  TodoListInvitationStatus = "pending"
+	TodoListInvitationStatusAccepted TodoListInvitationStatus = "accepted"
+	TodoListInvitationStatusDeclined TodoListInvitationStatus = "declined"
+)
+
+type TodoListInvitation struct {
+	Id              int
+	ListId          int
+	ListName        string
+	InviterId       int
+	InviterUsername string
+	InviteeId       int
+	InviteeUsername string
+	Role            TodoListRole
+	Status          TodoListInvitationStatus
+	CreatedAt       time.Time
+	RespondedAt     *time.Time
+}
+
+func todoListInvitationFromDataBase(i database_queries.TodoListInvitation, listName, inviterUsername, inviteeUsername string) TodoListInvitation {
+	var respondedAt *time.Time
+	if i.RespondedAt.Valid {
+		respondedAt = &i.RespondedAt.Time
+	}
+
+	return TodoListInvitation{
+		Id:              int(i.ID),
+		ListId:          int(i.ListID),
+		ListName:        listName,
+		InviterId:       int(i.InviterID),
+		InviterUsername: inviterUsername,
+		InviteeId:       int(i.InviteeID),
+		InviteeUsername: inviteeUsername,
+		Role:            TodoListRole(i.Role),
+		Status:          TodoListInvitationStatus(i.Status),
+		CreatedAt:       i.CreatedAt.Time,
+		RespondedAt:     respondedAt,
+	}
+}
+
+// ---------------------------------------------------------------------------------
+
+type TodoChecklistItem struct {
+	Id        int
+	TodoId    int
+	Text      string
+	IsDone    bool
+	Position  int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func todoChecklistItemFromDataBase(i database_queries.TodoChecklistItem) TodoChecklistItem {
+	return TodoChecklistItem{
+		Id:        int(i.ID),
+		TodoId:    int(i.TodoID),
+		Text:      i.Text,
+		IsDone:    i.IsDone,
+		Position:  int(i.Position),
+		CreatedAt: i.CreatedAt.Time,
+		UpdatedAt: i.UpdatedAt.Time,
+	}
+}
+
+type TodoChecklistItemData struct {
+	Text     *string
+	IsDone   *bool
+	Position *int
+}
+
+type TodoActivityAction string
+
+const (
+	TodoActivityActionCreated TodoActivityAction = "created"
+	// the changes of the fields other than the status
+	TodoActivityActionUpdated       TodoActivityAction = "updated"
+	TodoActivityActionStatusChanged TodoActivityAction = "status_changed"
+	// moved to the trash
+	TodoActivityActionDeleted  TodoActivityAction = "deleted"
+	TodoActivityActionRestored TodoActivityAction = "restored"
+	// deleted from the trash, the activity of the purged todos is kept until the trash retention
+	TodoActivityActionPurged TodoActivityAction = "purged"
+
+	TodoActivityActionChecklistItemAdded   TodoActivityAction = "checklist_item_added"
+	TodoActivityActionChecklistItemUpdated TodoActivityAction = "checklist_item_updated"
+	TodoActivityActionChecklistItemRemoved TodoActivityAction = "checklist_item_removed"
+	TodoActivityActionChecklistReordered   TodoActivityAction = "checklist_reordered"
+	// all the checklist items are marked done with the completion of the todo
+	TodoActivityActionChecklistCompleted TodoActivityAction = "checklist_completed"
+
+	TodoActivityActionAttachmentAdded   TodoActivityAction = "attachment_added"
+	TodoActivityActionAttachmentRemoved TodoActivityAction = "attachment_removed"
+
+	// the tags are private to their users, so only the id of the tag is recorded
+	TodoActivityActionTagAdded   TodoActivityAction = "tag_added"
+	TodoActivityActionTagRemoved TodoActivityAction = "tag_removed"
+)
+
+func (a TodoActivityAction) String() string {
+	return string(a)
+}
+
+// TodoFieldChange is the old and the new value of a field of the todo,
+// the values are null for the empty fields
+type TodoFieldChange struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+// TodoActor is the user who did something on a todo
+type TodoActor struct {
+	UserId       int
+	Username     string
+	FirstName    string
+	LastName     *string
+	ProfileImage *string
+}
+
+// todoActorFromDataBase returns nil for the deleted users
+func todoActorFromDataBase(userId pgtype.Int4, username, firstName, lastName, profileImage pgtype.Text) *TodoActor {
+	if !userId.Valid || !username.Valid {
+		return nil
+	}
+
+	actor := &TodoActor{
+		UserId:    int(userId.Int32),
+		Username:  username.String,
+		FirstName: firstName.String,
+	}
+	if lastName.Valid {
+		actor.LastName = &lastName.String
+	}
+	if profileImage.Valid {
+		actor.ProfileImage = &profileImage.String
+	}
+	return actor
+}
+
+type TodoActivity struct {
+	Id     int
+	TodoId int
+	// nil if the user is deleted
+	Actor     *TodoActor
+	Action    TodoActivityAction
+	Changes   map[string]TodoFieldChange
+	CreatedAt time.Time
+}
+
+func todoActivityFromDataBase(a database_queries.TodoActivityGetActivitiesRow) (TodoActivity, error) {
+	changes := make(map[string]TodoFieldChange)
+	if err := json.Unmarshal(a.Changes, &changes); err != nil {
+		return TodoActivity{}, err
+	}
+
+	return TodoActivity{
+		Id:        int(a.ID),
+		TodoId:    int(a.TodoID),
+		Actor:     todoActorFromDataBase(a.UserID, a.Username, a.FirstName, a.LastName, a.ProfileImage),
+		Action:    TodoActivityAction(a.Action),
+		Changes:   changes,
+		CreatedAt: a.CreatedAt.Time,
+	}, nil
+}
+
+type TodoComment struct {
+	Id     int
+	TodoId int
+	// nil if the user is deleted
+	Author    *TodoActor
+	Body      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func todoCommentFromDataBase(c database_queries.TodoCommentGetCommentRow) TodoComment {
+	return TodoComment{
+		Id:        int(c.ID),
+		TodoId:    int(c.TodoID),
+		Author:    todoActorFromDataBase(c.UserID, c.Username, c.FirstName, c.LastName, c.ProfileImage),
+		Body:      c.Body,
+		CreatedAt: c.CreatedAt.Time,
+		UpdatedAt: c.UpdatedAt.Time,
+	}
+}
+
+type TodoAttachment struct {
+	Id     int
+	TodoId int
+	// the user who uploaded the file, nil if the user is deleted
+	UploadedBy  *int
+	Filename    string
+	ContentType string
+	Size        int64
+	CreatedAt   time.Time
+
+	// the signed url to download the file without auth, until URLExpiresAt
+	URL          string
+	URLExpiresAt time.Time
+}
+
+func todoAttachmentFromDataBase(a database_queries.TodoAttachment) TodoAttachment {
+	var uploadedBy *int
+	if a.UserID.Valid {
+		id := int(a.UserID.Int32)
+		uploadedBy = &id
+	}
+
+	return TodoAttachment{
+		Id:          int(a.ID),
+		TodoId:      int(a.TodoID),
+		UploadedBy:  uploadedBy,
+		Filename:    a.Filename,
+		ContentType: a.ContentType,
+		Size:        a.SizeBytes,
+		CreatedAt:   a.CreatedAt.Time,
+	}
+}
+
+type TodoAttachmentData struct {
+	Filename string
+	// validated by the caller, it is returned as is to the downloaders
+	ContentType string
+	Size        int64
+	Content     io.Reader
 }
@@ -0,0 +1,169 @@
+package todo
+
+import (
+	"context"
+	"time"
+
+	"github.com/Nidal-Bakir/go-todo-backend/internal/database"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/database/database_queries"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/gateway"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/l10n"
+	dbutils "github.com/Nidal-Bakir/go-todo-backend/internal/utils/db_utils"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/utils/phonenumber"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog"
+	"golang.org/x/text/language"
+)
+
+const (
+	reminderSchedulerInterval = time.Minute
+	reminderBatchSize         = 100
+	reminderDefaultLang       = "en"
+	reminderDueAtLayout       = "2006-01-02 15:04"
+	// the failed reminders are retried on the next ticks until they reach the max attempts
+	reminderMaxAttempts = 5
+)
+
+// ReminderScheduler dispatches the due todo reminders through the gateway senders.
+//
+// The reminders are claimed in the database before sending them (FOR UPDATE SKIP LOCKED),
+// so it is safe to run more than one instance of the app. A reminder is sent at most once,
+// a failed one is released and retried on the next ticks up to reminderMaxAttempts times.
+type ReminderScheduler struct {
+	db               *database.Service
+	gatewaysProvider gateway.Provider
+}
+
+func NewReminderScheduler(db *database.Service, gatewaysProvider gateway.Provider) *ReminderScheduler {
+	return &ReminderScheduler{db: db, gatewaysProvider: gatewaysProvider}
+}
+
+// Start checks for the due reminders every minute until the ctx is done
+func (s *ReminderScheduler) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(reminderSchedulerInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.dispatchDueReminders(ctx)
+			}
+		}
+	}()
+}
+
+func (s *ReminderScheduler) dispatchDueReminders(ctx context.Context) {
+	zlog := zerolog.Ctx(ctx)
+
+	// released after claiming all the due reminders, so the same tick does not claim them again
+	var failedTodoIds []int32
+	defer func() {
+		for _, todoId := range failedTodoIds {
+			s.releaseFailedReminder(ctx, todoId)
+		}
+	}()
+
+	for {
+		reminders, err := s.db.Queries.TodoClaimDueReminders(ctx, reminderBatchSize)
+		if err != nil {
+			zlog.Err(err).Msg("can not claim the due todo reminders")
+			return
+		}
+
+		for _, reminder := range reminders {
+			if err := s.sendReminder(ctx, reminder); err != nil {
+				zlog.Err(err).Int32("todo_id", reminder.ID).Msg("can not send the todo reminder")
+				failedTodoIds = append(failedTodoIds, reminder.ID)
+			}
+		}
+
+		if len(reminders) < reminderBatchSize {
+			return
+		}
+	}
+}
+
+// releaseFailedReminder clears the claim of the reminder so the next tick retries it,
+// unless the reminder reached the max attempts
+func (s *ReminderScheduler) releaseFailedReminder(ctx context.Context, todoId int32) {
+	err := s.db.Queries.TodoReleaseFailedReminder(
+		ctx,
+		database_queries.TodoReleaseFailedReminderParams{
+			ID:          todoId,
+			MaxAttempts: reminderMaxAttempts,
+		},
+	)
+	if err != nil {
+		zerolog.Ctx(ctx).Err(err).Int32("todo_id", todoId).Msg("can not release the failed todo reminder")
+	}
+}
+
+func (s *ReminderScheduler) sendReminder(ctx context.Context, reminder database_queries.TodoClaimDueRemindersRow) error {
+	zlog := zerolog.Ctx(ctx).With().Int32("todo_id", reminder.ID).Int32("user_id", reminder.UserID).Logger()
+
+	target, err := s.db.Queries.TodoGetReminderTargetForUser(ctx, reminder.UserID)
+	if err != nil {
+		if dbutils.IsErrPgxNoRows(err) {
+			// the user is deleted
+			return nil
+		}
+		return err
+	}
+
+	content := reminderContent(reminder, target)
+
+	switch {
+	case target.Email.Valid:
+		return s.gatewaysProvider.NewEmailProvider(ctx).Send(ctx, target.Email.String, content)
+
+	case target.Phone.Valid:
+		phone, err := phonenumber.Parse(target.Phone.String)
+		if err != nil {
+			return err
+		}
+		return s.gatewaysProvider.NewSMSProvider(ctx, phone.CountryCode()).Send(ctx, phone.ToE164(), content)
+
+	default:
+		// e.g. guest and oidc users
+		zlog.Debug().Msg("the user does not have any contact to send the todo reminder to, skipping it")
+		return nil
+	}
+}
+
+func reminderContent(reminder database_queries.TodoClaimDueRemindersRow, target database_queries.TodoGetReminderTargetForUserRow) string {
+	localizer := l10n.GetLocalizer(reminderLang(target.Locale))
+
+	if !reminder.DueAt.Valid {
+		return localizer.GetWithData(l10n.TodoReminderTrId, map[string]any{"Title": reminder.Title})
+	}
+
+	// show the due date in the timezone of the last used device of the user
+	var offsetInMinutes int32
+	if target.TimezoneOffsetInMinutes.Valid {
+		offsetInMinutes = target.TimezoneOffsetInMinutes.Int32
+	}
+	loc := time.FixedZone("", int(offsetInMinutes)*60)
+
+	return localizer.GetWithData(
+		l10n.TodoReminderWithDueAtTrId,
+		map[string]any{
+			"Title": reminder.Title,
+			"DueAt": reminder.DueAt.Time.In(loc).Format(reminderDueAtLayout),
+		},
+	)
+}
+
+func reminderLang(locale pgtype.Text) string {
+	if !locale.Valid {
+		return reminderDefaultLang
+	}
+	tag, err := language.Parse(locale.String)
+	if err != nil {
+		return reminderDefaultLang
+	}
+	baseLang, _ := tag.Base()
+	return baseLang.String()
+}
@@ -2,47 +2,175 @@ package todo
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
 
 	"github.com/Nidal-Bakir/go-todo-backend/internal/apperr"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/blobstore"
 	"github.com/Nidal-Bakir/go-todo-backend/internal/database"
 	"github.com/Nidal-Bakir/go-todo-backend/internal/database/database_queries"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/gateway"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/l10n"
 	dbutils "github.com/Nidal-Bakir/go-todo-backend/internal/utils/db_utils"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
 )
 
 type Repository interface {
-	GetTodos(ctx context.Context, userId, offset, limit int) ([]TodoItem, error)
+	GetTodos(ctx context.Context, userId int, filters TodoFilters, offset, limit int) ([]TodoItem, error)
+	GetTodosAfterCursor(ctx context.Context, userId int, filters TodoFilters, cursor *TodoCursor, limit int) ([]TodoItem, error)
 	GetTodo(ctx context.Context, userId, todoId int) (TodoItem, error)
 
 	CreateTodo(ctx context.Context, userId int, data TodoData) (TodoItem, error)
 
 	UpdateTodo(ctx context.Context, userId, todoId int, data TodoData) (TodoItem, error)
 
-	DeleteTodo(ctx context.Context, userId, todoId int) error
+	// DeleteTodo with ifVersion deletes the todo only if it still has this version,
+	// otherwise it fails with apperr.ErrTodoVersionMismatch
+	DeleteTodo(ctx context.Context, userId, todoId int, ifVersion *int) error
+
+	// GetTodoChanges is the delta sync of the todos that the user can see, since is nil for the first sync
+	GetTodoChanges(ctx context.Context, userId int, since *TodoSyncToken, limit int) (TodoChanges, error)
+
+	GetDeletedTodos(ctx context.Context, userId, offset, limit int) ([]TodoItem, error)
+	GetDeletedTodosAfterCursor(ctx context.Context, userId int, cursor *TodoTrashCursor, limit int) ([]TodoItem, error)
+	RestoreTodo(ctx context.Context, userId, todoId int) (TodoItem, error)
+	PurgeTodo(ctx context.Context, userId, todoId int) error
+
+	GetChecklistItems(ctx context.Context, userId, todoId int) ([]TodoChecklistItem, error)
+	CreateChecklistItem(ctx context.Context, userId, todoId int, data TodoChecklistItemData) (TodoChecklistItem, error)
+	UpdateChecklistItem(ctx context.Context, userId, todoId, itemId int, data TodoChecklistItemData) (TodoChecklistItem, error)
+	ReorderChecklistItems(ctx context.Context, userId, todoId int, itemIds []int) ([]TodoChecklistItem, error)
+	DeleteChecklistItem(ctx context.Context, userId, todoId, itemId int) error
+
+	GetAttachments(ctx context.Context, userId, todoId int) ([]TodoAttachment, error)
+	GetAttachment(ctx context.Context, userId, todoId, attachmentId int) (TodoAttachment, error)
+	CreateAttachment(ctx context.Context, userId, todoId int, data TodoAttachmentData) (TodoAttachment, error)
+	// DeleteAttachment deletes the file from the blob store in the background
+	DeleteAttachment(ctx context.Context, userId, todoId, attachmentId int) error
+
+	// GetActivity returns the changes of the todo, the newest first
+	GetActivity(ctx context.Context, userId, todoId, offset, limit int) ([]TodoActivity, error)
+	GetActivityAfterCursor(ctx context.Context, userId, todoId int, cursor *TodoCursor, limit int) ([]TodoActivity, error)
+
+	GetComments(ctx context.Context, userId, todoId, offset, limit int) ([]TodoComment, error)
+	GetCommentsAfterCursor(ctx context.Context, userId, todoId int, cursor *TodoCursor, limit int) ([]TodoComment, error)
+	CreateComment(ctx context.Context, userId, todoId int, body string) (TodoComment, error)
+	// UpdateComment and DeleteComment are only allowed to the author of the comment
+	UpdateComment(ctx context.Context, userId, todoId, commentId int, body string) (TodoComment, error)
+	DeleteComment(ctx context.Context, userId, todoId, commentId int) error
+
+	GetWorkflow(ctx context.Context, userId int) (TodoWorkflow, error)
+	CreateWorkflowStatus(ctx context.Context, userId int, data TodoWorkflowStatusData) (TodoWorkflowStatus, error)
+	// UpdateWorkflowStatus also renames the status of the todos of the user
+	UpdateWorkflowStatus(ctx context.Context, userId, statusId int, data TodoWorkflowStatusData) (TodoWorkflowStatus, error)
+	ReorderWorkflowStatuses(ctx context.Context, userId int, statusIds []int) (TodoWorkflow, error)
+	// DeleteWorkflowStatus moves the todos of the user with the status to moveToStatusId (nil for the initial status)
+	DeleteWorkflowStatus(ctx context.Context, userId, statusId int, moveToStatusId *int) error
+
+	GetTags(ctx context.Context, userId int) ([]TodoTag, error)
+	CreateTag(ctx context.Context, userId int, data TodoTagData) (TodoTag, error)
+	UpdateTag(ctx context.Context, userId, tagId int, data TodoTagData) (TodoTag, error)
+	DeleteTag(ctx context.Context, userId, tagId int) error
+	AttachTagToTodo(ctx context.Context, userId, todoId, tagId int) error
+	DetachTagFromTodo(ctx context.Context, userId, todoId, tagId int) error
+
+	GetLists(ctx context.Context, userId int, includeArchived bool) ([]TodoList, error)
+	GetList(ctx context.Context, userId, listId int) (TodoList, error)
+	CreateList(ctx context.Context, userId int, data TodoListData) (TodoList, error)
+	UpdateList(ctx context.Context, userId, listId int, data TodoListData) (TodoList, error)
+	// DeleteList moves the todos of the list to moveToListId (nil for no list), or to the trash
+	DeleteList(ctx context.Context, userId, listId int, action DeleteListTodosAction, moveToListId *int) error
+
+	GetListMembers(ctx context.Context, userId, listId int) ([]TodoListMember, error)
+	ChangeListMemberRole(ctx context.Context, userId, listId, memberId int, role TodoListRole) error
+	RemoveListMember(ctx context.Context, userId, listId, memberId int) error
+
+	InviteToList(ctx context.Context, userId, listId int, invitee TodoListInvitee, role TodoListRole) (TodoListInvitation, error)
+	GetListInvitations(ctx context.Context, userId, listId int) ([]TodoListInvitation, error)
+	CancelListInvitation(ctx context.Context, userId, listId, invitationId int) error
+	GetMyListInvitations(ctx context.Context, userId int) ([]TodoListInvitation, error)
+	RespondToListInvitation(ctx context.Context, userId, invitationId int, accept bool) error
+
+	// RunInTransaction calls fn with a repository whose changes are committed together
+	// if fn returns nil. The transactions of its methods become savepoints, so a failed
+	// method does not roll back the changes of the others. It is not safe for concurrent use
+	RunInTransaction(ctx context.Context, fn func(txRepo Repository) error) error
 }
 
-func NewRepository(db *database.Service, redis *redis.Client) Repository {
-	return &repositoryImpl{db: db, redis: redis}
+func NewRepository(db *database.Service, redis *redis.Client, gatewaysProvider gateway.Provider, blobStore blobstore.Store) Repository {
+	return &repositoryImpl{db: db, redis: redis, gatewaysProvider: gatewaysProvider, blobStore: blobStore}
 }
 
 // ---------------------------------------------------------------------------------
 
 type repositoryImpl struct {
-	db    *database.Service
-	redis *redis.Client
+	db               *database.Service
+	redis            *redis.Client
+	gatewaysProvider gateway.Provider
+	blobStore        blobstore.Store
+
+	// set for the repository of RunInTransaction, the db.Queries use it
+	tx pgx.Tx
+}
+
+func (repo repositoryImpl) GetTodos(ctx context.Context, userId int, filters TodoFilters, offset, limit int) ([]TodoItem, error) {
+	return repo.getTodos(ctx, userId, filters, nil, offset, limit)
+}
+
+func (repo repositoryImpl) GetTodosAfterCursor(ctx context.Context, userId int, filters TodoFilters, cursor *TodoCursor, limit int) ([]TodoItem, error) {
+	switch filters.Sort {
+	case TodoSortCreatedAtAsc, TodoSortCreatedAtDesc, "":
+	default:
+		return []TodoItem{}, apperr.ErrUnsupportedTodoSort
+	}
+	return repo.getTodos(ctx, userId, filters, cursor, 0, limit)
 }
 
-func (repo repositoryImpl) GetTodos(ctx context.Context, userId, offset, limit int) ([]TodoItem, error) {
+// getTodos returns the todos after the cursor in the order of the sort, the cursor is only
+// supported with the creation date sorts. A nil cursor starts from the beginning
+func (repo repositoryImpl) getTodos(ctx context.Context, userId int, filters TodoFilters, cursor *TodoCursor, offset, limit int) ([]TodoItem, error) {
 	zlog := zerolog.Ctx(ctx)
 
+	var status pgtype.Text
+	if filters.Status != nil {
+		status.Valid = true
+		status.String = filters.Status.String()
+	}
+
+	sort := filters.Sort
+	if sort == "" {
+		sort = TodoSortDefault
+	}
+
+	cursorCreatedAt, cursorId := todoCursorToPgTypes(cursor)
+
 	data, err := repo.db.Queries.TodoGetTodosForUser(
 		ctx,
 		database_queries.TodoGetTodosForUserParams{
-			UserID: int32(userId),
-			Offset: int64(offset),
-			Limit:  int64(limit),
+			UserID:          int32(userId),
+			Status:          status,
+			ListID:          dbutils.PtrToPgTypeInt4(filters.ListId),
+			Query:           dbutils.ToPgTypeText(filters.Query),
+			TagIds:          uniqueInt32s(filters.TagIds),
+			TagMatchAll:     filters.TagMatchAll,
+			CreatedAfter:    dbutils.PtrToPgTypeTimestamptz(filters.CreatedAfter),
+			CreatedBefore:   dbutils.PtrToPgTypeTimestamptz(filters.CreatedBefore),
+			UpdatedAfter:    dbutils.PtrToPgTypeTimestamptz(filters.UpdatedAfter),
+			UpdatedBefore:   dbutils.PtrToPgTypeTimestamptz(filters.UpdatedBefore),
+			CursorCreatedAt: cursorCreatedAt,
+			CursorID:        cursorId,
+			Sort:            sort.String(),
+			PageOffset:      int32(offset),
+			PageLimit:       int32(limit),
 		},
 	)
 
@@ -66,6 +194,10 @@ func (repo repositoryImpl) GetTodos(ctx context.Context, userId, offset, limit i
 		todoItems[i] = todoItem
 	}
 
+	if err := repo.attachDetails(ctx, userId, todoItems); err != nil {
+		return []TodoItem{}, err
+	}
+
 	return todoItems, nil
 }
 
@@ -88,13 +220,18 @@ func (repo repositoryImpl) GetTodo(ctx context.Context, userId, todoId int) (Tod
 		return TodoItem{}, err
 	}
 
-	createdTodo, err := todoItemFromDataBase(res)
+	todoItem, err := todoItemFromDataBase(res)
 	if err != nil {
 		zlog.Err(err).Msg("can not convert database.Todo to TodoItem")
 		return TodoItem{}, err
 	}
 
-	return createdTodo, nil
+	todoItems := []TodoItem{todoItem}
+	if err := repo.attachDetails(ctx, userId, todoItems); err != nil {
+		return TodoItem{}, err
+	}
+
+	return todoItems[0], nil
 }
 
 func (repo repositoryImpl) CreateTodo(ctx context.Context, userId int, data TodoData) (TodoItem, error) {
@@ -107,22 +244,85 @@ func (repo repositoryImpl) CreateTodo(ctx context.Context, userId int, data Todo
 		return *strPtr
 	}
 
-	var status TodoStatus
-	if data.Status == nil {
-		status = TodoStatusPending
-	} else {
-		status = *data.Status
+	workflow, err := getWorkflow(ctx, repo.db.Queries, userId)
+	if err != nil {
+		zlog.Err(err).Msg("can not get the todo workflow")
+		return TodoItem{}, err
 	}
 
-	res, err := repo.db.Queries.TodoCreateTodo(
-		ctx,
-		database_queries.TodoCreateTodoParams{
-			Title:  nilToEmptyString(data.Title),
-			Body:   nilToEmptyString(data.Body),
-			Status: status.String(),
-			UserID: int32(userId),
-		},
-	)
+	status := workflow.Initial()
+	if data.Status != nil {
+		s, err := status.FromString(data.Status.String(), workflow)
+		if err != nil {
+			return TodoItem{}, err
+		}
+		status = *s
+	}
+
+	if data.ListId != nil {
+		if err := repo.checkCanWriteToList(ctx, userId, *data.ListId); err != nil {
+			return TodoItem{}, err
+		}
+	}
+
+	recurrenceTimezone := time.UTC.String()
+	if data.RecurrenceLocation != nil {
+		recurrenceTimezone = data.RecurrenceLocation.String()
+	}
+
+	var recurrenceRule pgtype.Text
+	var recurrenceStart pgtype.Timestamptz
+	if data.Recurrence != nil {
+		if data.DueAt == nil {
+			return TodoItem{}, apperr.ErrRecurrenceNeedsDueAt
+		}
+		recurrenceRule = pgtype.Text{String: data.Recurrence.String(), Valid: true}
+		recurrenceStart = dbutils.PtrToPgTypeTimestamptz(data.DueAt)
+	}
+
+	if err := repo.checkTagsOfUser(ctx, userId, data.TagIds); err != nil {
+		return TodoItem{}, err
+	}
+
+	var res database_queries.Todo
+	err = repo.usingTransaction(ctx, func(queries *database_queries.Queries) error {
+		var err error
+		res, err = queries.TodoCreateTodo(
+			ctx,
+			database_queries.TodoCreateTodoParams{
+				Title:              nilToEmptyString(data.Title),
+				Body:               nilToEmptyString(data.Body),
+				Status:             status.String(),
+				UserID:             int32(userId),
+				DueAt:              dbutils.PtrToPgTypeTimestamptz(data.DueAt),
+				RemindAt:           dbutils.PtrToPgTypeTimestamptz(data.RemindAt),
+				ListID:             dbutils.PtrToPgTypeInt4(data.ListId),
+				RecurrenceRule:     recurrenceRule,
+				RecurrenceTimezone: recurrenceTimezone,
+				RecurrenceStart:    recurrenceStart,
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		err = recordActivity(ctx, queries, int(res.ID), userId, TodoActivityActionCreated, todoChanges(nil, res))
+		if err != nil {
+			return err
+		}
+
+		if len(data.TagIds) == 0 {
+			return nil
+		}
+		_, err = queries.TodoTagAttachTags(
+			ctx,
+			database_queries.TodoTagAttachTagsParams{
+				TodoID: res.ID,
+				TagIds: uniqueInt32s(data.TagIds),
+			},
+		)
+		return err
+	})
 	if err != nil {
 		zlog.Err(err).Msg("can not create todo")
 		return TodoItem{}, err
@@ -134,71 +334,2629 @@ func (repo repositoryImpl) CreateTodo(ctx context.Context, userId int, data Todo
 		return TodoItem{}, err
 	}
 
-	return createdTodo, nil
+	todoItems := []TodoItem{createdTodo}
+	if err := repo.attachDetails(ctx, userId, todoItems); err != nil {
+		return TodoItem{}, err
+	}
+
+	return todoItems[0], nil
 }
 
 func (repo repositoryImpl) UpdateTodo(ctx context.Context, userId, todoId int, data TodoData) (TodoItem, error) {
 	zlog := zerolog.Ctx(ctx).With().Int("todo_id", todoId).Logger()
 
-	stringToPgTextType := func(strPtr *string) pgtype.Text {
-		var txt pgtype.Text
-		if strPtr != nil {
-			txt.String = *strPtr
-			txt.Valid = true
-		}
-		return txt
-	}
-
 	var status pgtype.Text
 	if data.Status != nil {
 		status.Valid = true
 		status.String = data.Status.String()
 	}
 
-	res, err := repo.db.Queries.TodoUpdateTodo(
-		ctx, database_queries.TodoUpdateTodoParams{
-			ID:     int32(todoId),
-			UserID: int32(userId),
-			Title:  stringToPgTextType(data.Title),
-			Body:   stringToPgTextType(data.Body),
-			Status: status,
+	if data.ListId != nil {
+		if err := repo.checkCanWriteToList(ctx, userId, *data.ListId); err != nil {
+			return TodoItem{}, err
+		}
+	}
+
+	if err := repo.checkTagsOfUser(ctx, userId, data.TagIds); err != nil {
+		return TodoItem{}, err
+	}
+
+	var res database_queries.Todo
+	var nextOccurrence *database_queries.Todo
+	err := repo.usingTransaction(ctx, func(queries *database_queries.Queries) error {
+		// locked until the end of the transaction, so completing a recurring todo
+		// twice at the same time does not create two next occurrences
+		prev, err := queries.TodoGetTodoForWriteLinkedToUser(
+			ctx,
+			database_queries.TodoGetTodoForWriteLinkedToUserParams{
+				ID:     int32(todoId),
+				UserID: int32(userId),
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		// the todo is locked, so it can not be changed between this check and the update
+		if data.IfVersion != nil && *data.IfVersion != int(prev.Version) {
+			return apperr.ErrTodoVersionMismatch
+		}
+
+		// the status follows the workflow of the user who created the todo
+		workflow, err := getWorkflow(ctx, queries, int(prev.UserID))
+		if err != nil {
+			return err
+		}
+		if data.Status != nil {
+			if _, err := new(TodoStatus).FromString(data.Status.String(), workflow); err != nil {
+				return err
+			}
+		}
+
+		params := database_queries.TodoUpdateTodoParams{
+			ID:            int32(todoId),
+			UserID:        int32(userId),
+			Title:         dbutils.PtrToPgTypeText(data.Title),
+			Body:          dbutils.PtrToPgTypeText(data.Body),
+			Status:        status,
+			ClearDueAt:    data.ClearDueAt,
+			DueAt:         dbutils.PtrToPgTypeTimestamptz(data.DueAt),
+			ClearRemindAt: data.ClearRemindAt,
+			RemindAt:      dbutils.PtrToPgTypeTimestamptz(data.RemindAt),
+			ClearListID:   data.ClearListId,
+			ListID:        dbutils.PtrToPgTypeInt4(data.ListId),
+		}
+		if err := setRecurrenceUpdateParams(&params, prev, data); err != nil {
+			return err
+		}
+		if err := checkRemindAtOfUpdate(prev, data); err != nil {
+			return err
+		}
+
+		res, err = queries.TodoUpdateTodo(ctx, params)
+		if err != nil {
+			return err
+		}
+
+		wasCompleted := workflow.IsCompleted(TodoStatus(prev.Status))
+		isCompleted := workflow.IsCompleted(TodoStatus(res.Status))
+
+		if data.CompleteChecklistItems && isCompleted {
+			completedItems, err := queries.TodoChecklistCompleteAllItems(ctx, res.ID)
+			if err != nil {
+				return err
+			}
+			if completedItems != 0 {
+				if err := recordActivity(ctx, queries, todoId, userId, TodoActivityActionChecklistCompleted, nil); err != nil {
+					return err
+				}
+			}
+		}
+
+		if data.TagIds != nil || data.ClearTags {
+			if err := setTagsOfTodo(ctx, queries, userId, todoId, data.TagIds); err != nil {
+				return err
+			}
+		}
+
+		if !wasCompleted && isCompleted && res.RecurrenceRule.Valid {
+			nextOccurrence, err = createNextOccurrence(ctx, queries, res, workflow.Initial())
+			if err != nil {
+				return err
+			}
+
+			if nextOccurrence != nil {
+				err = recordActivity(ctx, queries, int(nextOccurrence.ID), userId, TodoActivityActionCreated, todoChanges(nil, *nextOccurrence))
+				if err != nil {
+					return err
+				}
+			}
+
+			// the recurrence moves to the next occurrence, so reopening and completing
+			// this todo again does not create another one
+			res, err = queries.TodoClearRecurrence(ctx, res.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		return recordTodoUpdate(ctx, queries, userId, prev, res)
+	})
+
+	if err != nil {
+		if dbutils.IsErrPgxNoRows(err) {
+			err = repo.noWriteAccessErr(ctx, userId, todoId)
+		} else if !apperr.IsAppErr(err) {
+			zlog.Err(err).Msg("can not update todo")
+		}
+		return TodoItem{}, err
+	}
+
+	todoItems := make([]TodoItem, 0, 2)
+	for _, td := range []*database_queries.Todo{&res, nextOccurrence} {
+		if td == nil {
+			continue
+		}
+		todoItem, err := todoItemFromDataBase(*td)
+		if err != nil {
+			zlog.Err(err).Msg("can not convert database.Todo to TodoItem")
+			return TodoItem{}, err
+		}
+		todoItems = append(todoItems, todoItem)
+	}
+
+	if err := repo.attachDetails(ctx, userId, todoItems); err != nil {
+		return TodoItem{}, err
+	}
+
+	updatedTodo := todoItems[0]
+	if len(todoItems) == 2 {
+		updatedTodo.NextOccurrence = &todoItems[1]
+	}
+
+	return updatedTodo, nil
+}
+
+// setRecurrenceUpdateParams validates the recurrence of the todo after the update.
+// A new rule or a new due date starts the series again from the due date.
+func setRecurrenceUpdateParams(params *database_queries.TodoUpdateTodoParams, prev database_queries.Todo, data TodoData) error {
+	params.ClearRecurrence = data.ClearRecurrence
+	if data.Recurrence != nil {
+		params.RecurrenceRule = pgtype.Text{String: data.Recurrence.String(), Valid: true}
+	}
+	if data.RecurrenceLocation != nil {
+		params.RecurrenceTimezone = pgtype.Text{String: data.RecurrenceLocation.String(), Valid: true}
+	}
+
+	isRecurring := !data.ClearRecurrence && (data.Recurrence != nil || prev.RecurrenceRule.Valid)
+	if !isRecurring {
+		return nil
+	}
+
+	dueAt := prev.DueAt
+	if data.ClearDueAt {
+		dueAt = pgtype.Timestamptz{}
+	} else if data.DueAt != nil {
+		dueAt = dbutils.PtrToPgTypeTimestamptz(data.DueAt)
+	}
+	if !dueAt.Valid {
+		return apperr.ErrRecurrenceNeedsDueAt
+	}
+
+	if data.Recurrence != nil || data.DueAt != nil {
+		params.RecurrenceStart = dueAt
+	}
+	return nil
+}
+
+// checkRemindAtOfUpdate validates that the reminder is not after the due date after the update,
+// with the stored values of the fields that the update does not change
+func checkRemindAtOfUpdate(prev database_queries.Todo, data TodoData) error {
+	dueAt := prev.DueAt
+	if data.ClearDueAt {
+		dueAt = pgtype.Timestamptz{}
+	} else if data.DueAt != nil {
+		dueAt = dbutils.PtrToPgTypeTimestamptz(data.DueAt)
+	}
+
+	remindAt := prev.RemindAt
+	if data.ClearRemindAt {
+		remindAt = pgtype.Timestamptz{}
+	} else if data.RemindAt != nil {
+		remindAt = dbutils.PtrToPgTypeTimestamptz(data.RemindAt)
+	}
+
+	if dueAt.Valid && remindAt.Valid && remindAt.Time.After(dueAt.Time) {
+		return apperr.ErrTodoRemindAtAfterDueAt
+	}
+	return nil
+}
+
+// createNextOccurrence creates the occurrence that comes after the completed todo,
+// in the given status, with the same reminder offset and tags, and with the checklist items of it not done.
+// It returns nil when the series ended (COUNT or UNTIL)
+func createNextOccurrence(ctx context.Context, queries *database_queries.Queries, completed database_queries.Todo, status TodoStatus) (*database_queries.Todo, error) {
+	completedItem, err := todoItemFromDataBase(completed)
+	if err != nil {
+		return nil, err
+	}
+
+	nextDueAt, ok := completedItem.Recurrence.next(completed.DueAt.Time)
+	if !ok {
+		return nil, nil
+	}
+
+	var remindAt pgtype.Timestamptz
+	if completed.RemindAt.Valid {
+		remindAt.Time = nextDueAt.Add(completed.RemindAt.Time.Sub(completed.DueAt.Time))
+		remindAt.Valid = true
+	}
+
+	next, err := queries.TodoCreateTodo(
+		ctx,
+		database_queries.TodoCreateTodoParams{
+			Title:              completed.Title,
+			Body:               completed.Body,
+			Status:             status.String(),
+			UserID:             completed.UserID,
+			DueAt:              dbutils.PtrToPgTypeTimestamptz(&nextDueAt),
+			RemindAt:           remindAt,
+			ListID:             completed.ListID,
+			RecurrenceRule:     completed.RecurrenceRule,
+			RecurrenceTimezone: completed.RecurrenceTimezone,
+			RecurrenceStart:    completed.RecurrenceStart,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	err = queries.TodoChecklistCopyItems(
+		ctx,
+		database_queries.TodoChecklistCopyItemsParams{
+			ToTodoID:   next.ID,
+			FromTodoID: completed.ID,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	err = queries.TodoTagCopyTags(
+		ctx,
+		database_queries.TodoTagCopyTagsParams{
+			ToTodoID:   next.ID,
+			FromTodoID: completed.ID,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &next, nil
+}
+
+func (repo repositoryImpl) DeleteTodo(ctx context.Context, userId, todoId int, ifVersion *int) error {
+	zlog := zerolog.Ctx(ctx).With().Int("todo_id", todoId).Logger()
+
+	var rowsAffected int64
+	err := repo.usingTransaction(ctx, func(queries *database_queries.Queries) error {
+		if ifVersion != nil {
+			// locked until the end of the transaction, so the version can not change before the delete
+			prev, err := queries.TodoGetTodoForWriteLinkedToUser(
+				ctx,
+				database_queries.TodoGetTodoForWriteLinkedToUserParams{
+					ID:     int32(todoId),
+					UserID: int32(userId),
+				},
+			)
+			if err != nil {
+				if dbutils.IsErrPgxNoRows(err) {
+					// checked by the soft delete below
+					err = nil
+				}
+				return err
+			}
+			if *ifVersion != int(prev.Version) {
+				return apperr.ErrTodoVersionMismatch
+			}
+		}
+
+		var err error
+		rowsAffected, err = queries.TodoSoftDeleteTodoLinkedToUser(
+			ctx,
+			database_queries.TodoSoftDeleteTodoLinkedToUserParams{
+				ID:     int32(todoId),
+				UserID: int32(userId),
+			},
+		)
+		if err != nil || rowsAffected == 0 {
+			return err
+		}
+		return recordActivity(ctx, queries, todoId, userId, TodoActivityActionDeleted, nil)
+	})
+	if err != nil {
+		if !apperr.IsAppErr(err) {
+			zlog.Err(err).Msg("can not delete todo")
+		}
+		return err
+	}
+	if rowsAffected == 0 {
+		return repo.noWriteAccessErr(ctx, userId, todoId)
+	}
+
+	return nil
+}
+
+// noWriteAccessErr tells apart the todos that the user can not see (ErrNoResult),
+// from the todos that the user can only read as a viewer of a shared list (ErrPermissionDenied)
+func (repo repositoryImpl) noWriteAccessErr(ctx context.Context, userId, todoId int) error {
+	if _, err := repo.GetTodo(ctx, userId, todoId); err != nil {
+		return err
+	}
+	return apperr.ErrPermissionDenied
+}
+
+func (repo repositoryImpl) GetDeletedTodos(ctx context.Context, userId, offset, limit int) ([]TodoItem, error) {
+	zlog := zerolog.Ctx(ctx)
+
+	data, err := repo.db.Queries.TodoGetDeletedTodosForUser(
+		ctx,
+		database_queries.TodoGetDeletedTodosForUserParams{
+			UserID:     int32(userId),
+			PageOffset: int32(offset),
+			PageLimit:  int32(limit),
+		},
+	)
+	if err != nil {
+		zlog.Err(err).Msg("can not get the deleted todos")
+		return []TodoItem{}, err
+	}
+
+	todoItems := make([]TodoItem, len(data))
+
+	for i, v := range data {
+		todoItem, err := todoItemFromDataBase(v)
+		if err != nil {
+			zlog.Err(err).Msg("can not convert database.Todo to TodoItem")
+			return []TodoItem{}, err
+		}
+		todoItems[i] = todoItem
+	}
+
+	if err := repo.attachDetails(ctx, userId, todoItems); err != nil {
+		return []TodoItem{}, err
+	}
+
+	return todoItems, nil
+}
+
+func (repo repositoryImpl) GetDeletedTodosAfterCursor(ctx context.Context, userId int, cursor *TodoTrashCursor, limit int) ([]TodoItem, error) {
+	zlog := zerolog.Ctx(ctx)
+
+	var cursorDeletedAt pgtype.Timestamptz
+	var cursorId pgtype.Int4
+	if cursor != nil {
+		cursorDeletedAt = pgtype.Timestamptz{Time: cursor.DeletedAt, Valid: true}
+		cursorId = pgtype.Int4{Int32: int32(cursor.Id), Valid: true}
+	}
+
+	data, err := repo.db.Queries.TodoGetDeletedTodosForUserAfterCursor(
+		ctx,
+		database_queries.TodoGetDeletedTodosForUserAfterCursorParams{
+			UserID:          int32(userId),
+			CursorDeletedAt: cursorDeletedAt,
+			CursorID:        cursorId,
+			PageLimit:       int32(limit),
 		},
 	)
+	if err != nil {
+		zlog.Err(err).Msg("can not get the deleted todos")
+		return []TodoItem{}, err
+	}
+
+	todoItems := make([]TodoItem, len(data))
+
+	for i, v := range data {
+		todoItem, err := todoItemFromDataBase(v)
+		if err != nil {
+			zlog.Err(err).Msg("can not convert database.Todo to TodoItem")
+			return []TodoItem{}, err
+		}
+		todoItems[i] = todoItem
+	}
+
+	if err := repo.attachDetails(ctx, userId, todoItems); err != nil {
+		return []TodoItem{}, err
+	}
 
+	return todoItems, nil
+}
+
+func (repo repositoryImpl) RestoreTodo(ctx context.Context, userId, todoId int) (TodoItem, error) {
+	zlog := zerolog.Ctx(ctx).With().Int("todo_id", todoId).Logger()
+
+	var res database_queries.Todo
+	err := repo.usingTransaction(ctx, func(queries *database_queries.Queries) error {
+		var err error
+		res, err = queries.TodoRestoreTodoLinkedToUser(
+			ctx,
+			database_queries.TodoRestoreTodoLinkedToUserParams{
+				ID:     int32(todoId),
+				UserID: int32(userId),
+			},
+		)
+		if err != nil {
+			return err
+		}
+		return recordActivity(ctx, queries, todoId, userId, TodoActivityActionRestored, nil)
+	})
 	if err != nil {
 		if dbutils.IsErrPgxNoRows(err) {
 			err = apperr.ErrNoResult
 		} else {
-			zlog.Err(err).Msg("can not create todo")
+			zlog.Err(err).Msg("can not restore todo")
 		}
 		return TodoItem{}, err
 	}
 
-	createdTodo, err := todoItemFromDataBase(res)
+	restoredTodo, err := todoItemFromDataBase(res)
 	if err != nil {
 		zlog.Err(err).Msg("can not convert database.Todo to TodoItem")
 		return TodoItem{}, err
 	}
 
-	return createdTodo, nil
+	todoItems := []TodoItem{restoredTodo}
+	if err := repo.attachDetails(ctx, userId, todoItems); err != nil {
+		return TodoItem{}, err
+	}
+
+	return todoItems[0], nil
 }
 
-func (repo repositoryImpl) DeleteTodo(ctx context.Context, userId, todoId int) error {
+func (repo repositoryImpl) PurgeTodo(ctx context.Context, userId, todoId int) error {
 	zlog := zerolog.Ctx(ctx).With().Int("todo_id", todoId).Logger()
 
-	err := repo.db.Queries.TodoSoftDeleteTodoLinkedToUser(
-		ctx,
-		database_queries.TodoSoftDeleteTodoLinkedToUserParams{
-			ID:     int32(todoId),
-			UserID: int32(userId),
-		},
-	)
-
-	if dbutils.IsErrPgxNoRows(err) {
-		err = apperr.ErrNoResult
-	} else {
-		zlog.Err(err).Msg("can not delete todo")
+	var rowsAffected int64
+	err := repo.usingTransaction(ctx, func(queries *database_queries.Queries) error {
+		var err error
+		rowsAffected, err = queries.TodoPurgeTodoLinkedToUser(
+			ctx,
+			database_queries.TodoPurgeTodoLinkedToUserParams{
+				ID:     int32(todoId),
+				UserID: int32(userId),
+			},
+		)
+		if err != nil || rowsAffected == 0 {
+			return err
+		}
+		return recordActivity(ctx, queries, todoId, userId, TodoActivityActionPurged, nil)
+	})
+	if err != nil {
+		zlog.Err(err).Msg("can not purge todo")
+		return err
+	}
+	if rowsAffected == 0 {
+		// only the todos in the trash can be purged
+		return apperr.ErrNoResult
 	}
 
-	return err
+	return nil
+}
+
+//---------------------------------------------------------------------------------
+
+// GetTodoChanges returns the todos that are created, updated or deleted after
+// the since token (nil for all of them), in the order of their changes
+func (repo repositoryImpl) GetTodoChanges(ctx context.Context, userId int, since *TodoSyncToken, limit int) (TodoChanges, error) {
+	zlog := zerolog.Ctx(ctx)
+
+	listIds, err := repo.db.Queries.TodoSyncGetListIdsOfUser(ctx, int32(userId))
+	if err != nil {
+		zlog.Err(err).Msg("can not get the todo lists of the user")
+		return TodoChanges{}, err
+	}
+
+	retention, err := trashRetention()
+	if err != nil {
+		return TodoChanges{}, err
+	}
+
+	changes := TodoChanges{Todos: []TodoItem{}, Deleted: []TodoTombstone{}}
+	position := TodoSyncToken{Lists: todoSyncListsHash(listIds), IssuedAt: time.Now()}
+	if since != nil {
+		// the todos of the joined or left lists are not in the changes, and the tombstones
+		// are purged with the trash, so the sync starts over for the client
+		if since.Lists != position.Lists || since.Xid == 0 || since.IssuedAt.Before(time.Now().Add(-retention)) {
+			changes.Reset = true
+		} else {
+			position.Xid, position.Id = since.Xid, since.Id
+		}
+	}
+
+	// read before the changes, so all the transactions below it are
+	// committed (or rolled back) when the changes are read
+	horizon, err := repo.db.Queries.TodoSyncGetHorizon(ctx)
+	if err != nil {
+		zlog.Err(err).Msg("can not get the todo sync horizon")
+		return TodoChanges{}, err
+	}
+
+	rows, err := repo.db.Queries.TodoSyncGetChanges(
+		ctx,
+		database_queries.TodoSyncGetChangesParams{
+			UserID:    int32(userId),
+			SinceXid:  position.Xid,
+			SinceID:   int32(position.Id),
+			Horizon:   horizon,
+			PageLimit: int32(limit + 1),
+		},
+	)
+	if err != nil {
+		zlog.Err(err).Msg("can not get the todo changes")
+		return TodoChanges{}, err
+	}
+
+	changes.HasMore = len(rows) > limit
+	if changes.HasMore {
+		rows = rows[:limit]
+	}
+
+	todoIds := make([]int32, len(rows))
+	for i, row := range rows {
+		todoIds[i] = row.TodoID
+	}
+	// the current state of the todos, the tombstones of the todos that
+	// are moved between the lists of the user are not deletions for them
+	data, err := repo.db.Queries.TodoSyncGetTodos(
+		ctx,
+		database_queries.TodoSyncGetTodosParams{
+			TodoIds: todoIds,
+			UserID:  int32(userId),
+		},
+	)
+	if err != nil {
+		zlog.Err(err).Msg("can not get the changed todos")
+		return TodoChanges{}, err
+	}
+	todoOfId := make(map[int32]database_queries.Todo, len(data))
+	for _, v := range data {
+		todoOfId[v.ID] = v
+	}
+
+	handled := make(map[int32]bool, len(rows))
+	for _, row := range rows {
+		if handled[row.TodoID] {
+			continue
+		}
+		handled[row.TodoID] = true
+
+		td, ok := todoOfId[row.TodoID]
+		switch {
+		case !ok:
+			changes.Deleted = append(changes.Deleted, TodoTombstone{Id: int(row.TodoID), DeletedAt: row.ChangedAt.Time})
+		case td.DeletedAt.Valid:
+			changes.Deleted = append(changes.Deleted, TodoTombstone{Id: int(td.ID), DeletedAt: td.DeletedAt.Time})
+		default:
+			todoItem, err := todoItemFromDataBase(td)
+			if err != nil {
+				zlog.Err(err).Int("todo_id", int(td.ID)).Msg("can not convert database.Todo to TodoItem")
+				return TodoChanges{}, err
+			}
+			changes.Todos = append(changes.Todos, todoItem)
+		}
+	}
+
+	if err := repo.attachDetails(ctx, userId, changes.Todos); err != nil {
+		return TodoChanges{}, err
+	}
+
+	if changes.HasMore {
+		last := rows[len(rows)-1]
+		position.Xid, position.Id = last.Xid, int(last.TodoID)
+	} else {
+		// all the changes below the horizon are returned, and the
+		// ones of the running transactions are at or above it
+		position.Xid, position.Id = horizon, 0
+	}
+	changes.Next = position
+
+	return changes, nil
+}
+
+// todoSyncListsHash is a short hash of the ids of the lists that the user is a member of
+func todoSyncListsHash(listIds []int32) string {
+	hash := sha256.New()
+	for _, id := range listIds {
+		fmt.Fprintf(hash, "%d,", id)
+	}
+	return base64.RawURLEncoding.EncodeToString(hash.Sum(nil)[:12])
+}
+
+//---------------------------------------------------------------------------------
+
+// attachDetails loads the details of the todos that are not in the todo table,
+// with one query per detail for all the todos
+func (repo repositoryImpl) attachDetails(ctx context.Context, userId int, todoItems []TodoItem) error {
+	if err := repo.attachChecklistProgress(ctx, todoItems); err != nil {
+		return err
+	}
+	if err := repo.attachTags(ctx, userId, todoItems); err != nil {
+		return err
+	}
+	return repo.attachCompletion(ctx, todoItems)
+}
+
+// attachChecklistProgress sets the checklist counts of the todos, with one query for all of them
+func (repo repositoryImpl) attachChecklistProgress(ctx context.Context, todoItems []TodoItem) error {
+	if len(todoItems) == 0 {
+		return nil
+	}
+
+	todoIds := make([]int32, len(todoItems))
+	for i, v := range todoItems {
+		todoIds[i] = int32(v.Id)
+	}
+
+	progress, err := repo.db.Queries.TodoChecklistGetProgressOfTodos(ctx, todoIds)
+	if err != nil {
+		zerolog.Ctx(ctx).Err(err).Msg("can not get the checklist progress of the todos")
+		return err
+	}
+
+	progressOfTodo := make(map[int]database_queries.TodoChecklistGetProgressOfTodosRow, len(progress))
+	for _, p := range progress {
+		progressOfTodo[int(p.TodoID)] = p
+	}
+
+	for i := range todoItems {
+		if p, ok := progressOfTodo[todoItems[i].Id]; ok {
+			todoItems[i].ChecklistTotal = int(p.Total)
+			todoItems[i].ChecklistDone = int(p.Done)
+		}
+	}
+
+	return nil
+}
+
+// checkCanWriteTodo returns ErrNoResult if the user can not see the todo,
+// and ErrPermissionDenied if the todo is in a shared list where the user is only a viewer
+func (repo repositoryImpl) checkCanWriteTodo(ctx context.Context, userId, todoId int) error {
+	todoItem, err := repo.GetTodo(ctx, userId, todoId)
+	if err != nil {
+		return err
+	}
+	if todoItem.ListId == nil {
+		// the todos without a list are only visible to the users who created them
+		return nil
+	}
+
+	list, err := repo.GetList(ctx, userId, *todoItem.ListId)
+	if err != nil {
+		return err
+	}
+	if !list.Role.CanWrite() {
+		return apperr.ErrPermissionDenied
+	}
+	return nil
+}
+
+func (repo repositoryImpl) GetChecklistItems(ctx context.Context, userId, todoId int) ([]TodoChecklistItem, error) {
+	if _, err := repo.GetTodo(ctx, userId, todoId); err != nil {
+		return []TodoChecklistItem{}, err
+	}
+
+	data, err := repo.db.Queries.TodoChecklistGetItems(ctx, int32(todoId))
+	if err != nil {
+		zerolog.Ctx(ctx).Err(err).Int("todo_id", todoId).Msg("can not get the checklist items")
+		return []TodoChecklistItem{}, err
+	}
+
+	items := make([]TodoChecklistItem, len(data))
+	for i, v := range data {
+		items[i] = todoChecklistItemFromDataBase(v)
+	}
+
+	return items, nil
+}
+
+func (repo repositoryImpl) CreateChecklistItem(ctx context.Context, userId, todoId int, data TodoChecklistItemData) (TodoChecklistItem, error) {
+	if err := repo.checkCanWriteTodo(ctx, userId, todoId); err != nil {
+		return TodoChecklistItem{}, err
+	}
+
+	var res database_queries.TodoChecklistItem
+	err := repo.usingTransaction(ctx, func(queries *database_queries.Queries) error {
+		var err error
+		res, err = queries.TodoChecklistCreateItem(
+			ctx,
+			database_queries.TodoChecklistCreateItemParams{
+				TodoID:   int32(todoId),
+				Text:     *data.Text,
+				Position: dbutils.PtrToPgTypeInt4(data.Position),
+			},
+		)
+		if err != nil {
+			return err
+		}
+		err = recordActivity(
+			ctx,
+			queries,
+			todoId,
+			userId,
+			TodoActivityActionChecklistItemAdded,
+			map[string]TodoFieldChange{"checklist_item": {New: checklistItemValues(res)}},
+		)
+		if err != nil {
+			return err
+		}
+		return queries.TodoTouchTodo(ctx, int32(todoId))
+	})
+	if err != nil {
+		zerolog.Ctx(ctx).Err(err).Int("todo_id", todoId).Msg("can not create the checklist item")
+		return TodoChecklistItem{}, err
+	}
+
+	return todoChecklistItemFromDataBase(res), nil
+}
+
+func (repo repositoryImpl) UpdateChecklistItem(ctx context.Context, userId, todoId, itemId int, data TodoChecklistItemData) (TodoChecklistItem, error) {
+	if err := repo.checkCanWriteTodo(ctx, userId, todoId); err != nil {
+		return TodoChecklistItem{}, err
+	}
+
+	var isDone pgtype.Bool
+	if data.IsDone != nil {
+		isDone = pgtype.Bool{Bool: *data.IsDone, Valid: true}
+	}
+
+	var res database_queries.TodoChecklistItem
+	err := repo.usingTransaction(ctx, func(queries *database_queries.Queries) error {
+		prev, err := queries.TodoChecklistGetItemForUpdate(
+			ctx,
+			database_queries.TodoChecklistGetItemForUpdateParams{
+				ID:     int32(itemId),
+				TodoID: int32(todoId),
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		res, err = queries.TodoChecklistUpdateItem(
+			ctx,
+			database_queries.TodoChecklistUpdateItemParams{
+				ID:       int32(itemId),
+				TodoID:   int32(todoId),
+				Text:     dbutils.PtrToPgTypeText(data.Text),
+				IsDone:   isDone,
+				Position: dbutils.PtrToPgTypeInt4(data.Position),
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		// the position changes are recorded by the reorder
+		if prev.Text != res.Text || prev.IsDone != res.IsDone {
+			err = recordActivity(
+				ctx,
+				queries,
+				todoId,
+				userId,
+				TodoActivityActionChecklistItemUpdated,
+				map[string]TodoFieldChange{"checklist_item": {Old: checklistItemValues(prev), New: checklistItemValues(res)}},
+			)
+			if err != nil {
+				return err
+			}
+		}
+		return queries.TodoTouchTodo(ctx, int32(todoId))
+	})
+	if err != nil {
+		if dbutils.IsErrPgxNoRows(err) {
+			err = apperr.ErrNoResult
+		} else {
+			zerolog.Ctx(ctx).Err(err).Int("item_id", itemId).Msg("can not update the checklist item")
+		}
+		return TodoChecklistItem{}, err
+	}
+
+	return todoChecklistItemFromDataBase(res), nil
+}
+
+// ReorderChecklistItems sets the positions of the items to their order in itemIds
+func (repo repositoryImpl) ReorderChecklistItems(ctx context.Context, userId, todoId int, itemIds []int) ([]TodoChecklistItem, error) {
+	if err := repo.checkCanWriteTodo(ctx, userId, todoId); err != nil {
+		return []TodoChecklistItem{}, err
+	}
+
+	ids := make([]int32, len(itemIds))
+	for i, v := range itemIds {
+		ids[i] = int32(v)
+	}
+
+	err := repo.usingTransaction(ctx, func(queries *database_queries.Queries) error {
+		prevItems, err := queries.TodoChecklistGetItems(ctx, int32(todoId))
+		if err != nil {
+			return err
+		}
+
+		_, err = queries.TodoChecklistReorderItems(
+			ctx,
+			database_queries.TodoChecklistReorderItemsParams{
+				ItemIds: ids,
+				TodoID:  int32(todoId),
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		items, err := queries.TodoChecklistGetItems(ctx, int32(todoId))
+		if err != nil {
+			return err
+		}
+		prevOrder, order := checklistItemIds(prevItems), checklistItemIds(items)
+		if !slices.Equal(prevOrder, order) {
+			err = recordActivity(
+				ctx,
+				queries,
+				todoId,
+				userId,
+				TodoActivityActionChecklistReordered,
+				map[string]TodoFieldChange{"checklist_order": {Old: prevOrder, New: order}},
+			)
+			if err != nil {
+				return err
+			}
+		}
+		return queries.TodoTouchTodo(ctx, int32(todoId))
+	})
+	if err != nil {
+		zerolog.Ctx(ctx).Err(err).Int("todo_id", todoId).Msg("can not reorder the checklist items")
+		return []TodoChecklistItem{}, err
+	}
+
+	return repo.GetChecklistItems(ctx, userId, todoId)
+}
+
+func (repo repositoryImpl) DeleteChecklistItem(ctx context.Context, userId, todoId, itemId int) error {
+	if err := repo.checkCanWriteTodo(ctx, userId, todoId); err != nil {
+		return err
+	}
+
+	err := repo.usingTransaction(ctx, func(queries *database_queries.Queries) error {
+		deleted, err := queries.TodoChecklistDeleteItem(
+			ctx,
+			database_queries.TodoChecklistDeleteItemParams{
+				ID:     int32(itemId),
+				TodoID: int32(todoId),
+			},
+		)
+		if err != nil {
+			return err
+		}
+		err = recordActivity(
+			ctx,
+			queries,
+			todoId,
+			userId,
+			TodoActivityActionChecklistItemRemoved,
+			map[string]TodoFieldChange{"checklist_item": {Old: checklistItemValues(deleted)}},
+		)
+		if err != nil {
+			return err
+		}
+		return queries.TodoTouchTodo(ctx, int32(todoId))
+	})
+	if err != nil {
+		if dbutils.IsErrPgxNoRows(err) {
+			return apperr.ErrNoResult
+		}
+		zerolog.Ctx(ctx).Err(err).Int("item_id", itemId).Msg("can not delete the checklist item")
+		return err
+	}
+
+	return nil
+}
+
+// checklistItemValues returns the fields of the checklist item that are tracked in the activity
+func checklistItemValues(i database_queries.TodoChecklistItem) map[string]any {
+	return map[string]any{
+		"id":      int(i.ID),
+		"text":    i.Text,
+		"is_done": i.IsDone,
+	}
+}
+
+func checklistItemIds(items []database_queries.TodoChecklistItem) []int {
+	ids := make([]int, len(items))
+	for i, item := range items {
+		ids[i] = int(item.ID)
+	}
+	return ids
+}
+
+//---------------------------------------------------------------------------------
+
+func (repo repositoryImpl) GetActivity(ctx context.Context, userId, todoId, offset, limit int) ([]TodoActivity, error) {
+	if _, err := repo.GetTodo(ctx, userId, todoId); err != nil {
+		return []TodoActivity{}, err
+	}
+
+	data, err := repo.db.Queries.TodoActivityGetActivities(
+		ctx,
+		database_queries.TodoActivityGetActivitiesParams{
+			TodoID:     int32(todoId),
+			PageOffset: int32(offset),
+			PageLimit:  int32(limit),
+		},
+	)
+	if err != nil {
+		zerolog.Ctx(ctx).Err(err).Int("todo_id", todoId).Msg("can not get the todo activity")
+		return []TodoActivity{}, err
+	}
+
+	activities := make([]TodoActivity, len(data))
+	for i, v := range data {
+		activities[i], err = todoActivityFromDataBase(v)
+		if err != nil {
+			zerolog.Ctx(ctx).Err(err).Int32("activity_id", v.ID).Msg("can not decode the changes of the todo activity")
+			return []TodoActivity{}, err
+		}
+	}
+
+	return activities, nil
+}
+
+func (repo repositoryImpl) GetActivityAfterCursor(ctx context.Context, userId, todoId int, cursor *TodoCursor, limit int) ([]TodoActivity, error) {
+	if _, err := repo.GetTodo(ctx, userId, todoId); err != nil {
+		return []TodoActivity{}, err
+	}
+
+	cursorCreatedAt, cursorId := todoCursorToPgTypes(cursor)
+
+	data, err := repo.db.Queries.TodoActivityGetActivitiesAfterCursor(
+		ctx,
+		database_queries.TodoActivityGetActivitiesAfterCursorParams{
+			TodoID:          int32(todoId),
+			CursorCreatedAt: cursorCreatedAt,
+			CursorID:        cursorId,
+			PageLimit:       int32(limit),
+		},
+	)
+	if err != nil {
+		zerolog.Ctx(ctx).Err(err).Int("todo_id", todoId).Msg("can not get the todo activity")
+		return []TodoActivity{}, err
+	}
+
+	activities := make([]TodoActivity, len(data))
+	for i, v := range data {
+		activities[i], err = todoActivityFromDataBase(database_queries.TodoActivityGetActivitiesRow(v))
+		if err != nil {
+			zerolog.Ctx(ctx).Err(err).Int32("activity_id", v.ID).Msg("can not decode the changes of the todo activity")
+			return []TodoActivity{}, err
+		}
+	}
+
+	return activities, nil
+}
+
+// todoFieldValues returns the fields of the todo that are tracked in the activity,
+// with nil for the empty fields
+func todoFieldValues(td database_queries.Todo) map[string]any {
+	values := map[string]any{
+		"title":               td.Title,
+		"body":                td.Body,
+		"status":              td.Status,
+		"due_at":              nil,
+		"remind_at":           nil,
+		"list_id":             nil,
+		"recurrence":          nil,
+		"recurrence_timezone": nil,
+	}
+	if td.DueAt.Valid {
+		values["due_at"] = td.DueAt.Time.UTC()
+	}
+	if td.RemindAt.Valid {
+		values["remind_at"] = td.RemindAt.Time.UTC()
+	}
+	if td.ListID.Valid {
+		values["list_id"] = int(td.ListID.Int32)
+	}
+	// the timezone only matters for the recurring todos
+	if td.RecurrenceRule.Valid {
+		values["recurrence"] = td.RecurrenceRule.String
+		values["recurrence_timezone"] = td.RecurrenceTimezone
+	}
+	return values
+}
+
+// todoChanges returns the changed fields between the two versions of the todo,
+// prev is nil for the created todos so all the set fields are returned
+func todoChanges(prev *database_queries.Todo, next database_queries.Todo) map[string]TodoFieldChange {
+	var prevValues map[string]any
+	if prev != nil {
+		prevValues = todoFieldValues(*prev)
+	}
+
+	changes := make(map[string]TodoFieldChange)
+	for field, value := range todoFieldValues(next) {
+		prevValue := prevValues[field]
+
+		if t, ok := value.(time.Time); ok {
+			if prevT, ok := prevValue.(time.Time); ok && t.Equal(prevT) {
+				continue
+			}
+		} else if value == prevValue {
+			continue
+		}
+		if prev == nil && (value == nil || value == "") {
+			continue
+		}
+
+		changes[field] = TodoFieldChange{Old: prevValue, New: value}
+	}
+	return changes
+}
+
+// recordTodoUpdate records the status change and the changes of the other fields
+// as two activities, and nothing if nothing is changed
+func recordTodoUpdate(ctx context.Context, queries *database_queries.Queries, userId int, prev, next database_queries.Todo) error {
+	changes := todoChanges(&prev, next)
+
+	if statusChange, ok := changes["status"]; ok {
+		delete(changes, "status")
+		err := recordActivity(
+			ctx,
+			queries,
+			int(next.ID),
+			userId,
+			TodoActivityActionStatusChanged,
+			map[string]TodoFieldChange{"status": statusChange},
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(changes) == 0 {
+		return nil
+	}
+	return recordActivity(ctx, queries, int(next.ID), userId, TodoActivityActionUpdated, changes)
+}
+
+func recordActivity(ctx context.Context, queries *database_queries.Queries, todoId, userId int, action TodoActivityAction, changes map[string]TodoFieldChange) error {
+	changesJson, err := marshalTodoChanges(changes)
+	if err != nil {
+		return err
+	}
+
+	return queries.TodoActivityCreateActivity(
+		ctx,
+		database_queries.TodoActivityCreateActivityParams{
+			TodoID:  int32(todoId),
+			UserID:  pgtype.Int4{Int32: int32(userId), Valid: true},
+			Action:  action.String(),
+			Changes: changesJson,
+		},
+	)
+}
+
+// recordActivities records the same changes on many todos, with one query
+func recordActivities(ctx context.Context, queries *database_queries.Queries, todoIds []int32, userId int, action TodoActivityAction, changes map[string]TodoFieldChange) error {
+	if len(todoIds) == 0 {
+		return nil
+	}
+
+	changesJson, err := marshalTodoChanges(changes)
+	if err != nil {
+		return err
+	}
+
+	return queries.TodoActivityCreateActivities(
+		ctx,
+		database_queries.TodoActivityCreateActivitiesParams{
+			TodoIds: todoIds,
+			UserID:  int32(userId),
+			Action:  action.String(),
+			Changes: changesJson,
+		},
+	)
+}
+
+func marshalTodoChanges(changes map[string]TodoFieldChange) ([]byte, error) {
+	if changes == nil {
+		changes = map[string]TodoFieldChange{}
+	}
+	return json.Marshal(changes)
+}
+
+//---------------------------------------------------------------------------------
+
+func (repo repositoryImpl) GetComments(ctx context.Context, userId, todoId, offset, limit int) ([]TodoComment, error) {
+	if _, err := repo.GetTodo(ctx, userId, todoId); err != nil {
+		return []TodoComment{}, err
+	}
+
+	data, err := repo.db.Queries.TodoCommentGetComments(
+		ctx,
+		database_queries.TodoCommentGetCommentsParams{
+			TodoID:     int32(todoId),
+			PageOffset: int32(offset),
+			PageLimit:  int32(limit),
+		},
+	)
+	if err != nil {
+		zerolog.Ctx(ctx).Err(err).Int("todo_id", todoId).Msg("can not get the todo comments")
+		return []TodoComment{}, err
+	}
+
+	comments := make([]TodoComment, len(data))
+	for i, v := range data {
+		comments[i] = todoCommentFromDataBase(database_queries.TodoCommentGetCommentRow(v))
+	}
+
+	return comments, nil
+}
+
+func (repo repositoryImpl) GetCommentsAfterCursor(ctx context.Context, userId, todoId int, cursor *TodoCursor, limit int) ([]TodoComment, error) {
+	if _, err := repo.GetTodo(ctx, userId, todoId); err != nil {
+		return []TodoComment{}, err
+	}
+
+	cursorCreatedAt, cursorId := todoCursorToPgTypes(cursor)
+
+	data, err := repo.db.Queries.TodoCommentGetCommentsAfterCursor(
+		ctx,
+		database_queries.TodoCommentGetCommentsAfterCursorParams{
+			TodoID:          int32(todoId),
+			CursorCreatedAt: cursorCreatedAt,
+			CursorID:        cursorId,
+			PageLimit:       int32(limit),
+		},
+	)
+	if err != nil {
+		zerolog.Ctx(ctx).Err(err).Int("todo_id", todoId).Msg("can not get the todo comments")
+		return []TodoComment{}, err
+	}
+
+	comments := make([]TodoComment, len(data))
+	for i, v := range data {
+		comments[i] = todoCommentFromDataBase(database_queries.TodoCommentGetCommentRow(v))
+	}
+
+	return comments, nil
+}
+
+func (repo repositoryImpl) CreateComment(ctx context.Context, userId, todoId int, body string) (TodoComment, error) {
+	// the viewers of the shared lists can also comment
+	if _, err := repo.GetTodo(ctx, userId, todoId); err != nil {
+		return TodoComment{}, err
+	}
+
+	commentId, err := repo.db.Queries.TodoCommentCreateComment(
+		ctx,
+		database_queries.TodoCommentCreateCommentParams{
+			TodoID: int32(todoId),
+			UserID: pgtype.Int4{Int32: int32(userId), Valid: true},
+			Body:   body,
+		},
+	)
+	if err != nil {
+		zerolog.Ctx(ctx).Err(err).Int("todo_id", todoId).Msg("can not create the todo comment")
+		return TodoComment{}, err
+	}
+
+	return repo.getComment(ctx, todoId, int(commentId))
+}
+
+func (repo repositoryImpl) UpdateComment(ctx context.Context, userId, todoId, commentId int, body string) (TodoComment, error) {
+	if _, err := repo.GetTodo(ctx, userId, todoId); err != nil {
+		return TodoComment{}, err
+	}
+
+	rowsAffected, err := repo.db.Queries.TodoCommentUpdateComment(
+		ctx,
+		database_queries.TodoCommentUpdateCommentParams{
+			Body:   body,
+			ID:     int32(commentId),
+			TodoID: int32(todoId),
+			UserID: pgtype.Int4{Int32: int32(userId), Valid: true},
+		},
+	)
+	if err != nil {
+		zerolog.Ctx(ctx).Err(err).Int("comment_id", commentId).Msg("can not update the todo comment")
+		return TodoComment{}, err
+	}
+	if rowsAffected == 0 {
+		return TodoComment{}, repo.notCommentAuthorErr(ctx, todoId, commentId)
+	}
+
+	return repo.getComment(ctx, todoId, commentId)
+}
+
+func (repo repositoryImpl) DeleteComment(ctx context.Context, userId, todoId, commentId int) error {
+	if _, err := repo.GetTodo(ctx, userId, todoId); err != nil {
+		return err
+	}
+
+	rowsAffected, err := repo.db.Queries.TodoCommentDeleteComment(
+		ctx,
+		database_queries.TodoCommentDeleteCommentParams{
+			ID:     int32(commentId),
+			TodoID: int32(todoId),
+			UserID: pgtype.Int4{Int32: int32(userId), Valid: true},
+		},
+	)
+	if err != nil {
+		zerolog.Ctx(ctx).Err(err).Int("comment_id", commentId).Msg("can not delete the todo comment")
+		return err
+	}
+	if rowsAffected == 0 {
+		return repo.notCommentAuthorErr(ctx, todoId, commentId)
+	}
+
+	return nil
+}
+
+func (repo repositoryImpl) getComment(ctx context.Context, todoId, commentId int) (TodoComment, error) {
+	res, err := repo.db.Queries.TodoCommentGetComment(
+		ctx,
+		database_queries.TodoCommentGetCommentParams{
+			ID:     int32(commentId),
+			TodoID: int32(todoId),
+		},
+	)
+	if err != nil {
+		if dbutils.IsErrPgxNoRows(err) {
+			err = apperr.ErrNoResult
+		} else {
+			zerolog.Ctx(ctx).Err(err).Int("comment_id", commentId).Msg("can not get the todo comment")
+		}
+		return TodoComment{}, err
+	}
+
+	return todoCommentFromDataBase(res), nil
+}
+
+// notCommentAuthorErr tells apart the comments that do not exist (ErrNoResult),
+// from the comments of the other users (ErrPermissionDenied)
+func (repo repositoryImpl) notCommentAuthorErr(ctx context.Context, todoId, commentId int) error {
+	if _, err := repo.getComment(ctx, todoId, commentId); err != nil {
+		return err
+	}
+	return apperr.ErrPermissionDenied
+}
+
+//---------------------------------------------------------------------------------
+
+const (
+	todoAttachmentsLimit = 20
+	// the lifetime of the signed download urls
+	todoAttachmentURLExpiry = 15 * time.Minute
+	// the random part of the storage keys
+	todoAttachmentKeyBytesLength = 16
+)
+
+func (repo repositoryImpl) GetAttachments(ctx context.Context, userId, todoId int) ([]TodoAttachment, error) {
+	if _, err := repo.GetTodo(ctx, userId, todoId); err != nil {
+		return []TodoAttachment{}, err
+	}
+
+	data, err := repo.db.Queries.TodoAttachmentGetAttachments(ctx, int32(todoId))
+	if err != nil {
+		zerolog.Ctx(ctx).Err(err).Int("todo_id", todoId).Msg("can not get the attachments")
+		return []TodoAttachment{}, err
+	}
+
+	attachments := make([]TodoAttachment, len(data))
+	for i, v := range data {
+		attachments[i], err = repo.todoAttachmentWithURL(ctx, v)
+		if err != nil {
+			return []TodoAttachment{}, err
+		}
+	}
+
+	return attachments, nil
+}
+
+func (repo repositoryImpl) GetAttachment(ctx context.Context, userId, todoId, attachmentId int) (TodoAttachment, error) {
+	if _, err := repo.GetTodo(ctx, userId, todoId); err != nil {
+		return TodoAttachment{}, err
+	}
+
+	res, err := repo.db.Queries.TodoAttachmentGetAttachment(
+		ctx,
+		database_queries.TodoAttachmentGetAttachmentParams{
+			ID:     int32(attachmentId),
+			TodoID: int32(todoId),
+		},
+	)
+	if err != nil {
+		if dbutils.IsErrPgxNoRows(err) {
+			err = apperr.ErrNoResult
+		} else {
+			zerolog.Ctx(ctx).Err(err).Int("attachment_id", attachmentId).Msg("can not get the attachment")
+		}
+		return TodoAttachment{}, err
+	}
+
+	return repo.todoAttachmentWithURL(ctx, res)
+}
+
+func (repo repositoryImpl) CreateAttachment(ctx context.Context, userId, todoId int, data TodoAttachmentData) (TodoAttachment, error) {
+	zlog := zerolog.Ctx(ctx).With().Int("todo_id", todoId).Logger()
+
+	if err := repo.checkCanWriteTodo(ctx, userId, todoId); err != nil {
+		return TodoAttachment{}, err
+	}
+
+	count, err := repo.db.Queries.TodoAttachmentCountAttachments(ctx, int32(todoId))
+	if err != nil {
+		zlog.Err(err).Msg("can not count the attachments of the todo")
+		return TodoAttachment{}, err
+	}
+	if count >= todoAttachmentsLimit {
+		return TodoAttachment{}, apperr.ErrTooManyTodoAttachments
+	}
+
+	b := make([]byte, todoAttachmentKeyBytesLength)
+	if _, err := rand.Read(b); err != nil {
+		zlog.Err(err).Msg("error while generating the attachment storage key")
+		return TodoAttachment{}, err
+	}
+	storageKey := fmt.Sprintf("todo-attachments/%d/%s", todoId, base64.RawURLEncoding.EncodeToString(b))
+
+	if err := repo.blobStore.Put(ctx, storageKey, data.Content, data.Size, data.ContentType); err != nil {
+		zlog.Err(err).Msg("can not store the attachment file")
+		return TodoAttachment{}, err
+	}
+
+	var res database_queries.TodoAttachment
+	err = repo.usingTransaction(ctx, func(queries *database_queries.Queries) error {
+		var err error
+		res, err = queries.TodoAttachmentCreateAttachment(
+			ctx,
+			database_queries.TodoAttachmentCreateAttachmentParams{
+				TodoID:      int32(todoId),
+				UserID:      pgtype.Int4{Int32: int32(userId), Valid: true},
+				StorageKey:  storageKey,
+				Filename:    data.Filename,
+				ContentType: data.ContentType,
+				SizeBytes:   data.Size,
+			},
+		)
+		if err != nil {
+			return err
+		}
+		return recordActivity(
+			ctx,
+			queries,
+			todoId,
+			userId,
+			TodoActivityActionAttachmentAdded,
+			map[string]TodoFieldChange{"attachment": {New: attachmentValues(res)}},
+		)
+	})
+	if err != nil {
+		zlog.Err(err).Msg("can not create the attachment")
+		// e.g. the todo is purged while uploading the file
+		if err := repo.blobStore.Delete(ctx, storageKey); err != nil {
+			zlog.Err(err).Str("storage_key", storageKey).Msg("can not delete the file of the failed attachment")
+		}
+		return TodoAttachment{}, err
+	}
+
+	return repo.todoAttachmentWithURL(ctx, res)
+}
+
+func (repo repositoryImpl) DeleteAttachment(ctx context.Context, userId, todoId, attachmentId int) error {
+	if err := repo.checkCanWriteTodo(ctx, userId, todoId); err != nil {
+		return err
+	}
+
+	err := repo.usingTransaction(ctx, func(queries *database_queries.Queries) error {
+		deleted, err := queries.TodoAttachmentDeleteAttachment(
+			ctx,
+			database_queries.TodoAttachmentDeleteAttachmentParams{
+				ID:     int32(attachmentId),
+				TodoID: int32(todoId),
+			},
+		)
+		if err != nil {
+			return err
+		}
+		return recordActivity(
+			ctx,
+			queries,
+			todoId,
+			userId,
+			TodoActivityActionAttachmentRemoved,
+			map[string]TodoFieldChange{"attachment": {Old: attachmentValues(deleted)}},
+		)
+	})
+	if err != nil {
+		if dbutils.IsErrPgxNoRows(err) {
+			return apperr.ErrNoResult
+		}
+		zerolog.Ctx(ctx).Err(err).Int("attachment_id", attachmentId).Msg("can not delete the attachment")
+		return err
+	}
+
+	return nil
+}
+
+// attachmentValues returns the fields of the attachment that are tracked in the activity
+func attachmentValues(a database_queries.TodoAttachment) map[string]any {
+	return map[string]any{
+		"id":           int(a.ID),
+		"filename":     a.Filename,
+		"content_type": a.ContentType,
+		"size":         a.SizeBytes,
+	}
+}
+
+func (repo repositoryImpl) todoAttachmentWithURL(ctx context.Context, a database_queries.TodoAttachment) (TodoAttachment, error) {
+	attachment := todoAttachmentFromDataBase(a)
+
+	var err error
+	attachment.URLExpiresAt = time.Now().Add(todoAttachmentURLExpiry)
+	attachment.URL, err = repo.blobStore.SignedURL(ctx, a.StorageKey, a.Filename, todoAttachmentURLExpiry)
+	if err != nil {
+		zerolog.Ctx(ctx).Err(err).Int32("attachment_id", a.ID).Msg("can not sign the attachment url")
+		return TodoAttachment{}, err
+	}
+
+	return attachment, nil
+}
+
+//---------------------------------------------------------------------------------
+
+func (repo repositoryImpl) GetWorkflow(ctx context.Context, userId int) (TodoWorkflow, error) {
+	workflow, err := getWorkflow(ctx, repo.db.Queries, userId)
+	if err != nil {
+		zerolog.Ctx(ctx).Err(err).Msg("can not get the todo workflow")
+		return TodoWorkflow{}, err
+	}
+	return workflow, nil
+}
+
+// getWorkflow creates the default workflow for the users that did not use the workflow before
+func getWorkflow(ctx context.Context, queries *database_queries.Queries, userId int) (TodoWorkflow, error) {
+	data, err := queries.TodoWorkflowGetStatuses(ctx, int32(userId))
+	if err != nil {
+		return TodoWorkflow{}, err
+	}
+
+	if len(data) == 0 {
+		if err := queries.TodoWorkflowCreateDefaultStatuses(ctx, int32(userId)); err != nil {
+			return TodoWorkflow{}, err
+		}
+		data, err = queries.TodoWorkflowGetStatuses(ctx, int32(userId))
+		if err != nil {
+			return TodoWorkflow{}, err
+		}
+	}
+
+	workflow := make(TodoWorkflow, len(data))
+	for i, v := range data {
+		workflow[i] = todoWorkflowStatusFromDataBase(v)
+	}
+
+	return workflow, nil
+}
+
+func (repo repositoryImpl) CreateWorkflowStatus(ctx context.Context, userId int, data TodoWorkflowStatusData) (TodoWorkflowStatus, error) {
+	var res database_queries.TodoWorkflowStatus
+	err := repo.usingTransaction(ctx, func(queries *database_queries.Queries) error {
+		// the default workflow is created first, so the new status is added to it
+		if _, err := getWorkflow(ctx, queries, userId); err != nil {
+			return err
+		}
+
+		var err error
+		res, err = queries.TodoWorkflowCreateStatus(
+			ctx,
+			database_queries.TodoWorkflowCreateStatusParams{
+				UserID:   int32(userId),
+				Name:     *data.Name,
+				Position: dbutils.PtrToPgTypeInt4(data.Position),
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		if data.IsCompleted {
+			res.IsCompleted = true
+			return setCompletedWorkflowStatus(ctx, queries, userId, int(res.ID))
+		}
+		return nil
+	})
+	if err != nil {
+		if dbutils.IsErrPgxUniqueViolation(err) {
+			return TodoWorkflowStatus{}, apperr.ErrTodoWorkflowStatusAlreadyExists
+		}
+		zerolog.Ctx(ctx).Err(err).Msg("can not create the todo workflow status")
+		return TodoWorkflowStatus{}, err
+	}
+
+	return todoWorkflowStatusFromDataBase(res), nil
+}
+
+func (repo repositoryImpl) UpdateWorkflowStatus(ctx context.Context, userId, statusId int, data TodoWorkflowStatusData) (TodoWorkflowStatus, error) {
+	var res database_queries.TodoWorkflowStatus
+	err := repo.usingTransaction(ctx, func(queries *database_queries.Queries) error {
+		workflow, err := getWorkflow(ctx, queries, userId)
+		if err != nil {
+			return err
+		}
+		prev, ok := workflow.byId(statusId)
+		if !ok {
+			return apperr.ErrNoResult
+		}
+
+		res, err = queries.TodoWorkflowUpdateStatus(
+			ctx,
+			database_queries.TodoWorkflowUpdateStatusParams{
+				ID:       int32(statusId),
+				UserID:   int32(userId),
+				Name:     dbutils.PtrToPgTypeText(data.Name),
+				Position: dbutils.PtrToPgTypeInt4(data.Position),
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		if res.Name != prev.Name.String() {
+			if err := changeStatusOfUserTodos(ctx, queries, userId, prev.Name, TodoStatus(res.Name)); err != nil {
+				return err
+			}
+		}
+
+		if data.IsCompleted && !res.IsCompleted {
+			res.IsCompleted = true
+			return setCompletedWorkflowStatus(ctx, queries, userId, statusId)
+		}
+		return nil
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, apperr.ErrNoResult):
+		case dbutils.IsErrPgxUniqueViolation(err):
+			err = apperr.ErrTodoWorkflowStatusAlreadyExists
+		default:
+			zerolog.Ctx(ctx).Err(err).Int("status_id", statusId).Msg("can not update the todo workflow status")
+		}
+		return TodoWorkflowStatus{}, err
+	}
+
+	return todoWorkflowStatusFromDataBase(res), nil
+}
+
+// changeStatusOfUserTodos moves the todos of the user from a status to another,
+// e.g. when the status is renamed or deleted
+func changeStatusOfUserTodos(ctx context.Context, queries *database_queries.Queries, userId int, from, to TodoStatus) error {
+	todoIds, err := queries.TodoChangeStatusOfUserTodos(
+		ctx,
+		database_queries.TodoChangeStatusOfUserTodosParams{
+			ToStatus:   to.String(),
+			UserID:     int32(userId),
+			FromStatus: from.String(),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return recordActivities(
+		ctx,
+		queries,
+		todoIds,
+		userId,
+		TodoActivityActionStatusChanged,
+		map[string]TodoFieldChange{"status": {Old: from, New: to}},
+	)
+}
+
+// setCompletedWorkflowStatus moves the completed flag, in two steps to not
+// have two completed statuses in between (the unique index is not deferrable)
+func setCompletedWorkflowStatus(ctx context.Context, queries *database_queries.Queries, userId, statusId int) error {
+	workflow, err := getWorkflow(ctx, queries, userId)
+	if err != nil {
+		return err
+	}
+
+	if err := queries.TodoWorkflowClearCompletedStatus(ctx, int32(userId)); err != nil {
+		return err
+	}
+
+	rowsAffected, err := queries.TodoWorkflowSetCompletedStatus(
+		ctx,
+		database_queries.TodoWorkflowSetCompletedStatusParams{
+			ID:     int32(statusId),
+			UserID: int32(userId),
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return apperr.ErrNoResult
+	}
+
+	// the todos with the previous and the new completed statuses are changed for the delta sync
+	var statuses []string
+	for _, s := range workflow {
+		if s.IsCompleted || s.Id == statusId {
+			statuses = append(statuses, s.Name.String())
+		}
+	}
+	return queries.TodoTouchTodosOfUserWithStatuses(
+		ctx,
+		database_queries.TodoTouchTodosOfUserWithStatusesParams{
+			UserID:   int32(userId),
+			Statuses: statuses,
+		},
+	)
+}
+
+func (repo repositoryImpl) ReorderWorkflowStatuses(ctx context.Context, userId int, statusIds []int) (TodoWorkflow, error) {
+	ids := make([]int32, len(statusIds))
+	for i, v := range statusIds {
+		ids[i] = int32(v)
+	}
+
+	_, err := repo.db.Queries.TodoWorkflowReorderStatuses(
+		ctx,
+		database_queries.TodoWorkflowReorderStatusesParams{
+			StatusIds: ids,
+			UserID:    int32(userId),
+		},
+	)
+	if err != nil {
+		zerolog.Ctx(ctx).Err(err).Msg("can not reorder the todo workflow statuses")
+		return TodoWorkflow{}, err
+	}
+
+	return repo.GetWorkflow(ctx, userId)
+}
+
+func (repo repositoryImpl) DeleteWorkflowStatus(ctx context.Context, userId, statusId int, moveToStatusId *int) error {
+	err := repo.usingTransaction(ctx, func(queries *database_queries.Queries) error {
+		workflow, err := getWorkflow(ctx, queries, userId)
+		if err != nil {
+			return err
+		}
+		status, ok := workflow.byId(statusId)
+		if !ok {
+			return apperr.ErrNoResult
+		}
+		// so there is always a completed status, and at least one status
+		if status.IsCompleted {
+			return apperr.ErrCannotDeleteCompletedTodoStatus
+		}
+
+		var moveTo TodoWorkflowStatus
+		if moveToStatusId != nil {
+			moveTo, ok = workflow.byId(*moveToStatusId)
+			if !ok || moveTo.Id == statusId {
+				return apperr.ErrUnsupportedTodoStatus
+			}
+		} else {
+			moveTo, _ = workflow.find(func(s TodoWorkflowStatus) bool { return s.Id != statusId })
+		}
+
+		if err := changeStatusOfUserTodos(ctx, queries, userId, status.Name, moveTo.Name); err != nil {
+			return err
+		}
+
+		_, err = queries.TodoWorkflowDeleteStatus(
+			ctx,
+			database_queries.TodoWorkflowDeleteStatusParams{
+				ID:     int32(statusId),
+				UserID: int32(userId),
+			},
+		)
+		return err
+	})
+	if err != nil {
+		if !apperr.IsAppErr(err) {
+			zerolog.Ctx(ctx).Err(err).Int("status_id", statusId).Msg("can not delete the todo workflow status")
+		}
+		return err
+	}
+
+	return nil
+}
+
+// attachCompletion tells if the todos are in the completed status of the workflows
+// of their owners, with one query for all of them
+func (repo repositoryImpl) attachCompletion(ctx context.Context, todoItems []TodoItem) error {
+	if len(todoItems) == 0 {
+		return nil
+	}
+
+	userIds := make([]int, len(todoItems))
+	for i, v := range todoItems {
+		userIds[i] = v.UserId
+	}
+
+	data, err := repo.db.Queries.TodoWorkflowGetCompletedStatusesOfUsers(ctx, uniqueInt32s(userIds))
+	if err != nil {
+		zerolog.Ctx(ctx).Err(err).Msg("can not get the completed statuses of the todos")
+		return err
+	}
+
+	completedStatusOfUser := make(map[int]TodoStatus, len(data))
+	for _, v := range data {
+		completedStatusOfUser[int(v.UserID)] = TodoStatus(v.Name)
+	}
+
+	for i := range todoItems {
+		completedStatus, ok := completedStatusOfUser[todoItems[i].UserId]
+		if !ok {
+			// the user did not use the workflow yet, so it is the default one
+			completedStatus = TodoStatusDone
+		}
+		todoItems[i].IsCompleted = todoItems[i].Status == completedStatus
+	}
+
+	return nil
+}
+
+//---------------------------------------------------------------------------------
+
+func (repo repositoryImpl) GetTags(ctx context.Context, userId int) ([]TodoTag, error) {
+	data, err := repo.db.Queries.TodoTagGetTagsForUser(ctx, int32(userId))
+	if err != nil {
+		zerolog.Ctx(ctx).Err(err).Msg("can not get the todo tags")
+		return []TodoTag{}, err
+	}
+
+	tags := make([]TodoTag, len(data))
+	for i, v := range data {
+		tags[i] = todoTagFromDataBase(v)
+	}
+
+	return tags, nil
+}
+
+func (repo repositoryImpl) CreateTag(ctx context.Context, userId int, data TodoTagData) (TodoTag, error) {
+	tag, err := repo.db.Queries.TodoTagCreateTag(
+		ctx,
+		database_queries.TodoTagCreateTagParams{
+			UserID: int32(userId),
+			Name:   *data.Name,
+			Color:  dbutils.PtrToPgTypeText(data.Color),
+		},
+	)
+	if err != nil {
+		if dbutils.IsErrPgxUniqueViolation(err) {
+			return TodoTag{}, apperr.ErrTodoTagAlreadyExists
+		}
+		zerolog.Ctx(ctx).Err(err).Msg("can not create the todo tag")
+		return TodoTag{}, err
+	}
+
+	return todoTagFromDataBase(tag), nil
+}
+
+func (repo repositoryImpl) UpdateTag(ctx context.Context, userId, tagId int, data TodoTagData) (TodoTag, error) {
+	var tag database_queries.TodoTag
+	err := repo.usingTransaction(ctx, func(queries *database_queries.Queries) error {
+		var err error
+		tag, err = queries.TodoTagUpdateTag(
+			ctx,
+			database_queries.TodoTagUpdateTagParams{
+				ID:         int32(tagId),
+				UserID:     int32(userId),
+				Name:       dbutils.PtrToPgTypeText(data.Name),
+				ClearColor: data.ClearColor,
+				Color:      dbutils.PtrToPgTypeText(data.Color),
+			},
+		)
+		if err != nil {
+			return err
+		}
+		return queries.TodoTouchTodosOfTag(
+			ctx,
+			database_queries.TodoTouchTodosOfTagParams{
+				TagID:  tag.ID,
+				UserID: int32(userId),
+			},
+		)
+	})
+	if err != nil {
+		switch {
+		case dbutils.IsErrPgxNoRows(err):
+			err = apperr.ErrNoResult
+		case dbutils.IsErrPgxUniqueViolation(err):
+			err = apperr.ErrTodoTagAlreadyExists
+		default:
+			zerolog.Ctx(ctx).Err(err).Int("tag_id", tagId).Msg("can not update the todo tag")
+		}
+		return TodoTag{}, err
+	}
+
+	return todoTagFromDataBase(tag), nil
+}
+
+// DeleteTag also removes the tag from all the todos
+func (repo repositoryImpl) DeleteTag(ctx context.Context, userId, tagId int) error {
+	var rowsAffected int64
+	err := repo.usingTransaction(ctx, func(queries *database_queries.Queries) error {
+		// before the delete, that removes the links of the tag
+		err := queries.TodoTouchTodosOfTag(
+			ctx,
+			database_queries.TodoTouchTodosOfTagParams{
+				TagID:  int32(tagId),
+				UserID: int32(userId),
+			},
+		)
+		if err != nil {
+			return err
+		}
+		rowsAffected, err = queries.TodoTagDeleteTag(
+			ctx,
+			database_queries.TodoTagDeleteTagParams{
+				ID:     int32(tagId),
+				UserID: int32(userId),
+			},
+		)
+		return err
+	})
+	if err != nil {
+		zerolog.Ctx(ctx).Err(err).Int("tag_id", tagId).Msg("can not delete the todo tag")
+		return err
+	}
+	if rowsAffected == 0 {
+		return apperr.ErrNoResult
+	}
+
+	return nil
+}
+
+// AttachTagToTodo tags any todo that the user can read, the tag is only visible to the user
+func (repo repositoryImpl) AttachTagToTodo(ctx context.Context, userId, todoId, tagId int) error {
+	if _, err := repo.GetTodo(ctx, userId, todoId); err != nil {
+		return err
+	}
+
+	if err := repo.checkTagsOfUser(ctx, userId, []int{tagId}); err != nil {
+		if errors.Is(err, apperr.ErrInvalidTodoTag) {
+			return apperr.ErrNoResult
+		}
+		return err
+	}
+
+	err := repo.usingTransaction(ctx, func(queries *database_queries.Queries) error {
+		attached, err := queries.TodoTagAttachTags(
+			ctx,
+			database_queries.TodoTagAttachTagsParams{
+				TodoID: int32(todoId),
+				TagIds: []int32{int32(tagId)},
+			},
+		)
+		if err != nil || attached == 0 {
+			return err
+		}
+		err = recordActivity(
+			ctx,
+			queries,
+			todoId,
+			userId,
+			TodoActivityActionTagAdded,
+			map[string]TodoFieldChange{"tag_id": {New: tagId}},
+		)
+		if err != nil {
+			return err
+		}
+		return queries.TodoTouchTodo(ctx, int32(todoId))
+	})
+	if err != nil {
+		zerolog.Ctx(ctx).Err(err).Int("todo_id", todoId).Int("tag_id", tagId).Msg("can not attach the tag to the todo")
+		return err
+	}
+
+	return nil
+}
+
+func (repo repositoryImpl) DetachTagFromTodo(ctx context.Context, userId, todoId, tagId int) error {
+	if _, err := repo.GetTodo(ctx, userId, todoId); err != nil {
+		return err
+	}
+
+	var rowsAffected int64
+	err := repo.usingTransaction(ctx, func(queries *database_queries.Queries) error {
+		var err error
+		rowsAffected, err = queries.TodoTagDetachTag(
+			ctx,
+			database_queries.TodoTagDetachTagParams{
+				TodoID: int32(todoId),
+				TagID:  int32(tagId),
+				UserID: int32(userId),
+			},
+		)
+		if err != nil || rowsAffected == 0 {
+			return err
+		}
+		err = recordActivity(
+			ctx,
+			queries,
+			todoId,
+			userId,
+			TodoActivityActionTagRemoved,
+			map[string]TodoFieldChange{"tag_id": {Old: tagId}},
+		)
+		if err != nil {
+			return err
+		}
+		return queries.TodoTouchTodo(ctx, int32(todoId))
+	})
+	if err != nil {
+		zerolog.Ctx(ctx).Err(err).Int("todo_id", todoId).Int("tag_id", tagId).Msg("can not detach the tag from the todo")
+		return err
+	}
+	if rowsAffected == 0 {
+		return apperr.ErrNoResult
+	}
+
+	return nil
+}
+
+// checkTagsOfUser returns ErrInvalidTodoTag if any of the tags does not belong to the user
+func (repo repositoryImpl) checkTagsOfUser(ctx context.Context, userId int, tagIds []int) error {
+	ids := uniqueInt32s(tagIds)
+	if len(ids) == 0 {
+		return nil
+	}
+
+	count, err := repo.db.Queries.TodoTagCountTagsOfUser(
+		ctx,
+		database_queries.TodoTagCountTagsOfUserParams{
+			UserID: int32(userId),
+			TagIds: ids,
+		},
+	)
+	if err != nil {
+		zerolog.Ctx(ctx).Err(err).Msg("can not count the todo tags of the user")
+		return err
+	}
+	if int(count) != len(ids) {
+		return apperr.ErrInvalidTodoTag
+	}
+
+	return nil
+}
+
+// setTagsOfTodo replaces the tags of the user on the todo, the tags of the other
+// members of a shared list are kept
+func setTagsOfTodo(ctx context.Context, queries *database_queries.Queries, userId, todoId int, tagIds []int) error {
+	err := queries.TodoTagDetachTagsOfUserFromTodo(
+		ctx,
+		database_queries.TodoTagDetachTagsOfUserFromTodoParams{
+			TodoID: int32(todoId),
+			UserID: int32(userId),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	if len(tagIds) == 0 {
+		return nil
+	}
+	_, err = queries.TodoTagAttachTags(
+		ctx,
+		database_queries.TodoTagAttachTagsParams{
+			TodoID: int32(todoId),
+			TagIds: uniqueInt32s(tagIds),
+		},
+	)
+	return err
+}
+
+// attachTags sets the tags of the user on the todos, with one query for all of them
+func (repo repositoryImpl) attachTags(ctx context.Context, userId int, todoItems []TodoItem) error {
+	if len(todoItems) == 0 {
+		return nil
+	}
+
+	todoIds := make([]int32, len(todoItems))
+	for i, v := range todoItems {
+		todoIds[i] = int32(v.Id)
+	}
+
+	data, err := repo.db.Queries.TodoTagGetTagsOfTodos(
+		ctx,
+		database_queries.TodoTagGetTagsOfTodosParams{
+			TodoIds: todoIds,
+			UserID:  int32(userId),
+		},
+	)
+	if err != nil {
+		zerolog.Ctx(ctx).Err(err).Msg("can not get the tags of the todos")
+		return err
+	}
+
+	tagsOfTodo := make(map[int][]TodoTag, len(todoItems))
+	for _, v := range data {
+		tagsOfTodo[int(v.TodoID)] = append(tagsOfTodo[int(v.TodoID)], todoTagFromDataBase(v.TodoTag))
+	}
+
+	for i := range todoItems {
+		todoItems[i].Tags = tagsOfTodo[todoItems[i].Id]
+	}
+
+	return nil
+}
+
+//---------------------------------------------------------------------------------
+
+func (repo repositoryImpl) GetLists(ctx context.Context, userId int, includeArchived bool) ([]TodoList, error) {
+	data, err := repo.db.Queries.TodoListGetListsForUser(
+		ctx,
+		database_queries.TodoListGetListsForUserParams{
+			UserID:          int32(userId),
+			IncludeArchived: includeArchived,
+		},
+	)
+	if err != nil {
+		zerolog.Ctx(ctx).Err(err).Msg("can not get the todo lists")
+		return []TodoList{}, err
+	}
+
+	lists := make([]TodoList, len(data))
+	for i, v := range data {
+		lists[i] = todoListFromDataBase(v.TodoList, v.Role)
+	}
+
+	return lists, nil
+}
+
+// GetList returns the list if the user is a member of it
+func (repo repositoryImpl) GetList(ctx context.Context, userId, listId int) (TodoList, error) {
+	res, err := repo.db.Queries.TodoListGetListLinkedToUser(
+		ctx,
+		database_queries.TodoListGetListLinkedToUserParams{
+			ID:     int32(listId),
+			UserID: int32(userId),
+		},
+	)
+	if err != nil {
+		if dbutils.IsErrPgxNoRows(err) {
+			err = apperr.ErrNoResult
+		} else {
+			zerolog.Ctx(ctx).Err(err).Int("list_id", listId).Msg("can not get the todo list")
+		}
+		return TodoList{}, err
+	}
+
+	return todoListFromDataBase(res.TodoList, res.Role), nil
+}
+
+// checkCanWriteToList makes sure that the user does not add todos
+// to the lists that they are not members of, or only viewers in
+func (repo repositoryImpl) checkCanWriteToList(ctx context.Context, userId, listId int) error {
+	list, err := repo.GetList(ctx, userId, listId)
+	if err != nil {
+		if errors.Is(err, apperr.ErrNoResult) {
+			return apperr.ErrInvalidTodoList
+		}
+		return err
+	}
+	if !list.Role.CanWrite() {
+		return apperr.ErrPermissionDenied
+	}
+	return nil
+}
+
+// checkListOwner returns ErrNoResult if the user is not a member of the list,
+// and ErrPermissionDenied if the user is not one of its owners
+func (repo repositoryImpl) checkListOwner(ctx context.Context, userId, listId int) (TodoList, error) {
+	list, err := repo.GetList(ctx, userId, listId)
+	if err != nil {
+		return TodoList{}, err
+	}
+	if list.Role != TodoListRoleOwner {
+		return TodoList{}, apperr.ErrPermissionDenied
+	}
+	return list, nil
+}
+
+func (repo repositoryImpl) CreateList(ctx context.Context, userId int, data TodoListData) (TodoList, error) {
+	var position int32
+	if data.Position != nil {
+		position = int32(*data.Position)
+	}
+
+	var list database_queries.TodoList
+	err := repo.usingTransaction(ctx, func(queries *database_queries.Queries) error {
+		var err error
+		list, err = queries.TodoListCreateList(
+			ctx,
+			database_queries.TodoListCreateListParams{
+				UserID:   int32(userId),
+				Name:     *data.Name,
+				Color:    dbutils.PtrToPgTypeText(data.Color),
+				Position: position,
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		return queries.TodoListAddMember(
+			ctx,
+			database_queries.TodoListAddMemberParams{
+				ListID: list.ID,
+				UserID: int32(userId),
+				Role:   TodoListRoleOwner.String(),
+			},
+		)
+	})
+	if err != nil {
+		zerolog.Ctx(ctx).Err(err).Msg("can not create the todo list")
+		return TodoList{}, err
+	}
+
+	return todoListFromDataBase(list, TodoListRoleOwner.String()), nil
+}
+
+func (repo repositoryImpl) UpdateList(ctx context.Context, userId, listId int, data TodoListData) (TodoList, error) {
+	if _, err := repo.checkListOwner(ctx, userId, listId); err != nil {
+		return TodoList{}, err
+	}
+
+	var isArchived pgtype.Bool
+	if data.IsArchived != nil {
+		isArchived = pgtype.Bool{Bool: *data.IsArchived, Valid: true}
+	}
+
+	res, err := repo.db.Queries.TodoListUpdateList(
+		ctx,
+		database_queries.TodoListUpdateListParams{
+			ID:         int32(listId),
+			UserID:     int32(userId),
+			Name:       dbutils.PtrToPgTypeText(data.Name),
+			ClearColor: data.ClearColor,
+			Color:      dbutils.PtrToPgTypeText(data.Color),
+			Position:   dbutils.PtrToPgTypeInt4(data.Position),
+			IsArchived: isArchived,
+		},
+	)
+	if err != nil {
+		if dbutils.IsErrPgxNoRows(err) {
+			err = apperr.ErrNoResult
+		} else {
+			zerolog.Ctx(ctx).Err(err).Int("list_id", listId).Msg("can not update the todo list")
+		}
+		return TodoList{}, err
+	}
+
+	return todoListFromDataBase(res, TodoListRoleOwner.String()), nil
+}
+
+func (repo repositoryImpl) DeleteList(ctx context.Context, userId, listId int, action DeleteListTodosAction, moveToListId *int) error {
+	zlog := zerolog.Ctx(ctx).With().Int("list_id", listId).Logger()
+
+	if _, err := repo.checkListOwner(ctx, userId, listId); err != nil {
+		return err
+	}
+
+	if moveToListId != nil {
+		if *moveToListId == listId {
+			return apperr.ErrInvalidTodoList
+		}
+		if err := repo.checkCanWriteToList(ctx, userId, *moveToListId); err != nil {
+			return err
+		}
+	}
+
+	err := repo.usingTransaction(ctx, func(queries *database_queries.Queries) error {
+		rowsAffected, err := queries.TodoListSoftDeleteList(
+			ctx,
+			database_queries.TodoListSoftDeleteListParams{
+				ID:     int32(listId),
+				UserID: int32(userId),
+			},
+		)
+		if err != nil {
+			return err
+		}
+		if rowsAffected == 0 {
+			return apperr.ErrNoResult
+		}
+
+		// the todos of all the members are moved, the ones that end up
+		// without a list are only visible to the users who created them
+		switch action {
+		case DeleteListTodosActionTrash:
+			todoIds, err := queries.TodoSoftDeleteTodosOfList(ctx, dbutils.PtrToPgTypeInt4(&listId))
+			if err != nil {
+				return err
+			}
+			return recordActivities(
+				ctx,
+				queries,
+				todoIds,
+				userId,
+				TodoActivityActionDeleted,
+				map[string]TodoFieldChange{"list_id": {Old: listId, New: nil}},
+			)
+
+		default:
+			todoIds, err := queries.TodoMoveTodosOfList(
+				ctx,
+				database_queries.TodoMoveTodosOfListParams{
+					ToListID:   dbutils.PtrToPgTypeInt4(moveToListId),
+					FromListID: dbutils.PtrToPgTypeInt4(&listId),
+				},
+			)
+			if err != nil {
+				return err
+			}
+			return recordActivities(
+				ctx,
+				queries,
+				todoIds,
+				userId,
+				TodoActivityActionUpdated,
+				map[string]TodoFieldChange{"list_id": {Old: listId, New: moveToListId}},
+			)
+		}
+	})
+	if err != nil {
+		if !apperr.IsAppErr(err) {
+			zlog.Err(err).Msg("can not delete the todo list")
+		}
+		return err
+	}
+
+	return nil
+}
+
+//---------------------------------------------------------------------------------
+
+func (repo repositoryImpl) GetListMembers(ctx context.Context, userId, listId int) ([]TodoListMember, error) {
+	if _, err := repo.GetList(ctx, userId, listId); err != nil {
+		return []TodoListMember{}, err
+	}
+
+	data, err := repo.db.Queries.TodoListGetMembers(ctx, int32(listId))
+	if err != nil {
+		zerolog.Ctx(ctx).Err(err).Int("list_id", listId).Msg("can not get the todo list members")
+		return []TodoListMember{}, err
+	}
+
+	members := make([]TodoListMember, len(data))
+	for i, v := range data {
+		members[i] = todoListMemberFromDataBase(v)
+	}
+
+	return members, nil
+}
+
+func (repo repositoryImpl) ChangeListMemberRole(ctx context.Context, userId, listId, memberId int, role TodoListRole) error {
+	if _, err := repo.checkListOwner(ctx, userId, listId); err != nil {
+		return err
+	}
+
+	err := repo.usingTransaction(ctx, func(queries *database_queries.Queries) error {
+		if role != TodoListRoleOwner {
+			if err := checkNotLastListOwner(ctx, queries, listId, memberId); err != nil {
+				return err
+			}
+		}
+
+		rowsAffected, err := queries.TodoListUpdateMemberRole(
+			ctx,
+			database_queries.TodoListUpdateMemberRoleParams{
+				ListID: int32(listId),
+				UserID: int32(memberId),
+				Role:   role.String(),
+			},
+		)
+		if err != nil {
+			return err
+		}
+		if rowsAffected == 0 {
+			return apperr.ErrNoResult
+		}
+		return nil
+	})
+	if err != nil {
+		if !apperr.IsAppErr(err) {
+			zerolog.Ctx(ctx).Err(err).Int("list_id", listId).Int("member_id", memberId).Msg("can not change the role of the todo list member")
+		}
+		return err
+	}
+
+	return nil
+}
+
+// RemoveListMember is used by the owners to remove the members,
+// and by the members to leave the list (userId == memberId)
+func (repo repositoryImpl) RemoveListMember(ctx context.Context, userId, listId, memberId int) error {
+	if userId == memberId {
+		if _, err := repo.GetList(ctx, userId, listId); err != nil {
+			return err
+		}
+	} else {
+		if _, err := repo.checkListOwner(ctx, userId, listId); err != nil {
+			return err
+		}
+	}
+
+	err := repo.usingTransaction(ctx, func(queries *database_queries.Queries) error {
+		if err := checkNotLastListOwner(ctx, queries, listId, memberId); err != nil {
+			return err
+		}
+
+		rowsAffected, err := queries.TodoListRemoveMember(
+			ctx,
+			database_queries.TodoListRemoveMemberParams{
+				ListID: int32(listId),
+				UserID: int32(memberId),
+			},
+		)
+		if err != nil {
+			return err
+		}
+		if rowsAffected == 0 {
+			return apperr.ErrNoResult
+		}
+		return nil
+	})
+	if err != nil {
+		if !apperr.IsAppErr(err) {
+			zerolog.Ctx(ctx).Err(err).Int("list_id", listId).Int("member_id", memberId).Msg("can not remove the todo list member")
+		}
+		return err
+	}
+
+	return nil
+}
+
+// checkNotLastListOwner returns ErrTodoListLastOwner if the member is the only owner of the list.
+// The owners rows are locked until the end of the transaction, so two owners
+// can not demote or remove each other at the same time.
+func checkNotLastListOwner(ctx context.Context, queries *database_queries.Queries, listId, memberId int) error {
+	owners, err := queries.TodoListGetOwnersForUpdate(ctx, int32(listId))
+	if err != nil {
+		return err
+	}
+	if len(owners) == 1 && owners[0] == int32(memberId) {
+		return apperr.ErrTodoListLastOwner
+	}
+	return nil
+}
+
+//---------------------------------------------------------------------------------
+
+func (repo repositoryImpl) InviteToList(ctx context.Context, userId, listId int, invitee TodoListInvitee, role TodoListRole) (TodoListInvitation, error) {
+	zlog := zerolog.Ctx(ctx).With().Int("list_id", listId).Logger()
+
+	list, err := repo.checkListOwner(ctx, userId, listId)
+	if err != nil {
+		return TodoListInvitation{}, err
+	}
+
+	inviteeUser, err := repo.db.Queries.TodoListFindInvitee(
+		ctx,
+		database_queries.TodoListFindInviteeParams{
+			Username: dbutils.PtrToPgTypeText(invitee.Username),
+			Email:    dbutils.PtrToPgTypeText(invitee.Email),
+		},
+	)
+	if err != nil {
+		if dbutils.IsErrPgxNoRows(err) {
+			return TodoListInvitation{}, apperr.ErrTodoListInviteeNotFound
+		}
+		zlog.Err(err).Msg("can not find the todo list invitee")
+		return TodoListInvitation{}, err
+	}
+
+	_, err = repo.GetList(ctx, int(inviteeUser.ID), listId)
+	if err == nil {
+		return TodoListInvitation{}, apperr.ErrTodoListAlreadyMember
+	}
+	if !errors.Is(err, apperr.ErrNoResult) {
+		return TodoListInvitation{}, err
+	}
+
+	inviter, err := repo.db.Queries.UsersGetUserById(ctx, int32(userId))
+	if err != nil {
+		zlog.Err(err).Msg("can not get the todo list inviter")
+		return TodoListInvitation{}, err
+	}
+
+	res, err := repo.db.Queries.TodoListCreateInvitation(
+		ctx,
+		database_queries.TodoListCreateInvitationParams{
+			ListID:    int32(listId),
+			InviterID: int32(userId),
+			InviteeID: inviteeUser.ID,
+			Role:      role.String(),
+		},
+	)
+	if err != nil {
+		zlog.Err(err).Msg("can not create the todo list invitation")
+		return TodoListInvitation{}, err
+	}
+
+	// the invitation is also listed in the app, so it is not failed if the email is not sent
+	if err := repo.sendListInvitationEmail(ctx, inviteeUser.ID, inviter.Username, list.Name, role); err != nil {
+		zlog.Err(err).Int32("invitee_id", inviteeUser.ID).Msg("can not send the todo list invitation email")
+	}
+
+	return todoListInvitationFromDataBase(res, list.Name, inviter.Username, inviteeUser.Username), nil
+}
+
+func (repo repositoryImpl) sendListInvitationEmail(ctx context.Context, inviteeId int32, inviterUsername, listName string, role TodoListRole) error {
+	target, err := repo.db.Queries.TodoGetReminderTargetForUser(ctx, inviteeId)
+	if err != nil {
+		return err
+	}
+	if !target.Email.Valid {
+		// e.g. phone, guest and oidc users
+		return nil
+	}
+
+	content := l10n.GetLocalizer(reminderLang(target.Locale)).GetWithData(
+		l10n.TodoListInvitationTrId,
+		map[string]any{
+			"Inviter": inviterUsername,
+			"List":    listName,
+			"Role":    role.String(),
+		},
+	)
+
+	return repo.gatewaysProvider.NewEmailProvider(ctx).Send(ctx, target.Email.String, content)
+}
+
+func (repo repositoryImpl) GetListInvitations(ctx context.Context, userId, listId int) ([]TodoListInvitation, error) {
+	if _, err := repo.checkListOwner(ctx, userId, listId); err != nil {
+		return []TodoListInvitation{}, err
+	}
+
+	data, err := repo.db.Queries.TodoListGetPendingInvitationsOfList(ctx, int32(listId))
+	if err != nil {
+		zerolog.Ctx(ctx).Err(err).Int("list_id", listId).Msg("can not get the todo list invitations")
+		return []TodoListInvitation{}, err
+	}
+
+	invitations := make([]TodoListInvitation, len(data))
+	for i, v := range data {
+		invitations[i] = todoListInvitationFromDataBase(v.TodoListInvitation, v.ListName, v.InviterUsername, v.InviteeUsername)
+	}
+
+	return invitations, nil
+}
+
+func (repo repositoryImpl) CancelListInvitation(ctx context.Context, userId, listId, invitationId int) error {
+	if _, err := repo.checkListOwner(ctx, userId, listId); err != nil {
+		return err
+	}
+
+	rowsAffected, err := repo.db.Queries.TodoListDeleteInvitation(
+		ctx,
+		database_queries.TodoListDeleteInvitationParams{
+			ID:     int32(invitationId),
+			ListID: int32(listId),
+		},
+	)
+	if err != nil {
+		zerolog.Ctx(ctx).Err(err).Int("invitation_id", invitationId).Msg("can not cancel the todo list invitation")
+		return err
+	}
+	if rowsAffected == 0 {
+		return apperr.ErrNoResult
+	}
+
+	return nil
+}
+
+func (repo repositoryImpl) GetMyListInvitations(ctx context.Context, userId int) ([]TodoListInvitation, error) {
+	data, err := repo.db.Queries.TodoListGetPendingInvitationsForUser(ctx, int32(userId))
+	if err != nil {
+		zerolog.Ctx(ctx).Err(err).Msg("can not get the todo list invitations of the user")
+		return []TodoListInvitation{}, err
+	}
+
+	invitations := make([]TodoListInvitation, len(data))
+	for i, v := range data {
+		invitations[i] = todoListInvitationFromDataBase(v.TodoListInvitation, v.ListName, v.InviterUsername, v.InviteeUsername)
+	}
+
+	return invitations, nil
+}
+
+// RespondToListInvitation accepts or declines a pending invitation of the user,
+// on accept the user becomes a member of the list with the role of the invitation,
+// an older invitation does not change the role of a user that is already a member
+func (repo repositoryImpl) RespondToListInvitation(ctx context.Context, userId, invitationId int, accept bool) error {
+	status := TodoListInvitationStatusDeclined
+	if accept {
+		status = TodoListInvitationStatusAccepted
+	}
+
+	err := repo.usingTransaction(ctx, func(queries *database_queries.Queries) error {
+		invitation, err := queries.TodoListRespondToInvitation(
+			ctx,
+			database_queries.TodoListRespondToInvitationParams{
+				Status:    string(status),
+				ID:        int32(invitationId),
+				InviteeID: int32(userId),
+			},
+		)
+		if err != nil {
+			if dbutils.IsErrPgxNoRows(err) {
+				return apperr.ErrNoResult
+			}
+			return err
+		}
+
+		if !accept {
+			return nil
+		}
+
+		return queries.TodoListAddMember(
+			ctx,
+			database_queries.TodoListAddMemberParams{
+				ListID: invitation.ListID,
+				UserID: int32(userId),
+				Role:   invitation.Role,
+			},
+		)
+	})
+	if err != nil {
+		if !apperr.IsAppErr(err) {
+			zerolog.Ctx(ctx).Err(err).Int("invitation_id", invitationId).Msg("can not respond to the todo list invitation")
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (repo repositoryImpl) RunInTransaction(ctx context.Context, fn func(txRepo Repository) error) (err error) {
+	if repo.tx != nil {
+		return fn(repo)
+	}
+
+	tx, err := repo.db.ConnPool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, tx.Rollback(ctx))
+		} else {
+			err = tx.Commit(ctx)
+		}
+	}()
+
+	txRepo := repo
+	txRepo.tx = tx
+	txRepo.db = &database.Service{ConnPool: repo.db.ConnPool, Queries: repo.db.Queries.WithTx(tx)}
+
+	return fn(txRepo)
+}
+
+// usingTransaction runs fn in a transaction, or in a savepoint for the
+// repository of RunInTransaction
+func (repo repositoryImpl) usingTransaction(ctx context.Context, fn func(queries *database_queries.Queries) error) error {
+	var db dbutils.TxBeginner = repo.db.ConnPool
+	if repo.tx != nil {
+		db = repo.tx
+	}
+	return dbutils.UsingTransaction(ctx, db, repo.db.Queries, fn)
+}
+
+// uniqueInt32s returns nil for no ids, that is NULL in the queries
+func uniqueInt32s(ids []int) []int32 {
+	if len(ids) == 0 {
+		return nil
+	}
+	res := make([]int32, 0, len(ids))
+	for _, id := range ids {
+		if !slices.Contains(res, int32(id)) {
+			res = append(res, int32(id))
+		}
+	}
+	return res
+}
+
+// todoCursorToPgTypes returns NULLs for the first page (nil cursor)
+func todoCursorToPgTypes(cursor *TodoCursor) (pgtype.Timestamptz, pgtype.Int4) {
+	if cursor == nil {
+		return pgtype.Timestamptz{}, pgtype.Int4{}
+	}
+	return pgtype.Timestamptz{Time: cursor.CreatedAt, Valid: true}, pgtype.Int4{Int32: int32(cursor.Id), Valid: true}
 }
@@ -0,0 +1,112 @@
+package todo
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/Nidal-Bakir/go-todo-backend/internal/database"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/database/database_queries"
+	dbutils "github.com/Nidal-Bakir/go-todo-backend/internal/utils/db_utils"
+	"github.com/rs/zerolog"
+)
+
+const (
+	trashPurgerInterval           = time.Hour
+	trashPurgerBatchSize          = 1000
+	defaultTrashRetentionInDays   = 30
+	trashRetentionInDaysEnvVarKey = "TODO_TRASH_RETENTION_DAYS"
+)
+
+var trashRetentionInDays = os.Getenv(trashRetentionInDaysEnvVarKey)
+
+// TrashPurger hard-deletes the todos that stayed in the trash
+// for more than TODO_TRASH_RETENTION_DAYS (default 30 days)
+type TrashPurger struct {
+	db        *database.Service
+	retention time.Duration
+}
+
+func NewTrashPurger(db *database.Service) (*TrashPurger, error) {
+	retention, err := trashRetention()
+	if err != nil {
+		return nil, err
+	}
+	return &TrashPurger{db: db, retention: retention}, nil
+}
+
+func trashRetention() (time.Duration, error) {
+	days := defaultTrashRetentionInDays
+	if len(trashRetentionInDays) != 0 {
+		var err error
+		days, err = strconv.Atoi(trashRetentionInDays)
+		if err != nil || days < 1 {
+			return 0, fmt.Errorf("invalid %s, it should be a positive number of days", trashRetentionInDaysEnvVarKey)
+		}
+	}
+	return time.Duration(days) * 24 * time.Hour, nil
+}
+
+// Start purges the expired todos every hour until the ctx is done
+func (p *TrashPurger) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(trashPurgerInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				p.purgeExpiredTodos(ctx)
+			}
+		}
+	}()
+}
+
+func (p *TrashPurger) purgeExpiredTodos(ctx context.Context) {
+	zlog := zerolog.Ctx(ctx)
+
+	deletedBefore := dbutils.ToPgTypeTimestamptz(time.Now().Add(-p.retention))
+
+	var total int64
+	for {
+		// in batches, to not lock the table for a long time
+		rowsAffected, err := p.db.Queries.TodoPurgeDeletedTodos(ctx, database_queries.TodoPurgeDeletedTodosParams{
+			DeletedBefore: deletedBefore,
+			BatchSize:     trashPurgerBatchSize,
+		})
+		if err != nil {
+			zlog.Err(err).Msg("can not purge the expired todos from the trash")
+			return
+		}
+		total += rowsAffected
+
+		if rowsAffected < trashPurgerBatchSize {
+			break
+		}
+	}
+
+	if total != 0 {
+		zlog.Info().Int64("count", total).Msg("purged the expired todos from the trash")
+	}
+
+	// the tombstones of the delta sync are kept as long as the trash, the sync
+	// tokens that are older than that start over (see GetTodoChanges).
+	// The activity of the purged todos is purged with their tombstones
+	for {
+		rowsAffected, err := p.db.Queries.TodoSyncPurgeTombstones(ctx, database_queries.TodoSyncPurgeTombstonesParams{
+			CreatedBefore: deletedBefore,
+			BatchSize:     trashPurgerBatchSize,
+		})
+		if err != nil {
+			zlog.Err(err).Msg("can not purge the expired todo sync tombstones")
+			return
+		}
+		if rowsAffected < trashPurgerBatchSize {
+			break
+		}
+	}
+}
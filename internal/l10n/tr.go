@@ -5,6 +5,7 @@ const (
 	UnexpectedErrorOccurredTrId = "unexpected_error_occurred"
 	TooManyRequestsTrId         = "too_many_requests"
 	InvalidId                   = "invalid_id"
+	InvalidCursorTrId           = "invalid_cursor"
 
 	// auth
 	InvalidEmailTrId                      = "invalid_email"
@@ -22,10 +23,52 @@ const (
 	OperationDoneSuccessfullyTrId         = "operation_done_successfully"
 	OldPasswordDoesNotMatchCurrentOneTrId = "old_password_does_not_match_current_one"
 	ExpiredSessionToken                   = "expired_session_token"
+	NotGuestUserTrId                      = "not_guest_user"
+	OidcAccountAlreadyLinkedTrId          = "oidc_account_already_linked"
+	InvalidMfaCodeTrId                    = "invalid_mfa_code"
+	MfaAlreadyEnabledTrId                 = "mfa_already_enabled"
+	MfaNotEnabledTrId                     = "mfa_not_enabled"
+	MfaEnforcedTrId                       = "mfa_enforced"
+	InvalidMfaChallengeTrId               = "invalid_mfa_challenge"
+	InvalidRefreshTokenTrId               = "invalid_refresh_token"
+	RefreshTokenReusedTrId                = "refresh_token_reused"
 
 	// user
-	BlockedUser = "blocked_user"
+	BlockedUser                = "blocked_user"
+	CannotManageOwnAccountTrId = "cannot_manage_own_account"
+	InvalidRoleTrId            = "invalid_role"
+	InvalidBlockedUntilTrId    = "invalid_blocked_until"
 
 	// todo
-	UnsupportedTodoStatus = "unsupported_todo_status"
+	UnsupportedTodoStatus               = "unsupported_todo_status"
+	UnsupportedTodoSortTrId             = "unsupported_todo_sort"
+	InvalidTodoListTrId                 = "invalid_todo_list"
+	TodoReminderTrId                    = "todo_reminder"
+	TodoReminderWithDueAtTrId           = "todo_reminder_with_due_at"
+	UnsupportedTodoListRoleTrId         = "unsupported_todo_list_role"
+	TodoListInviteeNotFoundTrId         = "todo_list_invitee_not_found"
+	TodoListAlreadyMemberTrId           = "todo_list_already_member"
+	TodoListLastOwnerTrId               = "todo_list_last_owner"
+	TodoListInvitationTrId              = "todo_list_invitation"
+	InvalidRecurrenceRuleTrId           = "invalid_recurrence_rule"
+	RecurrenceNeedsDueAtTrId            = "recurrence_needs_due_at"
+	InvalidTimezoneTrId                 = "invalid_timezone"
+	InvalidTodoTagTrId                  = "invalid_todo_tag"
+	TodoTagAlreadyExistsTrId            = "todo_tag_already_exists"
+	TodoWorkflowStatusAlreadyExistsTrId = "todo_workflow_status_already_exists"
+	CannotDeleteCompletedTodoStatusTrId = "cannot_delete_completed_todo_status"
+	TooManyTodoAttachmentsTrId          = "too_many_todo_attachments"
+	TodoAttachmentTooLargeTrId          = "todo_attachment_too_large"
+	UnsupportedTodoAttachmentTypeTrId   = "unsupported_todo_attachment_type"
+	TodoVersionMismatchTrId             = "todo_version_mismatch"
+	InvalidTodoSyncTokenTrId            = "invalid_todo_sync_token"
+	TodoAttachmentsUnavailableTrId      = "todo_attachments_unavailable"
+	TodoRemindAtAfterDueAtTrId          = "todo_remind_at_after_due_at"
+
+	// perm
+	RoleAlreadyExistsTrId                    = "role_already_exists"
+	PermissionAlreadyExistsTrId              = "permission_already_exists"
+	CannotDeleteBaseRoleOrPermTrId           = "cannot_delete_base_role_or_perm"
+	CannotChangeOwnOrBaseRolePermissionsTrId = "cannot_change_own_or_base_role_permissions"
+	CannotManageHigherRoleTrId               = "cannot_manage_higher_role"
 )
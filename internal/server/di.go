@@ -14,6 +14,7 @@ func (s *Server) NewAuthRepository() auth.Repository {
 		s.gatewaysProvider,
 		password_hasher.NewPasswordHasher(password_hasher.BcryptPasswordHash), // changing this value will break the auth system
 		auth.NewAuthJWT(appjwt.NewAppJWT()),
+		s.NewPermRepository(),
 	)
 }
 
@@ -4,11 +4,11 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-	"time"
 
 	"github.com/Nidal-Bakir/go-todo-backend/internal/appenv"
 	"github.com/Nidal-Bakir/go-todo-backend/internal/apperr"
 	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/auth"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/perm"
 	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/perm/baseperm"
 	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/settings"
 	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/settings/labels"
@@ -49,7 +49,8 @@ func Auth(authRepo auth.Repository) func(http.Handler) http.HandlerFunc {
 				return
 			}
 
-			if _, err := authRepo.VerifyAuthToken(token); err != nil {
+			claims, err := authRepo.VerifyAccessToken(token)
+			if err != nil {
 				if appenv.IsStagOrLocal() {
 					zlog.Error().Err(err).Msg("Error from jwt verify function")
 				}
@@ -57,13 +58,14 @@ func Auth(authRepo auth.Repository) func(http.Handler) http.HandlerFunc {
 				return
 			}
 
-			userAndSessionData, err := authRepo.GetUserAndSessionDataBySessionToken(ctx, token)
+			// the access token is only valid while its session is active
+			userAndSessionData, err := authRepo.GetUserAndSessionDataBySessionId(ctx, int(claims.SessionId))
 
 			if err != nil {
 				if errors.Is(err, apperr.ErrNoResult) {
 					err = fmt.Errorf("unauthorized")
 				} else if appenv.IsProd() {
-					zlog.Error().Err(err).Msg("Error while geting a user by session tokne in auth middleware")
+					zlog.Error().Err(err).Msg("Error while geting a user by session id in auth middleware")
 					err = fmt.Errorf("unauthorized")
 				}
 
@@ -71,10 +73,13 @@ func Auth(authRepo auth.Repository) func(http.Handler) http.HandlerFunc {
 				return
 			}
 
+			if userAndSessionData.UserID != claims.UserId {
+				sendUnauthorizedError()
+				return
+			}
+
 			// check blocking status
-			blockedAt := userAndSessionData.UserBlockedAt
-			blockedUntil := userAndSessionData.UserBlockedUntil
-			if blockedAt.Valid || (blockedUntil.Valid && blockedUntil.Time.After(time.Now())) {
+			if auth.IsUserBlocked(userAndSessionData.UserBlockedAt, userAndSessionData.UserBlockedUntil) {
 				writeError(ctx, w, r, http.StatusUnauthorized, apperr.ErrBlockedUser)
 				return
 			}
@@ -87,6 +92,31 @@ func Auth(authRepo auth.Repository) func(http.Handler) http.HandlerFunc {
 	}
 }
 
+// Permission responds with 403 if the role of the logged-in user does not have all the permissions.
+//
+// Needs: Auth
+func Permission(permRepo perm.Repository, permissions ...string) func(http.Handler) http.HandlerFunc {
+	return func(next http.Handler) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+			err := permRepo.HasPermissionErr(ctx, userAndSession.UserRoleName.String, permissions...)
+			if err != nil {
+				if errors.Is(err, apperr.ErrPermissionDenied) {
+					writeError(ctx, w, r, http.StatusForbidden, err)
+					return
+				}
+				writeError(ctx, w, r, http.StatusInternalServerError, err)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+	}
+}
+
 func (s *Server) LoggerInjector(h http.Handler) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		h.ServeHTTP(w, r.WithContext(s.zlog.WithContext(r.Context())))
@@ -96,6 +126,16 @@ func (s *Server) LoggerInjector(h http.Handler) http.HandlerFunc {
 // Inject the Installation into the request context,
 // will respond with error if it can't find the Installation in the database or in the request headers
 func Installation(authRepo auth.Repository) func(http.Handler) http.HandlerFunc {
+	return installation(authRepo, true)
+}
+
+// Inject the Installation into the request context when the request has one,
+// will respond with error only if the sent Installation is invalid
+func OptionalInstallation(authRepo auth.Repository) func(http.Handler) http.HandlerFunc {
+	return installation(authRepo, false)
+}
+
+func installation(authRepo auth.Repository, required bool) func(http.Handler) http.HandlerFunc {
 	return func(next http.Handler) http.HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
 			ctx := r.Context()
@@ -116,14 +156,18 @@ func Installation(authRepo auth.Repository) func(http.Handler) http.HandlerFunc
 			installationToken := r.Header.Get("A-Installation")
 			if len(installationToken) == 0 {
 				installationTokenFromCookie, err := readInstallationCookie(r)
-				if err != nil {
+				if err != nil && required {
 					sendError()
 					return
 				}
 				installationToken = installationTokenFromCookie
 			}
 			if len(installationToken) == 0 {
-				sendError()
+				if required {
+					sendError()
+					return
+				}
+				next.ServeHTTP(w, r)
 				return
 			}
 
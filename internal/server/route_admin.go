@@ -0,0 +1,743 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Nidal-Bakir/go-todo-backend/internal/apperr"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/auth"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/perm"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/perm/baseperm"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/middleware"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/utils/paginate"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func adminRouter(_ context.Context, authRepo auth.Repository, permRepo perm.Repository) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc(
+		"POST /users/{id}/mfa-enforced",
+		middleware.MiddlewareChain(
+			setUserMfaEnforced(authRepo),
+			middleware.ACT_app_x_www_form_urlencoded,
+			Permission(permRepo, baseperm.BasePermEnforceUserMfa),
+		),
+	)
+
+	mux.HandleFunc(
+		"GET /users",
+		middleware.MiddlewareChain(
+			adminSearchUsers(authRepo),
+			Permission(permRepo, baseperm.BasePermReadUsers),
+		),
+	)
+
+	mux.HandleFunc(
+		"GET /users/{id}",
+		middleware.MiddlewareChain(
+			adminShowUser(authRepo),
+			Permission(permRepo, baseperm.BasePermReadUsers),
+		),
+	)
+
+	mux.HandleFunc(
+		"POST /users/{id}/block",
+		middleware.MiddlewareChain(
+			adminBlockUser(authRepo),
+			middleware.ACT_app_x_www_form_urlencoded,
+			Permission(permRepo, baseperm.BasePermBlockUsers),
+		),
+	)
+
+	mux.HandleFunc(
+		"POST /users/{id}/unblock",
+		middleware.MiddlewareChain(
+			adminUnblockUser(authRepo),
+			Permission(permRepo, baseperm.BasePermBlockUsers),
+		),
+	)
+
+	mux.HandleFunc(
+		"POST /users/{id}/role",
+		middleware.MiddlewareChain(
+			adminSetUserRole(authRepo),
+			middleware.ACT_app_x_www_form_urlencoded,
+			Permission(permRepo, baseperm.BasePermChangeUsersRole),
+		),
+	)
+
+	mux.HandleFunc(
+		"DELETE /users/{id}",
+		middleware.MiddlewareChain(
+			adminDeleteUser(authRepo),
+			Permission(permRepo, baseperm.BasePermDeleteUsers),
+		),
+	)
+
+	mux.HandleFunc(
+		"GET /roles",
+		middleware.MiddlewareChain(
+			adminListRoles(permRepo),
+			Permission(permRepo, baseperm.BasePermReadRoles),
+		),
+	)
+
+	mux.HandleFunc(
+		"POST /roles",
+		middleware.MiddlewareChain(
+			adminCreateRole(permRepo),
+			middleware.ACT_app_x_www_form_urlencoded,
+			Permission(permRepo, baseperm.BasePermWriteRoles),
+		),
+	)
+
+	mux.HandleFunc(
+		"DELETE /roles/{name}",
+		middleware.MiddlewareChain(
+			adminDeleteRole(permRepo),
+			Permission(permRepo, baseperm.BasePermDeleteRoles),
+		),
+	)
+
+	mux.HandleFunc(
+		"GET /roles/{name}/permissions",
+		middleware.MiddlewareChain(
+			adminListRolePermissions(permRepo),
+			Permission(permRepo, baseperm.BasePermReadRoles),
+		),
+	)
+
+	mux.HandleFunc(
+		"POST /roles/{name}/permissions",
+		middleware.MiddlewareChain(
+			adminAttachPermissionToRole(permRepo),
+			middleware.ACT_app_x_www_form_urlencoded,
+			Permission(permRepo, baseperm.BasePermWriteRoles),
+		),
+	)
+
+	mux.HandleFunc(
+		"DELETE /roles/{name}/permissions/{permission}",
+		middleware.MiddlewareChain(
+			adminDetachPermissionFromRole(permRepo),
+			Permission(permRepo, baseperm.BasePermWriteRoles),
+		),
+	)
+
+	mux.HandleFunc(
+		"GET /permissions",
+		middleware.MiddlewareChain(
+			adminListPermissions(permRepo),
+			Permission(permRepo, baseperm.BasePermReadRoles),
+		),
+	)
+
+	mux.HandleFunc(
+		"POST /permissions",
+		middleware.MiddlewareChain(
+			adminCreatePermission(permRepo),
+			middleware.ACT_app_x_www_form_urlencoded,
+			Permission(permRepo, baseperm.BasePermWriteRoles),
+		),
+	)
+
+	mux.HandleFunc(
+		"DELETE /permissions/{name}",
+		middleware.MiddlewareChain(
+			adminDeletePermission(permRepo),
+			Permission(permRepo, baseperm.BasePermDeleteRoles),
+		),
+	)
+
+	return mux
+}
+
+//-----------------------------------------------------------------------------
+
+func setUserMfaEnforced(authRepo auth.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		err := r.ParseForm()
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		userId, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, errors.New("invalid user id"))
+			return
+		}
+
+		enforced, err := strconv.ParseBool(r.FormValue("enforced"))
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, errors.New("invalid enforced value"))
+			return
+		}
+
+		err = authRepo.SetMfaEnforcedForUser(ctx, userId, enforced)
+		if err != nil {
+			writeError(ctx, w, r, return400IfApp404IfNoResultErrOr500(err), err)
+			return
+		}
+
+		apiWriteOperationDoneSuccessfullyJson(ctx, w, r)
+	}
+}
+
+//-----------------------------------------------------------------------------
+
+type publicUserForAdmin struct {
+	ID           int32              `json:"id"`
+	Username     string             `json:"username"`
+	ProfileImage pgtype.Text        `json:"profile_image"`
+	FirstName    string             `json:"first_name"`
+	MiddleName   pgtype.Text        `json:"middle_name"`
+	LastName     pgtype.Text        `json:"last_name"`
+	RoleName     pgtype.Text        `json:"role_name"`
+	MfaEnforced  bool               `json:"mfa_enforced"`
+	IsBlocked    bool               `json:"is_blocked"`
+	BlockedAt    pgtype.Timestamptz `json:"blocked_at"`
+	BlockedUntil pgtype.Timestamptz `json:"blocked_until"`
+	BlockReason  pgtype.Text        `json:"block_reason,omitzero"`
+	DeletedAt    pgtype.Timestamptz `json:"deleted_at"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+}
+
+func newPublicUserForAdmin(u auth.UserForAdmin) publicUserForAdmin {
+	return publicUserForAdmin{
+		ID:           u.ID,
+		Username:     u.Username,
+		ProfileImage: u.ProfileImage,
+		FirstName:    u.FirstName,
+		MiddleName:   u.MiddleName,
+		LastName:     u.LastName,
+		RoleName:     u.RoleName,
+		MfaEnforced:  u.MfaEnforced,
+		IsBlocked:    auth.IsUserBlocked(u.BlockedAt, u.BlockedUntil),
+		BlockedAt:    u.BlockedAt,
+		BlockedUntil: u.BlockedUntil,
+		BlockReason:  u.BlockReason,
+		DeletedAt:    u.DeletedAt,
+		CreatedAt:    u.CreatedAt,
+	}
+}
+
+func userIdFromPath(r *http.Request) (int, error) {
+	userId, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return 0, errors.New("invalid user id")
+	}
+	return userId, nil
+}
+
+// userIdFromPathNotSelf stops the admins from locking themselves out by mistake.
+func userIdFromPathNotSelf(r *http.Request) (int, error) {
+	userId, err := userIdFromPath(r)
+	if err != nil {
+		return 0, err
+	}
+	userAndSession := auth.MustUserAndSessionFromContext(r.Context())
+	if userId == int(userAndSession.UserID) {
+		return 0, apperr.ErrCannotManageOwnAccount
+	}
+	return userId, nil
+}
+
+func adminSearchUsers(authRepo auth.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		err := r.ParseForm()
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		includeDeleted, _ := strconv.ParseBool(r.FormValue("include_deleted"))
+		params := auth.SearchUsersParams{
+			Query:          strings.TrimSpace(r.FormValue("q")),
+			RoleName:       r.FormValue("role_name"),
+			IncludeDeleted: includeDeleted,
+		}
+
+		paginatedDate, err := paginate.NewSimplePaginatedAction(
+			func(offset, limit int) ([]auth.UserForAdmin, error) {
+				return authRepo.SearchUsers(ctx, params, offset, limit)
+			},
+		).Exec(r)
+		if err != nil {
+			writeError(ctx, w, r, return400IfAppErrOr500(err), err)
+			return
+		}
+
+		writeResponse(ctx, w, r, http.StatusOK, paginate.PaginatedDataMapper(paginatedDate, newPublicUserForAdmin))
+	}
+}
+
+func adminShowUser(authRepo auth.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		userId, err := userIdFromPath(r)
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		user, err := authRepo.GetUserForAdmin(ctx, userId)
+		if err != nil {
+			writeError(ctx, w, r, return400IfApp404IfNoResultErrOr500(err), err)
+			return
+		}
+
+		loginIdentities, err := authRepo.GetAllLoginIdentitiesForUser(ctx, userId)
+		if err != nil {
+			writeError(ctx, w, r, return400IfAppErrOr500(err), err)
+			return
+		}
+
+		sessions, err := authRepo.GetActiveSessionsForUser(ctx, userId)
+		if err != nil {
+			writeError(ctx, w, r, return400IfAppErrOr500(err), err)
+			return
+		}
+
+		type PublicLoginIdentity struct {
+			ID                int32           `json:"id"`
+			Email             string          `json:"email,omitzero"`
+			Phone             *PhonePublicAPI `json:"phone,omitempty"`
+			IsVerified        bool            `json:"is_verified,omitzero"`
+			LoginIdentityType string          `json:"login_identity_type"`
+			OidcProvider      string          `json:"oidc_provider,omitzero"`
+		}
+
+		publicLoginIdentities := make([]PublicLoginIdentity, len(loginIdentities))
+		for i, lo := range loginIdentities {
+			publicLoginIdentities[i] = PublicLoginIdentity{
+				ID:                lo.ID,
+				Email:             lo.Email,
+				Phone:             NewPhonePublicAPI(lo.Phone),
+				IsVerified:        lo.IsVerified,
+				LoginIdentityType: lo.LoginIdentityType.String(),
+				OidcProvider:      lo.OidcProvider,
+			}
+		}
+
+		type PublicSession struct {
+			ID                 int32              `json:"id"`
+			IpAddress          string             `json:"ip_address"`
+			CreatedAt          pgtype.Timestamptz `json:"created_at"`
+			ExpiresAt          pgtype.Timestamptz `json:"expires_at"`
+			LastActiveAt       pgtype.Timestamptz `json:"last_active_at"`
+			LoginIdentityType  string             `json:"login_identity_type"`
+			DeviceOs           string             `json:"device_os"`
+			DeviceOsVersion    pgtype.Text        `json:"device_os_version"`
+			DeviceManufacturer pgtype.Text        `json:"device_manufacturer"`
+			ClientType         string             `json:"client_type"`
+			AppVersion         string             `json:"app_version"`
+		}
+
+		publicSessions := make([]PublicSession, len(sessions))
+		for i, s := range sessions {
+			publicSessions[i] = PublicSession{
+				ID:                 s.ID,
+				IpAddress:          s.IpAddress.String(),
+				CreatedAt:          s.CreatedAt,
+				ExpiresAt:          s.ExpiresAt,
+				LastActiveAt:       s.LastActiveAt,
+				LoginIdentityType:  s.LoginIdentityType.String(),
+				DeviceOs:           s.DeviceOs.String(),
+				DeviceOsVersion:    s.DeviceOsVersion,
+				DeviceManufacturer: s.DeviceManufacturer,
+				ClientType:         s.ClientType.String(),
+				AppVersion:         s.AppVersion,
+			}
+		}
+
+		response := struct {
+			publicUserForAdmin
+			LoginIdentities []PublicLoginIdentity `json:"login_identities"`
+			Sessions        []PublicSession       `json:"sessions"`
+		}{
+			publicUserForAdmin: newPublicUserForAdmin(user),
+			LoginIdentities:    publicLoginIdentities,
+			Sessions:           publicSessions,
+		}
+		writeResponse(ctx, w, r, http.StatusOK, response)
+	}
+}
+
+//-----------------------------------------------------------------------------
+
+type blockUserParams struct {
+	reason       string
+	blockedUntil *time.Time
+}
+
+func adminBlockUser(authRepo auth.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		err := r.ParseForm()
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		userId, err := userIdFromPathNotSelf(r)
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		params, errList := validateBlockUserParams(r)
+		if len(errList) != 0 {
+			writeError(ctx, w, r, http.StatusBadRequest, errList...)
+			return
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		err = authRepo.BlockUser(ctx, int(userAndSession.UserID), userAndSession.UserRoleName.String, userId, params.reason, params.blockedUntil)
+		if err != nil {
+			writeError(ctx, w, r, return403IfCannotManageHigherRoleOr(err), err)
+			return
+		}
+
+		apiWriteOperationDoneSuccessfullyJson(ctx, w, r)
+	}
+}
+
+func validateBlockUserParams(r *http.Request) (blockUserParams, []error) {
+	params := blockUserParams{}
+	errList := make([]error, 0, 2)
+
+	params.reason = strings.TrimSpace(r.FormValue("reason"))
+	if len(params.reason) == 0 {
+		errList = append(errList, errors.New("the reason is required"))
+	}
+
+	// RFC 3339, e.g. 2026-01-02T15:04:05Z
+	if untilStr := r.FormValue("blocked_until"); len(untilStr) != 0 {
+		until, err := time.Parse(time.RFC3339, untilStr)
+		if err != nil {
+			errList = append(errList, errors.New("invalid blocked_until, it should be in RFC 3339 format"))
+		} else {
+			params.blockedUntil = &until
+		}
+	}
+
+	return params, errList
+}
+
+func adminUnblockUser(authRepo auth.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		userId, err := userIdFromPath(r)
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		err = authRepo.UnblockUser(ctx, userId)
+		if err != nil {
+			writeError(ctx, w, r, return400IfApp404IfNoResultErrOr500(err), err)
+			return
+		}
+
+		apiWriteOperationDoneSuccessfullyJson(ctx, w, r)
+	}
+}
+
+//-----------------------------------------------------------------------------
+
+func adminSetUserRole(authRepo auth.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		err := r.ParseForm()
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		userId, err := userIdFromPathNotSelf(r)
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		// an empty role_name removes the role of the user
+		err = authRepo.SetRoleForUser(ctx, int(userAndSession.UserID), userAndSession.UserRoleName.String, userId, strings.TrimSpace(r.FormValue("role_name")))
+		if err != nil {
+			writeError(ctx, w, r, return403IfCannotManageHigherRoleOr(err), err)
+			return
+		}
+
+		apiWriteOperationDoneSuccessfullyJson(ctx, w, r)
+	}
+}
+
+//-----------------------------------------------------------------------------
+
+func adminDeleteUser(authRepo auth.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		userId, err := userIdFromPathNotSelf(r)
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		err = authRepo.SoftDeleteUser(ctx, int(userAndSession.UserID), userAndSession.UserRoleName.String, userId)
+		if err != nil {
+			writeError(ctx, w, r, return403IfCannotManageHigherRoleOr(err), err)
+			return
+		}
+
+		apiWriteOperationDoneSuccessfullyJson(ctx, w, r)
+	}
+}
+
+func return403IfCannotManageHigherRoleOr(err error) int {
+	if errors.Is(err, apperr.ErrCannotManageHigherRole) {
+		return http.StatusForbidden
+	}
+	return return400IfApp404IfNoResultErrOr500(err)
+}
+
+//-----------------------------------------------------------------------------
+
+type publicRoleOrPermission struct {
+	Name      string             `json:"name"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+// the role and permission names are limited to 100 chars in the database
+const maxRoleOrPermissionNameLength = 100
+
+func validateRoleOrPermissionName(name string) error {
+	if len(name) == 0 {
+		return errors.New("the name is required")
+	}
+	if len(name) > maxRoleOrPermissionNameLength {
+		return fmt.Errorf("the name can not be longer than %d chars", maxRoleOrPermissionNameLength)
+	}
+	return nil
+}
+
+func adminListRoles(permRepo perm.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		roles, err := permRepo.GetAllRoles(ctx)
+		if err != nil {
+			writeError(ctx, w, r, return400IfAppErrOr500(err), err)
+			return
+		}
+
+		publicRoles := make([]publicRoleOrPermission, len(roles))
+		for i, role := range roles {
+			publicRoles[i] = publicRoleOrPermission{
+				Name:      role.Name,
+				CreatedAt: role.CreatedAt,
+				UpdatedAt: role.UpdatedAt,
+			}
+		}
+
+		writeResponse(ctx, w, r, http.StatusOK, publicRoles)
+	}
+}
+
+func adminCreateRole(permRepo perm.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		err := r.ParseForm()
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		name := strings.TrimSpace(r.FormValue("name"))
+		if err := validateRoleOrPermissionName(name); err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		role, err := permRepo.CreateRole(ctx, name)
+		if err != nil {
+			writeError(ctx, w, r, return400IfAppErrOr500(err), err)
+			return
+		}
+
+		writeResponse(ctx, w, r, http.StatusCreated, publicRoleOrPermission{
+			Name:      role.Name,
+			CreatedAt: role.CreatedAt,
+			UpdatedAt: role.UpdatedAt,
+		})
+	}
+}
+
+func adminDeleteRole(permRepo perm.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		err := permRepo.SoftDeleteRole(ctx, r.PathValue("name"))
+		if err != nil {
+			writeError(ctx, w, r, return400IfApp404IfNoResultErrOr500(err), err)
+			return
+		}
+
+		apiWriteOperationDoneSuccessfullyJson(ctx, w, r)
+	}
+}
+
+func adminListRolePermissions(permRepo perm.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		permissions, err := permRepo.GetPermissionsOfRole(ctx, r.PathValue("name"))
+		if err != nil {
+			writeError(ctx, w, r, return400IfApp404IfNoResultErrOr500(err), err)
+			return
+		}
+
+		writeResponse(ctx, w, r, http.StatusOK, map[string][]string{"permissions": permissions})
+	}
+}
+
+func adminAttachPermissionToRole(permRepo perm.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		err := r.ParseForm()
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		permission := strings.TrimSpace(r.FormValue("permission_name"))
+		if err := validateRoleOrPermissionName(permission); err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		err = permRepo.AttachPermissionToRole(ctx, userAndSession.UserRoleName.String, r.PathValue("name"), permission)
+		if err != nil {
+			writeError(ctx, w, r, return403IfCannotChangeRolePermissionsOr(err), err)
+			return
+		}
+
+		apiWriteOperationDoneSuccessfullyJson(ctx, w, r)
+	}
+}
+
+func adminDetachPermissionFromRole(permRepo perm.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		err := permRepo.DetachPermissionFromRole(ctx, userAndSession.UserRoleName.String, r.PathValue("name"), r.PathValue("permission"))
+		if err != nil {
+			writeError(ctx, w, r, return403IfCannotChangeRolePermissionsOr(err), err)
+			return
+		}
+
+		apiWriteOperationDoneSuccessfullyJson(ctx, w, r)
+	}
+}
+
+func return403IfCannotChangeRolePermissionsOr(err error) int {
+	if errors.Is(err, apperr.ErrCannotChangeOwnOrBaseRolePermissions) {
+		return http.StatusForbidden
+	}
+	return return400IfApp403IfPermissionDenied404IfNoResultErrOr500(err)
+}
+
+//-----------------------------------------------------------------------------
+
+func adminListPermissions(permRepo perm.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		permissions, err := permRepo.GetAllPermissions(ctx)
+		if err != nil {
+			writeError(ctx, w, r, return400IfAppErrOr500(err), err)
+			return
+		}
+
+		publicPermissions := make([]publicRoleOrPermission, len(permissions))
+		for i, p := range permissions {
+			publicPermissions[i] = publicRoleOrPermission{
+				Name:      p.Name,
+				CreatedAt: p.CreatedAt,
+				UpdatedAt: p.UpdatedAt,
+			}
+		}
+
+		writeResponse(ctx, w, r, http.StatusOK, publicPermissions)
+	}
+}
+
+func adminCreatePermission(permRepo perm.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		err := r.ParseForm()
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		name := strings.TrimSpace(r.FormValue("name"))
+		if err := validateRoleOrPermissionName(name); err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		permission, err := permRepo.CreatePermission(ctx, name)
+		if err != nil {
+			writeError(ctx, w, r, return400IfAppErrOr500(err), err)
+			return
+		}
+
+		writeResponse(ctx, w, r, http.StatusCreated, publicRoleOrPermission{
+			Name:      permission.Name,
+			CreatedAt: permission.CreatedAt,
+			UpdatedAt: permission.UpdatedAt,
+		})
+	}
+}
+
+func adminDeletePermission(permRepo perm.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		err := permRepo.SoftDeletePermission(ctx, r.PathValue("name"))
+		if err != nil {
+			writeError(ctx, w, r, return400IfApp404IfNoResultErrOr500(err), err)
+			return
+		}
+
+		apiWriteOperationDoneSuccessfullyJson(ctx, w, r)
+	}
+}
@@ -57,6 +57,16 @@ func authRouter(ctx context.Context, s *Server, authRepo auth.Repository) http.H
 		),
 	)
 
+	mux.HandleFunc(
+		"POST /refresh",
+		middleware.MiddlewareChain(
+			refreshSession(authRepo),
+			middleware.ACT_app_x_www_form_urlencoded,
+			refreshSessionRateLimiterByIP(ctx, s.rdb),
+			Installation(authRepo),
+		),
+	)
+
 	mux.HandleFunc(
 		"POST /logout",
 		middleware.MiddlewareChain(
@@ -113,6 +123,121 @@ func authRouter(ctx context.Context, s *Server, authRepo auth.Repository) http.H
 		),
 	)
 
+	mux.HandleFunc(
+		"POST /guest-login",
+		middleware.MiddlewareChain(
+			guestLogin(authRepo),
+			guestLoginRateLimiterByIP(ctx, s.rdb),
+			Installation(authRepo),
+		),
+	)
+
+	// for logged-in guest user, the account is attached after /verify-account
+	mux.HandleFunc(
+		"POST /guest/upgrade",
+		middleware.MiddlewareChain(
+			guestUpgradeToPasswordAccount(authRepo),
+			middleware.ACT_app_x_www_form_urlencoded,
+			createAcccountRateLimiterByIP(ctx, s.rdb),
+			createAcccountRateLimiterByAccessKey(ctx, s.rdb),
+			Auth(authRepo),
+		),
+	)
+
+	// for logged-in guest user
+	mux.HandleFunc(
+		"POST /guest/upgrade/oidc",
+		middleware.MiddlewareChain(
+			guestUpgradeWithOidc(authRepo),
+			middleware.ACT_app_x_www_form_urlencoded,
+			mobileOidcLoginRateLimiterByIP(ctx, s.rdb),
+			Auth(authRepo),
+		),
+	)
+
+	// for logged-in user
+	mux.HandleFunc(
+		"GET /sessions",
+		middleware.MiddlewareChain(
+			activeSessions(authRepo),
+			Auth(authRepo),
+		),
+	)
+
+	// for logged-in user
+	mux.HandleFunc(
+		"DELETE /sessions/{id}",
+		middleware.MiddlewareChain(
+			revokeSession(authRepo),
+			Installation(authRepo),
+			Auth(authRepo),
+		),
+	)
+
+	// completes a login that returned an mfa challenge
+	mux.HandleFunc(
+		"POST /mfa/verify",
+		middleware.MiddlewareChain(
+			verifyMfaChallenge(authRepo),
+			middleware.ACT_app_x_www_form_urlencoded,
+			mfaVerifyRateLimiterByIP(ctx, s.rdb),
+			Installation(authRepo),
+		),
+	)
+
+	// for the users with enforced mfa that did not set up the totp yet
+	mux.HandleFunc(
+		"POST /mfa/challenge/totp/enroll",
+		middleware.MiddlewareChain(
+			enrollTotpForMfaChallenge(authRepo),
+			middleware.ACT_app_x_www_form_urlencoded,
+			mfaVerifyRateLimiterByIP(ctx, s.rdb),
+			Installation(authRepo),
+		),
+	)
+
+	// for logged-in user
+	mux.HandleFunc(
+		"POST /mfa/totp/enroll",
+		middleware.MiddlewareChain(
+			enrollTotp(authRepo),
+			Auth(authRepo),
+		),
+	)
+
+	// for logged-in user
+	mux.HandleFunc(
+		"POST /mfa/totp/confirm",
+		middleware.MiddlewareChain(
+			confirmTotp(authRepo),
+			middleware.ACT_app_x_www_form_urlencoded,
+			mfaVerifyRateLimiterByIP(ctx, s.rdb),
+			Auth(authRepo),
+		),
+	)
+
+	// for logged-in user
+	mux.HandleFunc(
+		"POST /mfa/totp/disable",
+		middleware.MiddlewareChain(
+			disableTotp(authRepo),
+			middleware.ACT_app_x_www_form_urlencoded,
+			mfaVerifyRateLimiterByIP(ctx, s.rdb),
+			Auth(authRepo),
+		),
+	)
+
+	// for logged-in user
+	mux.HandleFunc(
+		"POST /mfa/recovery-codes",
+		middleware.MiddlewareChain(
+			regenerateMfaRecoveryCodes(authRepo),
+			middleware.ACT_app_x_www_form_urlencoded,
+			mfaVerifyRateLimiterByIP(ctx, s.rdb),
+			Auth(authRepo),
+		),
+	)
+
 	return middleware.MiddlewareChain(
 		mux.ServeHTTP,
 	)
@@ -577,7 +702,7 @@ func passwordLogin(authRepo auth.Repository) http.HandlerFunc {
 		installation := auth.MustInstallationFromContext(ctx)
 		requestIpAddres := tracker.MustReqIPFromContext(ctx)
 
-		user, token, err := authRepo.PasswordLogin(
+		user, tokens, mfaChallenge, err := authRepo.PasswordLogin(
 			ctx,
 			auth.PasswordLoginAccessKey{Phone: loginParam.PhoneNumber, Email: loginParam.Email, LoginIdentityType: loginParam.LoginIdentityType},
 			loginParam.Password,
@@ -593,16 +718,21 @@ func passwordLogin(authRepo auth.Repository) http.HandlerFunc {
 			return
 		}
 
+		if mfaChallenge != nil {
+			writeMfaChallengeResponse(ctx, w, r, mfaChallenge)
+			return
+		}
+
 		if installation.ClientType.IsWeb() {
-			setAuthorizationCookie(w, token)
+			setAuthorizationCookie(w, tokens)
 		}
 
 		response := struct {
-			User  publicUser `json:"user"`
-			Token string     `json:"token"`
+			User publicUser `json:"user"`
+			publicSessionTokens
 		}{
-			User:  NewPublicUserFromAuthUser(user),
-			Token: token,
+			User:                NewPublicUserFromAuthUser(user),
+			publicSessionTokens: newPublicSessionTokens(tokens),
 		}
 		writeResponse(ctx, w, r, http.StatusCreated, response)
 	}
@@ -709,6 +839,67 @@ func validateLogoutParam(r *http.Request) (logoutParams, []error) {
 
 //-----------------------------------------------------------------------------
 
+func refreshSessionRateLimiterByIP(ctx context.Context, rdb *redis.Client) func(next http.Handler) http.HandlerFunc {
+	return middleware.RateLimiter(
+		func(r *http.Request) (string, error) {
+			return r.RemoteAddr, nil
+		},
+		redis_ratelimiter.NewRedisSlidingWindowLimiter(
+			ctx,
+			rdb,
+			ratelimiter.Config{
+				PerTimeFrame: 120,
+				TimeFrame:    time.Hour,
+				KeyPrefix:    "auth:refresh:ip",
+			},
+		),
+	)
+}
+
+func refreshSession(authRepo auth.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		err := r.ParseForm()
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		installation := auth.MustInstallationFromContext(ctx)
+
+		refreshToken := r.FormValue("refresh_token")
+		if len(refreshToken) == 0 && installation.ClientType.IsWeb() {
+			refreshToken, _ = readRefreshTokenCookie(r)
+		}
+		if len(refreshToken) == 0 {
+			writeError(ctx, w, r, http.StatusUnauthorized, apperr.ErrInvalidRefreshToken)
+			return
+		}
+
+		tokens, err := authRepo.RefreshSession(ctx, refreshToken, installation)
+		if err != nil {
+			statusCode := return400IfAppErrOr500(err)
+			if errors.Is(err, apperr.ErrInvalidRefreshToken) || errors.Is(err, apperr.ErrRefreshTokenReused) || errors.Is(err, apperr.ErrBlockedUser) {
+				statusCode = http.StatusUnauthorized
+				if installation.ClientType.IsWeb() {
+					removeAuthorizationCookie(w)
+				}
+			}
+			writeError(ctx, w, r, statusCode, err)
+			return
+		}
+
+		if installation.ClientType.IsWeb() {
+			setAuthorizationCookie(w, tokens)
+		}
+
+		writeResponse(ctx, w, r, http.StatusOK, newPublicSessionTokens(tokens))
+	}
+}
+
+//-----------------------------------------------------------------------------
+
 type changePasswordParams struct {
 	oldPassword string
 	newPassword string
@@ -777,6 +968,22 @@ func validatePassword(password string) []error {
 
 // -----------------------------------------------------------------------------
 
+// publicSessionTokens keeps the "token" key for the access token so the old clients
+// keep working until their access token expires.
+type publicSessionTokens struct {
+	Token          string    `json:"token"`
+	TokenExpiresAt time.Time `json:"token_expires_at"`
+	RefreshToken   string    `json:"refresh_token"`
+}
+
+func newPublicSessionTokens(tokens auth.SessionTokens) publicSessionTokens {
+	return publicSessionTokens{
+		Token:          tokens.AccessToken,
+		TokenExpiresAt: tokens.AccessTokenExpiresAt,
+		RefreshToken:   tokens.RefreshToken,
+	}
+}
+
 type publicUser struct {
 	ID           int32       `json:"id"`
 	Username     string      `json:"username"`
@@ -1055,7 +1262,7 @@ func mobileOidcLogin(authRepo auth.Repository) http.HandlerFunc {
 		installation := auth.MustInstallationFromContext(ctx)
 		requestIpAddres := tracker.MustReqIPFromContext(ctx)
 
-		user, token, err := authRepo.LoginOrCreateUserWithOidc(
+		user, tokens, mfaChallenge, err := authRepo.LoginOrCreateUserWithOidc(
 			ctx,
 			requestIpAddres,
 			installation,
@@ -1070,12 +1277,17 @@ func mobileOidcLogin(authRepo auth.Repository) http.HandlerFunc {
 			return
 		}
 
+		if mfaChallenge != nil {
+			writeMfaChallengeResponse(ctx, w, r, mfaChallenge)
+			return
+		}
+
 		response := struct {
-			User  publicUser `json:"user"`
-			Token string     `json:"token"`
+			User publicUser `json:"user"`
+			publicSessionTokens
 		}{
-			User:  NewPublicUserFromAuthUser(user),
-			Token: token,
+			User:                NewPublicUserFromAuthUser(user),
+			publicSessionTokens: newPublicSessionTokens(tokens),
 		}
 		writeResponse(ctx, w, r, http.StatusCreated, response)
 	}
@@ -1106,3 +1318,453 @@ func validateMobileOidcLoginParam(r *http.Request) (mobileOidcLoginParams, []err
 	}
 	return params, errList
 }
+
+//-----------------------------------------------------------------------------
+
+func guestLoginRateLimiterByIP(ctx context.Context, rdb *redis.Client) func(next http.Handler) http.HandlerFunc {
+	return middleware.RateLimiter(
+		func(r *http.Request) (string, error) {
+			return r.RemoteAddr, nil
+		},
+		redis_ratelimiter.NewRedisSlidingWindowLimiter(
+			ctx,
+			rdb,
+			ratelimiter.Config{
+				PerTimeFrame: 30,
+				TimeFrame:    time.Hour * 12,
+				KeyPrefix:    "auth:login:guest:ip",
+			},
+		),
+	)
+}
+
+func guestLogin(authRepo auth.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		installation := auth.MustInstallationFromContext(ctx)
+		requestIpAddres := tracker.MustReqIPFromContext(ctx)
+
+		user, tokens, mfaChallenge, err := authRepo.GuestLogin(ctx, requestIpAddres, installation)
+		if err != nil {
+			writeError(ctx, w, r, return400IfAppErrOr500(err), err)
+			return
+		}
+
+		if mfaChallenge != nil {
+			writeMfaChallengeResponse(ctx, w, r, mfaChallenge)
+			return
+		}
+
+		if installation.ClientType.IsWeb() {
+			setAuthorizationCookie(w, tokens)
+		}
+
+		response := struct {
+			User publicUser `json:"user"`
+			publicSessionTokens
+		}{
+			User:                NewPublicUserFromAuthUser(user),
+			publicSessionTokens: newPublicSessionTokens(tokens),
+		}
+		writeResponse(ctx, w, r, http.StatusCreated, response)
+	}
+}
+
+func guestUpgradeToPasswordAccount(authRepo auth.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		err := r.ParseForm()
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		createAccountParam, errList := validateCreateAccountParam(r)
+		if len(errList) != 0 {
+			writeError(ctx, w, r, http.StatusBadRequest, errList...)
+			return
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		tuser := new(auth.TempPasswordUser)
+		tuser.LoginIdentityType = createAccountParam.LoginIdentityType
+		tuser.Email = createAccountParam.Email
+		tuser.Phone = createAccountParam.PhoneNumber
+		tuser.Password = createAccountParam.Password
+		tuser.Lname = createAccountParam.LastName
+		tuser.Fname = createAccountParam.FirstName
+
+		tuser, err = authRepo.CreateTempPasswordUserForGuest(ctx, int(userAndSession.UserID), tuser)
+		if err != nil {
+			writeError(ctx, w, r, return400IfAppErrOr500(err), err)
+			return
+		}
+
+		response := struct {
+			Id string `json:"id"`
+		}{
+			Id: tuser.Id.String(),
+		}
+
+		writeResponse(ctx, w, r, http.StatusCreated, response)
+	}
+}
+
+func guestUpgradeWithOidc(authRepo auth.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		err := r.ParseForm()
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		oidcParam, errList := validateMobileOidcLoginParam(r)
+		if len(errList) != 0 {
+			writeError(ctx, w, r, http.StatusBadRequest, errList...)
+			return
+		}
+
+		zlog := zerolog.Ctx(ctx).With().Str("oauth_provider", oidcParam.provider.String()).Logger()
+		ctx = zlog.WithContext(ctx)
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		user, err := authRepo.UpgradeGuestWithOidc(
+			ctx,
+			int(userAndSession.UserID),
+			auth.LoginOrCreateUserWithOidcRepoParam{
+				OauthProvider: *oidcParam.provider,
+				Code:          oidcParam.code,
+				OidcToken:     oidcParam.oidcToken,
+			},
+		)
+		if err != nil {
+			writeError(ctx, w, r, return400IfAppErrOr500(err), err)
+			return
+		}
+
+		response := struct {
+			User publicUser `json:"user"`
+		}{
+			User: NewPublicUserFromAuthUser(user),
+		}
+		writeResponse(ctx, w, r, http.StatusOK, response)
+	}
+}
+
+//-----------------------------------------------------------------------------
+
+func writeMfaChallengeResponse(ctx context.Context, w http.ResponseWriter, r *http.Request, mfaChallenge *auth.MfaChallenge) {
+	response := struct {
+		MfaRequired bool `json:"mfa_required"`
+		*auth.MfaChallenge
+	}{
+		MfaRequired:  true,
+		MfaChallenge: mfaChallenge,
+	}
+	writeResponse(ctx, w, r, http.StatusOK, response)
+}
+
+func mfaVerifyRateLimiterByIP(ctx context.Context, rdb *redis.Client) func(next http.Handler) http.HandlerFunc {
+	return middleware.RateLimiter(
+		func(r *http.Request) (string, error) {
+			return r.RemoteAddr, nil
+		},
+		redis_ratelimiter.NewRedisSlidingWindowLimiter(
+			ctx,
+			rdb,
+			ratelimiter.Config{
+				PerTimeFrame: 25,
+				TimeFrame:    time.Hour,
+				KeyPrefix:    "auth:mfa:verify:ip",
+			},
+		),
+	)
+}
+
+type verifyMfaChallengeParams struct {
+	challengeToken uuid.UUID
+	code           string
+	recoveryCode   string
+}
+
+func verifyMfaChallenge(authRepo auth.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		err := r.ParseForm()
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		params, errList := validateVerifyMfaChallengeParams(r)
+		if len(errList) != 0 {
+			writeError(ctx, w, r, http.StatusBadRequest, errList...)
+			return
+		}
+
+		installation := auth.MustInstallationFromContext(ctx)
+		requestIpAddres := tracker.MustReqIPFromContext(ctx)
+
+		user, tokens, recoveryCodes, err := authRepo.VerifyMfaChallenge(
+			ctx,
+			params.challengeToken,
+			params.code,
+			params.recoveryCode,
+			requestIpAddres,
+			installation,
+		)
+		if err != nil {
+			statusCode := return400IfAppErrOr500(err)
+			if errors.Is(err, apperr.ErrInvalidMfaCode) || errors.Is(err, apperr.ErrInvalidMfaChallenge) {
+				statusCode = http.StatusUnauthorized
+			}
+			if errors.Is(err, apperr.ErrTooManyRequests) {
+				statusCode = http.StatusTooManyRequests
+			}
+			writeError(ctx, w, r, statusCode, err)
+			return
+		}
+
+		if installation.ClientType.IsWeb() {
+			setAuthorizationCookie(w, tokens)
+		}
+
+		response := struct {
+			User publicUser `json:"user"`
+			publicSessionTokens
+			RecoveryCodes []string `json:"recovery_codes,omitempty"` // only when the totp is set up during the login
+		}{
+			User:                NewPublicUserFromAuthUser(user),
+			publicSessionTokens: newPublicSessionTokens(tokens),
+			RecoveryCodes:       recoveryCodes,
+		}
+		writeResponse(ctx, w, r, http.StatusCreated, response)
+	}
+}
+
+func validateVerifyMfaChallengeParams(r *http.Request) (verifyMfaChallengeParams, []error) {
+	errList := make([]error, 0, 2)
+
+	challengeToken, err := uuid.Parse(r.FormValue("challenge_token"))
+	if err != nil {
+		errList = append(errList, apperr.ErrInvalidMfaChallenge)
+	}
+
+	code := r.FormValue("code")
+	recoveryCode := r.FormValue("recovery_code")
+	if len(code) == 0 && len(recoveryCode) == 0 {
+		errList = append(errList, apperr.ErrInvalidMfaCode)
+	}
+
+	params := verifyMfaChallengeParams{
+		challengeToken: challengeToken,
+		code:           code,
+		recoveryCode:   recoveryCode,
+	}
+	return params, errList
+}
+
+func enrollTotpForMfaChallenge(authRepo auth.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		err := r.ParseForm()
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		challengeToken, err := uuid.Parse(r.FormValue("challenge_token"))
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, apperr.ErrInvalidMfaChallenge)
+			return
+		}
+
+		installation := auth.MustInstallationFromContext(ctx)
+
+		enrollment, err := authRepo.EnrollTotpForMfaChallenge(ctx, challengeToken, installation)
+		if err != nil {
+			writeError(ctx, w, r, return400IfAppErrOr500(err), err)
+			return
+		}
+
+		writeResponse(ctx, w, r, http.StatusOK, enrollment)
+	}
+}
+
+func enrollTotp(authRepo auth.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		enrollment, err := authRepo.EnrollTotp(ctx, int(userAndSession.UserID))
+		if err != nil {
+			writeError(ctx, w, r, return400IfAppErrOr500(err), err)
+			return
+		}
+
+		writeResponse(ctx, w, r, http.StatusOK, enrollment)
+	}
+}
+
+func confirmTotp(authRepo auth.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		err := r.ParseForm()
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		recoveryCodes, err := authRepo.ConfirmTotp(ctx, int(userAndSession.UserID), r.FormValue("code"))
+		if err != nil {
+			writeError(ctx, w, r, return400IfAppErrOr500(err), err)
+			return
+		}
+
+		writeMfaRecoveryCodesResponse(ctx, w, r, recoveryCodes)
+	}
+}
+
+func disableTotp(authRepo auth.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		err := r.ParseForm()
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		err = authRepo.DisableTotp(ctx, int(userAndSession.UserID), r.FormValue("code"))
+		if err != nil {
+			writeError(ctx, w, r, return400IfAppErrOr500(err), err)
+			return
+		}
+
+		apiWriteOperationDoneSuccessfullyJson(ctx, w, r)
+	}
+}
+
+func regenerateMfaRecoveryCodes(authRepo auth.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		err := r.ParseForm()
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		recoveryCodes, err := authRepo.RegenerateRecoveryCodes(ctx, int(userAndSession.UserID), r.FormValue("code"))
+		if err != nil {
+			writeError(ctx, w, r, return400IfAppErrOr500(err), err)
+			return
+		}
+
+		writeMfaRecoveryCodesResponse(ctx, w, r, recoveryCodes)
+	}
+}
+
+func writeMfaRecoveryCodesResponse(ctx context.Context, w http.ResponseWriter, r *http.Request, recoveryCodes []string) {
+	response := struct {
+		RecoveryCodes []string `json:"recovery_codes"`
+	}{
+		RecoveryCodes: recoveryCodes,
+	}
+	writeResponse(ctx, w, r, http.StatusOK, response)
+}
+
+//-----------------------------------------------------------------------------
+
+func activeSessions(authRepo auth.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		sessions, err := authRepo.GetActiveSessionsForUser(ctx, int(userAndSession.UserID))
+		if err != nil {
+			writeError(ctx, w, r, return400IfAppErrOr500(err), err)
+			return
+		}
+
+		type PublicSession struct {
+			ID                 int32              `json:"id"`
+			IsCurrent          bool               `json:"is_current"`
+			IpAddress          string             `json:"ip_address"`
+			CreatedAt          pgtype.Timestamptz `json:"created_at"`
+			ExpiresAt          pgtype.Timestamptz `json:"expires_at"`
+			LastActiveAt       pgtype.Timestamptz `json:"last_active_at"`
+			LoginIdentityType  string             `json:"login_identity_type"`
+			DeviceOs           string             `json:"device_os"`
+			DeviceOsVersion    pgtype.Text        `json:"device_os_version"`
+			DeviceManufacturer pgtype.Text        `json:"device_manufacturer"`
+			ClientType         string             `json:"client_type"`
+			AppVersion         string             `json:"app_version"`
+		}
+
+		publicSessions := make([]PublicSession, len(sessions))
+		for i, s := range sessions {
+			publicSessions[i] = PublicSession{
+				ID:                 s.ID,
+				IsCurrent:          s.ID == userAndSession.SessionID,
+				IpAddress:          s.IpAddress.String(),
+				CreatedAt:          s.CreatedAt,
+				ExpiresAt:          s.ExpiresAt,
+				LastActiveAt:       s.LastActiveAt,
+				LoginIdentityType:  s.LoginIdentityType.String(),
+				DeviceOs:           s.DeviceOs.String(),
+				DeviceOsVersion:    s.DeviceOsVersion,
+				DeviceManufacturer: s.DeviceManufacturer,
+				ClientType:         s.ClientType.String(),
+				AppVersion:         s.AppVersion,
+			}
+		}
+
+		writeResponse(ctx, w, r, http.StatusOK, publicSessions)
+	}
+}
+
+func revokeSession(authRepo auth.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		sessionId, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, apperr.ErrInvalidId)
+			return
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+		installation := auth.MustInstallationFromContext(ctx)
+
+		err = authRepo.RevokeSessionForUser(ctx, int(userAndSession.UserID), sessionId)
+		if err != nil {
+			writeError(ctx, w, r, return400IfApp404IfNoResultErrOr500(err), err)
+			return
+		}
+
+		// the user revoked the session used for this request
+		if sessionId == int(userAndSession.SessionID) && installation.ClientType.IsWeb() {
+			removeAuthorizationCookie(w)
+		}
+
+		apiWriteOperationDoneSuccessfullyJson(ctx, w, r)
+	}
+}
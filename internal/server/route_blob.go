@@ -0,0 +1,57 @@
+package server
+
+import (
+	"errors"
+	"io/fs"
+	"mime"
+	"net/http"
+
+	"github.com/Nidal-Bakir/go-todo-backend/internal/apperr"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/blobstore"
+)
+
+// blobStoreRequired responds with 503 to the routes that use the
+// blob store when it is not configured (see blobstore.NewStoreFromEnv)
+func blobStoreRequired(store blobstore.Store) func(http.Handler) http.HandlerFunc {
+	return func(next http.Handler) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if store == nil {
+				writeError(r.Context(), w, r, http.StatusServiceUnavailable, apperr.ErrTodoAttachmentsUnavailable)
+				return
+			}
+			next.ServeHTTP(w, r)
+		}
+	}
+}
+
+// blobDownload serves the signed urls of the local blob store,
+// the signature is the auth so it is outside of the api
+func blobDownload(store *blobstore.LocalStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		file, filename, err := store.OpenSigned(r.PathValue("key"), r.URL.Query())
+		if err != nil {
+			switch {
+			case errors.Is(err, blobstore.ErrInvalidSignature):
+				writeError(ctx, w, r, http.StatusForbidden, err)
+			case errors.Is(err, fs.ErrNotExist):
+				writeError(ctx, w, r, http.StatusNotFound, apperr.ErrNoResult)
+			default:
+				writeError(ctx, w, r, http.StatusInternalServerError, err)
+			}
+			return
+		}
+		defer file.Close()
+
+		stat, err := file.Stat()
+		if err != nil {
+			writeError(ctx, w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		http.ServeContent(w, r, filename, stat.ModTime(), file)
+	}
+}
@@ -2,15 +2,22 @@ package server
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"hash/fnv"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Nidal-Bakir/go-todo-backend/internal/apperr"
 	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/auth"
 	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/todo"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/middleware"
 	"github.com/Nidal-Bakir/go-todo-backend/internal/utils/paginate"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/utils/rrule"
 )
 
 // this limits are also check on the db level,
@@ -22,8 +29,13 @@ const (
 	todoStatusLengthLimit int = 50
 )
 
+const (
+	todoRecurrenceLengthLimit         int = 200
+	todoRecurrenceTimezoneLengthLimit int = 64
+)
+
 func todoRouter(_ context.Context, s *Server) http.Handler {
-	todoRepo := todo.NewRepository(s.db, s.rdb)
+	todoRepo := todo.NewRepository(s.db, s.rdb, s.gatewaysProvider, s.blobStore)
 
 	mux := http.NewServeMux()
 
@@ -36,6 +48,88 @@ func todoRouter(_ context.Context, s *Server) http.Handler {
 
 	mux.HandleFunc("DELETE /todo/{id}", deleteTodo(todoRepo))
 
+	mux.HandleFunc("GET /todo/sync", todoSyncPull(todoRepo))
+	mux.HandleFunc(
+		"POST /todo/sync",
+		middleware.MiddlewareChain(
+			todoSyncPush(todoRepo),
+			middleware.ACT_app_json,
+			middleware.RequestSize(todoSyncPushRequestSizeLimit),
+		),
+	)
+
+	mux.HandleFunc(
+		"POST /todo/bulk",
+		middleware.MiddlewareChain(
+			todoBulkOperations(todoRepo),
+			middleware.ACT_app_json,
+			middleware.RequestSize(todoBulkRequestSizeLimit),
+		),
+	)
+
+	mux.HandleFunc("GET /todo/trash", todoTrashIndex(todoRepo))
+	mux.HandleFunc("POST /todo/{id}/restore", restoreTodo(todoRepo))
+	mux.HandleFunc("DELETE /todo/{id}/purge", purgeTodo(todoRepo))
+
+	mux.HandleFunc("GET /todo/{id}/items", todoChecklistIndex(todoRepo))
+	mux.HandleFunc("POST /todo/{id}/items", createTodoChecklistItem(todoRepo))
+	mux.HandleFunc("POST /todo/{id}/items/reorder", reorderTodoChecklistItems(todoRepo))
+	mux.HandleFunc("PATCH /todo/{id}/items/{item_id}", updateTodoChecklistItem(todoRepo))
+	mux.HandleFunc("DELETE /todo/{id}/items/{item_id}", deleteTodoChecklistItem(todoRepo))
+
+	mux.HandleFunc("GET /todo/{id}/activity", todoActivityIndex(todoRepo))
+
+	mux.HandleFunc("GET /todo/{id}/comments", todoCommentIndex(todoRepo))
+	mux.HandleFunc("POST /todo/{id}/comments", createTodoComment(todoRepo))
+	mux.HandleFunc("PATCH /todo/{id}/comments/{comment_id}", updateTodoComment(todoRepo))
+	mux.HandleFunc("DELETE /todo/{id}/comments/{comment_id}", deleteTodoComment(todoRepo))
+
+	blobStoreRequired := blobStoreRequired(s.blobStore)
+	mux.HandleFunc("GET /todo/{id}/attachments", blobStoreRequired(todoAttachmentIndex(todoRepo)))
+	mux.HandleFunc("GET /todo/{id}/attachments/{attachment_id}", blobStoreRequired(todoAttachmentShow(todoRepo)))
+	mux.HandleFunc(
+		"POST /todo/{id}/attachments",
+		middleware.MiddlewareChain(
+			createTodoAttachment(todoRepo),
+			blobStoreRequired,
+			middleware.ACT_multipart_form_data,
+			middleware.RequestSize(todoAttachmentRequestSizeLimit),
+		),
+	)
+	mux.HandleFunc("DELETE /todo/{id}/attachments/{attachment_id}", blobStoreRequired(deleteTodoAttachment(todoRepo)))
+
+	mux.HandleFunc("PUT /todo/{id}/tags/{tag_id}", attachTodoTag(todoRepo))
+	mux.HandleFunc("DELETE /todo/{id}/tags/{tag_id}", detachTodoTag(todoRepo))
+
+	mux.HandleFunc("GET /todo-tags", todoTagIndex(todoRepo))
+	mux.HandleFunc("POST /todo-tags", createTodoTag(todoRepo))
+	mux.HandleFunc("PATCH /todo-tags/{id}", updateTodoTag(todoRepo))
+	mux.HandleFunc("DELETE /todo-tags/{id}", deleteTodoTag(todoRepo))
+
+	mux.HandleFunc("GET /todo-workflow", todoWorkflowIndex(todoRepo))
+	mux.HandleFunc("POST /todo-workflow/statuses", createTodoWorkflowStatus(todoRepo))
+	mux.HandleFunc("POST /todo-workflow/statuses/reorder", reorderTodoWorkflowStatuses(todoRepo))
+	mux.HandleFunc("PATCH /todo-workflow/statuses/{id}", updateTodoWorkflowStatus(todoRepo))
+	mux.HandleFunc("DELETE /todo-workflow/statuses/{id}", deleteTodoWorkflowStatus(todoRepo))
+
+	mux.HandleFunc("GET /todo-lists", todoListIndex(todoRepo))
+	mux.HandleFunc("GET /todo-lists/{id}", todoListShow(todoRepo))
+	mux.HandleFunc("POST /todo-lists", createTodoList(todoRepo))
+	mux.HandleFunc("PATCH /todo-lists/{id}", updateTodoList(todoRepo))
+	mux.HandleFunc("DELETE /todo-lists/{id}", deleteTodoList(todoRepo))
+
+	mux.HandleFunc("GET /todo-lists/{id}/members", todoListMembersIndex(todoRepo))
+	mux.HandleFunc("PATCH /todo-lists/{id}/members/{user_id}", changeTodoListMemberRole(todoRepo))
+	mux.HandleFunc("DELETE /todo-lists/{id}/members/{user_id}", removeTodoListMember(todoRepo))
+
+	mux.HandleFunc("GET /todo-lists/{id}/invitations", todoListInvitationsIndex(todoRepo))
+	mux.HandleFunc("POST /todo-lists/{id}/invitations", inviteToTodoList(todoRepo))
+	mux.HandleFunc("DELETE /todo-lists/{id}/invitations/{invitation_id}", cancelTodoListInvitation(todoRepo))
+
+	mux.HandleFunc("GET /todo-lists/invitations", myTodoListInvitationsIndex(todoRepo))
+	mux.HandleFunc("POST /todo-lists/invitations/{id}/accept", respondToTodoListInvitation(todoRepo, true))
+	mux.HandleFunc("POST /todo-lists/invitations/{id}/decline", respondToTodoListInvitation(todoRepo, false))
+
 	return mux
 }
 
@@ -59,7 +153,7 @@ func createTodo(todoRepo todo.Repository) http.HandlerFunc {
 
 		res, err := todoRepo.CreateTodo(ctx, int(userAndSession.UserID), todoData)
 		if err != nil {
-			writeError(ctx, w, r, return400IfAppErrOr500(err), err)
+			writeError(ctx, w, r, return400IfApp403IfPermissionDenied404IfNoResultErrOr500(err), err)
 			return
 		}
 
@@ -68,10 +162,16 @@ func createTodo(todoRepo todo.Repository) http.HandlerFunc {
 }
 
 func extractTodoData(r *http.Request) (todo.TodoData, error) {
+	return extractTodoDataFromForm(r.Context(), r.Form)
+}
+
+// extractTodoDataFromForm parses the fields of the todo forms, the json changes
+// have the same fields (see todoDataFromFields)
+func extractTodoDataFromForm(ctx context.Context, form url.Values) (todo.TodoData, error) {
 	data := todo.TodoData{}
 
 	var title string
-	title = r.FormValue("title")
+	title = form.Get("title")
 	titleLen := len(title)
 	if titleLen > todoTitleLengthLimit {
 		return todo.TodoData{}, errors.New("too large todo title")
@@ -81,7 +181,7 @@ func extractTodoData(r *http.Request) (todo.TodoData, error) {
 	}
 
 	var body string
-	body = r.FormValue("body")
+	body = form.Get("body")
 	bodyLen := len(body)
 	if bodyLen > todoBodyLengthLimit {
 		return todo.TodoData{}, errors.New("too large todo body")
@@ -90,23 +190,143 @@ func extractTodoData(r *http.Request) (todo.TodoData, error) {
 		data.Body = &body
 	}
 
-	status := new(todo.TodoStatus)
-	statusStr := r.FormValue("status")
+	// the status is validated against the workflow of the user in the repository
+	statusStr := strings.TrimSpace(form.Get("status"))
 	statusStrLen := len(statusStr)
 	if statusStrLen > todoStatusLengthLimit {
 		return todo.TodoData{}, errors.New("too large todo status")
 	}
 	if statusStrLen != 0 {
-		status, err := status.FromString(statusStr)
+		status := todo.TodoStatus(statusStr)
+		data.Status = &status
+	}
+
+	// the due date and the reminder are removed by sending the field with an empty value
+	dueAtStr := form.Get("due_at")
+	if len(dueAtStr) != 0 {
+		dueAt, err := parseTodoTime(ctx, dueAtStr)
+		if err != nil {
+			return todo.TodoData{}, fmt.Errorf("invalid due_at: %w", err)
+		}
+		data.DueAt = &dueAt
+	} else if form.Has("due_at") {
+		data.ClearDueAt = true
+	}
+
+	remindAtStr := form.Get("remind_at")
+	if len(remindAtStr) != 0 {
+		remindAt, err := parseTodoTime(ctx, remindAtStr)
+		if err != nil {
+			return todo.TodoData{}, fmt.Errorf("invalid remind_at: %w", err)
+		}
+		if !remindAt.After(time.Now()) {
+			return todo.TodoData{}, errors.New("the remind_at should be in the future")
+		}
+		if data.DueAt != nil && remindAt.After(*data.DueAt) {
+			return todo.TodoData{}, apperr.ErrTodoRemindAtAfterDueAt
+		}
+		data.RemindAt = &remindAt
+	} else if form.Has("remind_at") {
+		data.ClearRemindAt = true
+	}
+
+	// the todo is removed from its list by sending the field with an empty value
+	listIdStr := form.Get("list_id")
+	if len(listIdStr) != 0 {
+		listId, err := strconv.Atoi(listIdStr)
+		if err != nil {
+			return todo.TodoData{}, errors.New("invalid list_id")
+		}
+		data.ListId = &listId
+	} else if form.Has("list_id") {
+		data.ClearListId = true
+	}
+
+	// the todo stops repeating by sending the field with an empty value
+	recurrenceStr := form.Get("recurrence")
+	if len(recurrenceStr) > todoRecurrenceLengthLimit {
+		return todo.TodoData{}, errors.New("too large todo recurrence")
+	}
+	if len(recurrenceStr) != 0 {
+		rule, err := rrule.Parse(recurrenceStr)
 		if err != nil {
 			return todo.TodoData{}, err
 		}
-		data.Status = status
+		data.Recurrence = &rule
+	} else if form.Has("recurrence") {
+		data.ClearRecurrence = true
+	}
+
+	// replaces the tags of the todo, they are removed by sending the field with an empty value
+	if tagIdsStr := form.Get("tag_ids"); len(tagIdsStr) != 0 {
+		tagIds, err := parseTodoTagIds(form["tag_ids"], "tag_ids")
+		if err != nil {
+			return todo.TodoData{}, err
+		}
+		data.TagIds = tagIds
+	} else if form.Has("tag_ids") {
+		data.ClearTags = true
+	}
+
+	timezoneStr := form.Get("recurrence_timezone")
+	if len(timezoneStr) > todoRecurrenceTimezoneLengthLimit {
+		return todo.TodoData{}, errors.New("too large todo recurrence_timezone")
+	}
+	if len(timezoneStr) != 0 {
+		// "Local" is the timezone of the server, not a real one
+		loc, err := time.LoadLocation(timezoneStr)
+		if err != nil || timezoneStr == "Local" {
+			return todo.TodoData{}, apperr.ErrInvalidTimezone
+		}
+		data.RecurrenceLocation = loc
 	}
 
 	return data, nil
 }
 
+// todoDataFromFields parses the fields of the changes that are sent as json, they
+// are the same as the fields of the todo forms with complete_items of the update
+func todoDataFromFields(ctx context.Context, fields map[string]string) (todo.TodoData, error) {
+	form := make(url.Values, len(fields))
+	for k, v := range fields {
+		form.Set(k, v)
+	}
+
+	data, err := extractTodoDataFromForm(ctx, form)
+	if err != nil {
+		return todo.TodoData{}, err
+	}
+
+	if completeItemsStr := form.Get("complete_items"); len(completeItemsStr) != 0 {
+		data.CompleteChecklistItems, err = strconv.ParseBool(completeItemsStr)
+		if err != nil {
+			return todo.TodoData{}, errors.New("invalid complete_items")
+		}
+	}
+	return data, nil
+}
+
+// the local time layout used by the clients that do not send the timezone offset
+const todoLocalTimeLayout = "2006-01-02T15:04"
+
+// parseTodoTime accepts RFC 3339 times, or local times without an offset
+// in the timezone of the installation (device) that sent the request.
+// The installation is optional for the todo routes, so the local times need the A-Installation header
+func parseTodoTime(ctx context.Context, timeStr string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, timeStr)
+	if err == nil {
+		return t, nil
+	}
+
+	installation, ok := auth.InstallationFromContext(ctx)
+	if !ok {
+		return time.Time{}, errors.New("send the time with an offset, or the A-Installation header for the local times")
+	}
+
+	loc := time.FixedZone("", int(installation.TimezoneOffsetInMinutes)*60)
+	return time.ParseInLocation(todoLocalTimeLayout, timeStr, loc)
+}
+
 func updateTodo(todoRepo todo.Repository) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
@@ -130,13 +350,37 @@ func updateTodo(todoRepo todo.Repository) http.HandlerFunc {
 			return
 		}
 
+		// to complete all the checklist items when the todo is moved to the completed status
+		if completeItemsStr := r.FormValue("complete_items"); len(completeItemsStr) != 0 {
+			todoData.CompleteChecklistItems, err = strconv.ParseBool(completeItemsStr)
+			if err != nil {
+				writeError(ctx, w, r, http.StatusBadRequest, errors.New("invalid complete_items"))
+				return
+			}
+		}
+
+		// the update is done only if the client has the latest version of the todo
+		todoData.IfVersion, err = todoVersionFromIfMatch(r.Header.Get("If-Match"))
+		if err != nil {
+			writeError(ctx, w, r, return412IfTodoVersionMismatchOr(err, http.StatusBadRequest), err)
+			return
+		}
+
 		res, err := todoRepo.UpdateTodo(ctx, int(userAndSession.UserID), todoId, todoData)
 		if err != nil {
-			writeError(ctx, w, r, return400IfAppErrOr500(err), err)
+			writeError(ctx, w, r, return412IfTodoVersionMismatchOr(err, return400IfApp403IfPermissionDenied404IfNoResultErrOr500(err)), err)
 			return
 		}
 
-		writeResponse(ctx, w, r, http.StatusCreated, publicTodoItemFromRepoModel(res))
+		publicTodo := publicTodoItemFromRepoModel(res)
+		etag, err := todoETag(publicTodo)
+		if err != nil {
+			writeError(ctx, w, r, http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set("ETag", etag)
+
+		writeResponse(ctx, w, r, http.StatusCreated, publicTodo)
 	}
 }
 
@@ -152,16 +396,41 @@ func todoIndex(todoRepo todo.Repository) http.HandlerFunc {
 
 		userAndSession := auth.MustUserAndSessionFromContext(ctx)
 
-		paginatedDate, err := paginate.NewSimplePaginatedAction(
-			func(offset, limit int) ([]todo.TodoItem, error) {
-				return todoRepo.GetTodos(
-					ctx,
-					int(userAndSession.UserID),
-					offset,
-					limit,
-				)
-			},
-		).Exec(r)
+		filters, err := extractTodoFilters(r)
+		if err != nil {
+			writeError(ctx, w, r, return400IfAppErrOr500(err), err)
+			return
+		}
+
+		var paginatedDate *paginate.PaginatedData[todo.TodoItem]
+
+		// the filters are kept in the next and prev links, since they are query params
+		if paginate.IsCursorRequest(r) {
+			paginatedDate, err = paginate.NewCursorPaginatedAction(
+				func(cursor *todo.TodoCursor, limit int) ([]todo.TodoItem, error) {
+					return todoRepo.GetTodosAfterCursor(
+						ctx,
+						int(userAndSession.UserID),
+						filters,
+						cursor,
+						limit,
+					)
+				},
+				todo.TodoCursorOf,
+			).Exec(r)
+		} else {
+			paginatedDate, err = paginate.NewSimplePaginatedAction(
+				func(offset, limit int) ([]todo.TodoItem, error) {
+					return todoRepo.GetTodos(
+						ctx,
+						int(userAndSession.UserID),
+						filters,
+						offset,
+						limit,
+					)
+				},
+			).Exec(r)
+		}
 		if err != nil && !errors.Is(err, apperr.ErrNoResult) {
 			writeError(ctx, w, r, return400IfAppErrOr500(err), err)
 			return
@@ -174,6 +443,88 @@ func todoIndex(todoRepo todo.Repository) http.HandlerFunc {
 	}
 }
 
+// the max length of the full-text search query
+const todoSearchQueryLengthLimit int = 200
+
+func extractTodoFilters(r *http.Request) (todo.TodoFilters, error) {
+	filters := todo.TodoFilters{Sort: todo.TodoSortDefault}
+
+	// not validated against a workflow, the todos of a shared list follow the workflows of their creators
+	statusStr := strings.TrimSpace(r.FormValue("status"))
+	if len(statusStr) > todoStatusLengthLimit {
+		return todo.TodoFilters{}, errors.New("too large todo status")
+	}
+	if len(statusStr) != 0 {
+		status := todo.TodoStatus(statusStr)
+		filters.Status = &status
+	}
+
+	filters.Query = strings.TrimSpace(r.FormValue("q"))
+	if len(filters.Query) > todoSearchQueryLengthLimit {
+		return todo.TodoFilters{}, errors.New("too large search query")
+	}
+
+	parseTimeFilter := func(name string) (*time.Time, error) {
+		timeStr := r.FormValue(name)
+		if len(timeStr) == 0 {
+			return nil, nil
+		}
+		t, err := parseTodoTime(r.Context(), timeStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", name, err)
+		}
+		return &t, nil
+	}
+
+	var err error
+	if filters.CreatedAfter, err = parseTimeFilter("created_after"); err != nil {
+		return todo.TodoFilters{}, err
+	}
+	if filters.CreatedBefore, err = parseTimeFilter("created_before"); err != nil {
+		return todo.TodoFilters{}, err
+	}
+	if filters.UpdatedAfter, err = parseTimeFilter("updated_after"); err != nil {
+		return todo.TodoFilters{}, err
+	}
+	if filters.UpdatedBefore, err = parseTimeFilter("updated_before"); err != nil {
+		return todo.TodoFilters{}, err
+	}
+
+	if listIdStr := r.FormValue("list_id"); len(listIdStr) != 0 {
+		listId, err := strconv.Atoi(listIdStr)
+		if err != nil {
+			return todo.TodoFilters{}, errors.New("invalid list_id")
+		}
+		filters.ListId = &listId
+	}
+
+	// tag=1,2 matches the todos with any of the tags, and with tag_match=all the todos with all of them
+	if r.Form.Has("tag") {
+		tagIds, err := parseTodoTagIds(r.Form["tag"], "tag")
+		if err != nil {
+			return todo.TodoFilters{}, err
+		}
+		filters.TagIds = tagIds
+	}
+	switch tagMatch := r.FormValue("tag_match"); tagMatch {
+	case "", "any":
+	case "all":
+		filters.TagMatchAll = true
+	default:
+		return todo.TodoFilters{}, errors.New("invalid tag_match, it should be any or all")
+	}
+
+	if sortStr := r.FormValue("sort"); len(sortStr) != 0 {
+		sort, err := new(todo.TodoSort).FromString(sortStr)
+		if err != nil {
+			return todo.TodoFilters{}, err
+		}
+		filters.Sort = *sort
+	}
+
+	return filters, nil
+}
+
 func todoShow(todoRepo todo.Repository) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
@@ -192,11 +543,182 @@ func todoShow(todoRepo todo.Repository) http.HandlerFunc {
 			return
 		}
 
-		writeResponse(ctx, w, r, http.StatusCreated, publicTodoItemFromRepoModel(res))
+		publicTodo := publicTodoItemFromRepoModel(res)
+		etag, err := todoETag(publicTodo)
+		if err != nil {
+			writeError(ctx, w, r, http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set("ETag", etag)
+
+		if etagsMatchWeakly(r.Header.Get("If-None-Match"), etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		writeResponse(ctx, w, r, http.StatusCreated, publicTodo)
+	}
+}
+
+// todoETag is the version of the todo, followed by a hash of its representation,
+// since the checklist progress and the tags of the todo do not change its version
+// e.g. "7-1b2d3f4a5c6e7d8f"
+func todoETag(publicTodo publicTodoItem) (string, error) {
+	// it is only returned by the update, and not part of the todo itself
+	publicTodo.NextOccurrence = nil
+
+	b, err := json.Marshal(publicTodo)
+	if err != nil {
+		return "", err
+	}
+	hash := fnv.New64a()
+	hash.Write(b)
+
+	return fmt.Sprintf(`"%d-%x"`, publicTodo.Version, hash.Sum64()), nil
+}
+
+// todoVersionFromIfMatch returns the version in the entity tag of the If-Match header,
+// the header is compared by the version only, so the changes of the checklist items or
+// the tags do not fail the updates. nil for no header or "*"
+func todoVersionFromIfMatch(ifMatch string) (*int, error) {
+	ifMatch = strings.TrimSpace(ifMatch)
+	if len(ifMatch) == 0 || ifMatch == "*" {
+		return nil, nil
+	}
+	if strings.Contains(ifMatch, ",") {
+		return nil, errors.New("only one entity tag is supported in the If-Match header")
+	}
+	// the weak entity tags never match with If-Match (strong comparison)
+	if strings.HasPrefix(ifMatch, "W/") {
+		return nil, apperr.ErrTodoVersionMismatch
+	}
+
+	versionStr, _, _ := strings.Cut(strings.Trim(ifMatch, `"`), "-")
+	version, err := strconv.Atoi(versionStr)
+	if err != nil {
+		// not an entity tag of this server, so it does not match
+		return nil, apperr.ErrTodoVersionMismatch
+	}
+	return &version, nil
+}
+
+func return412IfTodoVersionMismatchOr(err error, code int) int {
+	if errors.Is(err, apperr.ErrTodoVersionMismatch) {
+		return http.StatusPreconditionFailed
+	}
+	return code
+}
+
+// etagsMatchWeakly reports whether the If-None-Match header has the etag or "*",
+// the If-None-Match uses the weak comparison (the W/ prefix is ignored)
+func etagsMatchWeakly(ifNoneMatch, etag string) bool {
+	for tag := range strings.SplitSeq(ifNoneMatch, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
 	}
+	return false
 }
 
 func deleteTodo(todoRepo todo.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		todoId, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, errors.New("can not parse the todo id from the url"))
+			return
+		}
+
+		// the todo is deleted only if the client has the latest version of it
+		ifVersion, err := todoVersionFromIfMatch(r.Header.Get("If-Match"))
+		if err != nil {
+			writeError(ctx, w, r, return412IfTodoVersionMismatchOr(err, http.StatusBadRequest), err)
+			return
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		err = todoRepo.DeleteTodo(ctx, int(userAndSession.UserID), todoId, ifVersion)
+		if err != nil {
+			writeError(ctx, w, r, return412IfTodoVersionMismatchOr(err, return400IfApp403IfPermissionDenied404IfNoResultErrOr500(err)), err)
+			return
+		}
+		apiWriteOperationDoneSuccessfullyJson(ctx, w, r)
+	}
+}
+
+func todoTrashIndex(todoRepo todo.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		err := r.ParseForm()
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		var paginatedDate *paginate.PaginatedData[todo.TodoItem]
+
+		if paginate.IsCursorRequest(r) {
+			paginatedDate, err = paginate.NewCursorPaginatedAction(
+				func(cursor *todo.TodoTrashCursor, limit int) ([]todo.TodoItem, error) {
+					return todoRepo.GetDeletedTodosAfterCursor(
+						ctx,
+						int(userAndSession.UserID),
+						cursor,
+						limit,
+					)
+				},
+				todo.TodoTrashCursorOf,
+			).Exec(r)
+		} else {
+			paginatedDate, err = paginate.NewSimplePaginatedAction(
+				func(offset, limit int) ([]todo.TodoItem, error) {
+					return todoRepo.GetDeletedTodos(
+						ctx,
+						int(userAndSession.UserID),
+						offset,
+						limit,
+					)
+				},
+			).Exec(r)
+		}
+		if err != nil {
+			writeError(ctx, w, r, return400IfAppErrOr500(err), err)
+			return
+		}
+
+		writeResponse(ctx, w, r, http.StatusOK, paginate.PaginatedDataMapper(paginatedDate, publicTodoItemFromRepoModel))
+	}
+}
+
+func restoreTodo(todoRepo todo.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		todoId, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, errors.New("can not parse the todo id from the url"))
+			return
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		res, err := todoRepo.RestoreTodo(ctx, int(userAndSession.UserID), todoId)
+		if err != nil {
+			writeError(ctx, w, r, return400IfApp404IfNoResultErrOr500(err), err)
+			return
+		}
+
+		writeResponse(ctx, w, r, http.StatusOK, publicTodoItemFromRepoModel(res))
+	}
+}
+
+func purgeTodo(todoRepo todo.Repository) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
@@ -208,7 +730,7 @@ func deleteTodo(todoRepo todo.Repository) http.HandlerFunc {
 
 		userAndSession := auth.MustUserAndSessionFromContext(ctx)
 
-		err = todoRepo.DeleteTodo(ctx, int(userAndSession.UserID), todoId)
+		err = todoRepo.PurgeTodo(ctx, int(userAndSession.UserID), todoId)
 		if err != nil {
 			writeError(ctx, w, r, return400IfApp404IfNoResultErrOr500(err), err)
 			return
@@ -218,21 +740,83 @@ func deleteTodo(todoRepo todo.Repository) http.HandlerFunc {
 }
 
 type publicTodoItem struct {
-	Id        int       `json:"id"`
-	Title     string    `json:"title"`
-	Body      string    `json:"body"`
-	Status    string    `json:"status"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	Id     int    `json:"id"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+	Status string `json:"status"`
+	ListId *int   `json:"list_id"`
+	// incremented on every change of the todo fields
+	Version int `json:"version"`
+	// whether the status is the completed status of the workflow of the creator
+	IsCompleted bool       `json:"is_completed"`
+	DueAt       *time.Time `json:"due_at"`
+	RemindAt    *time.Time `json:"remind_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+
+	// the tags of the current user
+	Tags []publicTodoTag `json:"tags"`
+
+	// the RRULE of the todo, null if the todo does not repeat
+	Recurrence         *string `json:"recurrence"`
+	RecurrenceTimezone *string `json:"recurrence_timezone"`
+
+	ChecklistTotal int `json:"checklist_total"`
+	ChecklistDone  int `json:"checklist_done"`
+	// the done items over all the items, null if the todo does not have a checklist
+	CompletionRatio *float64 `json:"completion_ratio"`
+
+	// the todo that is created when a recurring todo is completed
+	NextOccurrence *publicTodoItem `json:"next_occurrence,omitempty"`
 }
 
 func publicTodoItemFromRepoModel(i todo.TodoItem) publicTodoItem {
+	var completionRatio *float64
+	if i.ChecklistTotal != 0 {
+		ratio := float64(i.ChecklistDone) / float64(i.ChecklistTotal)
+		completionRatio = &ratio
+	}
+
+	var recurrence, recurrenceTimezone *string
+	if i.Recurrence != nil {
+		rule := i.Recurrence.Rule.String()
+		timezone := i.Recurrence.Location.String()
+		recurrence, recurrenceTimezone = &rule, &timezone
+	}
+
+	var nextOccurrence *publicTodoItem
+	if i.NextOccurrence != nil {
+		next := publicTodoItemFromRepoModel(*i.NextOccurrence)
+		nextOccurrence = &next
+	}
+
 	return publicTodoItem{
-		Id:        i.Id,
-		Title:     i.Title,
-		Body:      i.Body,
-		Status:    i.Status.String(),
+		Id:     i.Id,
+		Title:  i.Title,
+		Body:   i.Body,
+		Status: i.Status.String(),
+		ListId: i.ListId,
+
+		Version: i.Version,
+
+		IsCompleted: i.IsCompleted,
+
+		DueAt:     i.DueAt,
+		RemindAt:  i.RemindAt,
 		CreatedAt: i.CreatedAt,
 		UpdatedAt: i.UpdatedAt,
+		DeletedAt: i.DeletedAt,
+
+		Tags: publicTodoTagsFromRepoModels(i.Tags),
+
+		Recurrence:         recurrence,
+		RecurrenceTimezone: recurrenceTimezone,
+
+		ChecklistTotal:  i.ChecklistTotal,
+		ChecklistDone:   i.ChecklistDone,
+		CompletionRatio: completionRatio,
+
+		NextOccurrence: nextOccurrence,
 	}
 }
@@ -0,0 +1,306 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/auth"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/todo"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/utils/paginate"
+)
+
+// this limits are also check on the db level.
+// see the todo_comment table migration file(s)
+const todoCommentBodyLengthLimit int = 5000
+
+func todoActivityIndex(todoRepo todo.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		todoId, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, errors.New("can not parse the todo id from the url"))
+			return
+		}
+
+		err = r.ParseForm()
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		var paginatedDate *paginate.PaginatedData[todo.TodoActivity]
+
+		if paginate.IsCursorRequest(r) {
+			paginatedDate, err = paginate.NewCursorPaginatedAction(
+				func(cursor *todo.TodoCursor, limit int) ([]todo.TodoActivity, error) {
+					return todoRepo.GetActivityAfterCursor(
+						ctx,
+						int(userAndSession.UserID),
+						todoId,
+						cursor,
+						limit,
+					)
+				},
+				todo.TodoActivityCursorOf,
+			).Exec(r)
+		} else {
+			paginatedDate, err = paginate.NewSimplePaginatedAction(
+				func(offset, limit int) ([]todo.TodoActivity, error) {
+					return todoRepo.GetActivity(
+						ctx,
+						int(userAndSession.UserID),
+						todoId,
+						offset,
+						limit,
+					)
+				},
+			).Exec(r)
+		}
+		if err != nil {
+			writeError(ctx, w, r, return400IfApp404IfNoResultErrOr500(err), err)
+			return
+		}
+
+		writeResponse(ctx, w, r, http.StatusOK, paginate.PaginatedDataMapper(paginatedDate, publicTodoActivityFromRepoModel))
+	}
+}
+
+//---------------------------------------------------------------------------------
+
+func todoCommentIndex(todoRepo todo.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		todoId, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, errors.New("can not parse the todo id from the url"))
+			return
+		}
+
+		err = r.ParseForm()
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		var paginatedDate *paginate.PaginatedData[todo.TodoComment]
+
+		if paginate.IsCursorRequest(r) {
+			paginatedDate, err = paginate.NewCursorPaginatedAction(
+				func(cursor *todo.TodoCursor, limit int) ([]todo.TodoComment, error) {
+					return todoRepo.GetCommentsAfterCursor(
+						ctx,
+						int(userAndSession.UserID),
+						todoId,
+						cursor,
+						limit,
+					)
+				},
+				todo.TodoCommentCursorOf,
+			).Exec(r)
+		} else {
+			paginatedDate, err = paginate.NewSimplePaginatedAction(
+				func(offset, limit int) ([]todo.TodoComment, error) {
+					return todoRepo.GetComments(
+						ctx,
+						int(userAndSession.UserID),
+						todoId,
+						offset,
+						limit,
+					)
+				},
+			).Exec(r)
+		}
+		if err != nil {
+			writeError(ctx, w, r, return400IfApp404IfNoResultErrOr500(err), err)
+			return
+		}
+
+		writeResponse(ctx, w, r, http.StatusOK, paginate.PaginatedDataMapper(paginatedDate, publicTodoCommentFromRepoModel))
+	}
+}
+
+func createTodoComment(todoRepo todo.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		todoId, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, errors.New("can not parse the todo id from the url"))
+			return
+		}
+
+		err = r.ParseForm()
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		body, err := extractTodoCommentBody(r)
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		res, err := todoRepo.CreateComment(ctx, int(userAndSession.UserID), todoId, body)
+		if err != nil {
+			writeError(ctx, w, r, return400IfApp404IfNoResultErrOr500(err), err)
+			return
+		}
+
+		writeResponse(ctx, w, r, http.StatusCreated, publicTodoCommentFromRepoModel(res))
+	}
+}
+
+func updateTodoComment(todoRepo todo.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		todoId, commentId, err := todoIdAndCommentIdFromPath(r)
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		err = r.ParseForm()
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		body, err := extractTodoCommentBody(r)
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		res, err := todoRepo.UpdateComment(ctx, int(userAndSession.UserID), todoId, commentId, body)
+		if err != nil {
+			writeError(ctx, w, r, return400IfApp403IfPermissionDenied404IfNoResultErrOr500(err), err)
+			return
+		}
+
+		writeResponse(ctx, w, r, http.StatusOK, publicTodoCommentFromRepoModel(res))
+	}
+}
+
+func extractTodoCommentBody(r *http.Request) (string, error) {
+	body := strings.TrimSpace(r.FormValue("body"))
+	if len(body) == 0 {
+		return "", errors.New("the comment body is required")
+	}
+	if len(body) > todoCommentBodyLengthLimit {
+		return "", errors.New("too large comment body")
+	}
+	return body, nil
+}
+
+func deleteTodoComment(todoRepo todo.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		todoId, commentId, err := todoIdAndCommentIdFromPath(r)
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		err = todoRepo.DeleteComment(ctx, int(userAndSession.UserID), todoId, commentId)
+		if err != nil {
+			writeError(ctx, w, r, return400IfApp403IfPermissionDenied404IfNoResultErrOr500(err), err)
+			return
+		}
+		apiWriteOperationDoneSuccessfullyJson(ctx, w, r)
+	}
+}
+
+func todoIdAndCommentIdFromPath(r *http.Request) (todoId, commentId int, err error) {
+	todoId, err = strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return 0, 0, errors.New("can not parse the todo id from the url")
+	}
+	commentId, err = strconv.Atoi(r.PathValue("comment_id"))
+	if err != nil {
+		return 0, 0, errors.New("can not parse the comment id from the url")
+	}
+	return todoId, commentId, nil
+}
+
+//---------------------------------------------------------------------------------
+
+type publicTodoActor struct {
+	UserId       int     `json:"user_id"`
+	Username     string  `json:"username"`
+	FirstName    string  `json:"first_name"`
+	LastName     *string `json:"last_name"`
+	ProfileImage *string `json:"profile_image"`
+}
+
+func publicTodoActorFromRepoModel(a *todo.TodoActor) *publicTodoActor {
+	if a == nil {
+		return nil
+	}
+	return &publicTodoActor{
+		UserId:       a.UserId,
+		Username:     a.Username,
+		FirstName:    a.FirstName,
+		LastName:     a.LastName,
+		ProfileImage: a.ProfileImage,
+	}
+}
+
+type publicTodoActivity struct {
+	Id     int    `json:"id"`
+	TodoId int    `json:"todo_id"`
+	Action string `json:"action"`
+	// the user who did the change, null if the user is deleted
+	Actor     *publicTodoActor                `json:"actor"`
+	Changes   map[string]todo.TodoFieldChange `json:"changes"`
+	CreatedAt time.Time                       `json:"created_at"`
+}
+
+func publicTodoActivityFromRepoModel(a todo.TodoActivity) publicTodoActivity {
+	return publicTodoActivity{
+		Id:        a.Id,
+		TodoId:    a.TodoId,
+		Action:    a.Action.String(),
+		Actor:     publicTodoActorFromRepoModel(a.Actor),
+		Changes:   a.Changes,
+		CreatedAt: a.CreatedAt,
+	}
+}
+
+type publicTodoComment struct {
+	Id     int `json:"id"`
+	TodoId int `json:"todo_id"`
+	// null if the user is deleted
+	Author    *publicTodoActor `json:"author"`
+	Body      string           `json:"body"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+func publicTodoCommentFromRepoModel(c todo.TodoComment) publicTodoComment {
+	return publicTodoComment{
+		Id:        c.Id,
+		TodoId:    c.TodoId,
+		Author:    publicTodoActorFromRepoModel(c.Author),
+		Body:      c.Body,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+	}
+}
@@ -0,0 +1,277 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Nidal-Bakir/go-todo-backend/internal/apperr"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/auth"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/todo"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/utils/mimes"
+)
+
+const (
+	// the max size of one attachment file
+	todoAttachmentSizeLimit int64 = 10 << 20
+	// the file and the overhead of the multipart form (boundaries and headers)
+	todoAttachmentRequestSizeLimit = todoAttachmentSizeLimit + 1<<20
+	// the bigger files are written to temp files while parsing the multipart form
+	todoAttachmentMaxMemory int64 = 1 << 20
+	// the uploads take longer than the ReadTimeout of the server
+	todoAttachmentReadTimeout = 2 * time.Minute
+
+	// this limit is also check on the db level.
+	// see the todo_attachment table migration file(s)
+	todoAttachmentFilenameLengthLimit int = 255
+)
+
+// the content type of the files is detected from their content (http.DetectContentType),
+// and not taken from the clients
+var todoAttachmentAllowedMimes = []string{
+	mimes.Image_jpeg,
+	mimes.Image_png,
+	mimes.Image_gif,
+	mimes.Image_webp,
+	mimes.App_pdf,
+	mimes.App_zip,
+	mimes.Text_plain,
+	mimes.Text_csv,
+	mimes.Audio_mpeg,
+	mimes.Video_mp4,
+	mimes.Video_webm,
+}
+
+func todoAttachmentIndex(todoRepo todo.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		todoId, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, errors.New("can not parse the todo id from the url"))
+			return
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		attachments, err := todoRepo.GetAttachments(ctx, int(userAndSession.UserID), todoId)
+		if err != nil {
+			writeError(ctx, w, r, return400IfApp404IfNoResultErrOr500(err), err)
+			return
+		}
+
+		writeResponse(ctx, w, r, http.StatusOK, publicTodoAttachmentsFromRepoModels(attachments))
+	}
+}
+
+func todoAttachmentShow(todoRepo todo.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		todoId, attachmentId, err := todoIdAndAttachmentIdFromPath(r)
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		attachment, err := todoRepo.GetAttachment(ctx, int(userAndSession.UserID), todoId, attachmentId)
+		if err != nil {
+			writeError(ctx, w, r, return400IfApp404IfNoResultErrOr500(err), err)
+			return
+		}
+
+		writeResponse(ctx, w, r, http.StatusOK, publicTodoAttachmentFromRepoModel(attachment))
+	}
+}
+
+// createTodoAttachment takes the file in the "file" field of a multipart form
+func createTodoAttachment(todoRepo todo.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		todoId, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, errors.New("can not parse the todo id from the url"))
+			return
+		}
+
+		// not supported by all the response writers, the default timeout is used then
+		_ = http.NewResponseController(w).SetReadDeadline(time.Now().Add(todoAttachmentReadTimeout))
+
+		err = r.ParseMultipartForm(todoAttachmentMaxMemory)
+		if err != nil {
+			// the body is limited by the RequestSize middleware
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				writeError(ctx, w, r, http.StatusRequestEntityTooLarge, apperr.ErrTodoAttachmentTooLarge)
+				return
+			}
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+		defer r.MultipartForm.RemoveAll()
+
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, errors.New("the file is required"))
+			return
+		}
+		defer file.Close()
+
+		if header.Size > todoAttachmentSizeLimit {
+			writeError(ctx, w, r, http.StatusRequestEntityTooLarge, apperr.ErrTodoAttachmentTooLarge)
+			return
+		}
+		if header.Size == 0 {
+			writeError(ctx, w, r, http.StatusBadRequest, errors.New("the file is empty"))
+			return
+		}
+
+		filename := strings.TrimSpace(header.Filename)
+		if len(filename) > todoAttachmentFilenameLengthLimit {
+			writeError(ctx, w, r, http.StatusBadRequest, errors.New("too large file name"))
+			return
+		}
+		if len(filename) == 0 {
+			filename = "file"
+		}
+
+		contentType, err := detectTodoAttachmentContentType(file, header)
+		if err != nil {
+			writeError(ctx, w, r, return400IfAppErrOr500(err), err)
+			return
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		res, err := todoRepo.CreateAttachment(
+			ctx,
+			int(userAndSession.UserID),
+			todoId,
+			todo.TodoAttachmentData{
+				Filename:    filename,
+				ContentType: contentType,
+				Size:        header.Size,
+				Content:     file,
+			},
+		)
+		if err != nil {
+			writeError(ctx, w, r, return400IfApp403IfPermissionDenied404IfNoResultErrOr500(err), err)
+			return
+		}
+
+		writeResponse(ctx, w, r, http.StatusCreated, publicTodoAttachmentFromRepoModel(res))
+	}
+}
+
+// detectTodoAttachmentContentType sniffs the content type of the file, and rewinds it
+func detectTodoAttachmentContentType(file multipart.File, header *multipart.FileHeader) (string, error) {
+	// http.DetectContentType considers at most the first 512 bytes
+	buf := make([]byte, 512)
+	n, err := io.ReadFull(file, buf)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	// without the parameters e.g. "text/plain; charset=utf-8"
+	contentType, _, err := mime.ParseMediaType(http.DetectContentType(buf[:n]))
+	if err != nil {
+		return "", apperr.ErrUnsupportedTodoAttachmentType
+	}
+
+	// the text formats can not be told apart by their content e.g. csv
+	if contentType == mimes.Text_plain {
+		declared, _, err := mime.ParseMediaType(header.Header.Get("Content-Type"))
+		if err == nil && strings.HasPrefix(declared, "text/") && slices.Contains(todoAttachmentAllowedMimes, declared) {
+			contentType = declared
+		}
+	}
+
+	if !slices.Contains(todoAttachmentAllowedMimes, contentType) {
+		return "", apperr.ErrUnsupportedTodoAttachmentType
+	}
+	return contentType, nil
+}
+
+func deleteTodoAttachment(todoRepo todo.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		todoId, attachmentId, err := todoIdAndAttachmentIdFromPath(r)
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		err = todoRepo.DeleteAttachment(ctx, int(userAndSession.UserID), todoId, attachmentId)
+		if err != nil {
+			writeError(ctx, w, r, return400IfApp403IfPermissionDenied404IfNoResultErrOr500(err), err)
+			return
+		}
+		apiWriteOperationDoneSuccessfullyJson(ctx, w, r)
+	}
+}
+
+func todoIdAndAttachmentIdFromPath(r *http.Request) (todoId, attachmentId int, err error) {
+	todoId, err = strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return 0, 0, errors.New("can not parse the todo id from the url")
+	}
+	attachmentId, err = strconv.Atoi(r.PathValue("attachment_id"))
+	if err != nil {
+		return 0, 0, errors.New("can not parse the attachment id from the url")
+	}
+	return todoId, attachmentId, nil
+}
+
+//---------------------------------------------------------------------------------
+
+type publicTodoAttachment struct {
+	Id          int       `json:"id"`
+	TodoId      int       `json:"todo_id"`
+	UploadedBy  *int      `json:"uploaded_by"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	// the signed download url, request the attachment again after it expires
+	URL          string    `json:"url"`
+	URLExpiresAt time.Time `json:"url_expires_at"`
+}
+
+func publicTodoAttachmentFromRepoModel(a todo.TodoAttachment) publicTodoAttachment {
+	return publicTodoAttachment{
+		Id:          a.Id,
+		TodoId:      a.TodoId,
+		UploadedBy:  a.UploadedBy,
+		Filename:    a.Filename,
+		ContentType: a.ContentType,
+		Size:        a.Size,
+		CreatedAt:   a.CreatedAt,
+
+		URL:          a.URL,
+		URLExpiresAt: a.URLExpiresAt,
+	}
+}
+
+func publicTodoAttachmentsFromRepoModels(attachments []todo.TodoAttachment) []publicTodoAttachment {
+	publicAttachments := make([]publicTodoAttachment, len(attachments))
+	for i, a := range attachments {
+		publicAttachments[i] = publicTodoAttachmentFromRepoModel(a)
+	}
+	return publicAttachments
+}
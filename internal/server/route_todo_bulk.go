@@ -0,0 +1,124 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/auth"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/todo"
+)
+
+const (
+	todoBulkOperationsLimit  int   = 100
+	todoBulkRequestSizeLimit int64 = 1 << 20
+)
+
+const (
+	todoBulkActionUpdate  = "update"
+	todoBulkActionDelete  = "delete"
+	todoBulkActionRestore = "restore"
+	todoBulkActionMove    = "move"
+)
+
+type todoBulkRequest struct {
+	Operations []todoBulkOperation `json:"operations"`
+}
+
+type todoBulkOperation struct {
+	// chosen by the client to match the results with the operations
+	Ref    string `json:"ref"`
+	Action string `json:"action"`
+	Id     *int   `json:"id"`
+	// the update, the delete and the move actions are conflicts
+	// if the todo has another version now
+	IfVersion *int `json:"if_version"`
+	// the fields of the update action, the same as the form of PATCH /todo/{id}
+	Fields map[string]string `json:"fields"`
+	// the list of the move action, null to remove the todo from its list
+	ListId *int `json:"list_id"`
+}
+
+// todoBulkOperations applies the operations in their order in one transaction.
+// Each operation has its own savepoint, so a failed operation does not undo the
+// others, and has a result with the same index
+func todoBulkOperations(todoRepo todo.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		var req todoBulkRequest
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, errors.New("invalid json body"))
+			return
+		}
+		if len(req.Operations) == 0 {
+			writeError(ctx, w, r, http.StatusBadRequest, errors.New("the operations are required"))
+			return
+		}
+		if len(req.Operations) > todoBulkOperationsLimit {
+			writeError(ctx, w, r, http.StatusBadRequest, fmt.Errorf("too many operations, the max is %d", todoBulkOperationsLimit))
+			return
+		}
+		for _, operation := range req.Operations {
+			if len(operation.Ref) > todoChangeRefLengthLimit {
+				writeError(ctx, w, r, http.StatusBadRequest, errors.New("too large operation ref"))
+				return
+			}
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+		userId := int(userAndSession.UserID)
+
+		results := make([]publicTodoChangeResult, len(req.Operations))
+		err = todoRepo.RunInTransaction(ctx, func(txRepo todo.Repository) error {
+			for i, operation := range req.Operations {
+				results[i] = applyTodoBulkOperation(ctx, txRepo, userId, operation)
+			}
+			return nil
+		})
+		if err != nil {
+			writeError(ctx, w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		writeResponse(ctx, w, r, http.StatusOK, map[string]any{"results": results})
+	}
+}
+
+func applyTodoBulkOperation(ctx context.Context, todoRepo todo.Repository, userId int, operation todoBulkOperation) publicTodoChangeResult {
+	result := publicTodoChangeResult{Ref: operation.Ref, Action: operation.Action, Id: operation.Id}
+
+	if operation.Id == nil {
+		return result.failed(ctx, errors.New("the id is required"))
+	}
+
+	switch operation.Action {
+	case todoBulkActionUpdate:
+		data, err := todoDataFromFields(ctx, operation.Fields)
+		if err != nil {
+			return result.failed(ctx, err)
+		}
+		data.IfVersion = operation.IfVersion
+		res, err := todoRepo.UpdateTodo(ctx, userId, *operation.Id, data)
+		return result.done(ctx, todoRepo, userId, &res, err)
+
+	case todoBulkActionMove:
+		data := todo.TodoData{ListId: operation.ListId, ClearListId: operation.ListId == nil, IfVersion: operation.IfVersion}
+		res, err := todoRepo.UpdateTodo(ctx, userId, *operation.Id, data)
+		return result.done(ctx, todoRepo, userId, &res, err)
+
+	case todoBulkActionDelete:
+		err := todoRepo.DeleteTodo(ctx, userId, *operation.Id, operation.IfVersion)
+		return result.done(ctx, todoRepo, userId, nil, err)
+
+	case todoBulkActionRestore:
+		res, err := todoRepo.RestoreTodo(ctx, userId, *operation.Id)
+		return result.done(ctx, todoRepo, userId, &res, err)
+
+	default:
+		return result.failed(ctx, errors.New("unsupported action, it should be update, delete, restore or move"))
+	}
+}
@@ -0,0 +1,243 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/auth"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/todo"
+)
+
+// this limits are also check on the db level.
+// see the todo_checklist_item table migration file(s)
+const todoChecklistItemTextLengthLimit int = 500
+
+// the max number of the items in one reorder request
+const todoChecklistReorderLimit int = 500
+
+func todoChecklistIndex(todoRepo todo.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		todoId, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, errors.New("can not parse the todo id from the url"))
+			return
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		items, err := todoRepo.GetChecklistItems(ctx, int(userAndSession.UserID), todoId)
+		if err != nil {
+			writeError(ctx, w, r, return400IfApp404IfNoResultErrOr500(err), err)
+			return
+		}
+
+		writeResponse(ctx, w, r, http.StatusOK, publicTodoChecklistItemsFromRepoModels(items))
+	}
+}
+
+func createTodoChecklistItem(todoRepo todo.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		todoId, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, errors.New("can not parse the todo id from the url"))
+			return
+		}
+
+		err = r.ParseForm()
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		itemData, err := extractTodoChecklistItemData(r)
+		if err != nil {
+			writeError(ctx, w, r, return400IfAppErrOr500(err), err)
+			return
+		}
+		if itemData.Text == nil {
+			writeError(ctx, w, r, http.StatusBadRequest, errors.New("the item text is required"))
+			return
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		res, err := todoRepo.CreateChecklistItem(ctx, int(userAndSession.UserID), todoId, itemData)
+		if err != nil {
+			writeError(ctx, w, r, return400IfApp403IfPermissionDenied404IfNoResultErrOr500(err), err)
+			return
+		}
+
+		writeResponse(ctx, w, r, http.StatusCreated, publicTodoChecklistItemFromRepoModel(res))
+	}
+}
+
+// updateTodoChecklistItem is also used to toggle the item with is_done
+func updateTodoChecklistItem(todoRepo todo.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		todoId, itemId, err := todoIdAndChecklistItemIdFromPath(r)
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		err = r.ParseForm()
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		itemData, err := extractTodoChecklistItemData(r)
+		if err != nil {
+			writeError(ctx, w, r, return400IfAppErrOr500(err), err)
+			return
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		res, err := todoRepo.UpdateChecklistItem(ctx, int(userAndSession.UserID), todoId, itemId, itemData)
+		if err != nil {
+			writeError(ctx, w, r, return400IfApp403IfPermissionDenied404IfNoResultErrOr500(err), err)
+			return
+		}
+
+		writeResponse(ctx, w, r, http.StatusOK, publicTodoChecklistItemFromRepoModel(res))
+	}
+}
+
+func extractTodoChecklistItemData(r *http.Request) (todo.TodoChecklistItemData, error) {
+	data := todo.TodoChecklistItemData{}
+
+	text := strings.TrimSpace(r.FormValue("text"))
+	if len(text) > todoChecklistItemTextLengthLimit {
+		return todo.TodoChecklistItemData{}, errors.New("too large item text")
+	}
+	if len(text) != 0 {
+		data.Text = &text
+	}
+
+	if isDoneStr := r.FormValue("is_done"); len(isDoneStr) != 0 {
+		isDone, err := strconv.ParseBool(isDoneStr)
+		if err != nil {
+			return todo.TodoChecklistItemData{}, errors.New("invalid is_done")
+		}
+		data.IsDone = &isDone
+	}
+
+	if positionStr := r.FormValue("position"); len(positionStr) != 0 {
+		position, err := strconv.Atoi(positionStr)
+		if err != nil {
+			return todo.TodoChecklistItemData{}, errors.New("invalid item position")
+		}
+		data.Position = &position
+	}
+
+	return data, nil
+}
+
+// reorderTodoChecklistItems takes the ids of the items in their new order, e.g. item_ids=3,1,2
+func reorderTodoChecklistItems(todoRepo todo.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		todoId, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, errors.New("can not parse the todo id from the url"))
+			return
+		}
+
+		itemIdsStr := strings.Split(r.FormValue("item_ids"), ",")
+		if len(itemIdsStr) > todoChecklistReorderLimit {
+			writeError(ctx, w, r, http.StatusBadRequest, errors.New("too many item ids"))
+			return
+		}
+
+		itemIds := make([]int, len(itemIdsStr))
+		for i, v := range itemIdsStr {
+			itemIds[i], err = strconv.Atoi(strings.TrimSpace(v))
+			if err != nil {
+				writeError(ctx, w, r, http.StatusBadRequest, errors.New("invalid item_ids, it should be a comma separated list of the item ids"))
+				return
+			}
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		items, err := todoRepo.ReorderChecklistItems(ctx, int(userAndSession.UserID), todoId, itemIds)
+		if err != nil {
+			writeError(ctx, w, r, return400IfApp403IfPermissionDenied404IfNoResultErrOr500(err), err)
+			return
+		}
+
+		writeResponse(ctx, w, r, http.StatusOK, publicTodoChecklistItemsFromRepoModels(items))
+	}
+}
+
+func deleteTodoChecklistItem(todoRepo todo.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		todoId, itemId, err := todoIdAndChecklistItemIdFromPath(r)
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		err = todoRepo.DeleteChecklistItem(ctx, int(userAndSession.UserID), todoId, itemId)
+		if err != nil {
+			writeError(ctx, w, r, return400IfApp403IfPermissionDenied404IfNoResultErrOr500(err), err)
+			return
+		}
+		apiWriteOperationDoneSuccessfullyJson(ctx, w, r)
+	}
+}
+
+func todoIdAndChecklistItemIdFromPath(r *http.Request) (todoId, itemId int, err error) {
+	todoId, err = strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return 0, 0, errors.New("can not parse the todo id from the url")
+	}
+	itemId, err = strconv.Atoi(r.PathValue("item_id"))
+	if err != nil {
+		return 0, 0, errors.New("can not parse the item id from the url")
+	}
+	return todoId, itemId, nil
+}
+
+type publicTodoChecklistItem struct {
+	Id        int       `json:"id"`
+	Text      string    `json:"text"`
+	IsDone    bool      `json:"is_done"`
+	Position  int       `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func publicTodoChecklistItemFromRepoModel(i todo.TodoChecklistItem) publicTodoChecklistItem {
+	return publicTodoChecklistItem{
+		Id:        i.Id,
+		Text:      i.Text,
+		IsDone:    i.IsDone,
+		Position:  i.Position,
+		CreatedAt: i.CreatedAt,
+		UpdatedAt: i.UpdatedAt,
+	}
+}
+
+func publicTodoChecklistItemsFromRepoModels(items []todo.TodoChecklistItem) []publicTodoChecklistItem {
+	publicItems := make([]publicTodoChecklistItem, len(items))
+	for i, v := range items {
+		publicItems[i] = publicTodoChecklistItemFromRepoModel(v)
+	}
+	return publicItems
+}
@@ -0,0 +1,530 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Nidal-Bakir/go-todo-backend/internal/apperr"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/auth"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/todo"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/utils/emailvalidator"
+)
+
+// this limits are also check on the db level.
+// see the todo_list table migration file(s)
+const todoListNameLengthLimit int = 100
+
+var todoListColorRegex = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+func todoListIndex(todoRepo todo.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		includeArchived, _ := strconv.ParseBool(r.FormValue("include_archived"))
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		lists, err := todoRepo.GetLists(ctx, int(userAndSession.UserID), includeArchived)
+		if err != nil {
+			writeError(ctx, w, r, return400IfAppErrOr500(err), err)
+			return
+		}
+
+		publicLists := make([]publicTodoList, len(lists))
+		for i, l := range lists {
+			publicLists[i] = publicTodoListFromRepoModel(l)
+		}
+
+		writeResponse(ctx, w, r, http.StatusOK, publicLists)
+	}
+}
+
+func todoListShow(todoRepo todo.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		listId, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, errors.New("can not parse the list id from the url"))
+			return
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		res, err := todoRepo.GetList(ctx, int(userAndSession.UserID), listId)
+		if err != nil {
+			writeError(ctx, w, r, return400IfApp404IfNoResultErrOr500(err), err)
+			return
+		}
+
+		writeResponse(ctx, w, r, http.StatusOK, publicTodoListFromRepoModel(res))
+	}
+}
+
+func createTodoList(todoRepo todo.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		err := r.ParseForm()
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		listData, err := extractTodoListData(r)
+		if err != nil {
+			writeError(ctx, w, r, return400IfAppErrOr500(err), err)
+			return
+		}
+		if listData.Name == nil {
+			writeError(ctx, w, r, http.StatusBadRequest, errors.New("the list name is required"))
+			return
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		res, err := todoRepo.CreateList(ctx, int(userAndSession.UserID), listData)
+		if err != nil {
+			writeError(ctx, w, r, return400IfAppErrOr500(err), err)
+			return
+		}
+
+		writeResponse(ctx, w, r, http.StatusCreated, publicTodoListFromRepoModel(res))
+	}
+}
+
+func updateTodoList(todoRepo todo.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		listId, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, errors.New("can not parse the list id from the url"))
+			return
+		}
+
+		err = r.ParseForm()
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		listData, err := extractTodoListData(r)
+		if err != nil {
+			writeError(ctx, w, r, return400IfAppErrOr500(err), err)
+			return
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		res, err := todoRepo.UpdateList(ctx, int(userAndSession.UserID), listId, listData)
+		if err != nil {
+			writeError(ctx, w, r, return400IfApp403IfPermissionDenied404IfNoResultErrOr500(err), err)
+			return
+		}
+
+		writeResponse(ctx, w, r, http.StatusOK, publicTodoListFromRepoModel(res))
+	}
+}
+
+func extractTodoListData(r *http.Request) (todo.TodoListData, error) {
+	data := todo.TodoListData{}
+
+	name := r.FormValue("name")
+	if len(name) > todoListNameLengthLimit {
+		return todo.TodoListData{}, errors.New("too large list name")
+	}
+	if len(name) != 0 {
+		data.Name = &name
+	}
+
+	// the color is removed by sending the field with an empty value
+	color := r.FormValue("color")
+	if len(color) != 0 {
+		if !todoListColorRegex.MatchString(color) {
+			return todo.TodoListData{}, errors.New("invalid list color, it should be a hex color e.g. #1E90FF")
+		}
+		data.Color = &color
+	} else if r.Form.Has("color") {
+		data.ClearColor = true
+	}
+
+	if positionStr := r.FormValue("position"); len(positionStr) != 0 {
+		position, err := strconv.Atoi(positionStr)
+		if err != nil {
+			return todo.TodoListData{}, errors.New("invalid list position")
+		}
+		data.Position = &position
+	}
+
+	if isArchivedStr := r.FormValue("is_archived"); len(isArchivedStr) != 0 {
+		isArchived, err := strconv.ParseBool(isArchivedStr)
+		if err != nil {
+			return todo.TodoListData{}, errors.New("invalid is_archived")
+		}
+		data.IsArchived = &isArchived
+	}
+
+	return data, nil
+}
+
+func deleteTodoList(todoRepo todo.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		listId, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, errors.New("can not parse the list id from the url"))
+			return
+		}
+
+		// by default the todos of the list are kept without a list
+		action := todo.DeleteListTodosActionMove
+		switch actionStr := r.FormValue("todos_action"); actionStr {
+		case "", string(todo.DeleteListTodosActionMove):
+		case string(todo.DeleteListTodosActionTrash):
+			action = todo.DeleteListTodosActionTrash
+		default:
+			writeError(ctx, w, r, http.StatusBadRequest, errors.New("invalid todos_action, it should be move or trash"))
+			return
+		}
+
+		var moveToListId *int
+		if moveToListIdStr := r.FormValue("move_to_list_id"); len(moveToListIdStr) != 0 {
+			if action != todo.DeleteListTodosActionMove {
+				writeError(ctx, w, r, http.StatusBadRequest, errors.New("the move_to_list_id can only be used with the move todos_action"))
+				return
+			}
+			id, err := strconv.Atoi(moveToListIdStr)
+			if err != nil {
+				writeError(ctx, w, r, http.StatusBadRequest, errors.New("invalid move_to_list_id"))
+				return
+			}
+			moveToListId = &id
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		err = todoRepo.DeleteList(ctx, int(userAndSession.UserID), listId, action, moveToListId)
+		if err != nil {
+			writeError(ctx, w, r, return400IfApp403IfPermissionDenied404IfNoResultErrOr500(err), err)
+			return
+		}
+		apiWriteOperationDoneSuccessfullyJson(ctx, w, r)
+	}
+}
+
+//---------------------------------------------------------------------------------
+
+func todoListMembersIndex(todoRepo todo.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		listId, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, errors.New("can not parse the list id from the url"))
+			return
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		members, err := todoRepo.GetListMembers(ctx, int(userAndSession.UserID), listId)
+		if err != nil {
+			writeError(ctx, w, r, return400IfApp404IfNoResultErrOr500(err), err)
+			return
+		}
+
+		publicMembers := make([]publicTodoListMember, len(members))
+		for i, m := range members {
+			publicMembers[i] = publicTodoListMemberFromRepoModel(m)
+		}
+
+		writeResponse(ctx, w, r, http.StatusOK, publicMembers)
+	}
+}
+
+func changeTodoListMemberRole(todoRepo todo.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		listId, memberId, err := todoListIdAndMemberIdFromPath(r)
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		role, err := new(todo.TodoListRole).FromString(r.FormValue("role"))
+		if err != nil {
+			writeError(ctx, w, r, return400IfAppErrOr500(err), err)
+			return
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		err = todoRepo.ChangeListMemberRole(ctx, int(userAndSession.UserID), listId, memberId, *role)
+		if err != nil {
+			writeError(ctx, w, r, return400IfApp403IfPermissionDenied404IfNoResultErrOr500(err), err)
+			return
+		}
+		apiWriteOperationDoneSuccessfullyJson(ctx, w, r)
+	}
+}
+
+// removeTodoListMember is also used to leave the list, by sending the id of the logged-in user
+func removeTodoListMember(todoRepo todo.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		listId, memberId, err := todoListIdAndMemberIdFromPath(r)
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		err = todoRepo.RemoveListMember(ctx, int(userAndSession.UserID), listId, memberId)
+		if err != nil {
+			writeError(ctx, w, r, return400IfApp403IfPermissionDenied404IfNoResultErrOr500(err), err)
+			return
+		}
+		apiWriteOperationDoneSuccessfullyJson(ctx, w, r)
+	}
+}
+
+func todoListIdAndMemberIdFromPath(r *http.Request) (listId, memberId int, err error) {
+	listId, err = strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return 0, 0, errors.New("can not parse the list id from the url")
+	}
+	memberId, err = strconv.Atoi(r.PathValue("user_id"))
+	if err != nil {
+		return 0, 0, errors.New("can not parse the member id from the url")
+	}
+	return listId, memberId, nil
+}
+
+//---------------------------------------------------------------------------------
+
+func inviteToTodoList(todoRepo todo.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		listId, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, errors.New("can not parse the list id from the url"))
+			return
+		}
+
+		err = r.ParseForm()
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		invitee := todo.TodoListInvitee{}
+		username := strings.TrimSpace(r.FormValue("username"))
+		email := strings.TrimSpace(r.FormValue("email"))
+		switch {
+		case len(username) != 0 && len(email) != 0:
+			writeError(ctx, w, r, http.StatusBadRequest, errors.New("send the username or the email of the invitee, not both"))
+			return
+		case len(username) != 0:
+			invitee.Username = &username
+		case len(email) != 0:
+			if !emailvalidator.IsValidEmail(email) {
+				writeError(ctx, w, r, http.StatusBadRequest, apperr.ErrInvalidEmail)
+				return
+			}
+			invitee.Email = &email
+		default:
+			writeError(ctx, w, r, http.StatusBadRequest, errors.New("the username or the email of the invitee is required"))
+			return
+		}
+
+		role, err := new(todo.TodoListRole).FromString(r.FormValue("role"))
+		if err != nil {
+			writeError(ctx, w, r, return400IfAppErrOr500(err), err)
+			return
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		res, err := todoRepo.InviteToList(ctx, int(userAndSession.UserID), listId, invitee, *role)
+		if err != nil {
+			writeError(ctx, w, r, return400IfApp403IfPermissionDenied404IfNoResultErrOr500(err), err)
+			return
+		}
+
+		writeResponse(ctx, w, r, http.StatusCreated, publicTodoListInvitationFromRepoModel(res))
+	}
+}
+
+func todoListInvitationsIndex(todoRepo todo.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		listId, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, errors.New("can not parse the list id from the url"))
+			return
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		invitations, err := todoRepo.GetListInvitations(ctx, int(userAndSession.UserID), listId)
+		if err != nil {
+			writeError(ctx, w, r, return400IfApp403IfPermissionDenied404IfNoResultErrOr500(err), err)
+			return
+		}
+
+		writeResponse(ctx, w, r, http.StatusOK, publicTodoListInvitationsFromRepoModels(invitations))
+	}
+}
+
+func cancelTodoListInvitation(todoRepo todo.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		listId, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, errors.New("can not parse the list id from the url"))
+			return
+		}
+		invitationId, err := strconv.Atoi(r.PathValue("invitation_id"))
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, errors.New("can not parse the invitation id from the url"))
+			return
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		err = todoRepo.CancelListInvitation(ctx, int(userAndSession.UserID), listId, invitationId)
+		if err != nil {
+			writeError(ctx, w, r, return400IfApp403IfPermissionDenied404IfNoResultErrOr500(err), err)
+			return
+		}
+		apiWriteOperationDoneSuccessfullyJson(ctx, w, r)
+	}
+}
+
+func myTodoListInvitationsIndex(todoRepo todo.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		invitations, err := todoRepo.GetMyListInvitations(ctx, int(userAndSession.UserID))
+		if err != nil {
+			writeError(ctx, w, r, return400IfAppErrOr500(err), err)
+			return
+		}
+
+		writeResponse(ctx, w, r, http.StatusOK, publicTodoListInvitationsFromRepoModels(invitations))
+	}
+}
+
+func respondToTodoListInvitation(todoRepo todo.Repository, accept bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		invitationId, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, errors.New("can not parse the invitation id from the url"))
+			return
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		err = todoRepo.RespondToListInvitation(ctx, int(userAndSession.UserID), invitationId, accept)
+		if err != nil {
+			writeError(ctx, w, r, return400IfApp404IfNoResultErrOr500(err), err)
+			return
+		}
+		apiWriteOperationDoneSuccessfullyJson(ctx, w, r)
+	}
+}
+
+//---------------------------------------------------------------------------------
+
+type publicTodoList struct {
+	Id         int       `json:"id"`
+	Name       string    `json:"name"`
+	Color      *string   `json:"color"`
+	Position   int       `json:"position"`
+	IsArchived bool      `json:"is_archived"`
+	Role       string    `json:"role"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func publicTodoListFromRepoModel(l todo.TodoList) publicTodoList {
+	return publicTodoList{
+		Id:         l.Id,
+		Name:       l.Name,
+		Color:      l.Color,
+		Position:   l.Position,
+		IsArchived: l.IsArchived,
+		Role:       l.Role.String(),
+		CreatedAt:  l.CreatedAt,
+		UpdatedAt:  l.UpdatedAt,
+	}
+}
+
+type publicTodoListMember struct {
+	UserId       int       `json:"user_id"`
+	Username     string    `json:"username"`
+	FirstName    string    `json:"first_name"`
+	LastName     *string   `json:"last_name"`
+	ProfileImage *string   `json:"profile_image"`
+	Role         string    `json:"role"`
+	JoinedAt     time.Time `json:"joined_at"`
+}
+
+func publicTodoListMemberFromRepoModel(m todo.TodoListMember) publicTodoListMember {
+	return publicTodoListMember{
+		UserId:       m.UserId,
+		Username:     m.Username,
+		FirstName:    m.FirstName,
+		LastName:     m.LastName,
+		ProfileImage: m.ProfileImage,
+		Role:         m.Role.String(),
+		JoinedAt:     m.JoinedAt,
+	}
+}
+
+type publicTodoListInvitation struct {
+	Id              int       `json:"id"`
+	ListId          int       `json:"list_id"`
+	ListName        string    `json:"list_name"`
+	InviterUsername string    `json:"inviter_username"`
+	InviteeUsername string    `json:"invitee_username"`
+	Role            string    `json:"role"`
+	Status          string    `json:"status"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+func publicTodoListInvitationFromRepoModel(i todo.TodoListInvitation) publicTodoListInvitation {
+	return publicTodoListInvitation{
+		Id:              i.Id,
+		ListId:          i.ListId,
+		ListName:        i.ListName,
+		InviterUsername: i.InviterUsername,
+		InviteeUsername: i.InviteeUsername,
+		Role:            i.Role.String(),
+		Status:          string(i.Status),
+		CreatedAt:       i.CreatedAt,
+	}
+}
+
+func publicTodoListInvitationsFromRepoModels(invitations []todo.TodoListInvitation) []publicTodoListInvitation {
+	publicInvitations := make([]publicTodoListInvitation, len(invitations))
+	for i, v := range invitations {
+		publicInvitations[i] = publicTodoListInvitationFromRepoModel(v)
+	}
+	return publicInvitations
+}
@@ -0,0 +1,280 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Nidal-Bakir/go-todo-backend/internal/apperr"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/auth"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/todo"
+)
+
+const (
+	// the max number of the changed todos in one sync response, the clients
+	// call the sync again with the next token while has_more is true
+	todoSyncPageLimit int = 500
+
+	todoSyncPushChangesLimit     int   = 100
+	todoSyncPushRequestSizeLimit int64 = 1 << 20
+	todoChangeRefLengthLimit     int   = 100
+)
+
+const (
+	todoSyncChangeActionCreate = "create"
+	todoSyncChangeActionUpdate = "update"
+	todoSyncChangeActionDelete = "delete"
+
+	todoChangeStatusApplied  = "applied"
+	todoChangeStatusConflict = "conflict"
+	todoChangeStatusFailed   = "failed"
+)
+
+// todoSyncPull returns the todos that are changed since the token in the "since"
+// query param, without it all the todos are returned (the first sync)
+func todoSyncPull(todoRepo todo.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		since, err := decodeTodoSyncToken(r.URL.Query().Get("since"))
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		changes, err := todoRepo.GetTodoChanges(ctx, int(userAndSession.UserID), since, todoSyncPageLimit)
+		if err != nil {
+			writeError(ctx, w, r, return400IfAppErrOr500(err), err)
+			return
+		}
+
+		res, err := publicTodoChangesFromRepoModel(changes)
+		if err != nil {
+			writeError(ctx, w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		writeResponse(ctx, w, r, http.StatusOK, res)
+	}
+}
+
+func encodeTodoSyncToken(token todo.TodoSyncToken) (string, error) {
+	b, err := json.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func decodeTodoSyncToken(tokenStr string) (*todo.TodoSyncToken, error) {
+	if len(tokenStr) == 0 {
+		return nil, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(tokenStr)
+	if err != nil {
+		return nil, apperr.ErrInvalidTodoSyncToken
+	}
+
+	token := new(todo.TodoSyncToken)
+	if err := json.Unmarshal(b, token); err != nil {
+		return nil, apperr.ErrInvalidTodoSyncToken
+	}
+	return token, nil
+}
+
+//---------------------------------------------------------------------------------
+
+type todoSyncPushRequest struct {
+	Changes []todoSyncChange `json:"changes"`
+}
+
+type todoSyncChange struct {
+	// chosen by the client to match the results with the changes
+	Ref    string `json:"ref"`
+	Action string `json:"action"`
+	// the todo of the update and the delete actions
+	Id *int `json:"id"`
+	// the version of the todo that the client changed, the update and the
+	// delete actions are conflicts if the todo has another version now
+	IfVersion *int `json:"if_version"`
+	// the same fields as the forms of POST /todo and PATCH /todo/{id}
+	Fields map[string]string `json:"fields"`
+}
+
+// todoSyncPush applies the changes that the clients made offline, in their order.
+// Each change is applied on its own, and has a result with the same index
+func todoSyncPush(todoRepo todo.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		var req todoSyncPushRequest
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, errors.New("invalid json body"))
+			return
+		}
+		if len(req.Changes) == 0 {
+			writeError(ctx, w, r, http.StatusBadRequest, errors.New("the changes are required"))
+			return
+		}
+		if len(req.Changes) > todoSyncPushChangesLimit {
+			writeError(ctx, w, r, http.StatusBadRequest, fmt.Errorf("too many changes, the max is %d", todoSyncPushChangesLimit))
+			return
+		}
+		for _, change := range req.Changes {
+			if len(change.Ref) > todoChangeRefLengthLimit {
+				writeError(ctx, w, r, http.StatusBadRequest, errors.New("too large change ref"))
+				return
+			}
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+		userId := int(userAndSession.UserID)
+
+		results := make([]publicTodoChangeResult, len(req.Changes))
+		for i, change := range req.Changes {
+			results[i] = applyTodoSyncChange(ctx, todoRepo, userId, change)
+		}
+
+		writeResponse(ctx, w, r, http.StatusOK, map[string]any{"results": results})
+	}
+}
+
+func applyTodoSyncChange(ctx context.Context, todoRepo todo.Repository, userId int, change todoSyncChange) publicTodoChangeResult {
+	result := publicTodoChangeResult{Ref: change.Ref, Action: change.Action, Id: change.Id}
+
+	if change.Action != todoSyncChangeActionCreate && change.Id == nil {
+		return result.failed(ctx, errors.New("the id is required"))
+	}
+
+	switch change.Action {
+	case todoSyncChangeActionCreate:
+		data, err := todoDataFromFields(ctx, change.Fields)
+		if err != nil {
+			return result.failed(ctx, err)
+		}
+		res, err := todoRepo.CreateTodo(ctx, userId, data)
+		return result.done(ctx, todoRepo, userId, &res, err)
+
+	case todoSyncChangeActionUpdate:
+		data, err := todoDataFromFields(ctx, change.Fields)
+		if err != nil {
+			return result.failed(ctx, err)
+		}
+		data.IfVersion = change.IfVersion
+		res, err := todoRepo.UpdateTodo(ctx, userId, *change.Id, data)
+		return result.done(ctx, todoRepo, userId, &res, err)
+
+	case todoSyncChangeActionDelete:
+		err := todoRepo.DeleteTodo(ctx, userId, *change.Id, change.IfVersion)
+		return result.done(ctx, todoRepo, userId, nil, err)
+
+	default:
+		return result.failed(ctx, errors.New("unsupported action, it should be create, update or delete"))
+	}
+}
+
+//---------------------------------------------------------------------------------
+
+type publicTodoTombstone struct {
+	Id        int       `json:"id"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+type publicTodoChanges struct {
+	// the created and the updated todos
+	Todos []publicTodoItem `json:"todos"`
+	// the deleted todos, and the todos that the user can not see anymore
+	Deleted []publicTodoTombstone `json:"deleted"`
+	// drop the synced todos before applying the changes, they start from the beginning
+	Reset bool `json:"reset"`
+	// call the sync again with the next token to get the rest of the changes
+	HasMore bool `json:"has_more"`
+	// send it as the "since" of the next sync
+	Next string `json:"next"`
+}
+
+func publicTodoChangesFromRepoModel(changes todo.TodoChanges) (publicTodoChanges, error) {
+	next, err := encodeTodoSyncToken(changes.Next)
+	if err != nil {
+		return publicTodoChanges{}, err
+	}
+
+	todos := make([]publicTodoItem, len(changes.Todos))
+	for i, v := range changes.Todos {
+		todos[i] = publicTodoItemFromRepoModel(v)
+	}
+
+	deleted := make([]publicTodoTombstone, len(changes.Deleted))
+	for i, v := range changes.Deleted {
+		deleted[i] = publicTodoTombstone{Id: v.Id, DeletedAt: v.DeletedAt}
+	}
+
+	return publicTodoChanges{
+		Todos:   todos,
+		Deleted: deleted,
+		Reset:   changes.Reset,
+		HasMore: changes.HasMore,
+		Next:    next,
+	}, nil
+}
+
+// publicTodoChangeResult is the result of one of the changes of the sync push and the bulk operations
+type publicTodoChangeResult struct {
+	Ref    string `json:"ref"`
+	Action string `json:"action"`
+	// the id of the created todo for the create action
+	Id *int `json:"id"`
+	// applied, conflict or failed
+	Status string `json:"status"`
+	// the todo after the change, or the current todo of a conflict
+	Todo  *publicTodoItem `json:"todo,omitempty"`
+	Error any             `json:"error,omitempty"`
+}
+
+// done sets the status of the change from its err, res is nil
+// for the changes that do not return the todo
+func (result publicTodoChangeResult) done(ctx context.Context, todoRepo todo.Repository, userId int, res *todo.TodoItem, err error) publicTodoChangeResult {
+	if err != nil {
+		if errors.Is(err, apperr.ErrTodoVersionMismatch) && result.Id != nil {
+			// the current todo, so the client can resolve the conflict
+			result.Status = todoChangeStatusConflict
+			if current, err := todoRepo.GetTodo(ctx, userId, *result.Id); err == nil {
+				publicTodo := publicTodoItemFromRepoModel(current)
+				result.Todo = &publicTodo
+			}
+			return result
+		}
+		if !apperr.IsAppErr(err) {
+			// logged by the repository
+			err = apperr.ErrUnexpectedErrorOccurred
+		}
+		return result.failed(ctx, err)
+	}
+
+	result.Status = todoChangeStatusApplied
+	if res != nil {
+		publicTodo := publicTodoItemFromRepoModel(*res)
+		result.Id = &publicTodo.Id
+		result.Todo = &publicTodo
+	}
+	return result
+}
+
+func (result publicTodoChangeResult) failed(ctx context.Context, err error) publicTodoChangeResult {
+	result.Status = todoChangeStatusFailed
+	if appErr := apperr.UnwrapAppErr(err); appErr != nil {
+		appErr.SetTranslation(ctx)
+		result.Error = appErr
+	} else {
+		result.Error = map[string]string{"error": err.Error()}
+	}
+	return result
+}
@@ -0,0 +1,250 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/auth"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/todo"
+)
+
+// this limits are also check on the db level.
+// see the todo_tag table migration file(s)
+const todoTagNameLengthLimit int = 50
+
+// the max number of the tag ids in one request, on a todo or in the filter
+const todoTagIdsLimit int = 50
+
+func todoTagIndex(todoRepo todo.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		tags, err := todoRepo.GetTags(ctx, int(userAndSession.UserID))
+		if err != nil {
+			writeError(ctx, w, r, return400IfAppErrOr500(err), err)
+			return
+		}
+
+		writeResponse(ctx, w, r, http.StatusOK, publicTodoTagsFromRepoModels(tags))
+	}
+}
+
+func createTodoTag(todoRepo todo.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		err := r.ParseForm()
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		tagData, err := extractTodoTagData(r)
+		if err != nil {
+			writeError(ctx, w, r, return400IfAppErrOr500(err), err)
+			return
+		}
+		if tagData.Name == nil {
+			writeError(ctx, w, r, http.StatusBadRequest, errors.New("the tag name is required"))
+			return
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		res, err := todoRepo.CreateTag(ctx, int(userAndSession.UserID), tagData)
+		if err != nil {
+			writeError(ctx, w, r, return400IfAppErrOr500(err), err)
+			return
+		}
+
+		writeResponse(ctx, w, r, http.StatusCreated, publicTodoTagFromRepoModel(res))
+	}
+}
+
+func updateTodoTag(todoRepo todo.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		tagId, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, errors.New("can not parse the tag id from the url"))
+			return
+		}
+
+		err = r.ParseForm()
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		tagData, err := extractTodoTagData(r)
+		if err != nil {
+			writeError(ctx, w, r, return400IfAppErrOr500(err), err)
+			return
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		res, err := todoRepo.UpdateTag(ctx, int(userAndSession.UserID), tagId, tagData)
+		if err != nil {
+			writeError(ctx, w, r, return400IfApp404IfNoResultErrOr500(err), err)
+			return
+		}
+
+		writeResponse(ctx, w, r, http.StatusOK, publicTodoTagFromRepoModel(res))
+	}
+}
+
+func extractTodoTagData(r *http.Request) (todo.TodoTagData, error) {
+	data := todo.TodoTagData{}
+
+	name := strings.TrimSpace(r.FormValue("name"))
+	if len(name) > todoTagNameLengthLimit {
+		return todo.TodoTagData{}, errors.New("too large tag name")
+	}
+	if len(name) != 0 {
+		data.Name = &name
+	}
+
+	// the color is removed by sending the field with an empty value
+	color := r.FormValue("color")
+	if len(color) != 0 {
+		if !todoListColorRegex.MatchString(color) {
+			return todo.TodoTagData{}, errors.New("invalid tag color, it should be a hex color e.g. #1E90FF")
+		}
+		data.Color = &color
+	} else if r.Form.Has("color") {
+		data.ClearColor = true
+	}
+
+	return data, nil
+}
+
+func deleteTodoTag(todoRepo todo.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		tagId, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, errors.New("can not parse the tag id from the url"))
+			return
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		err = todoRepo.DeleteTag(ctx, int(userAndSession.UserID), tagId)
+		if err != nil {
+			writeError(ctx, w, r, return400IfApp404IfNoResultErrOr500(err), err)
+			return
+		}
+		apiWriteOperationDoneSuccessfullyJson(ctx, w, r)
+	}
+}
+
+//---------------------------------------------------------------------------------
+
+func attachTodoTag(todoRepo todo.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		todoId, tagId, err := todoIdAndTagIdFromPath(r)
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		err = todoRepo.AttachTagToTodo(ctx, int(userAndSession.UserID), todoId, tagId)
+		if err != nil {
+			writeError(ctx, w, r, return400IfApp404IfNoResultErrOr500(err), err)
+			return
+		}
+		apiWriteOperationDoneSuccessfullyJson(ctx, w, r)
+	}
+}
+
+func detachTodoTag(todoRepo todo.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		todoId, tagId, err := todoIdAndTagIdFromPath(r)
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		err = todoRepo.DetachTagFromTodo(ctx, int(userAndSession.UserID), todoId, tagId)
+		if err != nil {
+			writeError(ctx, w, r, return400IfApp404IfNoResultErrOr500(err), err)
+			return
+		}
+		apiWriteOperationDoneSuccessfullyJson(ctx, w, r)
+	}
+}
+
+func todoIdAndTagIdFromPath(r *http.Request) (todoId, tagId int, err error) {
+	todoId, err = strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return 0, 0, errors.New("can not parse the todo id from the url")
+	}
+	tagId, err = strconv.Atoi(r.PathValue("tag_id"))
+	if err != nil {
+		return 0, 0, errors.New("can not parse the tag id from the url")
+	}
+	return todoId, tagId, nil
+}
+
+// parseTodoTagIds accepts comma separated ids, in one or more values e.g. tag=1,2&tag=3
+func parseTodoTagIds(values []string, name string) ([]int, error) {
+	var tagIds []int
+	for _, value := range values {
+		for _, idStr := range strings.Split(value, ",") {
+			id, err := strconv.Atoi(strings.TrimSpace(idStr))
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s, it should be a comma separated list of the tag ids", name)
+			}
+			tagIds = append(tagIds, id)
+		}
+	}
+	if len(tagIds) > todoTagIdsLimit {
+		return nil, fmt.Errorf("too many tag ids in %s", name)
+	}
+	return tagIds, nil
+}
+
+//---------------------------------------------------------------------------------
+
+type publicTodoTag struct {
+	Id        int       `json:"id"`
+	Name      string    `json:"name"`
+	Color     *string   `json:"color"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func publicTodoTagFromRepoModel(t todo.TodoTag) publicTodoTag {
+	return publicTodoTag{
+		Id:        t.Id,
+		Name:      t.Name,
+		Color:     t.Color,
+		CreatedAt: t.CreatedAt,
+		UpdatedAt: t.UpdatedAt,
+	}
+}
+
+func publicTodoTagsFromRepoModels(tags []todo.TodoTag) []publicTodoTag {
+	publicTags := make([]publicTodoTag, len(tags))
+	for i, t := range tags {
+		publicTags[i] = publicTodoTagFromRepoModel(t)
+	}
+	return publicTags
+}
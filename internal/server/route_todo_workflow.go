@@ -0,0 +1,225 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/auth"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/todo"
+)
+
+// the max number of the statuses in one reorder request
+const todoWorkflowReorderLimit int = 100
+
+func todoWorkflowIndex(todoRepo todo.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		workflow, err := todoRepo.GetWorkflow(ctx, int(userAndSession.UserID))
+		if err != nil {
+			writeError(ctx, w, r, return400IfAppErrOr500(err), err)
+			return
+		}
+
+		writeResponse(ctx, w, r, http.StatusOK, publicTodoWorkflowFromRepoModel(workflow))
+	}
+}
+
+func createTodoWorkflowStatus(todoRepo todo.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		err := r.ParseForm()
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		statusData, err := extractTodoWorkflowStatusData(r)
+		if err != nil {
+			writeError(ctx, w, r, return400IfAppErrOr500(err), err)
+			return
+		}
+		if statusData.Name == nil {
+			writeError(ctx, w, r, http.StatusBadRequest, errors.New("the status name is required"))
+			return
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		res, err := todoRepo.CreateWorkflowStatus(ctx, int(userAndSession.UserID), statusData)
+		if err != nil {
+			writeError(ctx, w, r, return400IfAppErrOr500(err), err)
+			return
+		}
+
+		writeResponse(ctx, w, r, http.StatusCreated, publicTodoWorkflowStatusFromRepoModel(res))
+	}
+}
+
+func updateTodoWorkflowStatus(todoRepo todo.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		statusId, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, errors.New("can not parse the status id from the url"))
+			return
+		}
+
+		err = r.ParseForm()
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		statusData, err := extractTodoWorkflowStatusData(r)
+		if err != nil {
+			writeError(ctx, w, r, return400IfAppErrOr500(err), err)
+			return
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		res, err := todoRepo.UpdateWorkflowStatus(ctx, int(userAndSession.UserID), statusId, statusData)
+		if err != nil {
+			writeError(ctx, w, r, return400IfApp404IfNoResultErrOr500(err), err)
+			return
+		}
+
+		writeResponse(ctx, w, r, http.StatusOK, publicTodoWorkflowStatusFromRepoModel(res))
+	}
+}
+
+func extractTodoWorkflowStatusData(r *http.Request) (todo.TodoWorkflowStatusData, error) {
+	data := todo.TodoWorkflowStatusData{}
+
+	// the same limit of the status of the todos
+	name := strings.TrimSpace(r.FormValue("name"))
+	if len(name) > todoStatusLengthLimit {
+		return todo.TodoWorkflowStatusData{}, errors.New("too large status name")
+	}
+	if len(name) != 0 {
+		data.Name = &name
+	}
+
+	if positionStr := r.FormValue("position"); len(positionStr) != 0 {
+		position, err := strconv.Atoi(positionStr)
+		if err != nil {
+			return todo.TodoWorkflowStatusData{}, errors.New("invalid status position")
+		}
+		data.Position = &position
+	}
+
+	// the completed flag can only be moved to a status, and not removed from it
+	if isCompletedStr := r.FormValue("is_completed"); len(isCompletedStr) != 0 {
+		isCompleted, err := strconv.ParseBool(isCompletedStr)
+		if err != nil {
+			return todo.TodoWorkflowStatusData{}, errors.New("invalid is_completed")
+		}
+		data.IsCompleted = isCompleted
+	}
+
+	return data, nil
+}
+
+// reorderTodoWorkflowStatuses takes the ids of the statuses in their new order, e.g. status_ids=3,1,2
+func reorderTodoWorkflowStatuses(todoRepo todo.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		statusIdsStr := strings.Split(r.FormValue("status_ids"), ",")
+		if len(statusIdsStr) > todoWorkflowReorderLimit {
+			writeError(ctx, w, r, http.StatusBadRequest, errors.New("too many status ids"))
+			return
+		}
+
+		statusIds := make([]int, len(statusIdsStr))
+		for i, v := range statusIdsStr {
+			var err error
+			statusIds[i], err = strconv.Atoi(strings.TrimSpace(v))
+			if err != nil {
+				writeError(ctx, w, r, http.StatusBadRequest, errors.New("invalid status_ids, it should be a comma separated list of the status ids"))
+				return
+			}
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		workflow, err := todoRepo.ReorderWorkflowStatuses(ctx, int(userAndSession.UserID), statusIds)
+		if err != nil {
+			writeError(ctx, w, r, return400IfAppErrOr500(err), err)
+			return
+		}
+
+		writeResponse(ctx, w, r, http.StatusOK, publicTodoWorkflowFromRepoModel(workflow))
+	}
+}
+
+// deleteTodoWorkflowStatus moves the todos in the deleted status to move_to_status_id,
+// or to the first status of the workflow
+func deleteTodoWorkflowStatus(todoRepo todo.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		statusId, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			writeError(ctx, w, r, http.StatusBadRequest, errors.New("can not parse the status id from the url"))
+			return
+		}
+
+		var moveToStatusId *int
+		if moveToStr := r.FormValue("move_to_status_id"); len(moveToStr) != 0 {
+			moveTo, err := strconv.Atoi(moveToStr)
+			if err != nil {
+				writeError(ctx, w, r, http.StatusBadRequest, errors.New("invalid move_to_status_id"))
+				return
+			}
+			moveToStatusId = &moveTo
+		}
+
+		userAndSession := auth.MustUserAndSessionFromContext(ctx)
+
+		err = todoRepo.DeleteWorkflowStatus(ctx, int(userAndSession.UserID), statusId, moveToStatusId)
+		if err != nil {
+			writeError(ctx, w, r, return400IfApp404IfNoResultErrOr500(err), err)
+			return
+		}
+		apiWriteOperationDoneSuccessfullyJson(ctx, w, r)
+	}
+}
+
+//---------------------------------------------------------------------------------
+
+type publicTodoWorkflowStatus struct {
+	Id          int       `json:"id"`
+	Name        string    `json:"name"`
+	Position    int       `json:"position"`
+	IsCompleted bool      `json:"is_completed"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func publicTodoWorkflowStatusFromRepoModel(s todo.TodoWorkflowStatus) publicTodoWorkflowStatus {
+	return publicTodoWorkflowStatus{
+		Id:          s.Id,
+		Name:        s.Name.String(),
+		Position:    s.Position,
+		IsCompleted: s.IsCompleted,
+		CreatedAt:   s.CreatedAt,
+		UpdatedAt:   s.UpdatedAt,
+	}
+}
+
+func publicTodoWorkflowFromRepoModel(workflow todo.TodoWorkflow) []publicTodoWorkflowStatus {
+	publicStatuses := make([]publicTodoWorkflowStatus, len(workflow))
+	for i, s := range workflow {
+		publicStatuses[i] = publicTodoWorkflowStatusFromRepoModel(s)
+	}
+	return publicStatuses
+}
@@ -11,6 +11,8 @@ import (
 	"github.com/Nidal-Bakir/go-todo-backend/internal/appenv"
 	"github.com/Nidal-Bakir/go-todo-backend/internal/apperr"
 	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/auth"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/auth/oauth/facebook"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/auth/oauth/genericoidc"
 	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/auth/oauth/google"
 	oauth "github.com/Nidal-Bakir/go-todo-backend/internal/feat/auth/oauth/utils"
 	"github.com/Nidal-Bakir/go-todo-backend/internal/middleware"
@@ -72,12 +74,28 @@ func oauthlogin(_ auth.Repository) http.HandlerFunc {
 		setOauthStateAndVerifierCookies(w, state, verifier)
 
 		var redirectUrl string
-		provider.Fold(oauth.OauthProviderFoldActions{
+		err := provider.Fold(oauth.OauthProviderFoldActions{
 			OnGoogle: func() error {
 				redirectUrl = google.AuthCodeURL(ctx, state, verifier)
 				return nil
 			},
+			OnFacebook: func() error {
+				redirectUrl = facebook.AuthCodeURL(ctx, state, verifier)
+				return nil
+			},
+			OnGenericOidc: func() error {
+				p, ok := genericoidc.GetProvider(provider.String())
+				if !ok {
+					return errors.New("the oidc provider is not registered")
+				}
+				redirectUrl = p.AuthCodeURL(ctx, state, verifier)
+				return nil
+			},
 		})
+		if err != nil {
+			writeError(ctx, w, r, http.StatusInternalServerError, err)
+			return
+		}
 		http.Redirect(w, r, redirectUrl, http.StatusFound)
 	}
 }
@@ -164,7 +182,7 @@ func oauthloginCallback(authRepo auth.Repository) http.HandlerFunc {
 
 		installation := auth.MustInstallationFromContext(ctx)
 		requestIpAddres := tracker.MustReqIPFromContext(ctx)
-		user, token, err := authRepo.LoginOrCreateUserWithOidc(
+		user, tokens, mfaChallenge, err := authRepo.LoginOrCreateUserWithOidc(
 			ctx,
 			requestIpAddres,
 			installation,
@@ -179,7 +197,16 @@ func oauthloginCallback(authRepo auth.Repository) http.HandlerFunc {
 			return
 		}
 
-		setAuthorizationCookie(w, token)
+		// no session yet, the frontend should complete the login using /auth/mfa/verify
+		if mfaChallenge != nil {
+			queryParams := url.Values{}
+			queryParams.Add("mfa_challenge_token", mfaChallenge.Token.String())
+			queryParams.Add("mfa_enrollment_required", strconv.FormatBool(mfaChallenge.EnrollmentRequired))
+			http.Redirect(w, r, "/"+"?"+queryParams.Encode(), http.StatusFound)
+			return
+		}
+
+		setAuthorizationCookie(w, tokens)
 
 		queryParams := url.Values{}
 		queryParams.Add("user_first_name", user.FirstName)
@@ -187,7 +214,7 @@ func oauthloginCallback(authRepo auth.Repository) http.HandlerFunc {
 		queryParams.Add("username", user.Username)
 		queryParams.Add("profile_image", user.ProfileImage.String)
 		queryParams.Add("id", strconv.Itoa(int(user.ID)))
-		queryParams.Add("token", token)
+		queryParams.Add("token", tokens.AccessToken) // the refresh token is only sent in its cookie
 		redirectURL := "/" + "?" + queryParams.Encode()
 
 		http.Redirect(w, r, redirectURL, http.StatusFound)
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/Nidal-Bakir/go-todo-backend/internal/appenv"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/blobstore"
 	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/auth"
 	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/settings"
 	"github.com/Nidal-Bakir/go-todo-backend/internal/middleware"
@@ -23,6 +24,11 @@ func (s *Server) RegisterRoutes(ctx context.Context) http.Handler {
 	mux.Handle("/api/", http.StripPrefix("/api", apiRouter(ctx, s, authRepo, settingsRepo)))
 	mux.Handle("/", webRouter(ctx, authRepo))
 
+	// the signed download urls of the local blob store, see BLOB_LOCAL_BASE_URL
+	if localBlobStore, ok := s.blobStore.(*blobstore.LocalStore); ok {
+		mux.HandleFunc("GET /blobs/{key...}", blobDownload(localBlobStore))
+	}
+
 	rateLimitGlobal := middleware.RateLimiter(
 		func(r *http.Request) (string, error) {
 			// since we are using the RealIp() middleware
@@ -74,7 +80,8 @@ func corsMiddleware(next http.Handler) http.HandlerFunc {
 		Debug:            appenv.IsLocal(),
 		AllowedOrigins:   FrontendDomains,
 		AllowedMethods:   []string{"OPTIONS", "HEAD", "GET", "POST", "DELETE", "PUT", "PATCH"},
-		AllowedHeaders:   []string{"Content-Type", "Authorization", "Accept", "Accept-Language", "A-Client-API-Token"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization", "Accept", "Accept-Language", "A-Client-API-Token", "If-Match", "If-None-Match"},
+		ExposedHeaders:   []string{"ETag"},
 		AllowCredentials: true,
 		MaxAge:           10, // 10 sec
 	}
@@ -100,6 +107,8 @@ func v1Router(ctx context.Context, s *Server, authRepo auth.Repository, settings
 
 	registerTodoHandler(ctx, mux, s, authRepo)
 
+	registerAdminHandler(ctx, mux, s, authRepo)
+
 	if appenv.IsStagOrLocal() {
 		mux.Handle("/dev-tools/", http.StripPrefix("/dev-tools", devToolsRouter(s)))
 	}
@@ -132,15 +141,34 @@ func registerSettingsHandler(ctx context.Context, mux *http.ServeMux, settingsRe
 	mux.Handle("/settings/", h)
 }
 
-// handel: /todo and /todo/
+// handel: /todo, /todo/, /todo-lists, /todo-lists/, /todo-tags, /todo-tags/, /todo-workflow and /todo-workflow/
 //
-// Needs: Auth
+// Needs: Auth, Installation (optional)
 func registerTodoHandler(ctx context.Context, mux *http.ServeMux, s *Server, authRepo auth.Repository) {
 	h := middleware.MiddlewareChain(
 		todoRouter(ctx, s).ServeHTTP,
+		OptionalInstallation(authRepo), // for the timezone of the local due dates
 		Auth(authRepo),
 	)
 
 	mux.Handle("/todo", h)
 	mux.Handle("/todo/", h)
+	mux.Handle("/todo-lists", h)
+	mux.Handle("/todo-lists/", h)
+	mux.Handle("/todo-tags", h)
+	mux.Handle("/todo-tags/", h)
+	mux.Handle("/todo-workflow", h)
+	mux.Handle("/todo-workflow/", h)
+}
+
+// handel: /admin/
+//
+// Needs: Auth
+func registerAdminHandler(ctx context.Context, mux *http.ServeMux, s *Server, authRepo auth.Repository) {
+	h := middleware.MiddlewareChain(
+		adminRouter(ctx, authRepo, s.NewPermRepository()).ServeHTTP,
+		Auth(authRepo),
+	)
+
+	mux.Handle("/admin/", http.StripPrefix("/admin", h))
 }
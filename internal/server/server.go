@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"net/http"
@@ -10,7 +11,10 @@ import (
 	"time"
 
 	"github.com/Nidal-Bakir/go-todo-backend/internal/appenv"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/blobstore"
 	"github.com/Nidal-Bakir/go-todo-backend/internal/database"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/auth/oauth/genericoidc"
+	"github.com/Nidal-Bakir/go-todo-backend/internal/feat/todo"
 	"github.com/Nidal-Bakir/go-todo-backend/internal/gateway"
 	"github.com/Nidal-Bakir/go-todo-backend/internal/l10n"
 	redisdb "github.com/Nidal-Bakir/go-todo-backend/internal/redis_db"
@@ -30,18 +34,45 @@ type Server struct {
 	rdb              *redis.Client
 	zlog             *zerolog.Logger
 	gatewaysProvider gateway.Provider
+	blobStore        blobstore.Store
 }
 
 func NewServer(ctx context.Context) *http.Server {
 
 	l10n.InitL10n("./l10n", []string{"en", "ar"}, ctx)
 
+	if err := genericoidc.RegisterProvidersFromEnv(ctx); err != nil {
+		zerolog.Ctx(ctx).Fatal().Err(err).Msg("can not register the generic oidc providers")
+	}
+
+	// the attachment routes respond with 503 without a blob store
+	blobStore, err := blobstore.NewStoreFromEnv()
+	if err != nil {
+		if !errors.Is(err, blobstore.ErrNotConfigured) {
+			zerolog.Ctx(ctx).Fatal().Err(err).Msg("can not create the blob store")
+		}
+		zerolog.Ctx(ctx).Warn().Msg("no blob store is configured, the todo attachments are disabled")
+	}
+
 	server := &Server{
 		port:             utils.Must(strconv.Atoi(serverPort)),
 		db:               database.NewConnection(ctx),
 		rdb:              redisdb.NewRedisClient(ctx),
 		zlog:             zerolog.Ctx(ctx),
 		gatewaysProvider: gateway.NewGatewaysProvider(ctx),
+		blobStore:        blobStore,
+	}
+
+	todo.NewReminderScheduler(server.db, server.gatewaysProvider).Start(ctx)
+
+	trashPurger, err := todo.NewTrashPurger(server.db)
+	if err != nil {
+		zerolog.Ctx(ctx).Fatal().Err(err).Msg("can not create the todo trash purger")
+	}
+	trashPurger.Start(ctx)
+
+	if server.blobStore != nil {
+		todo.NewAttachmentCleaner(server.db, server.blobStore).Start(ctx)
 	}
 
 	return &http.Server{
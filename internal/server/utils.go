@@ -5,6 +5,7 @@ import (
 	"errors"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/Nidal-Bakir/go-todo-backend/internal/appenv"
 	"github.com/Nidal-Bakir/go-todo-backend/internal/apperr"
@@ -43,6 +44,13 @@ func return400IfApp404IfNoResultErrOr500(err error) int {
 	return http.StatusInternalServerError
 }
 
+func return400IfApp403IfPermissionDenied404IfNoResultErrOr500(err error) int {
+	if errors.Is(err, apperr.ErrPermissionDenied) {
+		return http.StatusForbidden
+	}
+	return return400IfApp404IfNoResultErrOr500(err)
+}
+
 func readAuthorizationCookie(r *http.Request) (string, error) {
 	authorizationCookie, err := r.Cookie("Authorization")
 	if err != nil {
@@ -51,15 +59,36 @@ func readAuthorizationCookie(r *http.Request) (string, error) {
 	return authorizationCookie.Value, nil
 }
 
-func setAuthorizationCookie(w http.ResponseWriter, authorizationToken string) {
+const refreshTokenCookiePath = "/api/v1/auth/refresh"
+
+func readRefreshTokenCookie(r *http.Request) (string, error) {
+	refreshTokenCookie, err := r.Cookie("RefreshToken")
+	if err != nil {
+		return "", err
+	}
+	return refreshTokenCookie.Value, nil
+}
+
+// setAuthorizationCookie sets the access token cookie, and the refresh token
+// cookie which is only sent to the refresh endpoint.
+func setAuthorizationCookie(w http.ResponseWriter, tokens auth.SessionTokens) {
 	http.SetCookie(w, &http.Cookie{
 		Name:     "Authorization",
-		Value:    authorizationToken,
+		Value:    tokens.AccessToken,
 		HttpOnly: true,
 		Secure:   appenv.IsProdOrStag(),
 		SameSite: http.SameSiteLaxMode,
 		Path:     "/",
-		MaxAge:   int(auth.AuthTokenExpDuration.Seconds()),
+		MaxAge:   int(auth.AccessTokenExpDuration.Seconds()),
+	})
+	http.SetCookie(w, &http.Cookie{
+		Name:     "RefreshToken",
+		Value:    tokens.RefreshToken,
+		HttpOnly: true,
+		Secure:   appenv.IsProdOrStag(),
+		SameSite: http.SameSiteStrictMode,
+		Path:     refreshTokenCookiePath,
+		MaxAge:   int(time.Until(tokens.RefreshTokenExpiresAt).Seconds()),
 	})
 }
 
@@ -72,6 +101,14 @@ func removeAuthorizationCookie(w http.ResponseWriter) {
 		Path:     "/",
 		MaxAge:   -1,
 	})
+	http.SetCookie(w, &http.Cookie{
+		Name:     "RefreshToken",
+		HttpOnly: true,
+		Secure:   appenv.IsProdOrStag(),
+		SameSite: http.SameSiteStrictMode,
+		Path:     refreshTokenCookiePath,
+		MaxAge:   -1,
+	})
 }
 
 func readInstallationCookie(r *http.Request) (string, error) {
@@ -90,7 +127,7 @@ func setInstallationCookie(w http.ResponseWriter, installationToken string) {
 		Secure:   appenv.IsProdOrStag(),
 		SameSite: http.SameSiteLaxMode,
 		Path:     "/",
-		MaxAge:   int(auth.AuthTokenExpDuration.Seconds()),
+		MaxAge:   int(auth.InstallationTokenExpDuration.Seconds()),
 	})
 }
 
@@ -1,10 +1,14 @@
 package dbutils
 
 import (
+	"context"
 	"errors"
+	"strings"
 	"time"
 
+	"github.com/Nidal-Bakir/go-todo-backend/internal/database/database_queries"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/redis/go-redis/v9"
 )
@@ -13,6 +17,12 @@ func IsErrPgxNoRows(err error) bool {
 	return errors.Is(err, pgx.ErrNoRows)
 }
 
+// IsErrPgxUniqueViolation reports whether the err is a violation of a unique constraint or index
+func IsErrPgxUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
 func IsErrRedisNilNoRows(err error) bool {
 	return errors.Is(err, redis.Nil)
 }
@@ -35,3 +45,58 @@ func ToPgTypeInt4(num *int32) pgtype.Int4 {
 	}
 	return pgtype.Int4{Int32: int32(*num), Valid: true}
 }
+
+var likePatternEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// EscapeLikePattern escapes the wildcards of the (I)LIKE patterns in str, so it matches
+// literally in the queries with ESCAPE '\'
+func EscapeLikePattern(str string) string {
+	return likePatternEscaper.Replace(str)
+}
+
+// PtrToPgTypeText returns NULL for a nil str, unlike ToPgTypeText the empty string is a value
+func PtrToPgTypeText(str *string) pgtype.Text {
+	if str == nil {
+		return pgtype.Text{}
+	}
+	return pgtype.Text{String: *str, Valid: true}
+}
+
+func PtrToPgTypeInt4(num *int) pgtype.Int4 {
+	if num == nil {
+		return pgtype.Int4{}
+	}
+	return pgtype.Int4{Int32: int32(*num), Valid: true}
+}
+
+func PtrToPgTypeTimestamptz(t *time.Time) pgtype.Timestamptz {
+	if t == nil {
+		return pgtype.Timestamptz{}
+	}
+	return pgtype.Timestamptz{Time: *t, Valid: true}
+}
+
+// TxBeginner is a connection pool, or a transaction where Begin starts a savepoint
+type TxBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// UsingTransaction runs fn with the queries in a transaction of db. The transaction is
+// rolled back if fn returns an error or the ctx is done, and committed otherwise
+func UsingTransaction(ctx context.Context, db TxBeginner, queries *database_queries.Queries, fn func(queries *database_queries.Queries) error) (err error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil || ctx.Err() != nil {
+			rollBackErr := tx.Rollback(ctx)
+			err = errors.Join(rollBackErr, ctx.Err(), err)
+		} else {
+			err = tx.Commit(ctx)
+		}
+	}()
+
+	return fn(queries.WithTx(tx))
+}
@@ -0,0 +1,24 @@
+package dbutils
+
+import "testing"
+
+func TestEscapeLikePattern(t *testing.T) {
+	tests := []struct {
+		str  string
+		want string
+	}{
+		{str: "", want: ""},
+		{str: "john", want: "john"},
+		{str: "100%", want: `100\%`},
+		{str: "john_doe", want: `john\_doe`},
+		{str: `a\b`, want: `a\\b`},
+		// the added escape chars are not escaped again
+		{str: `\%_`, want: `\\\%\_`},
+	}
+
+	for _, tt := range tests {
+		if got := EscapeLikePattern(tt.str); got != tt.want {
+			t.Errorf("EscapeLikePattern(%q) = %q, want %q", tt.str, got, tt.want)
+		}
+	}
+}
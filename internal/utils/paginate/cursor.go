@@ -0,0 +1,92 @@
+package paginate
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+
+	"github.com/Nidal-Bakir/go-todo-backend/internal/apperr"
+)
+
+// CursorPaginatedActionFn receives nil cursor for the first page
+type CursorPaginatedActionFn[T, C any] = func(cursor *C, limit int) ([]T, error)
+
+// CursorPaginatedAction is a keyset pagination, the cursor C holds the sort keys
+// of the last returned item (e.g. created_at and id), and it is sent to the clients
+// as an opaque string. Unlike the offset pagination it does not skip or duplicate
+// rows when new rows are inserted while the client is paging.
+// It only goes forward, so there is no prev link.
+type CursorPaginatedAction[T, C any] struct {
+	Action   CursorPaginatedActionFn[T, C]
+	CursorOf func(e T) C
+}
+
+func NewCursorPaginatedAction[T, C any](action CursorPaginatedActionFn[T, C], cursorOf func(e T) C) *CursorPaginatedAction[T, C] {
+	return &CursorPaginatedAction[T, C]{Action: action, CursorOf: cursorOf}
+}
+
+// IsCursorRequest reports whether the client asked for the cursor pagination.
+// The first page is requested with an empty cursor e.g. ?cursor=
+func IsCursorRequest(r *http.Request) bool {
+	return r.URL.Query().Has("cursor")
+}
+
+func (a *CursorPaginatedAction[T, C]) Exec(r *http.Request) (*PaginatedData[T], error) {
+	paginatedData := &PaginatedData[T]{}
+
+	perPage := validatePerPageParam(r)
+
+	cursor, err := decodeCursor[C](r.FormValue("cursor"))
+	if err != nil {
+		return paginatedData, err
+	}
+
+	data, err := a.Action(cursor, perPage+1)
+	if err != nil {
+		return paginatedData, err
+	}
+
+	requestFullPath, err := constructFullPath(r)
+	if err != nil {
+		return paginatedData, err
+	}
+
+	if len(data) == perPage+1 {
+		paginatedData.Data = data[:perPage]
+
+		nextCursor, err := encodeCursor(a.CursorOf(data[perPage-1]))
+		if err != nil {
+			return paginatedData, err
+		}
+		paginatedData.setNextCursor(requestFullPath, r.URL.Query(), nextCursor, perPage)
+	} else {
+		paginatedData.Data = data
+	}
+
+	return paginatedData, nil
+}
+
+func encodeCursor[C any](cursor C) (string, error) {
+	b, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func decodeCursor[C any](cursorStr string) (*C, error) {
+	if len(cursorStr) == 0 {
+		return nil, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(cursorStr)
+	if err != nil {
+		return nil, apperr.ErrInvalidCursor
+	}
+
+	cursor := new(C)
+	if err := json.Unmarshal(b, cursor); err != nil {
+		return nil, apperr.ErrInvalidCursor
+	}
+	return cursor, nil
+}
@@ -0,0 +1,212 @@
+package paginate
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Nidal-Bakir/go-todo-backend/internal/apperr"
+)
+
+// the same shape as the keysets of the todos
+type testCursor struct {
+	CreatedAt time.Time `json:"c"`
+	Id        int       `json:"i"`
+}
+
+type testItem struct {
+	CreatedAt time.Time
+	Id        int
+}
+
+func testCursorOf(e testItem) testCursor {
+	return testCursor{CreatedAt: e.CreatedAt, Id: e.Id}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []testCursor{
+		{CreatedAt: time.Date(2026, time.January, 2, 15, 4, 5, 123456789, time.UTC), Id: 1},
+		{CreatedAt: time.Date(2026, time.March, 29, 1, 30, 0, 0, time.FixedZone("+03", 3*60*60)), Id: 42},
+		{CreatedAt: time.Time{}, Id: 0},
+	}
+
+	for _, want := range tests {
+		encoded, err := encodeCursor(want)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := decodeCursor[testCursor](encoded)
+		if err != nil {
+			t.Fatalf("decodeCursor(%q): %v", encoded, err)
+		}
+		if got == nil || !got.CreatedAt.Equal(want.CreatedAt) || got.Id != want.Id {
+			t.Errorf("decodeCursor(encodeCursor(%+v)) = %+v", want, got)
+		}
+	}
+}
+
+func TestDecodeCursorEmpty(t *testing.T) {
+	got, err := decodeCursor[testCursor]("")
+	if err != nil || got != nil {
+		t.Errorf("decodeCursor(\"\") = %+v, %v, want the first page (nil cursor)", got, err)
+	}
+}
+
+func TestDecodeInvalidCursor(t *testing.T) {
+	valid, err := encodeCursor(testCursor{CreatedAt: time.Date(2026, time.January, 2, 15, 4, 5, 0, time.UTC), Id: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b64 := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{name: "not base64", cursor: "not a cursor!"},
+		{name: "padded std base64", cursor: base64.StdEncoding.EncodeToString([]byte(`{"c":"2026-01-02T15:04:05Z","i":7}`)) + "="},
+		{name: "truncated", cursor: valid[:len(valid)-4]},
+		{name: "not json", cursor: b64("created_at=2026-01-02")},
+		{name: "tampered time", cursor: b64(`{"c":"yesterday","i":7}`)},
+		{name: "tampered id", cursor: b64(`{"c":"2026-01-02T15:04:05Z","i":"7 OR 1=1"}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decodeCursor[testCursor](tt.cursor)
+			if !errors.Is(err, apperr.ErrInvalidCursor) {
+				t.Errorf("decodeCursor(%q) = %v, want ErrInvalidCursor", tt.cursor, err)
+			}
+
+			called := false
+			action := NewCursorPaginatedAction(
+				func(cursor *testCursor, limit int) ([]testItem, error) {
+					called = true
+					return nil, nil
+				},
+				testCursorOf,
+			)
+			r := httptest.NewRequest("GET", "/todo?cursor="+url.QueryEscape(tt.cursor), nil)
+			if _, err := action.Exec(r); !errors.Is(err, apperr.ErrInvalidCursor) {
+				t.Errorf("Exec = %v, want ErrInvalidCursor", err)
+			}
+			if called {
+				t.Error("the action should not run with an invalid cursor")
+			}
+		})
+	}
+}
+
+func TestCursorPaginatedActionExec(t *testing.T) {
+	start := time.Date(2026, time.January, 2, 15, 4, 5, 0, time.UTC)
+	items := make([]testItem, 5)
+	for i := range items {
+		items[i] = testItem{CreatedAt: start.Add(time.Duration(i) * time.Minute), Id: i + 1}
+	}
+
+	// returns the items after the cursor, like the keyset queries
+	var gotLimits []int
+	action := NewCursorPaginatedAction(
+		func(cursor *testCursor, limit int) ([]testItem, error) {
+			gotLimits = append(gotLimits, limit)
+			rest := items
+			if cursor != nil {
+				i := slices.IndexFunc(items, func(e testItem) bool { return e.Id == cursor.Id })
+				rest = items[i+1:]
+			}
+			return rest[:min(limit, len(rest))], nil
+		},
+		testCursorOf,
+	)
+
+	tests := []struct {
+		name       string
+		target     string
+		wantIds    []int
+		wantNext   bool
+		wantCursor *testCursor
+	}{
+		{
+			name:       "first page",
+			target:     "/todo?cursor=&status=done&tag_id=1&tag_id=2&per_page=2",
+			wantIds:    []int{1, 2},
+			wantNext:   true,
+			wantCursor: &testCursor{CreatedAt: items[1].CreatedAt, Id: 2},
+		},
+		{
+			name:     "last page",
+			target:   "/todo?cursor=&status=done&per_page=5",
+			wantIds:  []int{1, 2, 3, 4, 5},
+			wantNext: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotLimits = nil
+			r := httptest.NewRequest("GET", tt.target, nil)
+
+			res, err := action.Exec(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ids := make([]int, len(res.Data))
+			for i, e := range res.Data {
+				ids[i] = e.Id
+			}
+			if !slices.Equal(ids, tt.wantIds) {
+				t.Errorf("got the items %v, want %v", ids, tt.wantIds)
+			}
+			// one more item tells if there is a next page
+			if want := len(tt.wantIds) + 1; !slices.Equal(gotLimits, []int{want}) {
+				t.Errorf("got the limits %v, want [%d]", gotLimits, want)
+			}
+
+			if !tt.wantNext {
+				if res.Next != "" || res.NextCursor != "" {
+					t.Errorf("got the next link %q and cursor %q on the last page", res.Next, res.NextCursor)
+				}
+				if res.Prev != "" {
+					t.Errorf("got the prev link %q, the cursor pagination only goes forward", res.Prev)
+				}
+				return
+			}
+
+			next, err := url.Parse(res.Next)
+			if err != nil {
+				t.Fatal(err)
+			}
+			query := next.Query()
+			// the filters are kept
+			if query.Get("status") != "done" || !slices.Equal(query["tag_id"], []string{"1", "2"}) || query.Get("per_page") != "2" {
+				t.Errorf("the next link %q does not keep the query params", res.Next)
+			}
+			if query.Get("cursor") != res.NextCursor {
+				t.Errorf("the cursor of the next link %q is not the next_cursor %q", query.Get("cursor"), res.NextCursor)
+			}
+
+			cursor, err := decodeCursor[testCursor](res.NextCursor)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !cursor.CreatedAt.Equal(tt.wantCursor.CreatedAt) || cursor.Id != tt.wantCursor.Id {
+				t.Errorf("got the next cursor %+v, want %+v", cursor, tt.wantCursor)
+			}
+
+			// following the next link continues after the last item
+			res, err = action.Exec(httptest.NewRequest("GET", next.RequestURI(), nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(res.Data) == 0 || res.Data[0].Id != tt.wantCursor.Id+1 {
+				t.Errorf("the next page starts with %+v, want the item %d", res.Data, tt.wantCursor.Id+1)
+			}
+		})
+	}
+}
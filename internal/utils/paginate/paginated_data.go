@@ -11,6 +11,8 @@ type PaginatedData[T any] struct {
 	Data []T    `json:"data"`
 	Next string `json:"next,omitzero"`
 	Prev string `json:"prev,omitzero"`
+	// only used by the cursor pagination
+	NextCursor string `json:"next_cursor,omitzero"`
 }
 
 func (d *PaginatedData[T]) setNext(path string, requestQueryParam url.Values, page, perPage int) {
@@ -30,10 +32,19 @@ func (d *PaginatedData[T]) setPrev(path string, requestQueryParam url.Values, pa
 	d.Prev = fmt.Sprintf("%s?%s", path, param.Encode())
 }
 
+func (d *PaginatedData[T]) setNextCursor(path string, requestQueryParam url.Values, cursor string, perPage int) {
+	param := maps.Clone(requestQueryParam)
+	param.Set("cursor", cursor)
+	param.Set("per_page", strconv.Itoa(perPage))
+	d.NextCursor = cursor
+	d.Next = fmt.Sprintf("%s?%s", path, param.Encode())
+}
+
 func PaginatedDataMapper[T, R any](d *PaginatedData[T], mapper func(e T) R) *PaginatedData[R] {
 	paginatedData := &PaginatedData[R]{}
 	paginatedData.Next = d.Next
 	paginatedData.Prev = d.Prev
+	paginatedData.NextCursor = d.NextCursor
 
 	data := make([]R, len(d.Data))
 	for i, e := range d.Data {
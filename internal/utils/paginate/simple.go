@@ -46,18 +46,23 @@ func (a *SimplePaginatedAction[T]) Exec(r *http.Request) (*PaginatedData[T], err
 }
 
 func (a *SimplePaginatedAction[T]) validatePaginationParam(r *http.Request) (page, perPage int) {
-	convToInt := func(strNum string) int {
-		n, err := strconv.Atoi(strNum)
-		if err != nil {
-			return 0
-		}
-		return n
-	}
 	page = convToInt(r.FormValue("page"))
-	perPage = utils.Clamp(convToInt(r.FormValue("per_page")), 1, 40)
+	perPage = validatePerPageParam(r)
 	return page, perPage
 }
 
+func validatePerPageParam(r *http.Request) int {
+	return utils.Clamp(convToInt(r.FormValue("per_page")), 1, 40)
+}
+
+func convToInt(strNum string) int {
+	n, err := strconv.Atoi(strNum)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
 func constructFullPath(r *http.Request) (string, error) {
 	scheme := "http"
 	if r.TLS != nil {
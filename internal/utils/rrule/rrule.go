@@ -0,0 +1,312 @@
+package rrule
+
+// A subset of the RFC 5545 recurrence rules (RRULE) used by the recurring todos:
+//
+//	FREQ=DAILY;INTERVAL=2
+//	FREQ=WEEKLY;BYDAY=MO,WE,FR
+//	FREQ=MONTHLY;BYMONTHDAY=-1;COUNT=12
+//	FREQ=YEARLY;UNTIL=20301231T235959Z
+//
+// The occurrences are expanded in a timezone (IANA location), so they keep
+// their wall clock time across the daylight saving time transitions.
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Nidal-Bakir/go-todo-backend/internal/apperr"
+)
+
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+const (
+	untilLayout = "20060102T150405Z"
+	maxInterval = 1000
+	// the max number of the periods (days, weeks, months or years) to expand
+	// while looking for the next occurrence, to not loop forever on a rule like
+	// FREQ=YEARLY starting on Feb 29 with INTERVAL=100 (every 100 years on Feb 29)
+	maxPeriods = 100_000
+)
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+type Rule struct {
+	Freq Frequency
+	// every Interval days, weeks, months or years
+	Interval int
+	// the days of the week, only with Weekly. Defaults to the weekday of the start
+	ByDay []time.Weekday
+	// the day of the month, only with Monthly. Defaults to the day of the start.
+	// The negative values count from the end of the month (-1 is the last day).
+	// The months that do not have the day are skipped (e.g. the 31st)
+	ByMonthDay int
+	// zero for no limit, and only one of Until and Count can be set
+	Until time.Time
+	// the number of the occurrences including the start, zero for no limit
+	Count int
+}
+
+// Parse parses and validates the rule, the "RRULE:" prefix is optional
+func Parse(str string) (Rule, error) {
+	str = strings.TrimPrefix(strings.TrimSpace(str), "RRULE:")
+	if len(str) == 0 {
+		return Rule{}, apperr.ErrInvalidRecurrenceRule
+	}
+
+	rule := Rule{Interval: 1}
+	seen := make(map[string]bool)
+
+	for _, part := range strings.Split(str, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		key = strings.ToUpper(key)
+		if !ok || len(value) == 0 || seen[key] {
+			return Rule{}, apperr.ErrInvalidRecurrenceRule
+		}
+		seen[key] = true
+
+		var err error
+		switch key {
+		case "FREQ":
+			rule.Freq = Frequency(strings.ToUpper(value))
+			if !slices.Contains([]Frequency{Daily, Weekly, Monthly, Yearly}, rule.Freq) {
+				return Rule{}, apperr.ErrInvalidRecurrenceRule
+			}
+
+		case "INTERVAL":
+			rule.Interval, err = strconv.Atoi(value)
+			if err != nil || rule.Interval < 1 || rule.Interval > maxInterval {
+				return Rule{}, apperr.ErrInvalidRecurrenceRule
+			}
+
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				weekday, ok := weekdays[strings.ToUpper(day)]
+				if !ok {
+					return Rule{}, apperr.ErrInvalidRecurrenceRule
+				}
+				if !slices.Contains(rule.ByDay, weekday) {
+					rule.ByDay = append(rule.ByDay, weekday)
+				}
+			}
+			// in the order of the week, that starts on Monday
+			slices.SortFunc(rule.ByDay, func(a, b time.Weekday) int {
+				return weekdayIndex(a) - weekdayIndex(b)
+			})
+
+		case "BYMONTHDAY":
+			rule.ByMonthDay, err = strconv.Atoi(value)
+			if err != nil || rule.ByMonthDay == 0 || rule.ByMonthDay < -31 || rule.ByMonthDay > 31 {
+				return Rule{}, apperr.ErrInvalidRecurrenceRule
+			}
+
+		case "COUNT":
+			rule.Count, err = strconv.Atoi(value)
+			if err != nil || rule.Count < 1 {
+				return Rule{}, apperr.ErrInvalidRecurrenceRule
+			}
+
+		case "UNTIL":
+			rule.Until, err = time.Parse(untilLayout, strings.ToUpper(value))
+			if err != nil {
+				return Rule{}, apperr.ErrInvalidRecurrenceRule
+			}
+
+		default:
+			return Rule{}, apperr.ErrInvalidRecurrenceRule
+		}
+	}
+
+	if len(rule.Freq) == 0 ||
+		(len(rule.ByDay) != 0 && rule.Freq != Weekly) ||
+		(rule.ByMonthDay != 0 && rule.Freq != Monthly) ||
+		(rule.Count != 0 && !rule.Until.IsZero()) {
+		return Rule{}, apperr.ErrInvalidRecurrenceRule
+	}
+
+	return rule, nil
+}
+
+// String returns the rule in its canonical form, that is stored in the database
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) != 0 {
+		days := make([]string, len(r.ByDay))
+		for i, weekday := range r.ByDay {
+			for name, d := range weekdays {
+				if d == weekday {
+					days[i] = name
+				}
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if r.ByMonthDay != 0 {
+		parts = append(parts, "BYMONTHDAY="+strconv.Itoa(r.ByMonthDay))
+	}
+	if r.Count != 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format(untilLayout))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Next returns the first occurrence of the rule that is strictly after the given time.
+// The start is always the first occurrence, and the wall clock time of the
+// occurrences is the wall clock time of the start in the loc.
+// It returns false when the rule ended (Count or Until).
+func (r Rule) Next(start time.Time, loc *time.Location, after time.Time) (time.Time, bool) {
+	start = start.In(loc)
+
+	count := 1
+	if start.After(after) {
+		return start, r.withinUntil(start)
+	}
+
+	interval := max(r.Interval, 1)
+
+	for period := 0; period < maxPeriods; period += interval {
+		for _, occurrence := range r.occurrencesOfPeriod(start, loc, period) {
+			// the start is already counted, and the ones before it are not part of the set
+			if !occurrence.After(start) {
+				continue
+			}
+
+			count++
+			if (r.Count != 0 && count > r.Count) || !r.withinUntil(occurrence) {
+				return time.Time{}, false
+			}
+			if occurrence.After(after) {
+				return occurrence, true
+			}
+		}
+	}
+
+	return time.Time{}, false
+}
+
+func (r Rule) withinUntil(t time.Time) bool {
+	return r.Until.IsZero() || !t.After(r.Until)
+}
+
+// occurrencesOfPeriod returns the sorted occurrences in the day, the week,
+// the month or the year that is the given number of periods after the start
+func (r Rule) occurrencesOfPeriod(start time.Time, loc *time.Location, period int) []time.Time {
+	year, month, day := start.Date()
+	hour, minute, sec := start.Clock()
+
+	at := func(year int, month time.Month, day int) time.Time {
+		return localTime(year, month, day, hour, minute, sec, loc)
+	}
+
+	switch r.Freq {
+	case Daily:
+		return []time.Time{at(year, month, day+period)}
+
+	case Weekly:
+		byDay := r.ByDay
+		if len(byDay) == 0 {
+			byDay = []time.Weekday{start.Weekday()}
+		}
+		// the weeks start on Monday
+		weekStart := day - weekdayIndex(start.Weekday()) + period*7
+		occurrences := make([]time.Time, len(byDay))
+		for i, weekday := range byDay {
+			occurrences[i] = at(year, month, weekStart+weekdayIndex(weekday))
+		}
+		return occurrences
+
+	case Monthly:
+		monthYear, periodMonth := addMonths(year, month, period)
+		monthDay := r.ByMonthDay
+		if monthDay == 0 {
+			monthDay = day
+		}
+		daysInMonth := daysIn(monthYear, periodMonth)
+		if monthDay < 0 {
+			monthDay = daysInMonth + monthDay + 1
+		}
+		if monthDay < 1 || monthDay > daysInMonth {
+			return nil
+		}
+		return []time.Time{at(monthYear, periodMonth, monthDay)}
+
+	case Yearly:
+		// e.g. Feb 29 only happens in the leap years
+		if day > daysIn(year+period, month) {
+			return nil
+		}
+		return []time.Time{at(year+period, month, day)}
+	}
+
+	return nil
+}
+
+// localTime is time.Date with the RFC 5545 rules for the daylight saving time
+// transitions, that time.Date does not guarantee: a wall clock time in a gap
+// is moved forward by the length of the gap (e.g. 02:30 -> 03:30), and a wall
+// clock time that happens twice is resolved to the first one.
+func localTime(year int, month time.Month, day, hour, minute, sec int, loc *time.Location) time.Time {
+	// the wall clock time as if it was in UTC
+	wall := time.Date(year, month, day, hour, minute, sec, 0, time.UTC)
+
+	// the zones are assumed to not change their offsets twice in two days
+	_, offsetBefore := wall.Add(-24 * time.Hour).In(loc).Zone()
+	_, offsetAfter := wall.Add(24 * time.Hour).In(loc).Zone()
+
+	var found []time.Time
+	for _, offset := range []int{offsetBefore, offsetAfter} {
+		t := wall.Add(-time.Duration(offset) * time.Second)
+		if _, o := t.In(loc).Zone(); o == offset {
+			found = append(found, t)
+		}
+	}
+
+	if len(found) == 0 {
+		// in the gap, with the offset before the gap
+		return wall.Add(-time.Duration(offsetBefore) * time.Second).In(loc)
+	}
+
+	first := found[0]
+	for _, t := range found[1:] {
+		if t.Before(first) {
+			first = t
+		}
+	}
+	return first.In(loc)
+}
+
+func weekdayIndex(weekday time.Weekday) int {
+	return (int(weekday) + 6) % 7
+}
+
+func addMonths(year int, month time.Month, months int) (int, time.Month) {
+	index := year*12 + int(month) - 1 + months
+	return index / 12, time.Month(index%12 + 1)
+}
+
+func daysIn(year int, month time.Month) int {
+	// the day 0 of the next month is the last day of the month
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
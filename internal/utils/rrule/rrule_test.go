@@ -0,0 +1,187 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestNext(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	date := func(loc *time.Location, year int, month time.Month, day, hour, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, loc)
+	}
+
+	tests := []struct {
+		name  string
+		rule  string
+		start time.Time
+		loc   *time.Location
+		// the occurrences in their order, the first one is the start
+		want []time.Time
+		// the rule has no occurrences after the last wanted one
+		ended bool
+	}{
+		{
+			name:  "DST gap moves the wall clock time forward",
+			rule:  "FREQ=DAILY",
+			start: date(newYork, 2024, time.March, 9, 2, 30),
+			loc:   newYork,
+			want: []time.Time{
+				date(newYork, 2024, time.March, 9, 2, 30),
+				// 02:30 does not exist on Mar 10 in New York
+				time.Date(2024, time.March, 10, 7, 30, 0, 0, time.UTC),
+				date(newYork, 2024, time.March, 11, 2, 30),
+			},
+		},
+		{
+			name:  "DST overlap resolves to the first occurrence",
+			rule:  "FREQ=DAILY",
+			start: date(newYork, 2024, time.November, 2, 1, 30),
+			loc:   newYork,
+			want: []time.Time{
+				date(newYork, 2024, time.November, 2, 1, 30),
+				// 01:30 EDT, not 01:30 EST
+				time.Date(2024, time.November, 3, 5, 30, 0, 0, time.UTC),
+				date(newYork, 2024, time.November, 4, 1, 30),
+			},
+		},
+		{
+			name:  "monthly from Jan 31 skips the shorter months",
+			rule:  "FREQ=MONTHLY",
+			start: date(time.UTC, 2024, time.January, 31, 9, 0),
+			loc:   time.UTC,
+			want: []time.Time{
+				date(time.UTC, 2024, time.January, 31, 9, 0),
+				date(time.UTC, 2024, time.March, 31, 9, 0),
+				date(time.UTC, 2024, time.May, 31, 9, 0),
+			},
+		},
+		{
+			name:  "the last day of the month in February",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=-1",
+			start: date(time.UTC, 2024, time.January, 31, 9, 0),
+			loc:   time.UTC,
+			want: []time.Time{
+				date(time.UTC, 2024, time.January, 31, 9, 0),
+				date(time.UTC, 2024, time.February, 29, 9, 0),
+				date(time.UTC, 2024, time.March, 31, 9, 0),
+			},
+		},
+		{
+			name:  "the last day of February in a non leap year",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=-1",
+			start: date(time.UTC, 2025, time.January, 31, 9, 0),
+			loc:   time.UTC,
+			want: []time.Time{
+				date(time.UTC, 2025, time.January, 31, 9, 0),
+				date(time.UTC, 2025, time.February, 28, 9, 0),
+			},
+		},
+		{
+			name:  "yearly from Feb 29 only happens in the leap years",
+			rule:  "FREQ=YEARLY",
+			start: date(time.UTC, 2024, time.February, 29, 9, 0),
+			loc:   time.UTC,
+			want: []time.Time{
+				date(time.UTC, 2024, time.February, 29, 9, 0),
+				date(time.UTC, 2028, time.February, 29, 9, 0),
+				date(time.UTC, 2032, time.February, 29, 9, 0),
+			},
+		},
+		{
+			name:  "weekly by day with count",
+			rule:  "FREQ=WEEKLY;BYDAY=WE,MO;COUNT=4",
+			start: date(newYork, 2024, time.January, 1, 8, 0),
+			loc:   newYork,
+			want: []time.Time{
+				date(newYork, 2024, time.January, 1, 8, 0),
+				date(newYork, 2024, time.January, 3, 8, 0),
+				date(newYork, 2024, time.January, 8, 8, 0),
+				date(newYork, 2024, time.January, 10, 8, 0),
+			},
+			ended: true,
+		},
+		{
+			name:  "until is inclusive",
+			rule:  "FREQ=DAILY;UNTIL=20240103T090000Z",
+			start: date(time.UTC, 2024, time.January, 1, 9, 0),
+			loc:   time.UTC,
+			want: []time.Time{
+				date(time.UTC, 2024, time.January, 1, 9, 0),
+				date(time.UTC, 2024, time.January, 2, 9, 0),
+				date(time.UTC, 2024, time.January, 3, 9, 0),
+			},
+			ended: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.rule, err)
+			}
+
+			after := tt.start.Add(-time.Second)
+			for i, want := range tt.want {
+				got, ok := rule.Next(tt.start, tt.loc, after)
+				if !ok {
+					t.Fatalf("occurrence %d: the rule ended, want %v", i, want)
+				}
+				if !got.Equal(want) {
+					t.Fatalf("occurrence %d: got %v, want %v", i, got, want)
+				}
+				after = got
+			}
+
+			got, ok := rule.Next(tt.start, tt.loc, after)
+			if tt.ended && ok {
+				t.Fatalf("the rule should have ended, got %v", got)
+			}
+			if !tt.ended && !ok {
+				t.Fatalf("the rule should not have ended")
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		rule    string
+		want    string
+		wantErr bool
+	}{
+		{rule: "RRULE:FREQ=weekly;BYDAY=FR,MO", want: "FREQ=WEEKLY;BYDAY=MO,FR"},
+		{rule: "FREQ=MONTHLY;INTERVAL=2;BYMONTHDAY=-1", want: "FREQ=MONTHLY;INTERVAL=2;BYMONTHDAY=-1"},
+		{rule: "FREQ=DAILY;UNTIL=20301231T235959Z", want: "FREQ=DAILY;UNTIL=20301231T235959Z"},
+		{rule: "", wantErr: true},
+		{rule: "FREQ=HOURLY", wantErr: true},
+		{rule: "FREQ=DAILY;BYDAY=MO", wantErr: true},
+		{rule: "FREQ=WEEKLY;BYMONTHDAY=1", wantErr: true},
+		{rule: "FREQ=DAILY;COUNT=2;UNTIL=20301231T235959Z", wantErr: true},
+		{rule: "FREQ=DAILY;FREQ=WEEKLY", wantErr: true},
+		{rule: "FREQ=MONTHLY;BYMONTHDAY=32", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		rule, err := Parse(tt.rule)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Parse(%q) should fail", tt.rule)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%q) error: %v", tt.rule, err)
+			continue
+		}
+		if got := rule.String(); got != tt.want {
+			t.Errorf("Parse(%q).String() = %q, want %q", tt.rule, got, tt.want)
+		}
+	}
+}
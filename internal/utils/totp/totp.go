@@ -0,0 +1,126 @@
+package totp
+
+// TOTP (RFC 6238) with the defaults used by all the authenticator apps:
+// HMAC-SHA1, 6 digits and a 30 seconds time step.
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	Digits    = 6
+	Period    = 30 * time.Second
+	Algorithm = "SHA1"
+
+	secretSize = 20 // 160 bits as recommended by RFC 4226
+	// number of time steps accepted before and after the current one
+	// to tolerate clock drift between the server and the device
+	allowedSkew = 1
+)
+
+var b32NoPadding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+func GenerateSecret() (string, error) {
+	b := make([]byte, secretSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return b32NoPadding.EncodeToString(b), nil
+}
+
+// KeyURI returns the otpauth:// uri that can be rendered as a QR code for the authenticator apps
+func KeyURI(issuer, accountName, secret string) string {
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(accountName)
+
+	q := url.Values{}
+	q.Set("secret", secret)
+	q.Set("issuer", issuer)
+	q.Set("algorithm", Algorithm)
+	q.Set("digits", fmt.Sprint(Digits))
+	q.Set("period", fmt.Sprint(int(Period.Seconds())))
+
+	return "otpauth://totp/" + label + "?" + q.Encode()
+}
+
+func GenerateCode(secret string, t time.Time) (string, error) {
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return generateCode(key, counterAt(t)), nil
+}
+
+// Validate checks the code against the current time step and its neighbors
+func Validate(code, secret string) (bool, error) {
+	return ValidateAt(code, secret, time.Now())
+}
+
+func ValidateAt(code, secret string, t time.Time) (bool, error) {
+	_, ok, err := ValidateStepAt(code, secret, t)
+	return ok, err
+}
+
+// ValidateStep is Validate that also returns the matched time step,
+// to accept every code only once
+func ValidateStep(code, secret string) (step uint64, ok bool, err error) {
+	return ValidateStepAt(code, secret, time.Now())
+}
+
+func ValidateStepAt(code, secret string, t time.Time) (step uint64, ok bool, err error) {
+	code = strings.TrimSpace(code)
+	if len(code) != Digits {
+		return 0, false, nil
+	}
+
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return 0, false, err
+	}
+
+	counter := counterAt(t)
+	for i := -allowedSkew; i <= allowedSkew; i++ {
+		step := uint64(int64(counter) + int64(i))
+		expected := generateCode(key, step)
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+func decodeSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.TrimRight(strings.TrimSpace(secret), "="))
+	return b32NoPadding.DecodeString(secret)
+}
+
+func counterAt(t time.Time) uint64 {
+	return uint64(t.Unix()) / uint64(Period.Seconds())
+}
+
+func generateCode(key []byte, counter uint64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	// dynamic truncation, RFC 4226 section 5.3
+	offset := sum[len(sum)-1] & 0x0f
+	bin := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for range Digits {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", Digits, bin%mod)
+}
@@ -0,0 +1,78 @@
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+// the SHA1 test vectors of RFC 6238 appendix B, truncated to 6 digits
+func TestGenerateCodeRfcVectors(t *testing.T) {
+	secret := b32NoPadding.EncodeToString([]byte("12345678901234567890"))
+
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+	}
+
+	for _, tt := range tests {
+		got, err := GenerateCode(secret, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("GenerateCode at %d = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidateStepAt(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Unix(1700000000, 0)
+	currentStep := counterAt(now)
+
+	tests := []struct {
+		name     string
+		codeAt   time.Time
+		wantStep uint64
+		wantOk   bool
+	}{
+		{name: "current step", codeAt: now, wantStep: currentStep, wantOk: true},
+		{name: "previous step", codeAt: now.Add(-Period), wantStep: currentStep - 1, wantOk: true},
+		{name: "next step", codeAt: now.Add(Period), wantStep: currentStep + 1, wantOk: true},
+		{name: "outside the skew", codeAt: now.Add(-2 * Period), wantOk: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := GenerateCode(secret, tt.codeAt)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			step, ok, err := ValidateStepAt(code, secret, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != tt.wantOk {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOk)
+			}
+			if ok && step != tt.wantStep {
+				t.Errorf("step = %d, want %d", step, tt.wantStep)
+			}
+		})
+	}
+
+	if _, ok, _ := ValidateStepAt("12345", secret, now); ok {
+		t.Error("a code with the wrong length should not be valid")
+	}
+}